@@ -200,8 +200,43 @@ func (api *EthereumAPI) NewPendingTransactionFilter() rpc.ID {
 
 // NewPendingTransactions creates a subscription that is triggered each time a transaction
 // enters the transaction pool and was signed from one of the transactions this nodes manages.
-func (api *EthereumAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
-	return api.publicFilterAPI.NewPendingTransactions(ctx)
+//
+// If fullTx is true, the full transactions are sent instead of the hashes. Only the
+// transactions matching the criteria are sent if it is given.
+func (api *EthereumAPI) NewPendingTransactions(ctx context.Context, fullTx *bool, crit *filters.PendingTxCriteria) (*rpc.Subscription, error) {
+	if fullTx == nil || !*fullTx {
+		return api.publicFilterAPI.NewPendingTransactions(ctx, fullTx, crit)
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		config := api.publicBlockChainAPI.b.ChainConfig()
+		pendingTxs := make(chan []*types.Transaction, 128)
+		pendingTxSub := api.publicFilterAPI.Events().SubscribeFullPendingTxs(pendingTxs)
+
+		for {
+			select {
+			case txs := <-pendingTxs:
+				for _, tx := range txs {
+					if crit.Matches(tx) {
+						notifier.Notify(rpcSub.ID, newEthRPCPendingTransaction(tx, config))
+					}
+				}
+			case <-rpcSub.Err():
+				pendingTxSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				pendingTxSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
 }
 
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
@@ -248,8 +283,9 @@ func (api *EthereumAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error)
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
-func (api *EthereumAPI) Logs(ctx context.Context, crit filters.FilterCriteria) (*rpc.Subscription, error) {
-	return api.publicFilterAPI.Logs(ctx, crit)
+// The historical logs are replayed if the fromBlock or the cursor is given.
+func (api *EthereumAPI) Logs(ctx context.Context, crit filters.FilterCriteria, cursor *filters.LogCursor) (*rpc.Subscription, error) {
+	return api.publicFilterAPI.Logs(ctx, crit, cursor)
 }
 
 // NewFilter creates a new filter and returns the filter id. It can be
@@ -645,11 +681,12 @@ func (api *EthereumAPI) GetStorageAt(ctx context.Context, address common.Address
 // OverrideAccount in go-ethereum has been renamed to EthOverrideAccount.
 // OverrideAccount is defined in go-ethereum's internal package, so OverrideAccount is redefined here as EthOverrideAccount.
 type EthOverrideAccount struct {
-	Nonce     *hexutil.Uint64              `json:"nonce"`
-	Code      *hexutil.Bytes               `json:"code"`
-	Balance   **hexutil.Big                `json:"balance"`
-	State     *map[common.Hash]common.Hash `json:"state"`
-	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
+	Nonce            *hexutil.Uint64              `json:"nonce"`
+	Code             *hexutil.Bytes               `json:"code"`
+	Balance          **hexutil.Big                `json:"balance"`
+	State            *map[common.Hash]common.Hash `json:"state"`
+	StateDiff        *map[common.Hash]common.Hash `json:"stateDiff"`
+	MovePrecompileTo *common.Address              `json:"movePrecompileToAddress"`
 }
 
 // EthStateOverride is the collection of overridden accounts.
@@ -657,11 +694,40 @@ type EthOverrideAccount struct {
 // StateOverride is defined in go-ethereum's internal package, so StateOverride is redefined here as EthStateOverride.
 type EthStateOverride map[common.Address]EthOverrideAccount
 
-func (diff *EthStateOverride) Apply(state *state.StateDB) error {
+// Apply overrides the fields of specified accounts into the given state.
+// If precompiles is not nil, the overridden precompiled contracts are removed
+// from it and the ones with movePrecompileToAddress are moved to the given
+// address. Moving precompiled contracts is not allowed if precompiles is nil.
+func (diff *EthStateOverride) Apply(state *state.StateDB, precompiles map[common.Address]vm.PrecompiledContract) error {
 	if diff == nil {
 		return nil
 	}
+	// Tracks the destinations of the moved precompiled contracts.
+	movedTo := make(map[common.Address]struct{})
 	for addr, account := range *diff {
+		// A precompiled contract moved to this address can't be overridden.
+		if _, ok := movedTo[addr]; ok {
+			return fmt.Errorf("account %s has already been overridden by a precompile", addr.Hex())
+		}
+		p, isPrecompile := precompiles[addr]
+		if account.MovePrecompileTo != nil {
+			if precompiles == nil {
+				return errors.New("movePrecompileToAddress is not supported")
+			}
+			if !isPrecompile {
+				return fmt.Errorf("account %s is not a precompile", addr.Hex())
+			}
+			// Refuse to move a precompiled contract to an address that has been
+			// or will be overridden.
+			if _, ok := (*diff)[*account.MovePrecompileTo]; ok {
+				return fmt.Errorf("account %s is already overridden", account.MovePrecompileTo.Hex())
+			}
+			precompiles[*account.MovePrecompileTo] = p
+			movedTo[*account.MovePrecompileTo] = struct{}{}
+		}
+		if isPrecompile {
+			delete(precompiles, addr)
+		}
 		// Override account nonce.
 		if account.Nonce != nil {
 			state.SetNonce(addr, uint64(*account.Nonce))
@@ -691,19 +757,86 @@ func (diff *EthStateOverride) Apply(state *state.StateDB) error {
 	return nil
 }
 
+// BlockOverrides is a set of header fields to override.
+// Coinbase is the address returned by COINBASE, which is the block proposer in
+// Kaia, and FeeRecipient is the rewardbase receiving the transaction fees.
+type BlockOverrides struct {
+	Number        *hexutil.Big            `json:"number"`
+	Time          *hexutil.Uint64         `json:"time"`
+	GasLimit      *hexutil.Uint64         `json:"gasLimit"`
+	Coinbase      *common.Address         `json:"coinbase"`
+	FeeRecipient  *common.Address         `json:"feeRecipient"`
+	BaseFeePerGas *hexutil.Big            `json:"baseFeePerGas"`
+	Random        *common.Hash            `json:"random"`
+	BlockHash     *map[uint64]common.Hash `json:"blockHash"`
+}
+
+// Apply overrides the given block context.
+func (diff *BlockOverrides) Apply(blockCtx *vm.BlockContext) {
+	if diff == nil {
+		return
+	}
+	if diff.Number != nil {
+		blockCtx.BlockNumber = new(big.Int).Set(diff.Number.ToInt())
+	}
+	if diff.Time != nil {
+		blockCtx.Time = new(big.Int).SetUint64(uint64(*diff.Time))
+	}
+	if diff.GasLimit != nil {
+		blockCtx.GasLimit = uint64(*diff.GasLimit)
+	}
+	if diff.Coinbase != nil {
+		blockCtx.Coinbase = *diff.Coinbase
+	}
+	if diff.FeeRecipient != nil {
+		blockCtx.Rewardbase = *diff.FeeRecipient
+	}
+	if diff.BaseFeePerGas != nil {
+		blockCtx.BaseFee = new(big.Int).Set(diff.BaseFeePerGas.ToInt())
+	}
+	if diff.Random != nil {
+		blockCtx.Random = *diff.Random
+	}
+	if diff.BlockHash != nil {
+		getHash := blockCtx.GetHash
+		blockCtx.GetHash = func(n uint64) common.Hash {
+			if hash, ok := (*diff.BlockHash)[n]; ok {
+				return hash
+			}
+			return getHash(n)
+		}
+	}
+}
+
+// newEVMWithBlockOverrides returns an EVM running on the block context of the
+// given EVM overridden by blockOverrides. The EVM is recreated so that the
+// chain rules follow the overridden block number.
+func newEVMWithBlockOverrides(evm *vm.EVM, blockOverrides *BlockOverrides) *vm.EVM {
+	if blockOverrides == nil {
+		return evm
+	}
+	blockCtx, txCtx := evm.Context, evm.TxContext
+	blockOverrides.Apply(&blockCtx)
+	// After magma hardfork, the effective gas price is the base fee.
+	if blockOverrides.BaseFeePerGas != nil && evm.ChainConfig().IsMagmaForkEnabled(blockCtx.BlockNumber) {
+		txCtx.GasPrice = new(big.Int).Set(blockCtx.BaseFee)
+	}
+	return vm.NewEVM(blockCtx, txCtx, evm.StateDB, evm.ChainConfig(), evm.Config)
+}
+
 // Call executes the given transaction on the state for the given block number.
 //
 // Additionally, the caller can specify a batch of contract for fields overriding.
 //
 // Note, this function doesn't make and changes in the state/blockchain and is
 // useful to execute and retrieve values.
-func (api *EthereumAPI) Call(ctx context.Context, args EthTransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *EthStateOverride) (hexutil.Bytes, error) {
+func (api *EthereumAPI) Call(ctx context.Context, args EthTransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *EthStateOverride, blockOverrides *BlockOverrides) (hexutil.Bytes, error) {
 	bcAPI := api.publicBlockChainAPI.b
 	gasCap := uint64(0)
 	if rpcGasCap := bcAPI.RPCGasCap(); rpcGasCap != nil {
 		gasCap = rpcGasCap.Uint64()
 	}
-	result, err := EthDoCall(ctx, bcAPI, args, blockNrOrHash, overrides, bcAPI.RPCEVMTimeout(), gasCap)
+	result, err := EthDoCall(ctx, bcAPI, args, blockNrOrHash, overrides, blockOverrides, bcAPI.RPCEVMTimeout(), gasCap)
 	if err != nil {
 		return nil, err
 	}
@@ -716,7 +849,7 @@ func (api *EthereumAPI) Call(ctx context.Context, args EthTransactionArgs, block
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
 // given transaction against the current pending block.
-func (api *EthereumAPI) EstimateGas(ctx context.Context, args EthTransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+func (api *EthereumAPI) EstimateGas(ctx context.Context, args EthTransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash, blockOverrides *BlockOverrides) (hexutil.Uint64, error) {
 	bcAPI := api.publicBlockChainAPI.b
 	bNrOrHash := rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
 	if blockNrOrHash != nil {
@@ -726,7 +859,7 @@ func (api *EthereumAPI) EstimateGas(ctx context.Context, args EthTransactionArgs
 	if rpcGasCap := bcAPI.RPCGasCap(); rpcGasCap != nil {
 		gasCap = rpcGasCap.Uint64()
 	}
-	return EthDoEstimateGas(ctx, bcAPI, args, bNrOrHash, gasCap)
+	return EthDoEstimateGas(ctx, bcAPI, args, bNrOrHash, blockOverrides, gasCap)
 }
 
 // GetBlockTransactionCountByNumber returns the number of transactions in the block with the given block number.
@@ -1197,21 +1330,40 @@ func (api *EthereumAPI) FillTransaction(ctx context.Context, args EthTransaction
 	return &EthSignTransactionResult{data, formatTxToEthTxJSON(tx)}, nil
 }
 
-// SendRawTransaction will add the signed transaction to the transaction pool.
-// The sender is responsible for signing the transaction and using the correct nonce.
-func (api *EthereumAPI) SendRawTransaction(ctx context.Context, input hexutil.Bytes) (common.Hash, error) {
+// ethRawTransaction converts the encoded Ethereum transaction to the encoding
+// of Kaia, which prefixes the typed transaction with the envelope type.
+func ethRawTransaction(input hexutil.Bytes) (hexutil.Bytes, error) {
 	if len(input) == 0 {
-		return common.Hash{}, fmt.Errorf("Empty input")
+		return nil, fmt.Errorf("Empty input")
 	}
 	if 0 < input[0] && input[0] < 0x7f {
 		inputBytes := []byte{byte(types.EthereumTxTypeEnvelope)}
-		inputBytes = append(inputBytes, input...)
-		return api.publicTransactionPoolAPI.SendRawTransaction(ctx, inputBytes)
+		return append(inputBytes, input...), nil
 	}
 	// legacy transaction
+	return input, nil
+}
+
+// SendRawTransaction will add the signed transaction to the transaction pool.
+// The sender is responsible for signing the transaction and using the correct nonce.
+func (api *EthereumAPI) SendRawTransaction(ctx context.Context, input hexutil.Bytes) (common.Hash, error) {
+	input, err := ethRawTransaction(input)
+	if err != nil {
+		return common.Hash{}, err
+	}
 	return api.publicTransactionPoolAPI.SendRawTransaction(ctx, input)
 }
 
+// SendRawTransactionConditional will add the signed transaction to the transaction pool
+// with its preconditions, which should hold in the block including the transaction.
+func (api *EthereumAPI) SendRawTransactionConditional(ctx context.Context, input hexutil.Bytes, cond types.TransactionConditional) (common.Hash, error) {
+	input, err := ethRawTransaction(input)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return api.publicTransactionPoolAPI.SendRawTransactionConditional(ctx, input, cond)
+}
+
 // Sign calculates an ECDSA signature for:
 // keccack256("\x19Klaytn Signed Message:\n" + len(message) + message).
 //
@@ -1383,14 +1535,14 @@ func (api *EthereumAPI) rpcMarshalBlock(block *types.Block, inclMiner, inclTx, f
 	return fields, nil
 }
 
-func EthDoCall(ctx context.Context, b Backend, args EthTransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *EthStateOverride, timeout time.Duration, globalGasCap uint64) (*blockchain.ExecutionResult, error) {
+func EthDoCall(ctx context.Context, b Backend, args EthTransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *EthStateOverride, blockOverrides *BlockOverrides, timeout time.Duration, globalGasCap uint64) (*blockchain.ExecutionResult, error) {
 	defer func(start time.Time) { logger.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
 	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
 	if state == nil || err != nil {
 		return nil, err
 	}
-	if err := overrides.Apply(state); err != nil {
+	if err := overrides.Apply(state, nil); err != nil {
 		return nil, err
 	}
 	// Setup context so it may be cancelled the call has completed
@@ -1409,6 +1561,9 @@ func EthDoCall(ctx context.Context, b Backend, args EthTransactionArgs, blockNrO
 	var baseFee *big.Int
 	if header.BaseFee != nil {
 		baseFee = header.BaseFee
+		if blockOverrides != nil && blockOverrides.BaseFeePerGas != nil {
+			baseFee = blockOverrides.BaseFeePerGas.ToInt()
+		}
 	} else {
 		baseFee = new(big.Int).SetUint64(params.ZeroBaseFee)
 	}
@@ -1440,6 +1595,7 @@ func EthDoCall(ctx context.Context, b Backend, args EthTransactionArgs, blockNrO
 	if err != nil {
 		return nil, err
 	}
+	evm = newEVMWithBlockOverrides(evm, blockOverrides)
 	// Wait for the context to be done and cancel the evm. Even if the
 	// EVM has finished, cancelling may be done (repeatedly)
 	go func() {
@@ -1462,7 +1618,7 @@ func EthDoCall(ctx context.Context, b Backend, args EthTransactionArgs, blockNrO
 	return result, nil
 }
 
-func EthDoEstimateGas(ctx context.Context, b Backend, args EthTransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, gasCap uint64) (hexutil.Uint64, error) {
+func EthDoEstimateGas(ctx context.Context, b Backend, args EthTransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, blockOverrides *BlockOverrides, gasCap uint64) (hexutil.Uint64, error) {
 	// Use zero address if sender unspecified.
 	if args.From == nil {
 		args.From = new(common.Address)
@@ -1491,7 +1647,7 @@ func EthDoEstimateGas(ctx context.Context, b Backend, args EthTransactionArgs, b
 
 	executable := func(gas uint64) (bool, *blockchain.ExecutionResult, error) {
 		args.Gas = (*hexutil.Uint64)(&gas)
-		result, err := EthDoCall(ctx, b, args, rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber), nil, b.RPCEVMTimeout(), gasCap)
+		result, err := EthDoCall(ctx, b, args, rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber), nil, blockOverrides, b.RPCEVMTimeout(), gasCap)
 		if err != nil {
 			if errors.Is(err, blockchain.ErrIntrinsicGas) {
 				return true, nil, nil // Special case, raise gas limit
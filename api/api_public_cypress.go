@@ -52,7 +52,7 @@ func (s *PublicBlockChainAPI) callCypressCreditGetFunc(ctx context.Context, pars
 		To:   &cypressCreditContractAddress,
 		Data: abiGet,
 	}
-	ret, err := s.Call(ctx, args, latestBlockNrOrHash)
+	ret, err := s.Call(ctx, args, latestBlockNrOrHash, nil)
 	if err != nil {
 		return nil, err
 	}
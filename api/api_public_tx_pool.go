@@ -21,13 +21,20 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 
+	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
 )
 
+// txEventsChanSize is the size of channel listening to TxLifecycleEvent.
+const txEventsChanSize = 1024
+
 // PublicTxPoolAPI offers and API for the transaction pool. It only operates on data that is non confidential.
 type PublicTxPoolAPI struct {
 	b Backend
@@ -65,8 +72,21 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]map[string]
 	return content
 }
 
-// Status returns the number of pending and queued transaction in the pool.
-func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
+// TxPoolTxStatus is the status of a transaction in the pool with its recent
+// lifecycle events.
+type TxPoolTxStatus struct {
+	Hash   common.Hash                   `json:"hash"`
+	Status string                        `json:"status"` // pending, queued, included, replaced, dropped or unknown
+	Events []blockchain.TxLifecycleEvent `json:"events"`
+}
+
+// Status returns the number of pending and queued transaction in the pool. If
+// the hash is given, it returns the status of the transaction with its recent
+// lifecycle events instead, which tells why the transaction left the pool.
+func (s *PublicTxPoolAPI) Status(hash *common.Hash) interface{} {
+	if hash != nil {
+		return s.txStatus(*hash)
+	}
 	pending, queue := s.b.Stats()
 	return map[string]hexutil.Uint{
 		"pending": hexutil.Uint(pending),
@@ -74,6 +94,101 @@ func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
 	}
 }
 
+func (s *PublicTxPoolAPI) txStatus(hash common.Hash) *TxPoolTxStatus {
+	status, events := s.b.TxPoolLifecycle(hash)
+	result := &TxPoolTxStatus{Hash: hash, Status: "unknown", Events: events}
+	if result.Events == nil {
+		result.Events = []blockchain.TxLifecycleEvent{}
+	}
+	switch status {
+	case blockchain.TxStatusPending:
+		result.Status = "pending"
+	case blockchain.TxStatusQueued:
+		result.Status = "queued"
+	case blockchain.TxStatusConditionFailed:
+		result.Status = string(blockchain.TxEventDropped)
+	default:
+		// The transaction left the pool, so the last event tells why.
+		if len(events) > 0 {
+			switch last := events[len(events)-1].Type; last {
+			case blockchain.TxEventIncluded, blockchain.TxEventReplaced, blockchain.TxEventDropped:
+				result.Status = string(last)
+			}
+		}
+	}
+	return result
+}
+
+// TxEventsCriteria selects the transactions whose lifecycle events are sent.
+// All events are sent if no criteria is given.
+type TxEventsCriteria struct {
+	Hashes []common.Hash    `json:"hashes"`
+	From   []common.Address `json:"from"`
+}
+
+func (crit *TxEventsCriteria) matches(ev blockchain.TxLifecycleEvent) bool {
+	if crit == nil {
+		return true
+	}
+	if len(crit.Hashes) > 0 && !includesHash(crit.Hashes, ev.Hash) {
+		return false
+	}
+	if len(crit.From) > 0 && !includesAddress(crit.From, ev.From) {
+		return false
+	}
+	return true
+}
+
+func includesHash(hashes []common.Hash, hash common.Hash) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+func includesAddress(addrs []common.Address, addr common.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// Events creates a subscription that fires for each lifecycle event of the
+// transactions in the pool, e.g. added, promoted, demoted, replaced and dropped
+// with the reason.
+func (s *PublicTxPoolAPI) Events(ctx context.Context, crit *TxEventsCriteria) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		events := make(chan blockchain.TxLifecycleEvent, txEventsChanSize)
+		eventsSub := s.b.SubscribeTxLifecycleEvent(events)
+		defer eventsSub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-events:
+				if crit.matches(ev) {
+					notifier.Notify(rpcSub.ID, ev)
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // Inspect retrieves the content of the transaction pool and flattens it into an
 // easily inspectable list.
 func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
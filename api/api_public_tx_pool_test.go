@@ -0,0 +1,83 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mock_api "github.com/klaytn/klaytn/api/mocks"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicTxPoolAPI_Status(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockBackend := mock_api.NewMockBackend(mockCtrl)
+	api := NewPublicTxPoolAPI(mockBackend)
+
+	mockBackend.EXPECT().Stats().Return(2, 1)
+	assert.Equal(t, map[string]hexutil.Uint{"pending": 2, "queued": 1}, api.Status(nil))
+
+	var (
+		hash        = common.HexToHash("0x1")
+		replacement = common.HexToHash("0x2")
+		added       = blockchain.TxLifecycleEvent{Hash: hash, Type: blockchain.TxEventAdded}
+		promoted    = blockchain.TxLifecycleEvent{Hash: hash, Type: blockchain.TxEventPromoted}
+		replaced    = blockchain.TxLifecycleEvent{Hash: hash, Type: blockchain.TxEventReplaced, ReplacedBy: &replacement}
+		included    = blockchain.TxLifecycleEvent{Hash: hash, Type: blockchain.TxEventIncluded}
+		dropped     = blockchain.TxLifecycleEvent{Hash: hash, Type: blockchain.TxEventDropped, Reason: blockchain.TxDropReasonLifetime}
+	)
+	tests := []struct {
+		status blockchain.TxStatus
+		events []blockchain.TxLifecycleEvent
+		want   string
+	}{
+		{blockchain.TxStatusUnknown, nil, "unknown"},
+		{blockchain.TxStatusQueued, []blockchain.TxLifecycleEvent{added}, "queued"},
+		{blockchain.TxStatusPending, []blockchain.TxLifecycleEvent{added, promoted}, "pending"},
+		{blockchain.TxStatusUnknown, []blockchain.TxLifecycleEvent{added, promoted, included}, "included"},
+		{blockchain.TxStatusUnknown, []blockchain.TxLifecycleEvent{added, promoted, replaced}, "replaced"},
+		{blockchain.TxStatusUnknown, []blockchain.TxLifecycleEvent{added, dropped}, "dropped"},
+		{blockchain.TxStatusConditionFailed, nil, "dropped"},
+	}
+	for _, tt := range tests {
+		mockBackend.EXPECT().TxPoolLifecycle(hash).Return(tt.status, tt.events)
+		status := api.Status(&hash).(*TxPoolTxStatus)
+		assert.Equal(t, hash, status.Hash)
+		assert.Equal(t, tt.want, status.Status)
+		assert.Len(t, status.Events, len(tt.events))
+		assert.NotNil(t, status.Events)
+	}
+}
+
+func TestTxEventsCriteria_Matches(t *testing.T) {
+	var (
+		hash = common.HexToHash("0x1")
+		from = common.HexToAddress("0xa")
+		ev   = blockchain.TxLifecycleEvent{Hash: hash, From: from, Type: blockchain.TxEventAdded}
+	)
+	assert.True(t, (*TxEventsCriteria)(nil).matches(ev))
+	assert.True(t, (&TxEventsCriteria{}).matches(ev))
+	assert.True(t, (&TxEventsCriteria{Hashes: []common.Hash{hash}}).matches(ev))
+	assert.True(t, (&TxEventsCriteria{From: []common.Address{from}}).matches(ev))
+	assert.False(t, (&TxEventsCriteria{Hashes: []common.Hash{{}}}).matches(ev))
+	assert.False(t, (&TxEventsCriteria{Hashes: []common.Hash{hash}, From: []common.Address{{}}}).matches(ev))
+}
@@ -86,12 +86,15 @@ type Backend interface {
 
 	// TxPool API
 	SendTx(ctx context.Context, signedTx *types.Transaction) error
+	SendBundle(ctx context.Context, bundle *blockchain.Bundle) (common.Hash, error)
 	GetPoolTransactions() (types.Transactions, error)
 	GetPoolTransaction(txHash common.Hash) *types.Transaction
 	GetPoolNonce(ctx context.Context, addr common.Address) uint64
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
+	TxPoolLifecycle(hash common.Hash) (blockchain.TxStatus, []blockchain.TxLifecycleEvent)
 	SubscribeNewTxsEvent(chan<- blockchain.NewTxsEvent) event.Subscription
+	SubscribeTxLifecycleEvent(chan<- blockchain.TxLifecycleEvent) event.Subscription
 
 	ChainConfig() *params.ChainConfig
 	CurrentBlock() *types.Block
@@ -0,0 +1,216 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/consensus/misc"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// DefaultBundleBlockRange is the number of blocks a bundle targets if the
+// maximum block is not given.
+const DefaultBundleBlockRange = 60
+
+// SendBundleArgs represents the arguments to submit a bundle. The bundle is
+// valid from MinBlock to MaxBlock, which are the next block and
+// DefaultBundleBlockRange blocks after MinBlock respectively if not given.
+type SendBundleArgs struct {
+	Txs      []hexutil.Bytes `json:"txs"`
+	MinBlock *hexutil.Uint64 `json:"minBlock"`
+	MaxBlock *hexutil.Uint64 `json:"maxBlock"`
+}
+
+// CallBundleResult is the result of a simulated bundle.
+type CallBundleResult struct {
+	BundleHash       common.Hash          `json:"bundleHash"`
+	StateBlockNumber hexutil.Uint64       `json:"stateBlockNumber"`
+	GasUsed          hexutil.Uint64       `json:"gasUsed"`
+	Success          bool                 `json:"success"` // true if all transactions succeeded, so the bundle can be included
+	Results          []CallBundleTxResult `json:"results"`
+}
+
+// CallBundleTxResult is the result of a transaction in a simulated bundle.
+type CallBundleTxResult struct {
+	TxHash      common.Hash    `json:"txHash"`
+	From        common.Address `json:"from"`
+	ReturnValue hexutil.Bytes  `json:"returnData"`
+	Logs        []*types.Log   `json:"logs"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	Status      hexutil.Uint64 `json:"status"`
+	Error       *SimCallError  `json:"error,omitempty"`
+}
+
+// decodeBundleTxs decodes the signed transactions of a bundle.
+func decodeBundleTxs(encodedTxs []hexutil.Bytes) (types.Transactions, error) {
+	if len(encodedTxs) == 0 {
+		return nil, blockchain.ErrEmptyBundle
+	}
+	if len(encodedTxs) > blockchain.MaxBundleTxs {
+		return nil, blockchain.ErrBundleTooLarge
+	}
+	txs := make(types.Transactions, len(encodedTxs))
+	for i, encodedTx := range encodedTxs {
+		tx := new(types.Transaction)
+		if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %w", i, err)
+		}
+		txs[i] = tx
+	}
+	return txs, nil
+}
+
+// SendBundle submits the ordered signed transactions which are included in a
+// block all together in the order, or not at all. The bundle is kept in the
+// bundle pool of the node until its block range passes, and it is not
+// propagated to the peers, so only the consensus nodes accept it. It returns
+// the hash of the bundle.
+func (s *PublicTransactionPoolAPI) SendBundle(ctx context.Context, args SendBundleArgs) (common.Hash, error) {
+	txs, err := decodeBundleTxs(args.Txs)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	bundle := &blockchain.Bundle{Txs: txs, MinBlock: s.b.CurrentBlock().NumberU64() + 1}
+	if args.MinBlock != nil && uint64(*args.MinBlock) > bundle.MinBlock {
+		bundle.MinBlock = uint64(*args.MinBlock)
+	}
+	if args.MaxBlock != nil {
+		bundle.MaxBlock = uint64(*args.MaxBlock)
+	} else {
+		bundle.MaxBlock = bundle.MinBlock + DefaultBundleBlockRange - 1
+	}
+	return s.b.SendBundle(ctx, bundle)
+}
+
+// CallBundle simulates the bundle in the block next to the given block, and
+// returns the results of the transactions. The simulation fails if any of the
+// transactions is invalid, while the transactions failed in the EVM are
+// reported in their results and make the bundle unsuccessful.
+func (s *PublicTransactionPoolAPI) CallBundle(ctx context.Context, encodedTxs []hexutil.Bytes, blockNrOrHash rpc.BlockNumberOrHash) (*CallBundleResult, error) {
+	txs, err := decodeBundleTxs(encodedTxs)
+	if err != nil {
+		return nil, err
+	}
+	state, parent, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	var cancel context.CancelFunc
+	if timeout := s.b.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	config := s.b.ChainConfig()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Rewardbase: parent.Rewardbase,
+		BlockScore: parent.BlockScore,
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       new(big.Int).Add(parent.Time, big.NewInt(params.BlockGenerationInterval)),
+	}
+	if config.IsMagmaForkEnabled(header.Number) {
+		if config.Governance != nil && config.Governance.KIP71 != nil && parent.BaseFee != nil {
+			header.BaseFee = misc.NextMagmaBlockBaseFee(parent, config.Governance.KIP71)
+		} else {
+			header.BaseFee = new(big.Int).SetUint64(params.ZeroBaseFee)
+		}
+	}
+	var (
+		number = header.Number.Uint64()
+		signer = types.MakeSigner(config, header.Number)
+		bundle = &blockchain.Bundle{Txs: txs}
+		result = &CallBundleResult{
+			BundleHash:       bundle.Hash(),
+			StateBlockNumber: hexutil.Uint64(parent.Number.Uint64()),
+			Success:          true,
+			Results:          make([]CallBundleTxResult, len(txs)),
+		}
+	)
+	for i, tx := range txs {
+		if err := tx.Validate(state, number); err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %w", i, err)
+		}
+		msg, err := tx.AsMessageWithAccountKeyPicker(signer, state, number)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %w", i, err)
+		}
+		state.SetTxContext(tx.Hash(), common.Hash{}, i)
+
+		evm, vmError, err := s.b.GetEVM(ctx, msg, state, header, vm.Config{})
+		if err != nil {
+			return nil, err
+		}
+		// Wait for the context to be done and cancel the evm. Even if the
+		// EVM has finished, cancelling may be done (repeatedly)
+		go func() {
+			<-ctx.Done()
+			evm.Cancel(vm.CancelByCtxDone)
+		}()
+
+		res, err := blockchain.ApplyMessage(evm, msg)
+		if err := vmError(); err != nil {
+			return nil, err
+		}
+		if evm.Cancelled() {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", s.b.RPCEVMTimeout())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %w", i, err)
+		}
+		state.Finalise(true, true)
+
+		txResult := CallBundleTxResult{
+			TxHash:      tx.Hash(),
+			From:        msg.ValidatedSender(),
+			ReturnValue: res.Return(),
+			Logs:        state.GetLogs(tx.Hash()),
+			GasUsed:     hexutil.Uint64(res.UsedGas),
+			Status:      hexutil.Uint64(types.ReceiptStatusSuccessful),
+		}
+		if res.Failed() {
+			result.Success = false
+			txResult.Status = hexutil.Uint64(types.ReceiptStatusFailed)
+			txResult.Logs = nil
+			if res.VmExecutionStatus == types.ReceiptStatusErrExecutionReverted {
+				revertErr := blockchain.NewRevertError(res)
+				txResult.ReturnValue = res.Revert()
+				txResult.Error = &SimCallError{Message: revertErr.Error(), Code: errCodeReverted, Data: revertErr.ErrorData().(string)}
+			} else {
+				txResult.Error = &SimCallError{Message: res.Unwrap().Error(), Code: errCodeVMError}
+			}
+		}
+		if txResult.Logs == nil {
+			txResult.Logs = []*types.Log{}
+		}
+		result.GasUsed += txResult.GasUsed
+		result.Results[i] = txResult
+	}
+	return result, nil
+}
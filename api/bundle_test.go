@@ -0,0 +1,160 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mock_api "github.com/klaytn/klaytn/api/mocks"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupBundleBackend sets up the genesis state funding the address of the
+// returned key, and returns a function signing transfers from the key.
+func setupBundleBackend(t *testing.T, mockBackend *mock_api.MockBackend) func(nonce uint64, to common.Address) hexutil.Bytes {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	chainConfig := &params.ChainConfig{ChainID: big.NewInt(1)}
+	chainConfig.IstanbulCompatibleBlock = common.Big0
+	chainConfig.LondonCompatibleBlock = common.Big0
+	chainConfig.EthTxTypeCompatibleBlock = common.Big0
+	chainConfig.MagmaCompatibleBlock = common.Big0
+	fork.SetHardForkBlockNumberConfig(chainConfig)
+	t.Cleanup(fork.ClearHardForkBlockNumberConfig)
+	var (
+		gspec = &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+			crypto.PubkeyToAddress(key.PublicKey): {Balance: big.NewInt(params.KAIA)},
+			simRevertContract:                     {Balance: common.Big0, Code: hexutil.MustDecode("0x60006000fd")},
+		}, Config: chainConfig}
+		dbm    = database.NewMemoryDBManager()
+		db     = state.NewDatabase(dbm)
+		block  = gspec.MustCommit(dbm)
+		header = block.Header()
+		chain  = &testChainContext{header: header}
+	)
+	any := gomock.Any()
+	getStateAndHeader := func(...interface{}) (*state.StateDB, *types.Header, error) {
+		state, err := state.New(block.Root(), db, nil, nil)
+		return state, header, err
+	}
+	getEVM := func(_ context.Context, msg blockchain.Message, state *state.StateDB, header *types.Header, vmConfig vm.Config) (*vm.EVM, func() error, error) {
+		vmError := func() error { return nil }
+		txContext := blockchain.NewEVMTxContext(msg, header, chainConfig)
+		blockContext := blockchain.NewEVMBlockContext(header, chain, nil)
+		return vm.NewEVM(blockContext, txContext, state, chainConfig, &vmConfig), vmError, nil
+	}
+	mockBackend.EXPECT().ChainConfig().Return(chainConfig).AnyTimes()
+	mockBackend.EXPECT().RPCEVMTimeout().Return(5 * time.Second).AnyTimes()
+	mockBackend.EXPECT().CurrentBlock().Return(block).AnyTimes()
+	mockBackend.EXPECT().StateAndHeaderByNumberOrHash(any, any).DoAndReturn(getStateAndHeader).AnyTimes()
+	mockBackend.EXPECT().GetEVM(any, any, any, any, any).DoAndReturn(getEVM).AnyTimes()
+
+	signer := types.LatestSignerForChainID(chainConfig.ChainID)
+	return func(nonce uint64, to common.Address) hexutil.Bytes {
+		tx, err := types.SignTx(types.NewTransaction(nonce, to, common.Big1, 100000, common.Big0, nil), signer, key)
+		require.NoError(t, err)
+		encoded, err := rlp.EncodeToBytes(tx)
+		require.NoError(t, err)
+		return encoded
+	}
+}
+
+func TestPublicTransactionPoolAPI_SendBundle(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockBackend := mock_api.NewMockBackend(mockCtrl)
+	api := NewPublicTransactionPoolAPI(mockBackend, new(AddrLocker))
+	transfer := setupBundleBackend(t, mockBackend)
+
+	var bundles []*blockchain.Bundle
+	mockBackend.EXPECT().SendBundle(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, bundle *blockchain.Bundle) (common.Hash, error) {
+		bundles = append(bundles, bundle)
+		return bundle.Hash(), nil
+	}).Times(2)
+
+	txs := []hexutil.Bytes{transfer(0, simAccount2), transfer(1, simAccount2)}
+	hash, err := api.SendBundle(context.Background(), SendBundleArgs{Txs: txs})
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+	assert.Equal(t, bundles[0].Hash(), hash)
+	assert.Len(t, bundles[0].Txs, 2)
+	assert.Equal(t, uint64(1), bundles[0].MinBlock)
+	assert.Equal(t, uint64(DefaultBundleBlockRange), bundles[0].MaxBlock)
+
+	minBlock, maxBlock := hexutil.Uint64(5), hexutil.Uint64(7)
+	_, err = api.SendBundle(context.Background(), SendBundleArgs{Txs: txs, MinBlock: &minBlock, MaxBlock: &maxBlock})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), bundles[1].MinBlock)
+	assert.Equal(t, uint64(7), bundles[1].MaxBlock)
+
+	_, err = api.SendBundle(context.Background(), SendBundleArgs{})
+	assert.ErrorIs(t, err, blockchain.ErrEmptyBundle)
+	_, err = api.SendBundle(context.Background(), SendBundleArgs{Txs: []hexutil.Bytes{{0x1}}})
+	assert.Error(t, err)
+}
+
+func TestPublicTransactionPoolAPI_CallBundle(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockBackend := mock_api.NewMockBackend(mockCtrl)
+	api := NewPublicTransactionPoolAPI(mockBackend, new(AddrLocker))
+	transfer := setupBundleBackend(t, mockBackend)
+	latest := rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	result, err := api.CallBundle(context.Background(), []hexutil.Bytes{transfer(0, simAccount2), transfer(1, simAccount2)}, latest)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, hexutil.Uint64(0), result.StateBlockNumber)
+	assert.Equal(t, hexutil.Uint64(2*params.TxGas), result.GasUsed)
+	require.Len(t, result.Results, 2)
+	for _, res := range result.Results {
+		assert.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), res.Status)
+		assert.Nil(t, res.Error)
+	}
+
+	// The reverted transaction makes the bundle unsuccessful.
+	result, err = api.CallBundle(context.Background(), []hexutil.Bytes{transfer(0, simAccount2), transfer(1, simRevertContract)}, latest)
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	require.Len(t, result.Results, 2)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), result.Results[0].Status)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusFailed), result.Results[1].Status)
+	require.NotNil(t, result.Results[1].Error)
+	assert.Equal(t, errCodeReverted, result.Results[1].Error.Code)
+
+	// The invalid transaction fails the simulation.
+	_, err = api.CallBundle(context.Background(), []hexutil.Bytes{transfer(0, simAccount2), transfer(0, simAccount2)}, latest)
+	assert.ErrorIs(t, err, blockchain.ErrNonceTooLow)
+}
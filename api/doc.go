@@ -41,6 +41,10 @@ Source Files
   - api_public_transaction_pool.go : provides public APIs having "klay" namespace to access transaction pool data.
   - api_public_tx_pool.go          : provides public APIs having "txpool" namespace to access transaction pool data.
   - backend.go                     : provides the common API services.
+  - bundle.go                      : provides sendBundle and callBundle APIs submitting and simulating transaction bundles.
+  - simulate.go                    : provides simulateV1 APIs executing calls in simulated blocks.
+  - simulate_tracer.go             : implements the tracer collecting logs and KAIA transfers of simulated calls.
   - tx_args.go                     : provides API argument structures and functions.
+  - tx_conditional.go              : provides sendRawTransactionConditional API submitting transactions with preconditions.
 */
 package api
@@ -539,6 +539,21 @@ func (mr *MockBackendMockRecorder) RPCTxFeeCap() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RPCTxFeeCap", reflect.TypeOf((*MockBackend)(nil).RPCTxFeeCap))
 }
 
+// SendBundle mocks base method.
+func (m *MockBackend) SendBundle(arg0 context.Context, arg1 *blockchain.Bundle) (common.Hash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendBundle", arg0, arg1)
+	ret0, _ := ret[0].(common.Hash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendBundle indicates an expected call of SendBundle.
+func (mr *MockBackendMockRecorder) SendBundle(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBundle", reflect.TypeOf((*MockBackend)(nil).SendBundle), arg0, arg1)
+}
+
 // SendTx mocks base method.
 func (m *MockBackend) SendTx(arg0 context.Context, arg1 *types.Transaction) error {
 	m.ctrl.T.Helper()
@@ -670,6 +685,20 @@ func (mr *MockBackendMockRecorder) SubscribeNewTxsEvent(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeNewTxsEvent", reflect.TypeOf((*MockBackend)(nil).SubscribeNewTxsEvent), arg0)
 }
 
+// SubscribeTxLifecycleEvent mocks base method.
+func (m *MockBackend) SubscribeTxLifecycleEvent(arg0 chan<- blockchain.TxLifecycleEvent) event.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeTxLifecycleEvent", arg0)
+	ret0, _ := ret[0].(event.Subscription)
+	return ret0
+}
+
+// SubscribeTxLifecycleEvent indicates an expected call of SubscribeTxLifecycleEvent.
+func (mr *MockBackendMockRecorder) SubscribeTxLifecycleEvent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeTxLifecycleEvent", reflect.TypeOf((*MockBackend)(nil).SubscribeTxLifecycleEvent), arg0)
+}
+
 // SuggestPrice mocks base method.
 func (m *MockBackend) SuggestPrice(arg0 context.Context) (*big.Int, error) {
 	m.ctrl.T.Helper()
@@ -715,6 +744,21 @@ func (mr *MockBackendMockRecorder) TxPoolContent() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxPoolContent", reflect.TypeOf((*MockBackend)(nil).TxPoolContent))
 }
 
+// TxPoolLifecycle mocks base method.
+func (m *MockBackend) TxPoolLifecycle(arg0 common.Hash) (blockchain.TxStatus, []blockchain.TxLifecycleEvent) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxPoolLifecycle", arg0)
+	ret0, _ := ret[0].(blockchain.TxStatus)
+	ret1, _ := ret[1].([]blockchain.TxLifecycleEvent)
+	return ret0, ret1
+}
+
+// TxPoolLifecycle indicates an expected call of TxPoolLifecycle.
+func (mr *MockBackendMockRecorder) TxPoolLifecycle(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxPoolLifecycle", reflect.TypeOf((*MockBackend)(nil).TxPoolLifecycle), arg0)
+}
+
 // UpperBoundGasPrice mocks base method.
 func (m *MockBackend) UpperBoundGasPrice(arg0 context.Context) *big.Int {
 	m.ctrl.T.Helper()
@@ -0,0 +1,561 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+)
+
+const (
+	// maxSimulateBlocks is the maximum number of blocks that can be simulated
+	// in a single request, including the blocks filling the number gaps.
+	maxSimulateBlocks = 256
+
+	// timestampIncrement is the default increment between the timestamps of
+	// the simulated blocks.
+	timestampIncrement = 1
+)
+
+// JSON-RPC error codes of eth_simulateV1.
+// See: https://github.com/ethereum/execution-apis/pull/484
+const (
+	errCodeNonceTooHigh            = -38011
+	errCodeNonceTooLow             = -38010
+	errCodeIntrinsicGas            = -38013
+	errCodeInsufficientFunds       = -38014
+	errCodeBlockGasLimitReached    = -38015
+	errCodeBlockNumberInvalid      = -38020
+	errCodeBlockTimestampInvalid   = -38021
+	errCodeFeeCapTooLow            = -38012
+	errCodeMaxInitCodeSizeExceeded = -38025
+	errCodeClientLimitExceeded     = -38026
+	errCodeInvalidParams           = -32602
+	errCodeDefault                 = -32000
+	errCodeReverted                = 3
+	errCodeVMError                 = -32015
+)
+
+// simError is an error of eth_simulateV1 carrying a JSON-RPC error code.
+type simError struct {
+	code int
+	msg  string
+}
+
+func (e *simError) Error() string  { return e.msg }
+func (e *simError) ErrorCode() int { return e.code }
+
+// txValidationError converts the consensus error of a simulated call into
+// the error failing the whole simulation.
+func txValidationError(err error) *simError {
+	code := errCodeDefault
+	switch {
+	case errors.Is(err, blockchain.ErrNonceTooHigh):
+		code = errCodeNonceTooHigh
+	case errors.Is(err, blockchain.ErrNonceTooLow):
+		code = errCodeNonceTooLow
+	case errors.Is(err, blockchain.ErrIntrinsicGas):
+		code = errCodeIntrinsicGas
+	case errors.Is(err, blockchain.ErrMaxInitCodeSizeExceeded):
+		code = errCodeMaxInitCodeSizeExceeded
+	case errors.Is(err, vm.ErrInsufficientBalance):
+		code = errCodeInsufficientFunds
+	}
+	return &simError{code: code, msg: err.Error()}
+}
+
+// SimOpts are the inputs to eth_simulateV1.
+type SimOpts struct {
+	BlockStateCalls        []SimBlock `json:"blockStateCalls"`
+	TraceTransfers         bool       `json:"traceTransfers"`
+	Validation             bool       `json:"validation"`
+	ReturnFullTransactions bool       `json:"returnFullTransactions"`
+}
+
+// SimBlock is a batch of calls to be simulated sequentially in a block.
+type SimBlock struct {
+	BlockOverrides *BlockOverrides      `json:"blockOverrides"`
+	StateOverrides *EthStateOverride    `json:"stateOverrides"`
+	Calls          []EthTransactionArgs `json:"calls"`
+}
+
+// SimCallResult is the result of a simulated call.
+type SimCallResult struct {
+	ReturnValue hexutil.Bytes  `json:"returnData"`
+	Logs        []*types.Log   `json:"logs"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	Status      hexutil.Uint64 `json:"status"`
+	Error       *SimCallError  `json:"error,omitempty"`
+}
+
+// SimCallError is the error of a simulated call failed in the EVM.
+type SimCallError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+	Data    string `json:"data,omitempty"`
+}
+
+// simBlockResult is the outcome of a simulated block, which is marshaled by
+// the namespace specific block format.
+type simBlockResult struct {
+	block    *types.Block
+	gasLimit uint64
+	senders  []common.Address
+	calls    []SimCallResult
+}
+
+// simulator simulates the blocks of eth_simulateV1 on top of a base block.
+// The state changes of a block are visible to the following blocks.
+type simulator struct {
+	b              Backend
+	state          *state.StateDB
+	base           *types.Header
+	chainConfig    *params.ChainConfig
+	gasRemaining   uint64
+	traceTransfers bool
+	validate       bool
+
+	// hashes keeps the hashes of the simulated blocks for BLOCKHASH.
+	hashes map[uint64]common.Hash
+}
+
+// doSimulate executes the series of blocks given in opts on top of the given
+// block. The total gas of the calls is limited by the RPC gas cap.
+func doSimulate(ctx context.Context, b Backend, opts SimOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]*simBlockResult, error) {
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, &simError{code: errCodeInvalidParams, msg: "empty input"}
+	} else if len(opts.BlockStateCalls) > maxSimulateBlocks {
+		return nil, &simError{code: errCodeClientLimitExceeded, msg: "too many blocks"}
+	}
+	if blockNrOrHash == nil {
+		latest := rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	state, base, err := b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// Setup context so it may be cancelled the simulation has completed
+	// or, in case of unmetered gas, setup a context with a timeout.
+	var cancel context.CancelFunc
+	timeout := b.RPCEVMTimeout()
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	gasCap := uint64(math.MaxUint64)
+	if rpcGasCap := b.RPCGasCap(); rpcGasCap != nil && rpcGasCap.Sign() > 0 {
+		gasCap = rpcGasCap.Uint64()
+	}
+	sim := &simulator{
+		b:              b,
+		state:          state,
+		base:           base,
+		chainConfig:    b.ChainConfig(),
+		gasRemaining:   gasCap,
+		traceTransfers: opts.TraceTransfers,
+		validate:       opts.Validation,
+		hashes:         make(map[uint64]common.Hash),
+	}
+	results, err := sim.execute(ctx, opts.BlockStateCalls)
+	// If the timer caused an abort, return an appropriate error message
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// execute runs the given blocks sequentially.
+func (sim *simulator) execute(ctx context.Context, blocks []SimBlock) ([]*simBlockResult, error) {
+	blocks, err := sim.sanitizeChain(blocks)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		results = make([]*simBlockResult, len(blocks))
+		parent  = sim.base
+	)
+	for i := range blocks {
+		result, err := sim.processBlock(ctx, &blocks[i], parent)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+		parent = result.block.Header()
+		sim.hashes[parent.Number.Uint64()] = parent.Hash()
+	}
+	return results, nil
+}
+
+// sanitizeChain checks the number and timestamp orders of the blocks, and
+// fills the gaps between the block numbers with empty blocks. The number and
+// timestamp of every returned block are set.
+func (sim *simulator) sanitizeChain(blocks []SimBlock) ([]SimBlock, error) {
+	var (
+		res      = make([]SimBlock, 0, len(blocks))
+		prevNum  = new(big.Int).Set(sim.base.Number)
+		prevTime = sim.base.Time.Uint64()
+	)
+	for _, block := range blocks {
+		if block.BlockOverrides == nil {
+			block.BlockOverrides = new(BlockOverrides)
+		}
+		if block.BlockOverrides.Number == nil {
+			n := new(big.Int).Add(prevNum, common.Big1)
+			block.BlockOverrides.Number = (*hexutil.Big)(n)
+		}
+		diff := new(big.Int).Sub(block.BlockOverrides.Number.ToInt(), prevNum)
+		if diff.Sign() <= 0 {
+			return nil, &simError{code: errCodeBlockNumberInvalid, msg: fmt.Sprintf("block numbers must be in order: %d <= %d", block.BlockOverrides.Number.ToInt(), prevNum)}
+		}
+		if total := new(big.Int).Sub(block.BlockOverrides.Number.ToInt(), sim.base.Number); total.Cmp(big.NewInt(maxSimulateBlocks)) > 0 {
+			return nil, &simError{code: errCodeClientLimitExceeded, msg: "too many blocks"}
+		}
+		// Fill the gap with empty blocks.
+		for gap := diff.Uint64(); gap > 1; gap-- {
+			prevNum = new(big.Int).Add(prevNum, common.Big1)
+			prevTime += timestampIncrement
+			t := hexutil.Uint64(prevTime)
+			res = append(res, SimBlock{BlockOverrides: &BlockOverrides{Number: (*hexutil.Big)(prevNum), Time: &t}})
+		}
+		prevNum = block.BlockOverrides.Number.ToInt()
+		if block.BlockOverrides.Time == nil {
+			t := hexutil.Uint64(prevTime + timestampIncrement)
+			block.BlockOverrides.Time = &t
+		} else if uint64(*block.BlockOverrides.Time) <= prevTime {
+			return nil, &simError{code: errCodeBlockTimestampInvalid, msg: fmt.Sprintf("block timestamps must be in order: %d <= %d", *block.BlockOverrides.Time, prevTime)}
+		}
+		prevTime = uint64(*block.BlockOverrides.Time)
+		res = append(res, block)
+	}
+	return res, nil
+}
+
+// makeHeader builds the header of a simulated block on top of the parent.
+// The roots and the gas used are filled after processing the block.
+func (sim *simulator) makeHeader(overrides *BlockOverrides, parent *types.Header) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Rewardbase: sim.base.Rewardbase,
+		BlockScore: new(big.Int).Set(sim.base.BlockScore),
+		Number:     new(big.Int).Set(overrides.Number.ToInt()),
+		Time:       new(big.Int).SetUint64(uint64(*overrides.Time)),
+		MixHash:    common.CopyBytes(sim.base.MixHash),
+	}
+	if overrides.FeeRecipient != nil {
+		header.Rewardbase = *overrides.FeeRecipient
+	}
+	// Unless validating, the base fee is zero to allow calls without gas price.
+	if sim.chainConfig.IsMagmaForkEnabled(header.Number) {
+		switch {
+		case overrides.BaseFeePerGas != nil:
+			header.BaseFee = new(big.Int).Set(overrides.BaseFeePerGas.ToInt())
+		case sim.validate && sim.base.BaseFee != nil:
+			header.BaseFee = new(big.Int).Set(sim.base.BaseFee)
+		default:
+			header.BaseFee = new(big.Int).SetUint64(params.ZeroBaseFee)
+		}
+	}
+	return header
+}
+
+// processBlock applies the state overrides and executes the calls of the
+// given block on top of the parent.
+func (sim *simulator) processBlock(ctx context.Context, block *SimBlock, parent *types.Header) (*simBlockResult, error) {
+	var (
+		header   = sim.makeHeader(block.BlockOverrides, parent)
+		rules    = sim.chainConfig.Rules(header.Number)
+		gasLimit = params.UpperGasLimit // There is no block gas limit in Kaia.
+	)
+	if block.BlockOverrides.GasLimit != nil {
+		gasLimit = uint64(*block.BlockOverrides.GasLimit)
+	}
+	precompiles := make(map[common.Address]vm.PrecompiledContract)
+	for addr, p := range vm.ActivePrecompiledContracts(rules) {
+		precompiles[addr] = p
+	}
+	if err := block.StateOverrides.Apply(sim.state, precompiles); err != nil {
+		return nil, err
+	}
+	// The moved precompiled contracts are called as smart contract accounts.
+	for addr := range precompiles {
+		if !common.IsPrecompiledContractAddress(addr) && !sim.state.Exist(addr) {
+			sim.state.CreateSmartContractAccount(addr, params.CodeFormatEVM, rules)
+		}
+	}
+
+	var (
+		gasUsed  uint64
+		txs      = make([]*types.Transaction, len(block.Calls))
+		senders  = make([]common.Address, len(block.Calls))
+		receipts = make([]*types.Receipt, len(block.Calls))
+		calls    = make([]SimCallResult, len(block.Calls))
+	)
+	for i := range block.Calls {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		msg, err := sim.toMessage(&block.Calls[i], header, rules, gasLimit-gasUsed)
+		if err != nil {
+			return nil, err
+		}
+		txHash := msg.Hash()
+		sim.state.SetTxContext(txHash, common.Hash{}, i)
+
+		vmConfig := vm.Config{ComputationCostLimit: params.OpcodeComputationCostLimitInfinite}
+		var tracer *simLogTracer
+		if sim.traceTransfers {
+			tracer = newSimLogTracer()
+			vmConfig.Debug = true
+			vmConfig.Tracer = tracer
+		}
+		evm, vmError, err := sim.b.GetEVM(ctx, msg, sim.state, header, vmConfig)
+		if err != nil {
+			return nil, err
+		}
+		evm.Context.Coinbase = header.Rewardbase
+		evm.Context.GasLimit = gasLimit
+		evm.Context.GetHash = sim.getHashFn(ctx, header)
+		block.BlockOverrides.Apply(&evm.Context)
+		evm.SetPrecompiles(precompiles)
+
+		// Wait for the context to be done and cancel the evm. Even if the
+		// EVM has finished, cancelling may be done (repeatedly)
+		go func() {
+			<-ctx.Done()
+			evm.Cancel(vm.CancelByCtxDone)
+		}()
+
+		result, err := blockchain.ApplyMessage(evm, msg)
+		if err := vmError(); err != nil {
+			return nil, err
+		}
+		if evm.Cancelled() {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, txValidationError(err)
+		}
+		sim.state.Finalise(true, true)
+
+		gasUsed += result.UsedGas
+		sim.gasRemaining -= result.UsedGas
+
+		var logs []*types.Log
+		if tracer != nil {
+			logs = tracer.Logs()
+		} else {
+			logs = sim.state.GetLogs(txHash)
+		}
+		call := SimCallResult{
+			ReturnValue: result.Return(),
+			Logs:        logs,
+			GasUsed:     hexutil.Uint64(result.UsedGas),
+			Status:      hexutil.Uint64(types.ReceiptStatusSuccessful),
+		}
+		if result.Failed() {
+			call.Status = hexutil.Uint64(types.ReceiptStatusFailed)
+			call.Logs = nil
+			if result.VmExecutionStatus == types.ReceiptStatusErrExecutionReverted {
+				revertErr := blockchain.NewRevertError(result)
+				call.ReturnValue = result.Revert()
+				call.Error = &SimCallError{Message: revertErr.Error(), Code: errCodeReverted, Data: revertErr.ErrorData().(string)}
+			} else {
+				call.Error = &SimCallError{Message: result.Unwrap().Error(), Code: errCodeVMError}
+			}
+		}
+		receipt := types.NewReceipt(result.VmExecutionStatus, txHash, result.UsedGas)
+		receipt.Logs = call.Logs
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		if msg.To() == nil {
+			receipt.ContractAddress = crypto.CreateAddress(msg.ValidatedSender(), msg.Nonce())
+		}
+
+		txs[i], senders[i], receipts[i], calls[i] = msg, msg.ValidatedSender(), receipt, call
+	}
+	header.GasUsed = gasUsed
+	header.Root = sim.state.IntermediateRoot(true)
+	b := types.NewBlock(header, txs, receipts)
+
+	// Fill the block context of the logs now that the block hash is known.
+	var logIndex uint
+	for i := range calls {
+		for _, log := range calls[i].Logs {
+			log.BlockNumber = b.NumberU64()
+			log.BlockHash = b.Hash()
+			log.TxHash = txs[i].Hash()
+			log.TxIndex = uint(i)
+			log.Index = logIndex
+			logIndex++
+		}
+		if calls[i].Logs == nil {
+			calls[i].Logs = []*types.Log{}
+		}
+	}
+	return &simBlockResult{block: b, gasLimit: gasLimit, senders: senders, calls: calls}, nil
+}
+
+// toMessage converts the call arguments to a message executed in the given
+// block. If gas is not specified, the call may use all the gas left in the
+// block. The nonce is taken from the state unless specified.
+func (sim *simulator) toMessage(args *EthTransactionArgs, header *types.Header, rules params.Rules, blockGasLeft uint64) (*types.Transaction, error) {
+	if args.Gas == nil {
+		gas := hexutil.Uint64(blockGasLeft)
+		if sim.gasRemaining < blockGasLeft {
+			gas = hexutil.Uint64(sim.gasRemaining)
+		}
+		args.Gas = &gas
+	}
+	if gas := uint64(*args.Gas); gas > blockGasLeft {
+		return nil, &simError{code: errCodeBlockGasLimitReached, msg: fmt.Sprintf("block gas limit reached: %d >= %d", gas, blockGasLeft)}
+	} else if gas > sim.gasRemaining {
+		return nil, &simError{code: errCodeClientLimitExceeded, msg: fmt.Sprintf("gas budget exceeded: %d >= %d", gas, sim.gasRemaining)}
+	}
+	baseFee := new(big.Int).SetUint64(params.ZeroBaseFee)
+	if header.BaseFee != nil {
+		baseFee = header.BaseFee
+	}
+	intrinsicGas, err := types.IntrinsicGas(args.data(), nil, args.To == nil, rules)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := args.ToMessage(0, baseFee, intrinsicGas)
+	if err != nil {
+		return nil, err
+	}
+	if sim.validate && header.BaseFee != nil && msg.GasPrice().Cmp(header.BaseFee) < 0 {
+		return nil, &simError{code: errCodeFeeCapTooLow, msg: fmt.Sprintf("max fee per gas less than block base fee: address %s, maxFeePerGas: %s, baseFee: %s", args.from().Hex(), msg.GasPrice(), header.BaseFee)}
+	}
+	nonce := sim.state.GetNonce(msg.ValidatedSender())
+	if args.Nonce != nil {
+		nonce = uint64(*args.Nonce)
+	}
+	return types.NewMessage(msg.ValidatedSender(), msg.To(), nonce, msg.Value(), msg.Gas(), msg.GasPrice(), msg.Data(), sim.validate, intrinsicGas, msg.AccessList()), nil
+}
+
+// getHashFn returns a GetHashFunc resolving the hashes of the simulated
+// blocks as well as the canonical blocks up to the base block.
+func (sim *simulator) getHashFn(ctx context.Context, header *types.Header) vm.GetHashFunc {
+	return func(n uint64) common.Hash {
+		current := header.Number.Uint64()
+		if n >= current || current-n > 256 {
+			return common.Hash{}
+		}
+		if hash, ok := sim.hashes[n]; ok {
+			return hash
+		}
+		if n > sim.base.Number.Uint64() {
+			return common.Hash{}
+		}
+		if n == sim.base.Number.Uint64() {
+			return sim.base.Hash()
+		}
+		h, err := sim.b.HeaderByNumber(ctx, rpc.BlockNumber(n))
+		if err != nil || h == nil {
+			return common.Hash{}
+		}
+		return h.Hash()
+	}
+}
+
+// SimulateV1 executes series of calls in the simulated blocks on top of the
+// given block, and returns the blocks with the results of the calls.
+// The state changes of a block are visible to the following blocks.
+func (api *EthereumAPI) SimulateV1(ctx context.Context, opts SimOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	results, err := doSimulate(ctx, api.publicBlockChainAPI.b, opts, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	config := api.publicBlockChainAPI.b.ChainConfig()
+	blocks := make([]map[string]interface{}, len(results))
+	for i, result := range results {
+		fields, err := api.rpcMarshalBlock(result.block, false, true, false)
+		if err != nil {
+			return nil, err
+		}
+		// The simulated blocks are neither sealed nor part of the chain.
+		delete(fields, "totalDifficulty")
+		fields["miner"] = result.block.Rewardbase()
+		fields["gasLimit"] = hexutil.Uint64(result.gasLimit)
+		if opts.ReturnFullTransactions {
+			txs := result.block.Transactions()
+			transactions := make([]interface{}, len(txs))
+			for j, tx := range txs {
+				rpcTx := newEthRPCTransaction(result.block, tx, result.block.Hash(), result.block.NumberU64(), uint64(j), config)
+				rpcTx.From = result.senders[j]
+				transactions[j] = rpcTx
+			}
+			fields["transactions"] = transactions
+		}
+		fields["calls"] = result.calls
+		blocks[i] = fields
+	}
+	return blocks, nil
+}
+
+// SimulateV1 executes series of calls in the simulated blocks on top of the
+// given block, and returns the blocks with the results of the calls.
+// The state changes of a block are visible to the following blocks.
+func (s *PublicBlockChainAPI) SimulateV1(ctx context.Context, opts SimOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	results, err := doSimulate(ctx, s.b, opts, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	config := s.b.ChainConfig()
+	blocks := make([]map[string]interface{}, len(results))
+	for i, result := range results {
+		fields, err := RpcOutputBlock(result.block, nil, true, false, config)
+		if err != nil {
+			return nil, err
+		}
+		// The simulated blocks are neither sealed nor part of the chain.
+		delete(fields, "totalBlockScore")
+		fields["gasLimit"] = hexutil.Uint64(result.gasLimit)
+		if opts.ReturnFullTransactions {
+			txs := result.block.Transactions()
+			transactions := make([]interface{}, len(txs))
+			for j, tx := range txs {
+				rpcTx := newRPCTransaction(result.block, tx, result.block.Hash(), result.block.NumberU64(), uint64(j), config)
+				rpcTx["from"] = result.senders[j]
+				transactions[j] = rpcTx
+			}
+			fields["transactions"] = transactions
+		}
+		fields["calls"] = result.calls
+		blocks[i] = fields
+	}
+	return blocks, nil
+}
@@ -0,0 +1,281 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mock_api "github.com/klaytn/klaytn/api/mocks"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	simAccount1 = common.HexToAddress("0xaaaa")
+	simAccount2 = common.HexToAddress("0xbbbb")
+	// PUSH1 0x2a, PUSH1 0, MSTORE, LOG1(offset 0, size 0x20, topic 1), RETURN(0, 0x20)
+	simLogContract = common.HexToAddress("0xcccc")
+	// REVERT(0, 0)
+	simRevertContract = common.HexToAddress("0xdddd")
+	// TIMESTAMP, PUSH1 0, MSTORE, RETURN(0, 0x20)
+	simTimeContract = common.HexToAddress("0xeeee")
+)
+
+func setupSimulateBackend(t *testing.T, mockBackend *mock_api.MockBackend) {
+	chainConfig := &params.ChainConfig{}
+	chainConfig.IstanbulCompatibleBlock = common.Big0
+	chainConfig.LondonCompatibleBlock = common.Big0
+	chainConfig.EthTxTypeCompatibleBlock = common.Big0
+	chainConfig.MagmaCompatibleBlock = common.Big0
+	var (
+		gspec = &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+			simAccount1:       {Balance: big.NewInt(params.KAIA * 2)},
+			simLogContract:    {Balance: common.Big0, Code: hexutil.MustDecode("0x602a600052600160206000a160206000f3")},
+			simRevertContract: {Balance: common.Big0, Code: hexutil.MustDecode("0x60006000fd")},
+			simTimeContract:   {Balance: common.Big0, Code: hexutil.MustDecode("0x4260005260206000f3")},
+		}, Config: chainConfig}
+		dbm    = database.NewMemoryDBManager()
+		db     = state.NewDatabase(dbm)
+		block  = gspec.MustCommit(dbm)
+		header = block.Header()
+		chain  = &testChainContext{header: header}
+	)
+	any := gomock.Any()
+	getStateAndHeader := func(...interface{}) (*state.StateDB, *types.Header, error) {
+		state, err := state.New(block.Root(), db, nil, nil)
+		return state, header, err
+	}
+	getEVM := func(_ context.Context, msg blockchain.Message, state *state.StateDB, header *types.Header, vmConfig vm.Config) (*vm.EVM, func() error, error) {
+		vmError := func() error { return nil }
+		txContext := blockchain.NewEVMTxContext(msg, header, chainConfig)
+		blockContext := blockchain.NewEVMBlockContext(header, chain, nil)
+		return vm.NewEVM(blockContext, txContext, state, chainConfig, &vmConfig), vmError, nil
+	}
+	mockBackend.EXPECT().ChainConfig().Return(chainConfig).AnyTimes()
+	mockBackend.EXPECT().RPCGasCap().Return(common.Big0).AnyTimes()
+	mockBackend.EXPECT().RPCEVMTimeout().Return(5 * time.Second).AnyTimes()
+	mockBackend.EXPECT().StateAndHeaderByNumberOrHash(any, any).DoAndReturn(getStateAndHeader).AnyTimes()
+	mockBackend.EXPECT().GetEVM(any, any, any, any, any).DoAndReturn(getEVM).AnyTimes()
+	mockBackend.EXPECT().GetTd(any).Return(nil).AnyTimes()
+}
+
+func TestEthereumAPI_SimulateV1(t *testing.T) {
+	mockCtrl, mockBackend, api := testInitForEthApi(t)
+	defer mockCtrl.Finish()
+	setupSimulateBackend(t, mockBackend)
+
+	var (
+		KAIA       = hexutil.Big(*big.NewInt(params.KAIA))
+		blockNum3  = hexutil.Big(*big.NewInt(3))
+		feeAccount = common.HexToAddress("0xfee")
+	)
+	blocks, err := api.SimulateV1(context.Background(), SimOpts{
+		TraceTransfers: true,
+		BlockStateCalls: []SimBlock{
+			{
+				BlockOverrides: &BlockOverrides{FeeRecipient: &feeAccount},
+				Calls: []EthTransactionArgs{
+					{From: &simAccount1, To: &simAccount2, Value: &KAIA},
+					{From: &simAccount1, To: &simLogContract},
+				},
+			},
+			{
+				BlockOverrides: &BlockOverrides{Number: &blockNum3},
+				Calls:          []EthTransactionArgs{{From: &simAccount2, To: &simRevertContract}},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+
+	// The gap between the block numbers is filled with an empty block.
+	for i, block := range blocks {
+		assert.Equal(t, (*hexutil.Big)(big.NewInt(int64(i+1))), block["number"])
+		assert.Equal(t, hexutil.Big(*big.NewInt(int64(i + 1))), block["timestamp"])
+	}
+	assert.Equal(t, blocks[0]["hash"], blocks[1]["parentHash"])
+	assert.Equal(t, feeAccount, blocks[0]["miner"])
+	assert.Len(t, blocks[0]["transactions"], 2)
+	assert.Empty(t, blocks[1]["calls"])
+
+	calls := blocks[0]["calls"].([]SimCallResult)
+	require.Len(t, calls, 2)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), calls[0].Status)
+	assert.Equal(t, hexutil.Uint64(params.TxGas), calls[0].GasUsed)
+	require.Len(t, calls[0].Logs, 1)
+	assert.Equal(t, transferAddress, calls[0].Logs[0].Address)
+	assert.Equal(t, []common.Hash{transferTopic, common.BytesToHash(simAccount1.Bytes()), common.BytesToHash(simAccount2.Bytes())}, calls[0].Logs[0].Topics)
+	assert.Equal(t, common.BigToHash(KAIA.ToInt()).Bytes(), calls[0].Logs[0].Data)
+
+	assert.Equal(t, hexutil.Bytes(common.BigToHash(big.NewInt(0x2a)).Bytes()), calls[1].ReturnValue)
+	require.Len(t, calls[1].Logs, 1)
+	assert.Equal(t, simLogContract, calls[1].Logs[0].Address)
+	assert.Equal(t, uint(1), calls[1].Logs[0].Index)
+	assert.Equal(t, uint(1), calls[1].Logs[0].TxIndex)
+	assert.Equal(t, blocks[0]["hash"], calls[1].Logs[0].BlockHash)
+
+	// The transferred KAIA is available in the following blocks.
+	calls = blocks[2]["calls"].([]SimCallResult)
+	require.Len(t, calls, 1)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusFailed), calls[0].Status)
+	require.NotNil(t, calls[0].Error)
+	assert.Equal(t, errCodeReverted, calls[0].Error.Code)
+	assert.Empty(t, calls[0].Logs)
+}
+
+func TestEthereumAPI_SimulateV1MovePrecompile(t *testing.T) {
+	mockCtrl, mockBackend, api := testInitForEthApi(t)
+	defer mockCtrl.Finish()
+	setupSimulateBackend(t, mockBackend)
+
+	var (
+		identity = common.BytesToAddress([]byte{0x04})
+		moved    = common.HexToAddress("0x1234")
+		input    = hexutil.Bytes{0xde, 0xad, 0xbe, 0xef}
+	)
+	blocks, err := api.SimulateV1(context.Background(), SimOpts{
+		BlockStateCalls: []SimBlock{{
+			StateOverrides: &EthStateOverride{identity: {MovePrecompileTo: &moved}},
+			Calls: []EthTransactionArgs{
+				{From: &simAccount1, To: &moved, Input: &input},
+				{From: &simAccount1, To: &identity, Input: &input},
+			},
+		}},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+
+	calls := blocks[0]["calls"].([]SimCallResult)
+	require.Len(t, calls, 2)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusSuccessful), calls[0].Status)
+	assert.Equal(t, input, calls[0].ReturnValue)
+	assert.Equal(t, hexutil.Uint64(types.ReceiptStatusFailed), calls[1].Status)
+
+	// Moving precompiled contracts is not allowed in eth_call.
+	_, err = api.Call(context.Background(), EthTransactionArgs{From: &simAccount1, To: &moved}, rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber), &EthStateOverride{identity: {MovePrecompileTo: &moved}}, nil)
+	assert.EqualError(t, err, "movePrecompileToAddress is not supported")
+}
+
+func TestEthereumAPI_SimulateV1Errors(t *testing.T) {
+	mockCtrl, mockBackend, api := testInitForEthApi(t)
+	defer mockCtrl.Finish()
+	setupSimulateBackend(t, mockBackend)
+
+	var (
+		blockNum1 = hexutil.Big(*big.NewInt(1))
+		time1     = hexutil.Uint64(1)
+		nonce1    = hexutil.Uint64(1)
+		gas100k   = hexutil.Uint64(100000)
+		gas50k    = hexutil.Uint64(50000)
+	)
+	testcases := []struct {
+		opts SimOpts
+		code int
+	}{
+		{ // empty input
+			opts: SimOpts{},
+			code: errCodeInvalidParams,
+		},
+		{ // block numbers out of order
+			opts: SimOpts{BlockStateCalls: []SimBlock{{}, {BlockOverrides: &BlockOverrides{Number: &blockNum1}}}},
+			code: errCodeBlockNumberInvalid,
+		},
+		{ // timestamps out of order
+			opts: SimOpts{BlockStateCalls: []SimBlock{{}, {BlockOverrides: &BlockOverrides{Time: &time1}}}},
+			code: errCodeBlockTimestampInvalid,
+		},
+		{ // nonce validation
+			opts: SimOpts{Validation: true, BlockStateCalls: []SimBlock{{Calls: []EthTransactionArgs{{From: &simAccount1, To: &simAccount2, Nonce: &nonce1}}}}},
+			code: errCodeNonceTooHigh,
+		},
+		{ // block gas limit
+			opts: SimOpts{BlockStateCalls: []SimBlock{{BlockOverrides: &BlockOverrides{GasLimit: &gas50k}, Calls: []EthTransactionArgs{{From: &simAccount1, To: &simAccount2, Gas: &gas100k}}}}},
+			code: errCodeBlockGasLimitReached,
+		},
+	}
+	for i, tc := range testcases {
+		_, err := api.SimulateV1(context.Background(), tc.opts, nil)
+		require.Error(t, err, i)
+		simErr, ok := err.(*simError)
+		require.True(t, ok, i)
+		assert.Equal(t, tc.code, simErr.ErrorCode(), i)
+	}
+}
+
+func TestPublicBlockChainAPI_SimulateV1(t *testing.T) {
+	mockCtrl, mockBackend, api := testInitForEthApi(t)
+	defer mockCtrl.Finish()
+	setupSimulateBackend(t, mockBackend)
+
+	KAIA := hexutil.Big(*big.NewInt(params.KAIA))
+	blocks, err := api.publicBlockChainAPI.SimulateV1(context.Background(), SimOpts{
+		ReturnFullTransactions: true,
+		BlockStateCalls: []SimBlock{{
+			Calls: []EthTransactionArgs{{From: &simAccount1, To: &simAccount2, Value: &KAIA}},
+		}},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+
+	txs := blocks[0]["transactions"].([]interface{})
+	require.Len(t, txs, 1)
+	assert.Equal(t, simAccount1, txs[0].(map[string]interface{})["from"])
+	assert.NotContains(t, blocks[0], "totalBlockScore")
+	assert.Len(t, blocks[0]["calls"], 1)
+}
+
+func TestEthereumAPI_CallWithBlockOverrides(t *testing.T) {
+	mockCtrl, mockBackend, api := testInitForEthApi(t)
+	defer mockCtrl.Finish()
+	setupSimulateBackend(t, mockBackend)
+
+	var (
+		contract = simTimeContract
+		future   = hexutil.Uint64(1 << 40)
+		latest   = rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		expected = hexutil.Bytes(common.BigToHash(new(big.Int).SetUint64(uint64(future))).Bytes())
+	)
+	ret, err := api.Call(context.Background(), EthTransactionArgs{From: &simAccount1, To: &contract}, latest, nil, &BlockOverrides{Time: &future})
+	require.NoError(t, err)
+	assert.Equal(t, expected, ret)
+
+	ret, err = api.publicBlockChainAPI.Call(context.Background(), CallArgs{From: simAccount1, To: &contract}, latest, &BlockOverrides{Time: &future})
+	require.NoError(t, err)
+	assert.Equal(t, expected, ret)
+
+	// Without block overrides, the timestamp of the genesis is returned.
+	ret, err = api.publicBlockChainAPI.Call(context.Background(), CallArgs{From: simAccount1, To: &contract}, latest, nil)
+	require.NoError(t, err)
+	assert.Equal(t, hexutil.Bytes(common.Hash{}.Bytes()), ret)
+
+	gas, err := api.EstimateGas(context.Background(), EthTransactionArgs{From: &simAccount1, To: &contract}, nil, &BlockOverrides{Time: &future})
+	require.NoError(t, err)
+	assert.Equal(t, hexutil.Uint64(params.TxGas+17), gas)
+}
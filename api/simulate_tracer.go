@@ -0,0 +1,139 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+)
+
+var (
+	// transferAddress is the address of the pseudo contract emitting the
+	// logs of KAIA transfers, as defined in ERC-7528.
+	transferAddress = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+	// transferTopic is the topic of the ERC-20 Transfer event.
+	transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+// simLogTracer collects the logs emitted during a simulated call. It also
+// emits an ERC-20 Transfer log for every KAIA transfer. The logs of a call
+// frame are discarded if the frame fails.
+type simLogTracer struct {
+	// logs keeps the logs of each call frame in the current call stack.
+	logs [][]*types.Log
+}
+
+func newSimLogTracer() *simLogTracer {
+	return &simLogTracer{}
+}
+
+// CaptureTxStart implements the Tracer interface and is not used.
+func (t *simLogTracer) CaptureTxStart(gasLimit uint64) {}
+
+// CaptureTxEnd implements the Tracer interface and is not used.
+func (t *simLogTracer) CaptureTxEnd(restGas uint64) {}
+
+// CaptureStart implements the Tracer interface to open the top call frame.
+func (t *simLogTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.logs = append(t.logs, make([]*types.Log, 0))
+	if value != nil && value.Sign() > 0 {
+		t.captureTransfer(from, to, value)
+	}
+}
+
+// CaptureEnd implements the Tracer interface to discard the logs if the
+// call failed.
+func (t *simLogTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	if err != nil && len(t.logs) > 0 {
+		t.logs[0] = nil
+	}
+}
+
+// CaptureEnter implements the Tracer interface to open a nested call frame.
+func (t *simLogTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.logs = append(t.logs, make([]*types.Log, 0))
+	// The value of DELEGATECALL is the one of the parent frame.
+	if typ != vm.DELEGATECALL && value != nil && value.Sign() > 0 {
+		t.captureTransfer(from, to, value)
+	}
+}
+
+// CaptureExit implements the Tracer interface to merge the logs of the
+// nested call frame into its parent unless the frame failed.
+func (t *simLogTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	size := len(t.logs)
+	if size <= 1 {
+		return
+	}
+	call := t.logs[size-1]
+	t.logs = t.logs[:size-1]
+	if err == nil {
+		t.logs[size-2] = append(t.logs[size-2], call...)
+	}
+}
+
+// CaptureState implements the Tracer interface to collect the logs emitted
+// by the LOG opcodes.
+func (t *simLogTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+	if err != nil || op < vm.LOG0 || op > vm.LOG4 {
+		return
+	}
+	var (
+		stack  = scope.Stack
+		size   = int(op - vm.LOG0)
+		topics = make([]common.Hash, size)
+	)
+	mStart, mSize := stack.Back(0), stack.Back(1)
+	for i := 0; i < size; i++ {
+		topics[i] = stack.Back(2 + i).Bytes32()
+	}
+	t.appendLog(&types.Log{
+		Address: scope.Contract.Address(),
+		Topics:  topics,
+		Data:    scope.Memory.GetCopy(int64(mStart.Uint64()), int64(mSize.Uint64())),
+	})
+}
+
+// CaptureFault implements the Tracer interface and is not used.
+func (t *simLogTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// captureTransfer emits the ERC-7528 log of a KAIA transfer.
+func (t *simLogTracer) captureTransfer(from, to common.Address, value *big.Int) {
+	t.appendLog(&types.Log{
+		Address: transferAddress,
+		Topics:  []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    common.BigToHash(value).Bytes(),
+	})
+}
+
+func (t *simLogTracer) appendLog(log *types.Log) {
+	t.logs[len(t.logs)-1] = append(t.logs[len(t.logs)-1], log)
+}
+
+// Logs returns the logs collected in the top call frame.
+func (t *simLogTracer) Logs() []*types.Log {
+	if len(t.logs) == 0 {
+		return nil
+	}
+	return t.logs[0]
+}
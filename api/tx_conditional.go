@@ -0,0 +1,72 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// errCodeConditionRejected is the error code of a conditional transaction
+// whose preconditions can't be met.
+const errCodeConditionRejected = -32003
+
+// txConditionalError is an error of sendRawTransactionConditional carrying a
+// JSON-RPC error code.
+type txConditionalError struct {
+	code int
+	err  error
+}
+
+func (e *txConditionalError) Error() string  { return e.err.Error() }
+func (e *txConditionalError) Unwrap() error  { return e.err }
+func (e *txConditionalError) ErrorCode() int { return e.code }
+
+// SendRawTransactionConditional adds the signed transaction to the transaction
+// pool with its preconditions: the ranges of the block number and timestamp,
+// and the storage roots or slots of the known accounts. The transaction is
+// rejected if the preconditions can't be met on top of the latest block, and
+// is dropped from the pool if they are violated later. The conditional
+// transaction is kept only in this node and is not propagated to the peers,
+// so only the consensus nodes accept it.
+func (s *PublicTransactionPoolAPI) SendRawTransactionConditional(ctx context.Context, encodedTx hexutil.Bytes, cond types.TransactionConditional) (common.Hash, error) {
+	if err := cond.Validate(); err != nil {
+		return common.Hash{}, &txConditionalError{errCodeInvalidParams, err}
+	}
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	state, head, err := s.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return common.Hash{}, err
+	}
+	// The timestamp of the next block is at least the one of the latest block.
+	next := new(big.Int).Add(head.Number, common.Big1)
+	if err := cond.Check(next, head.Time.Uint64(), state); errors.Is(err, types.ErrTxConditionFailed) {
+		return common.Hash{}, &txConditionalError{errCodeConditionRejected, err}
+	}
+	tx.SetConditional(&cond)
+	return submitTransaction(ctx, s.b, tx)
+}
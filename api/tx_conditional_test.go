@@ -0,0 +1,89 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mock_api "github.com/klaytn/klaytn/api/mocks"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicTransactionPoolAPI_SendRawTransactionConditional(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockBackend := mock_api.NewMockBackend(mockCtrl)
+	api := NewPublicTransactionPoolAPI(mockBackend, new(AddrLocker))
+	transfer := setupBundleBackend(t, mockBackend)
+
+	latest := rpc.NewBlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	mockBackend.EXPECT().StateAndHeaderByNumber(gomock.Any(), rpc.LatestBlockNumber).DoAndReturn(
+		func(ctx context.Context, _ rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+			return mockBackend.StateAndHeaderByNumberOrHash(ctx, latest)
+		}).AnyTimes()
+	var sent []*types.Transaction
+	mockBackend.EXPECT().SendTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, tx *types.Transaction) error {
+		sent = append(sent, tx)
+		return nil
+	}).AnyTimes()
+
+	blockNumber := func(n int64) *hexutil.Big { return (*hexutil.Big)(big.NewInt(n)) }
+	emptySlot := map[common.Hash]common.Hash{{}: {}}
+
+	// The preconditions to be met in the next block or later are accepted.
+	cond := types.TransactionConditional{
+		BlockNumberMin: blockNumber(5),
+		BlockNumberMax: blockNumber(10),
+		KnownAccounts:  map[common.Address]types.KnownAccount{simRevertContract: {StorageSlots: emptySlot}},
+	}
+	hash, err := api.SendRawTransactionConditional(context.Background(), transfer(0, simAccount2), cond)
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+	assert.Equal(t, sent[0].Hash(), hash)
+	assert.Equal(t, &cond, sent[0].Conditional())
+
+	// The preconditions which can't be met are rejected.
+	otherRoot := common.HexToHash("0x1")
+	for _, cond := range []types.TransactionConditional{
+		{BlockNumberMax: blockNumber(0)},
+		{KnownAccounts: map[common.Address]types.KnownAccount{simRevertContract: {StorageRoot: &otherRoot}}},
+	} {
+		_, err := api.SendRawTransactionConditional(context.Background(), transfer(1, simAccount2), cond)
+		assert.ErrorIs(t, err, types.ErrTxConditionFailed)
+		var condErr *txConditionalError
+		require.True(t, errors.As(err, &condErr))
+		assert.Equal(t, errCodeConditionRejected, condErr.ErrorCode())
+	}
+
+	// The malformed preconditions are invalid.
+	_, err = api.SendRawTransactionConditional(context.Background(), transfer(1, simAccount2),
+		types.TransactionConditional{BlockNumberMin: blockNumber(2), BlockNumberMax: blockNumber(1)})
+	var condErr *txConditionalError
+	require.True(t, errors.As(err, &condErr))
+	assert.Equal(t, errCodeInvalidParams, condErr.ErrorCode())
+	assert.Len(t, sent, 1)
+}
@@ -2404,10 +2404,11 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 		commonBlock *types.Block
 		deletedTxs  types.Transactions
 		deletedLogs []*types.Log
+		rebirthLogs []*types.Log
 		// collectLogs collects the logs that were generated during the
 		// processing of the block that corresponds with the given hash.
-		// These logs are later announced as deleted.
-		collectLogs = func(hash common.Hash) {
+		// These logs are later announced as deleted or reborn.
+		collectLogs = func(hash common.Hash, removed bool) {
 			// Coalesce logs and set 'Removed'.
 			number := bc.GetBlockNumber(hash)
 			if number == nil {
@@ -2416,9 +2417,13 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 			receipts := bc.db.ReadReceipts(hash, *number)
 			for _, receipt := range receipts {
 				for _, log := range receipt.Logs {
-					del := *log
-					del.Removed = true
-					deletedLogs = append(deletedLogs, &del)
+					l := *log
+					if removed {
+						l.Removed = true
+						deletedLogs = append(deletedLogs, &l)
+					} else {
+						rebirthLogs = append(rebirthLogs, &l)
+					}
 				}
 			}
 		}
@@ -2431,7 +2436,7 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 			oldChain = append(oldChain, oldBlock)
 			deletedTxs = append(deletedTxs, oldBlock.Transactions()...)
 
-			collectLogs(oldBlock.Hash())
+			collectLogs(oldBlock.Hash(), true)
 		}
 	} else {
 		// reduce new chain and append new chain blocks for inserting later on
@@ -2455,7 +2460,7 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 		oldChain = append(oldChain, oldBlock)
 		newChain = append(newChain, newBlock)
 		deletedTxs = append(deletedTxs, oldBlock.Transactions()...)
-		collectLogs(oldBlock.Hash())
+		collectLogs(oldBlock.Hash(), true)
 
 		oldBlock, newBlock = bc.GetBlock(oldBlock.ParentHash(), oldBlock.NumberU64()-1), bc.GetBlock(newBlock.ParentHash(), newBlock.NumberU64()-1)
 		if oldBlock == nil {
@@ -2484,6 +2489,10 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 		// write lookup entries for hash based transaction/receipt searches
 		bc.db.WriteTxLookupEntries(newChain[i])
 		addedTxs = append(addedTxs, newChain[i].Transactions()...)
+		// The logs of the new head block are posted by the caller.
+		if i > 0 {
+			collectLogs(newChain[i].Hash(), false)
+		}
 	}
 	// calculate the difference between deleted and added transactions
 	diff := types.TxDifference(deletedTxs, addedTxs)
@@ -2492,8 +2501,14 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 	for _, tx := range diff {
 		bc.db.DeleteTxLookupEntry(tx.Hash())
 	}
+	// The removed logs are sent before the logs of the new chain, and both are
+	// sent before returning so that the subscribers see them before the logs
+	// of the new head block posted by the caller.
 	if len(deletedLogs) > 0 {
-		go bc.rmLogsFeed.Send(RemovedLogsEvent{deletedLogs})
+		bc.rmLogsFeed.Send(RemovedLogsEvent{deletedLogs})
+	}
+	if len(rebirthLogs) > 0 {
+		bc.logsFeed.Send(rebirthLogs)
 	}
 	if len(oldChain) > 0 {
 		go func() {
@@ -905,7 +905,7 @@ func TestLogReorgs(t *testing.T) {
 	blockchain, _ := NewBlockChain(db, nil, gspec.Config, gxhash.NewFaker(), vm.Config{})
 	defer blockchain.Stop()
 
-	rmLogsCh := make(chan RemovedLogsEvent)
+	rmLogsCh := make(chan RemovedLogsEvent, 10)
 	blockchain.SubscribeRemovedLogsEvent(rmLogsCh)
 	chain, _ := GenerateChain(params.TestChainConfig, genesis, gxhash.NewFaker(), db, 2, func(i int, gen *BlockGen) {
 		if i == 1 {
@@ -936,6 +936,54 @@ func TestLogReorgs(t *testing.T) {
 	}
 }
 
+func TestLogRebirth(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		db      = database.NewMemoryDBManager()
+		// this code generates a log
+		code    = common.Hex2Bytes("60606040525b7f24ec1d3ff24c2f6ff210738839dbc339cd45a5294d85c79361016243157aae7b60405180905060405180910390a15b600a8060416000396000f360606040526008565b00")
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{addr1: {Balance: big.NewInt(10000000000000)}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.LatestSignerForChainID(gspec.Config.ChainID)
+	)
+
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, gxhash.NewFaker(), vm.Config{})
+	defer blockchain.Stop()
+
+	chain, _ := GenerateChain(params.TestChainConfig, genesis, gxhash.NewFaker(), db, 3, func(i int, gen *BlockGen) {})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	logsCh := make(chan []*types.Log, 10)
+	blockchain.SubscribeLogsEvent(logsCh)
+
+	// The log of the first block of the longer fork is not in its head block.
+	chain, _ = GenerateChain(params.TestChainConfig, genesis, gxhash.NewFaker(), db, 4, func(i int, gen *BlockGen) {
+		if i == 0 {
+			tx, err := types.SignTx(types.NewContractCreation(gen.TxNonce(addr1), new(big.Int), 1000000, new(big.Int), code), signer, key1)
+			if err != nil {
+				t.Fatalf("failed to create tx: %v", err)
+			}
+			gen.AddTx(tx)
+		}
+	})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert forked chain: %v", err)
+	}
+
+	// The logs of the new chain are sent before the insertion returns.
+	select {
+	case logs := <-logsCh:
+		if len(logs) == 0 || logs[0].BlockHash != chain[0].Hash() || logs[0].Removed {
+			t.Fatalf("unexpected logs of the new chain: %v", logs)
+		}
+	default:
+		t.Fatal("The logs of the new chain have not been sent.")
+	}
+}
+
 func TestReorgSideEvent(t *testing.T) {
 	var (
 		db      = database.NewMemoryDBManager()
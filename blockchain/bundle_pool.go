@@ -0,0 +1,288 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/kerrors"
+	"github.com/rcrowley/go-metrics"
+)
+
+const (
+	// MaxBundleTxs is the maximum number of transactions in a bundle.
+	MaxBundleTxs = 16
+	// MaxBundleBlockRange is the maximum number of blocks a bundle can target.
+	MaxBundleBlockRange = 3600
+	// DefaultBundlePoolSize is the default maximum number of bundles in the pool.
+	DefaultBundlePoolSize = 1024
+)
+
+var (
+	ErrEmptyBundle        = errors.New("empty bundle")
+	ErrBundleTooLarge     = errors.New("too many transactions in bundle")
+	ErrInvalidBundleRange = errors.New("invalid bundle block range")
+	ErrBundleRangeTooWide = errors.New("bundle block range too wide")
+	ErrBundleExpired      = errors.New("bundle block range already passed")
+	ErrKnownBundle        = errors.New("already known bundle")
+	ErrBundlePoolFull     = errors.New("bundle pool is full")
+	ErrBundleDuplicatedTx = errors.New("duplicated transaction in bundle")
+)
+
+var (
+	bundlePoolSizeGauge      = metrics.NewRegisteredGauge("bundlepool/size", nil)
+	bundlePoolDiscardCounter = metrics.NewRegisteredCounter("bundlepool/discard", nil) // Dropped after the block ranges
+)
+
+// Bundle is an ordered list of transactions that are included in a block all
+// together in the order, or not at all. It can be included in a block whose
+// number is between MinBlock and MaxBlock.
+type Bundle struct {
+	Txs      types.Transactions
+	MinBlock uint64
+	MaxBlock uint64
+}
+
+// Hash returns the hash of the transaction hashes of the bundle.
+func (b *Bundle) Hash() common.Hash {
+	hashes := make([]byte, 0, len(b.Txs)*common.HashLength)
+	for _, tx := range b.Txs {
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	return crypto.Keccak256Hash(hashes)
+}
+
+// validate checks the bundle can be included in a block after the given head.
+func (b *Bundle) validate(head uint64) error {
+	if len(b.Txs) == 0 {
+		return ErrEmptyBundle
+	}
+	if len(b.Txs) > MaxBundleTxs {
+		return ErrBundleTooLarge
+	}
+	if b.MinBlock > b.MaxBlock {
+		return ErrInvalidBundleRange
+	}
+	if b.MaxBlock-b.MinBlock >= MaxBundleBlockRange {
+		return ErrBundleRangeTooWide
+	}
+	if b.MaxBlock <= head {
+		return ErrBundleExpired
+	}
+	seen := make(map[common.Hash]bool, len(b.Txs))
+	for _, tx := range b.Txs {
+		if seen[tx.Hash()] {
+			return ErrBundleDuplicatedTx
+		}
+		seen[tx.Hash()] = true
+	}
+	return nil
+}
+
+// ValidateTxs checks the bundle can be included in a block after the given
+// head, and its transactions are valid on the state of the head: the
+// signatures, the nonces, the balances covering the costs and the intrinsic
+// gas. As the bundle may target a later block, the nonces are only required to
+// be not lower than the ones of the state and consecutive for the same sender.
+func (b *Bundle) ValidateTxs(signer types.Signer, statedb *state.StateDB, head uint64) error {
+	if err := b.validate(head); err != nil {
+		return err
+	}
+	nonces := make(map[common.Address]uint64)
+	for _, tx := range b.Txs {
+		if err := validateBundleTx(tx, signer, statedb, head, nonces); err != nil {
+			return fmt.Errorf("%w: %v", err, tx.Hash().String())
+		}
+	}
+	return nil
+}
+
+// validateBundleTx checks a transaction of a bundle. The nonces map keeps the
+// next nonce of the senders of the previous transactions in the bundle.
+func validateBundleTx(tx *types.Transaction, signer types.Signer, statedb *state.StateDB, head uint64, nonces map[common.Address]uint64) error {
+	gasFrom, err := tx.ValidateSender(signer, statedb, head)
+	if err != nil {
+		return types.ErrSender(err)
+	}
+	from := tx.ValidatedSender()
+
+	if next, ok := nonces[from]; ok {
+		if tx.Nonce() != next {
+			return ErrNonceTooHigh
+		}
+	} else if tx.Nonce() < statedb.GetNonce(from) {
+		return ErrNonceTooLow
+	}
+	nonces[from] = tx.Nonce() + 1
+
+	var (
+		feeBySender = tx.Fee()
+		gasFeePayer = uint64(0)
+	)
+	if tx.IsFeeDelegatedTransaction() {
+		gasFeePayer, err = tx.ValidateFeePayer(signer, statedb, head)
+		if err != nil {
+			return types.ErrFeePayer(err)
+		}
+		feeByFeePayer := tx.Fee()
+		feeBySender = new(big.Int)
+		if feeRatio, ok := tx.FeeRatio(); ok {
+			if !feeRatio.IsValid() {
+				return kerrors.ErrFeeRatioOutOfRange
+			}
+			feeByFeePayer, feeBySender = types.CalcFeeWithRatio(feeRatio, tx.Fee())
+		}
+		if statedb.GetBalance(tx.ValidatedFeePayer()).Cmp(feeByFeePayer) < 0 {
+			return ErrInsufficientFundsFeePayer
+		}
+	}
+	if statedb.GetBalance(from).Cmp(new(big.Int).Add(tx.Value(), feeBySender)) < 0 {
+		return ErrInsufficientFundsFrom
+	}
+
+	intrGas, err := tx.IntrinsicGas(head)
+	if err != nil {
+		return err
+	}
+	if tx.Gas() < intrGas+gasFrom+gasFeePayer {
+		return ErrIntrinsicGas
+	}
+	return nil
+}
+
+// bundleEntry is a bundle in the pool with its arrival order.
+type bundleEntry struct {
+	bundle *Bundle
+	seq    uint64
+}
+
+// BundlePool keeps the bundles separately from the transaction pool until
+// their block ranges pass. The bundles are not propagated to the peers, so
+// they are only included in the blocks proposed by the node receiving them.
+type BundlePool struct {
+	mu      sync.RWMutex
+	bundles map[common.Hash]*bundleEntry
+	seq     uint64
+	size    int
+}
+
+// NewBundlePool creates a bundle pool keeping at most size bundles.
+func NewBundlePool(size int) *BundlePool {
+	if size <= 0 {
+		size = DefaultBundlePoolSize
+	}
+	return &BundlePool{
+		bundles: make(map[common.Hash]*bundleEntry),
+		size:    size,
+	}
+}
+
+// Add validates the bundle against the current head and adds it to the pool.
+func (pool *BundlePool) Add(bundle *Bundle, head uint64) (common.Hash, error) {
+	if err := bundle.validate(head); err != nil {
+		return common.Hash{}, err
+	}
+	hash := bundle.Hash()
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if _, ok := pool.bundles[hash]; ok {
+		return common.Hash{}, ErrKnownBundle
+	}
+	if len(pool.bundles) >= pool.size {
+		pool.prune(head + 1)
+		if len(pool.bundles) >= pool.size {
+			return common.Hash{}, ErrBundlePoolFull
+		}
+	}
+	pool.seq++
+	pool.bundles[hash] = &bundleEntry{bundle: bundle, seq: pool.seq}
+	bundlePoolSizeGauge.Update(int64(len(pool.bundles)))
+	return hash, nil
+}
+
+// Get returns the bundle of the given hash, or nil if it is not in the pool.
+func (pool *BundlePool) Get(hash common.Hash) *Bundle {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	if entry, ok := pool.bundles[hash]; ok {
+		return entry.bundle
+	}
+	return nil
+}
+
+// Remove removes the bundle of the given hash from the pool.
+func (pool *BundlePool) Remove(hash common.Hash) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	delete(pool.bundles, hash)
+	bundlePoolSizeGauge.Update(int64(len(pool.bundles)))
+}
+
+// Pending removes the bundles whose block ranges end before the given block
+// number, and returns the bundles targeting the block in their arrival order.
+func (pool *BundlePool) Pending(number uint64) []*Bundle {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.prune(number)
+
+	entries := make([]*bundleEntry, 0, len(pool.bundles))
+	for _, entry := range pool.bundles {
+		if entry.bundle.MinBlock <= number {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+
+	bundles := make([]*Bundle, len(entries))
+	for i, entry := range entries {
+		bundles[i] = entry.bundle
+	}
+	return bundles
+}
+
+// Len returns the number of bundles in the pool.
+func (pool *BundlePool) Len() int {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return len(pool.bundles)
+}
+
+// prune removes the bundles which can't be included in the given block number
+// or later. The caller must hold the lock.
+func (pool *BundlePool) prune(number uint64) {
+	for hash, entry := range pool.bundles {
+		if entry.bundle.MaxBlock < number {
+			delete(pool.bundles, hash)
+			bundlePoolDiscardCounter.Inc(1)
+		}
+	}
+	bundlePoolSizeGauge.Update(int64(len(pool.bundles)))
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBundle(minBlock, maxBlock uint64, nonces ...uint64) *Bundle {
+	txs := make(types.Transactions, len(nonces))
+	for i, nonce := range nonces {
+		txs[i] = types.NewTransaction(nonce, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	}
+	return &Bundle{Txs: txs, MinBlock: minBlock, MaxBlock: maxBlock}
+}
+
+func TestBundlePool_Add(t *testing.T) {
+	pool := NewBundlePool(2)
+	head := uint64(10)
+
+	tests := []struct {
+		bundle *Bundle
+		err    error
+	}{
+		{newBundle(11, 12), ErrEmptyBundle},
+		{newBundle(11, 12, make([]uint64, MaxBundleTxs+1)...), ErrBundleTooLarge},
+		{newBundle(12, 11, 0), ErrInvalidBundleRange},
+		{newBundle(11, 11+MaxBundleBlockRange, 0), ErrBundleRangeTooWide},
+		{newBundle(5, 10, 0), ErrBundleExpired},
+		{newBundle(11, 12, 0, 0), ErrBundleDuplicatedTx},
+	}
+	for _, tt := range tests {
+		_, err := pool.Add(tt.bundle, head)
+		assert.ErrorIs(t, err, tt.err)
+	}
+	assert.Equal(t, 0, pool.Len())
+
+	bundle := newBundle(11, 11, 0, 1)
+	hash, err := pool.Add(bundle, head)
+	require.NoError(t, err)
+	assert.Equal(t, bundle.Hash(), hash)
+	assert.Equal(t, bundle, pool.Get(hash))
+
+	_, err = pool.Add(newBundle(11, 11, 0, 1), head)
+	assert.ErrorIs(t, err, ErrKnownBundle)
+
+	_, err = pool.Add(newBundle(11, 20, 2), head)
+	require.NoError(t, err)
+	_, err = pool.Add(newBundle(11, 20, 3), head)
+	assert.ErrorIs(t, err, ErrBundlePoolFull)
+
+	// The expired bundles are pruned to make room for the new one.
+	_, err = pool.Add(newBundle(12, 20, 3), head+1)
+	require.NoError(t, err)
+	assert.Nil(t, pool.Get(hash))
+	assert.Equal(t, 2, pool.Len())
+
+	pool.Remove(bundle.Hash())
+	pool.Remove(newBundle(11, 20, 2).Hash())
+	assert.Equal(t, 1, pool.Len())
+}
+
+func TestBundlePool_Pending(t *testing.T) {
+	var (
+		pool    = NewBundlePool(0)
+		bundles = []*Bundle{
+			newBundle(3, 5, 0),
+			newBundle(1, 2, 1),
+			newBundle(1, 3, 2),
+			newBundle(2, 4, 3),
+		}
+	)
+	for _, bundle := range bundles {
+		_, err := pool.Add(bundle, 0)
+		require.NoError(t, err)
+	}
+	// The bundles are returned in their arrival order.
+	assert.Equal(t, []*Bundle{bundles[1], bundles[2], bundles[3]}, pool.Pending(2))
+	assert.Equal(t, []*Bundle{bundles[0], bundles[2], bundles[3]}, pool.Pending(3))
+	assert.Equal(t, 3, pool.Len())
+	assert.Empty(t, pool.Pending(6))
+	assert.Equal(t, 0, pool.Len())
+}
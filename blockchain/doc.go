@@ -33,6 +33,7 @@ The cacheSender concurrently recovers and caches an address from a signature in
 Each file provides the following features
   - bad_blocks.go : keeps block hashes of bad blocks which are usually for hard forks.
   - block_validator.go : implements BlockValidator which is responsible for validation block headers and the processed state.
+  - bundle_pool.go : keeps the transaction bundles which are included in a block all together or not at all.
   - blockchain.go : implements the canonical chain of blocks and managing functions to support imports, reverts and reorganisations.
   - chain_indexer.go : implements ChainIndexer.
   - chain_makers.go : generates temporary blocks or chains to support SimulatedBackend.
@@ -52,6 +53,7 @@ Each file provides the following features
   - state_transition.go : implements a state transaction model worked with messages in transactions.
   - tx_cacher.go : recovers senders of transactions from signatures and caches the sender address.
   - tx_journal.go: keeps logs of transactions created by the local node.
+  - tx_lifecycle.go : records the lifecycle events of the transactions in tx pool.
   - tx_list.go : provides sorted map and list structures used in tx pool manipulation.
   - tx_pool.go : contains all currently known transactions and manages the transactions.
   - types.go : interfaces Validator and Processor which validate or process block data.
@@ -34,12 +34,14 @@ import (
 var emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
 
 type DumpAccount struct {
-	Balance  string            `json:"balance"`
-	Nonce    uint64            `json:"nonce"`
-	Root     string            `json:"root"`
-	CodeHash string            `json:"codeHash"`
-	Code     string            `json:"code"`
-	Storage  map[string]string `json:"storage"`
+	Balance   string            `json:"balance"`
+	Nonce     uint64            `json:"nonce"`
+	Root      string            `json:"root"`
+	CodeHash  string            `json:"codeHash"`
+	Code      string            `json:"code"`
+	Storage   map[string]string `json:"storage"`
+	Address   *common.Address   `json:"address,omitempty"` // Address only present in iterative (line-by-line) mode
+	SecureKey string            `json:"key,omitempty"`     // If we don't have address, we can output the key
 }
 
 type Dump struct {
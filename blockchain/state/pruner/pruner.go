@@ -0,0 +1,386 @@
+// Modifications Copyright 2024 The klaytn Authors
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+//
+// This file is derived from core/state/pruner/pruner.go (2024/03/18).
+// Modified and improved for the klaytn development.
+
+package pruner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/snapshot"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+)
+
+const (
+	// stateBloomFilePrefix is the filename prefix of state bloom filter.
+	stateBloomFilePrefix = "statebloom"
+
+	// stateBloomFileSuffix is the filename suffix of state bloom filter.
+	stateBloomFileSuffix = "bf.gz"
+
+	// stateBloomFileTempSuffix is the filename suffix of state bloom filter
+	// while it is being written out to detect write aborts.
+	stateBloomFileTempSuffix = ".tmp"
+
+	// snapshotLayers is the number of the snapshot layers searched for the
+	// pruning target, which is the maximum number of the diff layers kept
+	// in the snapshot tree.
+	snapshotLayers = 128
+)
+
+var logger = log.NewModuleLogger(log.BlockchainStatePruner)
+
+// Config includes all the configurations for pruning.
+type Config struct {
+	Datadir   string // The directory of the state bloom checkpoint
+	BloomSize uint64 // The megabytes of memory allocated to bloom-filter
+	DryRun    bool   // Reports the nodes to be deleted without deleting them
+}
+
+// Pruner is an offline tool to prune the stale state with the
+// help of the snapshot. The workflow of pruner is very simple:
+//
+//   - iterate the snapshot, reconstruct the relevant state
+//   - iterate the database, delete all other state entries which
+//     don't belong to the target state and the genesis state
+//
+// It can take several hours(around 2 hours for mainnet) to finish
+// the whole pruning work. It's recommended to run this offline tool
+// periodically in order to release the disk usage and improve the
+// disk read performance to some extent.
+type Pruner struct {
+	config   Config
+	db       database.DBManager
+	head     *types.Block
+	snaptree *snapshot.Tree
+}
+
+// NewPruner creates the pruner instance. The live pruning and the state
+// migration should not be in progress since they manage the trie nodes
+// in their own ways.
+func NewPruner(db database.DBManager, config Config) (*Pruner, error) {
+	if db.ReadPruningEnabled() {
+		return nil, errors.New("the state trie nodes are managed by the live pruning")
+	}
+	if db.InMigration() {
+		return nil, errors.New("the state migration is in progress")
+	}
+	head := db.ReadBlockByHash(db.ReadHeadBlockHash())
+	if head == nil {
+		return nil, errors.New("failed to load head block")
+	}
+	snaptree, err := snapshot.New(db, statedb.NewDatabase(db), 256, head.Root(), false, false, false)
+	if err != nil {
+		return nil, err // The relevant snapshot(s) might not exist
+	}
+	return &Pruner{
+		config:   config,
+		db:       db,
+		head:     head,
+		snaptree: snaptree,
+	}, nil
+}
+
+// Prune deletes all historical state nodes except the nodes belong to the
+// specified state version. If user doesn't specify the state version, use
+// the latest state whose trie is stored in the database within the snapshot
+// layers. If the previous pruning was interrupted after the bloom filter was
+// committed, it is resumed with the checkpoint of the bloom filter.
+func (p *Pruner) Prune(root common.Hash) error {
+	bloomPath, bloomRoot, err := findBloomFilter(p.config.Datadir)
+	if err != nil {
+		return err
+	}
+	if bloomPath != "" {
+		if root != (common.Hash{}) && root != bloomRoot {
+			return fmt.Errorf("the pruning of %x was interrupted, resume it first", bloomRoot)
+		}
+		logger.Info("Resuming the interrupted pruning", "root", bloomRoot, "checkpoint", bloomPath)
+		bloom, err := NewStateBloomFromDisk(bloomPath)
+		if err != nil {
+			return err
+		}
+		return p.prune(bloomRoot, bloom, bloomPath, time.Now())
+	}
+
+	if root == (common.Hash{}) {
+		if root, err = p.findTarget(); err != nil {
+			return err
+		}
+	} else if !p.hasState(root) {
+		return fmt.Errorf("the state of %x is not stored in both the snapshot and the trie", root)
+	}
+	logger.Info("Selected the pruning target", "root", root)
+
+	bloom, err := newStateBloomWithSize(p.config.BloomSize)
+	if err != nil {
+		return err
+	}
+	// Traverse the target state, re-construct the whole state trie and
+	// commit to the given bloom filter.
+	start := time.Now()
+	if err := snapshot.GenerateTrie(p.snaptree, root, p.db, bloom); err != nil {
+		return err
+	}
+	// Traverse the genesis, put all genesis state entries into the
+	// bloom filter too.
+	if err := extractGenesis(p.db, bloom); err != nil {
+		return err
+	}
+	if p.config.DryRun {
+		return p.prune(root, bloom, "", start)
+	}
+	filename := bloomFilterName(p.config.Datadir, root)
+	logger.Info("Writing state bloom to disk", "name", filename)
+	if err := bloom.Commit(filename, filename+stateBloomFileTempSuffix); err != nil {
+		return err
+	}
+	logger.Info("State bloom filter committed", "name", filename)
+	return p.prune(root, bloom, filename, start)
+}
+
+// findTarget returns the root of the latest block whose state is stored in
+// both the snapshot and the trie.
+func (p *Pruner) findTarget() (common.Hash, error) {
+	for i := uint64(0); i < snapshotLayers && i <= p.head.NumberU64(); i++ {
+		header := p.db.ReadHeader(p.db.ReadCanonicalHash(p.head.NumberU64()-i), p.head.NumberU64()-i)
+		if header == nil {
+			break
+		}
+		if p.hasState(header.Root) {
+			return header.Root, nil
+		}
+	}
+	return common.Hash{}, errors.New("no state stored in both the snapshot and the trie")
+}
+
+// hasState reports whether the state of the given root is stored in both
+// the snapshot and the trie. The weak assumption is the presence of root
+// can indicate the presence of the entire trie.
+func (p *Pruner) hasState(root common.Hash) bool {
+	if p.snaptree.Snapshot(root) == nil {
+		return false
+	}
+	ok, _ := p.db.HasTrieNode(root.ExtendZero())
+	return ok
+}
+
+// prune deletes the state trie nodes and the contract codes which are not
+// contained in the bloom filter. In the dry-run mode, they are only counted.
+func (p *Pruner) prune(root common.Hash, bloom *stateBloom, bloomPath string, start time.Time) error {
+	// The state roots of the other snapshot layers are deleted forcibly
+	// since the presence of a root indicates the presence of the entire
+	// trie, which is not true after the pruning.
+	var (
+		middleRoots = make(map[common.Hash]struct{})
+		genesisRoot common.Hash
+	)
+	if genesis := p.db.ReadHeader(p.db.ReadCanonicalHash(0), 0); genesis != nil {
+		genesisRoot = genesis.Root
+	}
+	for _, layer := range p.snaptree.Snapshots(p.head.Root(), snapshotLayers, false) {
+		if layer.Root() != root && layer.Root() != genesisRoot {
+			middleRoots[layer.Root()] = struct{}{}
+		}
+	}
+
+	// Delete all stale trie nodes in the disk. With the help of state bloom
+	// the trie nodes (and codes) belong to the active state will be filtered
+	// out. A very small part of stale tries will also be filtered because of
+	// the false-positive rate of bloom filter. But the assumption is held here
+	// that the false-positive is low enough (~0.05%). The probability of the
+	// dangling node is the state root is super low. So the dangling nodes in
+	// theory will never ever be visited again.
+	var (
+		count, kept int
+		size        common.StorageSize
+		pstart      = time.Now()
+		logged      = time.Now()
+		trieDB      = p.db.GetDatabase(database.StateTrieDB)
+		batch       = p.db.NewBatch(database.StateTrieDB)
+		iter        = trieDB.NewIterator(nil, nil)
+	)
+	defer batch.Release()
+	for iter.Next() {
+		key := iter.Key()
+
+		// All state entries which don't belong to the specific state and the
+		// genesis are deleted here. The legacy contract codes are stored with
+		// the code hash as the key, so that they are checked like trie nodes.
+		isCode, codeKey := database.IsCodeKey(key)
+		if len(key) != common.HashLength && !isCode {
+			continue
+		}
+		checkKey := key
+		if isCode {
+			checkKey = codeKey
+		}
+		if _, exist := middleRoots[common.BytesToHash(checkKey)]; exist {
+			logger.Debug("Forcibly delete the middle state roots", "hash", common.BytesToHash(checkKey))
+		} else if bloom.Contain(checkKey) {
+			kept++
+			continue
+		}
+		count++
+		size += common.StorageSize(len(key) + len(iter.Value()))
+		if !p.config.DryRun {
+			if err := batch.Delete(key); err != nil {
+				iter.Release()
+				return err
+			}
+			if batch.ValueSize() >= database.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					iter.Release()
+					return err
+				}
+				batch.Reset()
+
+				// The iterator is reopened to release the deleted entries.
+				iter.Release()
+				iter = trieDB.NewIterator(nil, key)
+			}
+		}
+		if time.Since(logged) > log.StatsReportLimit {
+			logger.Info("Pruning state data", "nodes", count, "size", size, "kept", kept,
+				"elapsed", common.PrettyDuration(time.Since(pstart)))
+			logged = time.Now()
+		}
+	}
+	iter.Release()
+
+	if p.config.DryRun {
+		logger.Info("Dry-run of the state pruning finished", "root", root, "nodes to prune", count, "size to prune", size,
+			"kept", kept, "elapsed", common.PrettyDuration(time.Since(start)))
+		return nil
+	}
+	if batch.ValueSize() > 0 {
+		if err := batch.Write(); err != nil {
+			return err
+		}
+	}
+	logger.Info("Pruned state data", "nodes", count, "size", size, "kept", kept, "elapsed", common.PrettyDuration(time.Since(pstart)))
+
+	// Pruning is done, now drop the "useless" layers from the snapshot.
+	// Firstly, flushing the target layer into the disk. After that all
+	// diff layers below the target will all be merged into the disk.
+	if root != p.snaptree.DiskRoot() {
+		if err := p.snaptree.Cap(root, 0); err != nil {
+			return err
+		}
+	}
+	// Secondly, flushing the snapshot journal into the disk. All diff
+	// layers upon the target are dropped silently. Eventually the entire
+	// snapshot tree is converted into a single disk layer with the pruning
+	// target as the root. The blocks above the target are rewound when the
+	// node is started again since their states are not available.
+	if _, err := p.snaptree.Journal(root); err != nil {
+		return err
+	}
+	// Delete the state bloom, the mark for the entire pruning procedure is finished.
+	if bloomPath != "" {
+		if err := os.RemoveAll(bloomPath); err != nil {
+			return err
+		}
+	}
+	// Start compactions, will remove the deleted data from the disk immediately.
+	cstart := time.Now()
+	logger.Info("Start compacting the state trie database")
+	if err := trieDB.Compact(nil, nil); err != nil {
+		logger.Error("Database compaction failed", "err", err)
+		return err
+	}
+	logger.Info("Database compaction finished", "elapsed", common.PrettyDuration(time.Since(cstart)))
+	logger.Info("State pruning successful", "pruned", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// extractGenesis loads the genesis state and commits all the state entries
+// into the given bloomfilter.
+func extractGenesis(db database.DBManager, bloom *stateBloom) error {
+	genesis := db.ReadBlock(db.ReadCanonicalHash(0), 0)
+	if genesis == nil {
+		return errors.New("missing genesis block")
+	}
+	statedb, err := state.New(genesis.Root(), state.NewDatabase(db), nil, nil)
+	if err != nil {
+		// The genesis state may have been pruned by the previous pruning
+		// when it was not stored.
+		logger.Warn("Genesis state is not found", "root", genesis.Root(), "err", err)
+		return nil
+	}
+	it := state.NewNodeIterator(statedb)
+	for it.Next() {
+		if it.Hash != (common.Hash{}) {
+			bloom.bloom.Add(stateBloomHasher(it.Hash.Bytes()))
+		}
+	}
+	return it.Error
+}
+
+// bloomFilterName returns the path of the state bloom filter of the given root.
+func bloomFilterName(datadir string, hash common.Hash) string {
+	return filepath.Join(datadir, fmt.Sprintf("%s.%s.%s", stateBloomFilePrefix, hash.Hex(), stateBloomFileSuffix))
+}
+
+// isBloomFilter reports whether the given file is a state bloom filter and
+// returns the root of the state.
+func isBloomFilter(filename string) (bool, common.Hash) {
+	filename = filepath.Base(filename)
+	if strings.HasPrefix(filename, stateBloomFilePrefix) && strings.HasSuffix(filename, stateBloomFileSuffix) {
+		return true, common.HexToHash(filename[len(stateBloomFilePrefix)+1 : len(filename)-len(stateBloomFileSuffix)-1])
+	}
+	return false, common.Hash{}
+}
+
+// findBloomFilter returns the path and the root of the committed state bloom
+// filter in the given directory, or an empty path if it is not found.
+func findBloomFilter(datadir string) (string, common.Hash, error) {
+	var (
+		stateBloomPath string
+		stateBloomRoot common.Hash
+	)
+	if err := filepath.Walk(datadir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != datadir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ok, root := isBloomFilter(path); ok {
+			stateBloomPath, stateBloomRoot = path, root
+		}
+		return nil
+	}); err != nil {
+		return "", common.Hash{}, err
+	}
+	return stateBloomPath, stateBloomRoot, nil
+}
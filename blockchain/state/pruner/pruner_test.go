@@ -0,0 +1,170 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/snapshot"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChain returns a database storing the states of all blocks of a
+// chain of the given length and the snapshot of them.
+func newTestChain(t *testing.T, n int) (database.DBManager, []*types.Block) {
+	var (
+		key, _   = crypto.GenerateKey()
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.Address{0xc}
+		signer   = types.LatestSignerForChainID(params.TestChainConfig.ChainID)
+		engine   = gxhash.NewFaker()
+		gspec    = &blockchain.Genesis{Config: params.TestChainConfig, Alloc: blockchain.GenesisAlloc{
+			addr:     {Balance: big.NewInt(params.KAIA)},
+			contract: {Code: []byte{0x1}, Storage: map[common.Hash]common.Hash{{0x1}: {0x1}}, Balance: common.Big0},
+		}}
+		gendb   = database.NewMemoryDBManager()
+		genesis = gspec.MustCommit(gendb)
+	)
+	blocks, _ := blockchain.GenerateChain(gspec.Config, genesis, engine, gendb, n, func(i int, b *blockchain.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), common.Address{byte(i + 1)}, big.NewInt(1), params.TxGas, big.NewInt(0), nil), signer, key)
+		require.NoError(t, err)
+		b.AddTx(tx)
+	})
+
+	db := database.NewMemoryDBManager()
+	gspec.MustCommit(db)
+	cacheConfig := &blockchain.CacheConfig{
+		ArchiveMode:         true,
+		CacheSize:           512,
+		BlockInterval:       blockchain.DefaultBlockInterval,
+		TriesInMemory:       blockchain.DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+		SnapshotCacheSize:   512,
+	}
+	chain, err := blockchain.NewBlockChain(db, cacheConfig, gspec.Config, engine, vm.Config{})
+	require.NoError(t, err)
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+	chain.Stop()
+	return db, blocks
+}
+
+// countTrieDB returns the number of the entries in the state trie database.
+func countTrieDB(db database.DBManager) int {
+	count := 0
+	it := db.GetDatabase(database.StateTrieDB).NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		count++
+	}
+	return count
+}
+
+// checkState checks that the whole state of the given root is stored.
+func checkState(db database.DBManager, root common.Hash) error {
+	sdb, err := state.New(root, state.NewDatabase(db), nil, nil)
+	if err != nil {
+		return err
+	}
+	it := state.NewNodeIterator(sdb)
+	for it.Next() {
+	}
+	return it.Error
+}
+
+func TestPrune(t *testing.T) {
+	db, blocks := newTestChain(t, 10)
+	head, middle := blocks[len(blocks)-1], blocks[len(blocks)/2]
+	require.NoError(t, checkState(db, middle.Root()))
+	before := countTrieDB(db)
+
+	// The dry-run reports the stale nodes without deleting them.
+	datadir := t.TempDir()
+	p, err := NewPruner(db, Config{Datadir: datadir, BloomSize: 1, DryRun: true})
+	require.NoError(t, err)
+	require.NoError(t, p.Prune(common.Hash{}))
+	assert.Equal(t, before, countTrieDB(db))
+
+	p, err = NewPruner(db, Config{Datadir: datadir, BloomSize: 1})
+	require.NoError(t, err)
+	require.NoError(t, p.Prune(common.Hash{}))
+	assert.Less(t, countTrieDB(db), before)
+
+	// The states of the head and the genesis are kept, while the others are deleted.
+	assert.NoError(t, checkState(db, head.Root()))
+	assert.NoError(t, checkState(db, db.ReadBlockByNumber(0).Root()))
+	ok, _ := db.HasTrieNode(middle.Root().ExtendZero())
+	assert.False(t, ok)
+	assert.Equal(t, []byte{0x1}, db.ReadCode(crypto.Keccak256Hash([]byte{0x1})))
+
+	// The checkpoint is removed after the pruning.
+	path, _, err := findBloomFilter(datadir)
+	require.NoError(t, err)
+	assert.Empty(t, path)
+
+	// The snapshot is flattened into the disk layer of the target.
+	snaptree, err := snapshot.New(db, statedb.NewDatabase(db), 256, head.Root(), false, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, head.Root(), snaptree.DiskRoot())
+	assert.NoError(t, snaptree.Verify(head.Root()))
+}
+
+func TestPrune_Resume(t *testing.T) {
+	db, blocks := newTestChain(t, 10)
+	head, middle := blocks[len(blocks)-1], blocks[len(blocks)/2]
+	datadir := t.TempDir()
+
+	// Commit the checkpoint as if the previous pruning was interrupted
+	// after the bloom filter had been generated.
+	p, err := NewPruner(db, Config{Datadir: datadir, BloomSize: 1})
+	require.NoError(t, err)
+	bloom, err := newStateBloomWithSize(1)
+	require.NoError(t, err)
+	require.NoError(t, snapshot.GenerateTrie(p.snaptree, head.Root(), db, bloom))
+	require.NoError(t, extractGenesis(db, bloom))
+	filename := bloomFilterName(datadir, head.Root())
+	require.NoError(t, bloom.Commit(filename, filename+stateBloomFileTempSuffix))
+
+	// The interrupted pruning should be resumed first.
+	assert.Error(t, p.Prune(middle.Root()))
+	require.NoError(t, p.Prune(common.Hash{}))
+	assert.NoError(t, checkState(db, head.Root()))
+	ok, _ := db.HasTrieNode(middle.Root().ExtendZero())
+	assert.False(t, ok)
+	_, err = os.Stat(filename)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestNewPruner_LivePruning(t *testing.T) {
+	db, _ := newTestChain(t, 1)
+	db.WritePruningEnabled()
+	_, err := NewPruner(db, Config{Datadir: t.TempDir(), BloomSize: 1})
+	assert.Error(t, err)
+}
@@ -412,6 +412,15 @@ func (s *StateDB) StorageTrie(addr common.Address) Trie {
 	return cpy.updateStorageTrie(s.db)
 }
 
+// GetStorageRoot returns the storage root of an account including the pending
+// storage changes. It returns the empty root for non-existent accounts.
+func (s *StateDB) GetStorageRoot(addr common.Address) common.Hash {
+	if tr := s.StorageTrie(addr); tr != nil {
+		return tr.Hash()
+	}
+	return emptyRoot
+}
+
 func (s *StateDB) HasSelfDestructed(addr common.Address) bool {
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
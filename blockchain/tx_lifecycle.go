@@ -0,0 +1,165 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/event"
+	"github.com/rcrowley/go-metrics"
+)
+
+const (
+	// txLifecycleHashLimit is the number of transactions whose lifecycle is kept.
+	txLifecycleHashLimit = 4096
+	// txLifecycleEventLimit is the number of the latest events kept per transaction.
+	txLifecycleEventLimit = 32
+	// txLifecycleChSize is the number of lifecycle events can be queued for event feed.
+	txLifecycleChSize = 1024
+)
+
+var txLifecycleDropCounter = metrics.NewRegisteredCounter("txpool/lifecycle/drop", nil) // Events not sent to the subscribers
+
+// TxLifecycleEventType is the type of a change of a transaction in the pool.
+type TxLifecycleEventType string
+
+const (
+	TxEventAdded    TxLifecycleEventType = "added"    // added to the non-executable queue
+	TxEventPromoted TxLifecycleEventType = "promoted" // moved or directly added to the executable pending list
+	TxEventDemoted  TxLifecycleEventType = "demoted"  // moved back to the non-executable queue
+	TxEventReplaced TxLifecycleEventType = "replaced" // replaced by a transaction of the same nonce with a price bump
+	TxEventIncluded TxLifecycleEventType = "included" // removed from the pool since it's included in a block
+	TxEventDropped  TxLifecycleEventType = "dropped"  // removed from the pool for the reason
+)
+
+// The reasons why the transactions are dropped from the pool.
+const (
+	// TxDropReasonNonceTooLow means the nonce is used by another transaction of
+	// the same nonce included in a block.
+	TxDropReasonNonceTooLow     = "nonce too low"
+	TxDropReasonUnexecutable    = "unexecutable" // out of funds or invalidated by the state
+	TxDropReasonUnderpriced     = "underpriced"
+	TxDropReasonPoolFull        = "txpool is full"
+	TxDropReasonExecSlots       = "exec slots limit"
+	TxDropReasonNonExecSlots    = "non-exec slots limit"
+	TxDropReasonLifetime        = "lifetime"
+	TxDropReasonSpamThrottler   = "spam throttler"
+	TxDropReasonConditionFailed = "condition failed"
+	TxDropReasonNonceExists     = "nonce already exists"
+)
+
+// TxLifecycleEvent is a change of a transaction in the pool.
+type TxLifecycleEvent struct {
+	Hash       common.Hash          `json:"hash"`
+	From       common.Address       `json:"from"`
+	Type       TxLifecycleEventType `json:"type"`
+	Time       time.Time            `json:"time"`
+	ReplacedBy *common.Hash         `json:"replacedBy,omitempty"`
+	Reason     string               `json:"reason,omitempty"`
+}
+
+// txLifecycle records the latest events of the recent transactions, and
+// queues the events for the subscribers.
+type txLifecycle struct {
+	mu     sync.Mutex
+	events *lru.Cache // hash -> []TxLifecycleEvent
+	ch     chan TxLifecycleEvent
+}
+
+func newTxLifecycle() *txLifecycle {
+	events, _ := lru.New(txLifecycleHashLimit)
+	return &txLifecycle{
+		events: events,
+		ch:     make(chan TxLifecycleEvent, txLifecycleChSize),
+	}
+}
+
+// record appends the event to the lifecycle of the transaction, dropping the
+// oldest one if the lifecycle is too long. The event is queued for the
+// subscribers without blocking the pool, so it's not sent if the queue is full.
+func (l *txLifecycle) record(ev TxLifecycleEvent) {
+	ev.Time = time.Now()
+
+	l.mu.Lock()
+	var events []TxLifecycleEvent
+	if prev, ok := l.events.Get(ev.Hash); ok {
+		events = prev.([]TxLifecycleEvent)
+	}
+	if len(events) >= txLifecycleEventLimit {
+		events = events[1:]
+	}
+	// Copy to keep the returned lifecycles immutable
+	l.events.Add(ev.Hash, append(append([]TxLifecycleEvent{}, events...), ev))
+	l.mu.Unlock()
+
+	select {
+	case l.ch <- ev:
+	default:
+		txLifecycleDropCounter.Inc(1)
+	}
+}
+
+// get returns the recorded lifecycle of the transaction, oldest first.
+func (l *txLifecycle) get(hash common.Hash) []TxLifecycleEvent {
+	if events, ok := l.events.Get(hash); ok {
+		return events.([]TxLifecycleEvent)
+	}
+	return nil
+}
+
+// recordTxEvent records the event of the transaction in the pool.
+func (pool *TxPool) recordTxEvent(tx *types.Transaction, typ TxLifecycleEventType, reason string) {
+	from, _ := types.Sender(pool.signer, tx)
+	pool.lifecycle.record(TxLifecycleEvent{Hash: tx.Hash(), From: from, Type: typ, Reason: reason})
+}
+
+// recordTxReplaced records the old transaction is replaced by the new one.
+func (pool *TxPool) recordTxReplaced(old, tx *types.Transaction) {
+	from, _ := types.Sender(pool.signer, old)
+	replacedBy := tx.Hash()
+	pool.lifecycle.record(TxLifecycleEvent{Hash: old.Hash(), From: from, Type: TxEventReplaced, ReplacedBy: &replacedBy})
+}
+
+// TxLifecycle returns the recorded events of the transaction in the pool,
+// oldest first. Only the latest events of the recent transactions are kept.
+func (pool *TxPool) TxLifecycle(hash common.Hash) []TxLifecycleEvent {
+	return pool.lifecycle.get(hash)
+}
+
+// SubscribeTxLifecycleEvent registers a subscription of TxLifecycleEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeTxLifecycleEvent(ch chan<- TxLifecycleEvent) event.Subscription {
+	return pool.scope.Track(pool.txLifecycleFeed.Subscribe(ch))
+}
+
+// handleTxLifecycleFeed sends the recorded lifecycle events to the subscribers.
+func (pool *TxPool) handleTxLifecycleFeed() {
+	defer pool.wg.Done()
+
+	for {
+		select {
+		case ev := <-pool.lifecycle.ch:
+			pool.txLifecycleFeed.Send(ev)
+		case <-pool.chainHeadSub.Err():
+			return
+		}
+	}
+}
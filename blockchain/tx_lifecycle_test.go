@@ -0,0 +1,147 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// minedBlockChain returns the mined block for any block of the test chain.
+type minedBlockChain struct {
+	*testBlockChain
+	block *types.Block
+}
+
+func (bc *minedBlockChain) GetBlock(common.Hash, uint64) *types.Block {
+	return bc.block
+}
+
+// lifecycleTypes returns the types of the recorded events of the transaction.
+func lifecycleTypes(pool *TxPool, tx *types.Transaction) []TxLifecycleEventType {
+	var typs []TxLifecycleEventType
+	for _, ev := range pool.TxLifecycle(tx.Hash()) {
+		typs = append(typs, ev.Type)
+	}
+	return typs
+}
+
+func TestTxPoolLifecycle(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPoolWithConfig(kip71Config)
+	defer pool.Stop()
+	pool.SetBaseFee(big.NewInt(1))
+
+	events := make(chan TxLifecycleEvent, txLifecycleChSize)
+	sub := pool.SubscribeTxLifecycleEvent(events)
+	defer sub.Unsubscribe()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000000000))
+
+	var (
+		queued      = pricedTransaction(1, 100000, big.NewInt(1), key)
+		pending     = pricedTransaction(0, 100000, big.NewInt(1), key)
+		replacement = pricedTransaction(0, 100000, big.NewInt(2), key)
+	)
+	require.NoError(t, pool.AddRemote(queued))
+	require.NoError(t, pool.AddRemote(pending))
+	require.NoError(t, pool.AddRemote(replacement))
+
+	assert.Equal(t, []TxLifecycleEventType{TxEventAdded, TxEventPromoted, TxEventReplaced}, lifecycleTypes(pool, pending))
+	// The replacement is put into the pending list directly.
+	assert.Equal(t, []TxLifecycleEventType{TxEventPromoted}, lifecycleTypes(pool, replacement))
+	assert.Equal(t, []TxLifecycleEventType{TxEventAdded, TxEventPromoted}, lifecycleTypes(pool, queued))
+
+	replaced := pool.TxLifecycle(pending.Hash())[2]
+	require.NotNil(t, replaced.ReplacedBy)
+	assert.Equal(t, replacement.Hash(), *replaced.ReplacedBy)
+	assert.Equal(t, account, replaced.From)
+
+	// The block includes the replacement and another transaction of the same
+	// nonce as the queued one.
+	other := pricedTransaction(1, 100000, big.NewInt(3), key)
+	pool.mu.Lock()
+	pool.chain = &minedBlockChain{pool.chain.(*testBlockChain), types.NewBlock(&types.Header{Number: big.NewInt(1)}, types.Transactions{replacement, other}, nil)}
+	pool.mu.Unlock()
+	testSetNonce(pool, account, 2)
+	pool.lockedReset(nil, nil)
+
+	lifecycle := pool.TxLifecycle(replacement.Hash())
+	require.NotEmpty(t, lifecycle)
+	assert.Equal(t, TxEventIncluded, lifecycle[len(lifecycle)-1].Type)
+	assert.Empty(t, lifecycle[len(lifecycle)-1].Reason)
+
+	lifecycle = pool.TxLifecycle(queued.Hash())
+	require.NotEmpty(t, lifecycle)
+	assert.Equal(t, TxEventDropped, lifecycle[len(lifecycle)-1].Type)
+	assert.Equal(t, TxDropReasonNonceTooLow, lifecycle[len(lifecycle)-1].Reason)
+	assert.Nil(t, pool.TxLifecycle(common.Hash{}))
+
+	// All events are sent to the subscriber in the order.
+	expected := []common.Hash{
+		queued.Hash(), pending.Hash(), pending.Hash(), queued.Hash(),
+		pending.Hash(), replacement.Hash(),
+	}
+	for i, hash := range expected {
+		select {
+		case ev := <-events:
+			assert.Equal(t, hash, ev.Hash, "event %d", i)
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not fired", i)
+		}
+	}
+}
+
+func TestTxLifecycle_EventLimit(t *testing.T) {
+	lifecycle := newTxLifecycle()
+	hash := common.HexToHash("0x1")
+	for i := 0; i < txLifecycleEventLimit+1; i++ {
+		typ := TxEventPromoted
+		if i == 0 {
+			typ = TxEventAdded
+		}
+		lifecycle.record(TxLifecycleEvent{Hash: hash, Type: typ})
+	}
+	events := lifecycle.get(hash)
+	assert.Len(t, events, txLifecycleEventLimit)
+	// The oldest event is dropped.
+	for _, ev := range events {
+		assert.Equal(t, TxEventPromoted, ev.Type)
+	}
+}
+
+func TestTxLifecycle_QueueFull(t *testing.T) {
+	lifecycle := newTxLifecycle()
+	drops := txLifecycleDropCounter.Count()
+
+	// The events are recorded without blocking although nobody receives them.
+	for i := 0; i < txLifecycleChSize+1; i++ {
+		lifecycle.record(TxLifecycleEvent{Hash: common.BigToHash(big.NewInt(int64(i))), Type: TxEventAdded})
+	}
+	assert.Len(t, lifecycle.ch, txLifecycleChSize)
+	assert.Equal(t, drops+1, txLifecycleDropCounter.Count())
+	assert.Len(t, lifecycle.get(common.BigToHash(big.NewInt(txLifecycleChSize))), 1)
+}
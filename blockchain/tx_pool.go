@@ -29,6 +29,7 @@ import (
 	"sync"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/klaytn/klaytn/blockchain/state"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
@@ -91,6 +92,7 @@ var (
 	invalidTxCounter     = metrics.NewRegisteredCounter("txpool/invalid", nil)
 	underpricedTxCounter = metrics.NewRegisteredCounter("txpool/underpriced", nil)
 	refusedTxCounter     = metrics.NewRegisteredCounter("txpool/refuse", nil)
+	conditionFailCounter = metrics.NewRegisteredCounter("txpool/conditional/failed", nil)
 	slotsGauge           = metrics.NewRegisteredGauge("txpool/slots", nil)
 )
 
@@ -103,8 +105,15 @@ const (
 	TxStatusPending
 	// for Les
 	TxStatusIncluded
+	// TxStatusConditionFailed is the status of a conditional transaction dropped
+	// since its preconditions can't be met anymore.
+	TxStatusConditionFailed
 )
 
+// conditionFailuresLimit is the number of dropped conditional transactions
+// whose status is kept in the pool.
+const conditionFailuresLimit = 1024
+
 // blockChain provides the state of blockchain and current gas limit to do
 // some pre checks in tx pool and event subscribers.
 type blockChain interface {
@@ -183,16 +192,17 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 // current state) and future transactions. Transactions move between those
 // two states over time as they are received and processed.
 type TxPool struct {
-	config       TxPoolConfig
-	chainconfig  *params.ChainConfig
-	chain        blockChain
-	gasPrice     *big.Int
-	txFeed       event.Feed
-	scope        event.SubscriptionScope
-	chainHeadCh  chan ChainHeadEvent
-	chainHeadSub event.Subscription
-	signer       types.Signer
-	mu           sync.RWMutex
+	config          TxPoolConfig
+	chainconfig     *params.ChainConfig
+	chain           blockChain
+	gasPrice        *big.Int
+	txFeed          event.Feed
+	txLifecycleFeed event.Feed
+	scope           event.SubscriptionScope
+	chainHeadCh     chan ChainHeadEvent
+	chainHeadSub    event.Subscription
+	signer          types.Signer
+	mu              sync.RWMutex
 
 	currentBlockNumber uint64                    // Current block number
 	currentState       *state.StateDB            // Current state in the blockchain head
@@ -210,6 +220,10 @@ type TxPool struct {
 	all     *txLookup                    // All transactions to allow lookups
 	priced  *txPricedList                // All transactions sorted by price
 
+	conditionals      map[common.Hash]*types.Transaction // Conditional transactions to be checked on reset
+	conditionFailures *lru.Cache                         // Errors of the recently dropped conditional transactions
+	lifecycle         *txLifecycle                       // Recent events of the transactions
+
 	wg sync.WaitGroup // for shutdown sync
 
 	txMsgCh  chan types.Transactions // A buffer for async tx intake via AddRemotes
@@ -234,12 +248,15 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		queue:        make(map[common.Address]*txList),
 		beats:        make(map[common.Address]time.Time),
 		all:          newTxLookup(),
+		conditionals: make(map[common.Hash]*types.Transaction),
+		lifecycle:    newTxLifecycle(),
 		pendingNonce: make(map[common.Address]uint64),
 		chainHeadCh:  make(chan ChainHeadEvent, chainHeadChanSize),
 		gasPrice:     new(big.Int).SetUint64(chainconfig.UnitPrice),
 		txMsgCh:      make(chan types.Transactions, txMsgChSize),
 		txFeedCh:     make(chan types.Transactions, txFeedChSize),
 	}
+	pool.conditionFailures, _ = lru.New(conditionFailuresLimit)
 	pool.locals = newAccountSet(pool.signer)
 	pool.priced = newTxPricedList(pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
@@ -259,10 +276,11 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
 
 	// Start the event loop and return
-	pool.wg.Add(3)
+	pool.wg.Add(4)
 	go pool.loop()
 	go pool.handleTxMsg()
 	go pool.handleTxFeed()
+	go pool.handleTxLifecycleFeed()
 
 	if config.EnableSpamThrottlerAtRuntime {
 		if err := pool.StartSpamThrottler(DefaultSpamThrottlerConfig); err != nil {
@@ -345,6 +363,7 @@ func (pool *TxPool) loop() {
 				if time.Since(beat) > pool.config.Lifetime {
 					if pool.queue[addr] != nil {
 						for _, tx := range pool.queue[addr].Flatten() {
+							pool.recordTxEvent(tx, TxEventDropped, TxDropReasonLifetime)
 							pool.removeTx(tx.Hash(), true)
 						}
 					}
@@ -379,7 +398,7 @@ func (pool *TxPool) lockedReset(oldHead, newHead *types.Header) {
 // of the transaction pool is valid with regard to the chain state.
 func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// If we're reorging an old state, reinject all dropped transactions
-	var reinject types.Transactions
+	var reinject, included types.Transactions
 
 	if oldHead != nil && oldHead.Hash() != newHead.ParentHash {
 		// If the reorg is too deep, avoid doing it (will happen during fast sync)
@@ -390,7 +409,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 			logger.Debug("Skipping deep transaction reorg", "depth", depth)
 		} else {
 			// Reorg seems shallow enough to pull in all transactions into memory
-			var discarded, included types.Transactions
+			var discarded types.Transactions
 
 			var (
 				rem = pool.chain.GetBlock(oldHead.Hash(), oldHead.Number.Uint64())
@@ -447,6 +466,11 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	if newHead == nil {
 		newHead = pool.chain.CurrentBlock().Header() // Special case during testing
 	}
+	if oldHead == nil || oldHead.Hash() == newHead.ParentHash {
+		if block := pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64()); block != nil {
+			included = block.Transactions()
+		}
+	}
 	stateDB, err := pool.chain.StateAt(newHead.Root)
 	if err != nil {
 		logger.Error("Failed to reset txpool state", "err", err)
@@ -469,7 +493,16 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// any transactions that have been included in the block or
 	// have been invalidated because of another transaction (e.g.
 	// higher gas price)
-	pool.demoteUnexecutables()
+	mined := make(map[common.Hash]bool, len(included))
+	for _, tx := range included {
+		mined[tx.Hash()] = true
+	}
+	pool.demoteUnexecutables(mined)
+
+	// Drop the conditional transactions which can't be included anymore. It's
+	// done after the included transactions are removed, so they are not checked
+	// against the state after them.
+	pool.dropFailedConditionals(newHead)
 
 	pool.txMu.Lock()
 	// Update all accounts to the latest known pending nonce
@@ -650,21 +683,48 @@ func (pool *TxPool) CachedPendingTxsByCount(count int) types.Transactions {
 }
 
 // local retrieves all currently known local transactions, groupped by origin
-// account and sorted by nonce. The returned transaction set is a copy and can be
-// freely modified by calling code.
+// account and sorted by nonce. The conditional transactions are excluded since
+// their preconditions are not journaled. The returned transaction set is a copy
+// and can be freely modified by calling code.
 func (pool *TxPool) local() map[common.Address]types.Transactions {
 	txs := make(map[common.Address]types.Transactions)
 	for addr := range pool.locals.accounts {
 		if pending := pool.pending[addr]; pending != nil {
-			txs[addr] = append(txs[addr], pending.Flatten()...)
+			txs[addr] = append(txs[addr], types.WithoutConditionalTxs(pending.Flatten())...)
 		}
 		if queued := pool.queue[addr]; queued != nil {
-			txs[addr] = append(txs[addr], queued.Flatten()...)
+			txs[addr] = append(txs[addr], types.WithoutConditionalTxs(queued.Flatten())...)
 		}
 	}
 	return txs
 }
 
+// dropFailedConditionals removes the conditional transactions whose
+// preconditions can't be met anymore in the blocks after the given head. The
+// preconditions not yet met are checked again on the next reset.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) dropFailedConditionals(head *types.Header) {
+	next := new(big.Int).Add(head.Number, common.Big1)
+	for hash, tx := range pool.conditionals {
+		if pool.all.Get(hash) != tx {
+			delete(pool.conditionals, hash)
+			continue
+		}
+		// The timestamp of the next block is at least the one of the head.
+		err := tx.Conditional().Check(next, head.Time.Uint64(), pool.currentState)
+		if err == nil || errors.Is(err, types.ErrTxConditionNotYetMet) {
+			continue
+		}
+		logger.Debug("Dropping conditional transaction", "hash", hash, "err", err)
+		conditionFailCounter.Inc(1)
+		pool.conditionFailures.Add(hash, err)
+		delete(pool.conditionals, hash)
+		pool.recordTxEvent(tx, TxEventDropped, TxDropReasonConditionFailed)
+		pool.removeTx(hash, true)
+	}
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction) error {
@@ -901,6 +961,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		maxTx := pool.getMaxTxFromQueueWhenNonceIsMissing(tx, &from)
 		if maxTx != tx {
 			// (2) remove an old Tx with the largest nonce from queue to make a room for a new Tx with missing nonce
+			pool.recordTxEvent(maxTx, TxEventDropped, TxDropReasonPoolFull)
 			pool.removeTx(maxTx.Hash(), true)
 			logger.Trace("Removing an old Tx with the max nonce to insert a new Tx with missing nonce, because TxPool is full", "account", from, "new nonce(previously missing)", tx.Nonce(), "removed max nonce", maxTx.Nonce())
 		} else {
@@ -922,6 +983,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		for _, tx := range drop {
 			logger.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
 			underpricedTxCounter.Inc(1)
+			pool.recordTxEvent(tx, TxEventDropped, TxDropReasonUnderpriced)
 			pool.removeTx(tx.Hash(), false)
 		}
 	}
@@ -939,10 +1001,15 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed()
 			pendingReplaceCounter.Inc(1)
+			pool.recordTxReplaced(old, tx)
 		}
 		pool.all.Add(tx)
 		pool.priced.Put(tx)
 		pool.journalTx(from, tx)
+		pool.recordTxEvent(tx, TxEventPromoted, "")
+		if tx.Conditional() != nil {
+			pool.conditionals[hash] = tx
+		}
 
 		logger.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 
@@ -956,11 +1023,15 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	pool.recordTxEvent(tx, TxEventAdded, "")
 	// Mark local addresses and journal local transactions
 	if local {
 		pool.locals.add(from)
 	}
 	pool.journalTx(from, tx)
+	if tx.Conditional() != nil {
+		pool.conditionals[hash] = tx
+	}
 
 	logger.Trace("Pooled new future transaction", "hash", hash, "from", from, "to", tx.To())
 	return replace, nil
@@ -986,6 +1057,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed()
 		queuedReplaceCounter.Inc(1)
+		pool.recordTxReplaced(old, tx)
 	}
 	if pool.all.Get(hash) == nil {
 		pool.all.Add(tx)
@@ -999,8 +1071,9 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 // journalTx adds the specified transaction to the local disk journal if it is
 // deemed to have been sent from a local account.
 func (pool *TxPool) journalTx(from common.Address, tx *types.Transaction) {
-	// Only journal if it's enabled and the transaction is local
-	if pool.journal == nil || !pool.locals.contains(from) {
+	// Only journal if it's enabled and the transaction is local, and not
+	// conditional since the preconditions are not journaled
+	if pool.journal == nil || !pool.locals.contains(from) || tx.Conditional() != nil {
 		return
 	}
 	if err := pool.journal.insert(tx); err != nil {
@@ -1026,6 +1099,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingDiscardCounter.Inc(1)
+		pool.recordTxEvent(tx, TxEventDropped, TxDropReasonNonceExists)
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -1034,6 +1108,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingReplaceCounter.Inc(1)
+		pool.recordTxReplaced(old, tx)
 	}
 	// Failsafe to work around direct pending inserts (tests)
 	if pool.all.Get(hash) == nil {
@@ -1043,6 +1118,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 	// Set the potentially new pending nonce and notify any subsystems of the new tx
 	pool.beats[addr] = time.Now()
 	pool.setPendingNonce(addr, tx.Nonce()+1)
+	pool.recordTxEvent(tx, TxEventPromoted, "")
 
 	return true
 }
@@ -1071,6 +1147,7 @@ func (pool *TxPool) HandleTxMsg(txs types.Transactions) {
 				default:
 					logger.Trace("drop a tx when throttleTxs channel is full", "txHash", tx.Hash())
 					throttlerDropCount.Inc(1)
+					pool.recordTxEvent(tx, TxEventDropped, TxDropReasonSpamThrottler)
 				}
 			}
 
@@ -1326,11 +1403,22 @@ func (pool *TxPool) Status(hashes []common.Hash) []TxStatus {
 			} else {
 				status[i] = TxStatusQueued
 			}
+		} else if pool.conditionFailures.Contains(hash) {
+			status[i] = TxStatusConditionFailed
 		}
 	}
 	return status
 }
 
+// ConditionFailure returns the reason why the conditional transaction was
+// dropped, or nil if it's not a recently dropped conditional transaction.
+func (pool *TxPool) ConditionFailure(hash common.Hash) error {
+	if err, ok := pool.conditionFailures.Get(hash); ok {
+		return err.(error)
+	}
+	return nil
+}
+
 // Get returns a transaction if it is contained in the pool
 // and nil otherwise.
 func (pool *TxPool) Get(hash common.Hash) *types.Transaction {
@@ -1371,6 +1459,7 @@ func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
 			// Postpone any invalidated transactions
 			for _, tx := range invalids {
 				pool.enqueueTx(tx.Hash(), tx)
+				pool.recordTxEvent(tx, TxEventDemoted, "")
 			}
 			pool.updatePendingNonce(addr, tx.Nonce())
 			return
@@ -1414,6 +1503,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			logger.Trace("Removed old queued transaction", "hash", hash)
 			pool.all.Remove(hash)
 			pool.priced.Removed()
+			pool.recordTxEvent(tx, TxEventDropped, TxDropReasonNonceTooLow)
 		}
 		// Drop all transactions that are too costly (low balance)
 		drops, _ := list.Filter(addr, pool)
@@ -1423,6 +1513,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 			queuedNofundsCounter.Inc(1)
+			pool.recordTxEvent(tx, TxEventDropped, TxDropReasonUnexecutable)
 		}
 
 		// Gather all executable transactions and promote them
@@ -1447,6 +1538,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 				pool.all.Remove(hash)
 				pool.priced.Removed()
 				queuedRateLimitCounter.Inc(1)
+				pool.recordTxEvent(tx, TxEventDropped, TxDropReasonNonExecSlots)
 				logger.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
 		}
@@ -1499,6 +1591,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 
 							// Update the account nonce to the dropped transaction
 							pool.updatePendingNonce(offenders[i], tx.Nonce())
+							pool.recordTxEvent(tx, TxEventDropped, TxDropReasonExecSlots)
 							logger.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 						}
 						pending--
@@ -1519,6 +1612,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 
 						// Update the account nonce to the dropped transaction
 						pool.updatePendingNonce(addr, tx.Nonce())
+						pool.recordTxEvent(tx, TxEventDropped, TxDropReasonExecSlots)
 						logger.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 					}
 					pending--
@@ -1553,6 +1647,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			// Drop all transactions if they are less than the overflow
 			if size := uint64(list.Len()); size <= drop {
 				for _, tx := range list.Flatten() {
+					pool.recordTxEvent(tx, TxEventDropped, TxDropReasonNonExecSlots)
 					pool.removeTx(tx.Hash(), true)
 				}
 				drop -= size
@@ -1562,6 +1657,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			// Otherwise drop only last few transactions
 			txs := list.Flatten()
 			for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
+				pool.recordTxEvent(txs[i], TxEventDropped, TxDropReasonNonExecSlots)
 				pool.removeTx(txs[i].Hash(), true)
 				drop--
 				queuedRateLimitCounter.Inc(1)
@@ -1572,8 +1668,9 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 
 // demoteUnexecutables removes invalid and processed transactions from the pools
 // executable/pending queue and any subsequent transactions that become unexecutable
-// are moved back into the future queue.
-func (pool *TxPool) demoteUnexecutables() {
+// are moved back into the future queue. The processed transactions in the mined
+// set are recorded as included, and the others as dropped by their nonces.
+func (pool *TxPool) demoteUnexecutables(mined map[common.Hash]bool) {
 	pool.txMu.Lock()
 	defer pool.txMu.Unlock()
 
@@ -1590,6 +1687,11 @@ func (pool *TxPool) demoteUnexecutables() {
 			logger.Trace("Removed old pending transaction", "hash", hash)
 			pool.all.Remove(hash)
 			pool.priced.Removed()
+			if mined[hash] {
+				pool.recordTxEvent(tx, TxEventIncluded, "")
+			} else {
+				pool.recordTxEvent(tx, TxEventDropped, TxDropReasonNonceTooLow)
+			}
 		}
 
 		// demoteUnexecutables does full-validation for a limited number of txs. Otherwise, it only validate nonce.
@@ -1608,12 +1710,14 @@ func (pool *TxPool) demoteUnexecutables() {
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 			pendingNofundsCounter.Inc(1)
+			pool.recordTxEvent(tx, TxEventDropped, TxDropReasonUnexecutable)
 		}
 
 		for _, tx := range invalids {
 			hash := tx.Hash()
 			logger.Trace("Demoting pending transaction", "hash", hash)
 			pool.enqueueTx(hash, tx)
+			pool.recordTxEvent(tx, TxEventDemoted, "")
 		}
 		// If there's a gap in front, warn (should never happen) and postpone all transactions
 		if list.Len() > 0 && list.txs.Get(nonce) == nil {
@@ -1621,6 +1725,7 @@ func (pool *TxPool) demoteUnexecutables() {
 				hash := tx.Hash()
 				logger.Error("Demoting invalidated transaction", "hash", hash)
 				pool.enqueueTx(hash, tx)
+				pool.recordTxEvent(tx, TxEventDemoted, "")
 			}
 		}
 
@@ -1635,8 +1740,10 @@ func (pool *TxPool) demoteUnexecutables() {
 					if removed {
 						for _, invalidTx := range invalids {
 							pool.enqueueTx(invalidTx.Hash(), invalidTx)
+							pool.recordTxEvent(invalidTx, TxEventDemoted, "")
 						}
 						pool.enqueueTx(hash, tx)
+						pool.recordTxEvent(tx, TxEventDemoted, "")
 					}
 					break
 				}
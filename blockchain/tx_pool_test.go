@@ -35,6 +35,7 @@ import (
 	"github.com/klaytn/klaytn/blockchain/state"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/event"
 	"github.com/klaytn/klaytn/fork"
@@ -783,6 +784,103 @@ func TestTransactionDropping(t *testing.T) {
 	}
 }
 
+// Tests that the conditional transactions are dropped on reset if their
+// preconditions can't be met anymore, while the ones not yet met are kept.
+func TestConditionalTransactionDropping(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	var (
+		account  = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xc0de")
+		slot     = common.HexToHash("0x1")
+	)
+	testAddBalance(pool, account, big.NewInt(1000000))
+	pool.mu.Lock()
+	pool.currentState.SetState(contract, slot, common.HexToHash("0x2"))
+	pool.mu.Unlock()
+
+	conditional := func(nonce uint64, cond *types.TransactionConditional) *types.Transaction {
+		tx := transaction(nonce, 100000, key)
+		tx.SetConditional(cond)
+		assert.NoError(t, pool.AddLocal(tx))
+		return tx
+	}
+	maxBlock, minBlock := hexutil.Big(*big.NewInt(1)), hexutil.Big(*big.NewInt(10))
+	expired := conditional(0, &types.TransactionConditional{BlockNumberMax: &maxBlock})
+	notYet := conditional(1, &types.TransactionConditional{BlockNumberMin: &minBlock})
+	storage := conditional(2, &types.TransactionConditional{KnownAccounts: map[common.Address]types.KnownAccount{
+		contract: {StorageSlots: map[common.Hash]common.Hash{slot: common.HexToHash("0x2")}},
+	}})
+	assert.Equal(t, 3, pool.all.Count())
+
+	// All preconditions can be met in the next block.
+	pool.lockedReset(nil, &types.Header{Number: big.NewInt(0), Time: big.NewInt(0)})
+	assert.Equal(t, 3, pool.all.Count())
+
+	// The block number passed the maximum, and the storage is changed.
+	pool.mu.Lock()
+	pool.currentState.SetState(contract, slot, common.HexToHash("0x3"))
+	pool.mu.Unlock()
+	pool.lockedReset(nil, &types.Header{Number: big.NewInt(1), Time: big.NewInt(0)})
+
+	assert.Nil(t, pool.Get(expired.Hash()))
+	assert.Nil(t, pool.Get(storage.Hash()))
+	assert.NotNil(t, pool.Get(notYet.Hash()))
+	assert.Equal(t, []TxStatus{TxStatusConditionFailed, TxStatusQueued, TxStatusConditionFailed},
+		pool.Status([]common.Hash{expired.Hash(), notYet.Hash(), storage.Hash()}))
+	assert.ErrorIs(t, pool.ConditionFailure(expired.Hash()), types.ErrTxConditionFailed)
+	assert.ErrorIs(t, pool.ConditionFailure(storage.Hash()), types.ErrTxConditionFailed)
+	assert.NoError(t, pool.ConditionFailure(notYet.Hash()))
+}
+
+// Tests that the included conditional transactions are not reported as failed,
+// although their preconditions can't be met after the block including them.
+func TestConditionalTransactionIncluded(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	var (
+		account  = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xc0de")
+		slot     = common.HexToHash("0x1")
+	)
+	testAddBalance(pool, account, big.NewInt(1000000))
+	pool.mu.Lock()
+	pool.currentState.SetState(contract, slot, common.HexToHash("0x2"))
+	pool.mu.Unlock()
+
+	maxBlock := hexutil.Big(*big.NewInt(1))
+	txs := []*types.Transaction{transaction(0, 100000, key), transaction(1, 100000, key)}
+	txs[0].SetConditional(&types.TransactionConditional{BlockNumberMax: &maxBlock})
+	txs[1].SetConditional(&types.TransactionConditional{KnownAccounts: map[common.Address]types.KnownAccount{
+		contract: {StorageSlots: map[common.Hash]common.Hash{slot: common.HexToHash("0x2")}},
+	}})
+	for _, tx := range txs {
+		assert.NoError(t, pool.AddLocal(tx))
+	}
+
+	// The block 1 includes the transactions, and the second one writes the slot.
+	pool.mu.Lock()
+	pool.currentState.SetState(contract, slot, common.HexToHash("0x3"))
+	pool.mu.Unlock()
+	testSetNonce(pool, account, 2)
+	pool.lockedReset(nil, &types.Header{Number: big.NewInt(1), Time: big.NewInt(0)})
+
+	assert.Equal(t, 0, pool.all.Count())
+	for _, tx := range txs {
+		assert.Equal(t, []TxStatus{TxStatusUnknown}, pool.Status([]common.Hash{tx.Hash()}))
+		assert.NoError(t, pool.ConditionFailure(tx.Hash()))
+		for _, ev := range pool.TxLifecycle(tx.Hash()) {
+			assert.NotEqual(t, TxDropReasonConditionFailed, ev.Reason)
+		}
+	}
+}
+
 // Tests that if a transaction is dropped from the current pending pool (e.g. out
 // of fund), all consecutive (still valid, but not executable) transactions are
 // postponed back into the future queue to prevent broadcasting them.
@@ -2523,7 +2621,7 @@ func TestTransactionsDemotionMultipleAccount(t *testing.T) {
 	// tx[7] : queue[from[2]]
 	// tx[7] : queue[from[2]]
 	pool.SetBaseFee(big.NewInt(35))
-	pool.demoteUnexecutables()
+	pool.demoteUnexecutables(nil)
 
 	assert.Equal(t, 2, pool.queue[froms[0]].Len())
 	assert.Equal(t, 2, pool.pending[froms[0]].Len())
@@ -2750,7 +2848,7 @@ func benchmarkPendingDemotion(b *testing.B, size int) {
 	// Benchmark the speed of pool validation
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		pool.demoteUnexecutables()
+		pool.demoteUnexecutables(nil)
 	}
 }
 
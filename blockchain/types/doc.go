@@ -53,6 +53,7 @@ Functions and variables related to Block and Transaction are defined in the file
   - receipt.go : implements transaction receipt
   - transaction.go : defines transaction
   - transaction_signing.go : interfaces signer and implements transaction signing/verification functions
+  - tx_conditional.go : defines preconditions of a transaction checked before its inclusion
   - tx_internal_data.go : defines internal data of transaction supporting various transaction types
   - tx_internal_data_account_creation.go : implements the transaction creating an EOA account
   - tx_internal_data_account_update.go : implements the transaction updating account key of an account
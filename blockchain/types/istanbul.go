@@ -38,37 +38,96 @@ var (
 
 	// ErrInvalidIstanbulHeaderExtra is returned if the length of extra-data is less than 32 bytes
 	ErrInvalidIstanbulHeaderExtra = errors.New("invalid istanbul header extra-data")
+	// ErrInvalidCommitterBitmap is returned if the committer bitmap doesn't match the validators
+	ErrInvalidCommitterBitmap = errors.New("invalid committer bitmap")
 )
 
 type IstanbulExtra struct {
 	Validators    []common.Address
 	Seal          []byte
 	CommittedSeal [][]byte
+
+	// After the BlsSeal fork, the BLS committed seals are aggregated into AggregatedSeal
+	// and CommitterBitmap marks the validators who signed it. CommittedSeal is empty then.
+	AggregatedSeal  []byte
+	CommitterBitmap []byte // bit i (the bit (i % 8) of byte (i / 8)) is set if Validators[i] is a committer
 }
 
 // EncodeRLP serializes the istanbul fields into the Kaia RLP format.
+// The aggregated seal fields are encoded only if they are present, so that the
+// encoding of the extra without them is the same as before the BlsSeal fork.
 func (ist *IstanbulExtra) EncodeRLP(w io.Writer) error {
+	if len(ist.AggregatedSeal) == 0 && len(ist.CommitterBitmap) == 0 {
+		return rlp.Encode(w, []interface{}{
+			ist.Validators,
+			ist.Seal,
+			ist.CommittedSeal,
+		})
+	}
 	return rlp.Encode(w, []interface{}{
 		ist.Validators,
 		ist.Seal,
 		ist.CommittedSeal,
+		ist.AggregatedSeal,
+		ist.CommitterBitmap,
 	})
 }
 
 // DecodeRLP implements rlp.Decoder, and load the istanbul fields from a RLP stream.
 func (ist *IstanbulExtra) DecodeRLP(s *rlp.Stream) error {
 	var istanbulExtra struct {
-		Validators    []common.Address
-		Seal          []byte
-		CommittedSeal [][]byte
+		Validators      []common.Address
+		Seal            []byte
+		CommittedSeal   [][]byte
+		AggregatedSeal  []byte `rlp:"optional"`
+		CommitterBitmap []byte `rlp:"optional"`
 	}
 	if err := s.Decode(&istanbulExtra); err != nil {
 		return err
 	}
 	ist.Validators, ist.Seal, ist.CommittedSeal = istanbulExtra.Validators, istanbulExtra.Seal, istanbulExtra.CommittedSeal
+	ist.AggregatedSeal, ist.CommitterBitmap = istanbulExtra.AggregatedSeal, istanbulExtra.CommitterBitmap
 	return nil
 }
 
+// NewCommitterBitmap returns the bitmap marking the committers in the validators.
+// It returns ErrInvalidCommitterBitmap if a committer is not one of the validators.
+func NewCommitterBitmap(validators, committers []common.Address) ([]byte, error) {
+	index := make(map[common.Address]int, len(validators))
+	for i, val := range validators {
+		index[val] = i
+	}
+	bitmap := make([]byte, (len(validators)+7)/8)
+	for _, committer := range committers {
+		i, ok := index[committer]
+		if !ok {
+			return nil, ErrInvalidCommitterBitmap
+		}
+		bitmap[i/8] |= 1 << (i % 8)
+	}
+	return bitmap, nil
+}
+
+// Committers returns the validators marked in the committer bitmap. It returns
+// ErrInvalidCommitterBitmap if the length of the bitmap doesn't match the number
+// of validators or a bit out of the validators is set.
+func (ist *IstanbulExtra) Committers() ([]common.Address, error) {
+	if len(ist.CommitterBitmap) != (len(ist.Validators)+7)/8 {
+		return nil, ErrInvalidCommitterBitmap
+	}
+	var committers []common.Address
+	for i := 0; i < len(ist.CommitterBitmap)*8; i++ {
+		if ist.CommitterBitmap[i/8]&(1<<(i%8)) == 0 {
+			continue
+		}
+		if i >= len(ist.Validators) {
+			return nil, ErrInvalidCommitterBitmap
+		}
+		committers = append(committers, ist.Validators[i])
+	}
+	return committers, nil
+}
+
 // ExtractIstanbulExtra extracts all values of the IstanbulExtra from the header. It returns an
 // error if the length of the given extra-data is less than 32 bytes or the extra-data can not
 // be decoded.
@@ -85,7 +144,7 @@ func ExtractIstanbulExtra(h *Header) (*IstanbulExtra, error) {
 	return istanbulExtra, nil
 }
 
-// IstanbulFilteredHeader returns a filtered header which some information (like seal, committed seals)
+// IstanbulFilteredHeader returns a filtered header which some information (like seal, committed seals, aggregated seal)
 // are clean to fulfill the Istanbul hash rules. It returns nil if the extra-data cannot be
 // decoded/encoded by rlp.
 func IstanbulFilteredHeader(h *Header, keepSeal bool) *Header {
@@ -99,6 +158,7 @@ func IstanbulFilteredHeader(h *Header, keepSeal bool) *Header {
 		istanbulExtra.Seal = []byte{}
 	}
 	istanbulExtra.CommittedSeal = [][]byte{}
+	istanbulExtra.AggregatedSeal, istanbulExtra.CommitterBitmap = nil, nil
 
 	payload, err := rlp.EncodeToBytes(&istanbulExtra)
 	if err != nil {
@@ -0,0 +1,100 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIstanbulExtra_EncodeDecodeRLP(t *testing.T) {
+	validators := []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2")}
+
+	// The extra without the aggregated seal is encoded as before
+	legacy := &IstanbulExtra{Validators: validators, Seal: []byte{1}, CommittedSeal: [][]byte{{2}, {3}}}
+	encoded, err := rlp.EncodeToBytes(legacy)
+	require.NoError(t, err)
+	expected, err := rlp.EncodeToBytes([]interface{}{legacy.Validators, legacy.Seal, legacy.CommittedSeal})
+	require.NoError(t, err)
+	assert.Equal(t, expected, encoded)
+
+	decoded := new(IstanbulExtra)
+	require.NoError(t, rlp.DecodeBytes(encoded, decoded))
+	assert.Equal(t, legacy.CommittedSeal, decoded.CommittedSeal)
+	assert.Empty(t, decoded.AggregatedSeal)
+	assert.Empty(t, decoded.CommitterBitmap)
+
+	aggregated := &IstanbulExtra{Validators: validators, Seal: []byte{1}, CommittedSeal: [][]byte{}, AggregatedSeal: []byte{4}, CommitterBitmap: []byte{3}}
+	encoded, err = rlp.EncodeToBytes(aggregated)
+	require.NoError(t, err)
+	decoded = new(IstanbulExtra)
+	require.NoError(t, rlp.DecodeBytes(encoded, decoded))
+	assert.Equal(t, aggregated.AggregatedSeal, decoded.AggregatedSeal)
+	assert.Equal(t, aggregated.CommitterBitmap, decoded.CommitterBitmap)
+}
+
+func TestIstanbulExtra_Committers(t *testing.T) {
+	validators := make([]common.Address, 10)
+	for i := range validators {
+		validators[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+	committers := []common.Address{validators[0], validators[3], validators[9]}
+
+	bitmap, err := NewCommitterBitmap(validators, committers)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x09, 0x02}, bitmap)
+
+	extra := &IstanbulExtra{Validators: validators, CommitterBitmap: bitmap}
+	decoded, err := extra.Committers()
+	require.NoError(t, err)
+	assert.Equal(t, committers, decoded)
+
+	_, err = NewCommitterBitmap(validators[:2], committers)
+	assert.Equal(t, ErrInvalidCommitterBitmap, err)
+
+	// The bits out of the validators
+	extra.CommitterBitmap = []byte{0x09, 0x04}
+	_, err = extra.Committers()
+	assert.Equal(t, ErrInvalidCommitterBitmap, err)
+
+	// The length mismatch
+	extra.CommitterBitmap = []byte{0x09}
+	_, err = extra.Committers()
+	assert.Equal(t, ErrInvalidCommitterBitmap, err)
+}
+
+func TestIstanbulFilteredHeader_AggregatedSeal(t *testing.T) {
+	extra := &IstanbulExtra{Validators: []common.Address{common.HexToAddress("0x1")}, Seal: []byte{1}, CommittedSeal: [][]byte{}}
+	payload, err := rlp.EncodeToBytes(extra)
+	require.NoError(t, err)
+	header := &Header{Number: big.NewInt(1), Extra: append(bytes.Repeat([]byte{0}, IstanbulExtraVanity), payload...)}
+
+	extra.AggregatedSeal, extra.CommitterBitmap = []byte{2}, []byte{1}
+	payload, err = rlp.EncodeToBytes(extra)
+	require.NoError(t, err)
+	sealed := CopyHeader(header)
+	sealed.Extra = append(bytes.Repeat([]byte{0}, IstanbulExtraVanity), payload...)
+
+	// The aggregated seal doesn't change the hash
+	assert.Equal(t, rlpHash(IstanbulFilteredHeader(header, true)), rlpHash(IstanbulFilteredHeader(sealed, true)))
+}
@@ -93,6 +93,8 @@ type Transaction struct {
 	checkNonce bool
 	// This value is set when the tx is invalidated in block tx validation, and is used to remove pending tx in txPool.
 	markedUnexecutable int32
+	// conditional is the preconditions of the transaction given on submission. It is kept only locally.
+	conditional atomic.Value
 
 	// lock for protecting fields in Transaction struct
 	mu sync.RWMutex
@@ -744,6 +746,20 @@ func (tx *Transaction) IsMarkedUnexecutable() bool {
 	return atomic.LoadInt32(&tx.markedUnexecutable) == 1
 }
 
+// SetConditional sets the preconditions of the transaction, which are checked
+// before the transaction is included in a block.
+func (tx *Transaction) SetConditional(cond *TransactionConditional) {
+	tx.conditional.Store(cond)
+}
+
+// Conditional returns the preconditions of the transaction, or nil if none.
+func (tx *Transaction) Conditional() *TransactionConditional {
+	if cond, ok := tx.conditional.Load().(*TransactionConditional); ok {
+		return cond
+	}
+	return nil
+}
+
 func (tx *Transaction) RawSignatureValues() TxSignatures {
 	return tx.data.RawSignatureValues()
 }
@@ -0,0 +1,177 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+// MaxTxConditionalCost is the maximum number of the storage roots and slots
+// checked for a conditional transaction.
+const MaxTxConditionalCost = 1000
+
+var (
+	// ErrTxConditionNotYetMet is returned if the block of the transaction is
+	// before the minimum block number or timestamp of the conditional.
+	ErrTxConditionNotYetMet = errors.New("transaction condition not yet met")
+
+	// ErrTxConditionFailed is returned if the conditional can't be met anymore,
+	// which means the maximum block number or timestamp has passed, or the
+	// storage of a known account doesn't match.
+	ErrTxConditionFailed = errors.New("transaction condition failed")
+
+	errInvalidTxConditional = errors.New("invalid transaction conditional")
+)
+
+// ConditionalStateReader provides the storage checked by the conditionals.
+type ConditionalStateReader interface {
+	GetState(addr common.Address, key common.Hash) common.Hash
+	GetStorageRoot(addr common.Address) common.Hash
+}
+
+// KnownAccount is the expected storage of an account, given as either the
+// storage root or the values of some storage slots.
+type KnownAccount struct {
+	StorageRoot  *common.Hash
+	StorageSlots map[common.Hash]common.Hash
+}
+
+// MarshalJSON implements json.Marshaler. The storage root is encoded as a hex
+// string, and the storage slots as an object.
+func (a KnownAccount) MarshalJSON() ([]byte, error) {
+	if a.StorageRoot != nil {
+		return json.Marshal(a.StorageRoot)
+	}
+	return json.Marshal(a.StorageSlots)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *KnownAccount) UnmarshalJSON(input []byte) error {
+	var root common.Hash
+	if err := json.Unmarshal(input, &root); err == nil {
+		a.StorageRoot, a.StorageSlots = &root, nil
+		return nil
+	}
+	var slots map[common.Hash]common.Hash
+	if err := json.Unmarshal(input, &slots); err != nil {
+		return fmt.Errorf("%w: known account should be a storage root or storage slots", errInvalidTxConditional)
+	}
+	a.StorageRoot, a.StorageSlots = nil, slots
+	return nil
+}
+
+// TransactionConditional is the preconditions of a transaction, which should
+// hold in the block including the transaction. It is kept only in the node
+// receiving the transaction, and is not encoded with the transaction.
+type TransactionConditional struct {
+	KnownAccounts  map[common.Address]KnownAccount `json:"knownAccounts"`
+	BlockNumberMin *hexutil.Big                    `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *hexutil.Big                    `json:"blockNumberMax,omitempty"`
+	TimestampMin   *hexutil.Uint64                 `json:"timestampMin,omitempty"`
+	TimestampMax   *hexutil.Uint64                 `json:"timestampMax,omitempty"`
+}
+
+// Cost returns the number of the storage roots and slots to be checked.
+func (c *TransactionConditional) Cost() int {
+	cost := 0
+	for _, account := range c.KnownAccounts {
+		if account.StorageRoot != nil {
+			cost++
+		} else {
+			cost += len(account.StorageSlots)
+		}
+	}
+	return cost
+}
+
+// Validate checks the conditional is well-formed.
+func (c *TransactionConditional) Validate() error {
+	if c.BlockNumberMin != nil && c.BlockNumberMax != nil && c.BlockNumberMin.ToInt().Cmp(c.BlockNumberMax.ToInt()) > 0 {
+		return fmt.Errorf("%w: blockNumberMin is greater than blockNumberMax", errInvalidTxConditional)
+	}
+	if c.TimestampMin != nil && c.TimestampMax != nil && *c.TimestampMin > *c.TimestampMax {
+		return fmt.Errorf("%w: timestampMin is greater than timestampMax", errInvalidTxConditional)
+	}
+	if cost := c.Cost(); cost > MaxTxConditionalCost {
+		return fmt.Errorf("%w: too many known account storages %d, max %d", errInvalidTxConditional, cost, MaxTxConditionalCost)
+	}
+	return nil
+}
+
+// CheckBlock checks the block number and the timestamp of the block including
+// the transaction are in the ranges.
+func (c *TransactionConditional) CheckBlock(number *big.Int, time uint64) error {
+	if c.BlockNumberMax != nil && number.Cmp(c.BlockNumberMax.ToInt()) > 0 {
+		return fmt.Errorf("%w: block number %v is greater than %v", ErrTxConditionFailed, number, c.BlockNumberMax.ToInt())
+	}
+	if c.TimestampMax != nil && time > uint64(*c.TimestampMax) {
+		return fmt.Errorf("%w: timestamp %d is greater than %d", ErrTxConditionFailed, time, uint64(*c.TimestampMax))
+	}
+	if c.BlockNumberMin != nil && number.Cmp(c.BlockNumberMin.ToInt()) < 0 {
+		return fmt.Errorf("%w: block number %v is less than %v", ErrTxConditionNotYetMet, number, c.BlockNumberMin.ToInt())
+	}
+	if c.TimestampMin != nil && time < uint64(*c.TimestampMin) {
+		return fmt.Errorf("%w: timestamp %d is less than %d", ErrTxConditionNotYetMet, time, uint64(*c.TimestampMin))
+	}
+	return nil
+}
+
+// CheckState checks the storages of the known accounts match the given state.
+func (c *TransactionConditional) CheckState(state ConditionalStateReader) error {
+	for addr, account := range c.KnownAccounts {
+		if account.StorageRoot != nil {
+			if root := state.GetStorageRoot(addr); root != *account.StorageRoot {
+				return fmt.Errorf("%w: storage root of %v is %v, expected %v", ErrTxConditionFailed, addr, root, *account.StorageRoot)
+			}
+			continue
+		}
+		for slot, expected := range account.StorageSlots {
+			if value := state.GetState(addr, slot); value != expected {
+				return fmt.Errorf("%w: storage %v of %v is %v, expected %v", ErrTxConditionFailed, slot, addr, value, expected)
+			}
+		}
+	}
+	return nil
+}
+
+// Check checks the conditional in the block of the given number and timestamp
+// on top of the given state.
+func (c *TransactionConditional) Check(number *big.Int, time uint64, state ConditionalStateReader) error {
+	if err := c.CheckBlock(number, time); err != nil {
+		return err
+	}
+	return c.CheckState(state)
+}
+
+// WithoutConditionalTxs returns the transactions without the conditional ones.
+// The conditional transactions are kept only in the node receiving them, since
+// their preconditions are not encoded with the transactions.
+func WithoutConditionalTxs(txs Transactions) Transactions {
+	filtered := txs[:0:0]
+	for _, tx := range txs {
+		if tx.Conditional() == nil {
+			filtered = append(filtered, tx)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConditionalState is a state having the storage of a single account.
+type testConditionalState struct {
+	addr    common.Address
+	root    common.Hash
+	storage map[common.Hash]common.Hash
+}
+
+func (s *testConditionalState) GetState(addr common.Address, key common.Hash) common.Hash {
+	if addr != s.addr {
+		return common.Hash{}
+	}
+	return s.storage[key]
+}
+
+func (s *testConditionalState) GetStorageRoot(addr common.Address) common.Hash {
+	if addr != s.addr {
+		return common.Hash{}
+	}
+	return s.root
+}
+
+func TestTransactionConditional_JSON(t *testing.T) {
+	input := `{
+		"knownAccounts": {
+			"0x000000000000000000000000000000000000000a": "0x0000000000000000000000000000000000000000000000000000000000000001",
+			"0x000000000000000000000000000000000000000b": {"0x0000000000000000000000000000000000000000000000000000000000000002": "0x0000000000000000000000000000000000000000000000000000000000000003"}
+		},
+		"blockNumberMin": "0x1",
+		"blockNumberMax": "0x2",
+		"timestampMin": "0x3",
+		"timestampMax": "0x4"
+	}`
+	var cond TransactionConditional
+	require.NoError(t, json.Unmarshal([]byte(input), &cond))
+	require.Len(t, cond.KnownAccounts, 2)
+
+	root := common.HexToHash("0x1")
+	assert.Equal(t, KnownAccount{StorageRoot: &root}, cond.KnownAccounts[common.HexToAddress("0xa")])
+	assert.Equal(t, KnownAccount{StorageSlots: map[common.Hash]common.Hash{common.HexToHash("0x2"): common.HexToHash("0x3")}},
+		cond.KnownAccounts[common.HexToAddress("0xb")])
+	assert.Equal(t, big.NewInt(1), cond.BlockNumberMin.ToInt())
+	assert.Equal(t, big.NewInt(2), cond.BlockNumberMax.ToInt())
+	assert.Equal(t, uint64(3), uint64(*cond.TimestampMin))
+	assert.Equal(t, uint64(4), uint64(*cond.TimestampMax))
+	assert.Equal(t, 2, cond.Cost())
+	assert.NoError(t, cond.Validate())
+
+	// The conditional is encoded back in the same form.
+	encoded, err := json.Marshal(cond)
+	require.NoError(t, err)
+	var decoded TransactionConditional
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, cond, decoded)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"knownAccounts": {"0x000000000000000000000000000000000000000a": 1}}`), &cond))
+}
+
+func TestTransactionConditional_Validate(t *testing.T) {
+	var cond TransactionConditional
+	require.NoError(t, json.Unmarshal([]byte(`{"blockNumberMin": "0x2", "blockNumberMax": "0x1"}`), &cond))
+	assert.ErrorIs(t, cond.Validate(), errInvalidTxConditional)
+
+	cond = TransactionConditional{}
+	require.NoError(t, json.Unmarshal([]byte(`{"timestampMin": "0x2", "timestampMax": "0x1"}`), &cond))
+	assert.ErrorIs(t, cond.Validate(), errInvalidTxConditional)
+
+	slots := make(map[common.Hash]common.Hash)
+	for i := 0; i <= MaxTxConditionalCost; i++ {
+		slots[common.BigToHash(big.NewInt(int64(i)))] = common.Hash{}
+	}
+	cond = TransactionConditional{KnownAccounts: map[common.Address]KnownAccount{{}: {StorageSlots: slots}}}
+	assert.ErrorIs(t, cond.Validate(), errInvalidTxConditional)
+}
+
+func TestTransactionConditional_Check(t *testing.T) {
+	var (
+		addr  = common.HexToAddress("0xa")
+		root  = common.HexToHash("0x1")
+		slot  = common.HexToHash("0x2")
+		value = common.HexToHash("0x3")
+		state = &testConditionalState{addr, root, map[common.Hash]common.Hash{slot: value}}
+	)
+	var cond TransactionConditional
+	require.NoError(t, json.Unmarshal([]byte(`{"blockNumberMin": "0xa", "blockNumberMax": "0x14", "timestampMin": "0x64", "timestampMax": "0xc8"}`), &cond))
+
+	tests := []struct {
+		number int64
+		time   uint64
+		err    error
+	}{
+		{10, 100, nil},
+		{20, 200, nil},
+		{9, 150, ErrTxConditionNotYetMet},
+		{15, 99, ErrTxConditionNotYetMet},
+		{21, 150, ErrTxConditionFailed},
+		{15, 201, ErrTxConditionFailed},
+		// The failure takes precedence over the not yet met one.
+		{9, 201, ErrTxConditionFailed},
+	}
+	for _, tt := range tests {
+		err := cond.Check(big.NewInt(tt.number), tt.time, state)
+		if tt.err == nil {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, tt.err)
+		}
+	}
+
+	otherRoot, otherValue := common.HexToHash("0x4"), common.HexToHash("0x5")
+	for _, tt := range []struct {
+		account KnownAccount
+		err     error
+	}{
+		{KnownAccount{StorageRoot: &root}, nil},
+		{KnownAccount{StorageRoot: &otherRoot}, ErrTxConditionFailed},
+		{KnownAccount{StorageSlots: map[common.Hash]common.Hash{slot: value}}, nil},
+		{KnownAccount{StorageSlots: map[common.Hash]common.Hash{slot: otherValue}}, ErrTxConditionFailed},
+	} {
+		cond := TransactionConditional{KnownAccounts: map[common.Address]KnownAccount{addr: tt.account}}
+		err := cond.Check(big.NewInt(1), 1, state)
+		if tt.err == nil {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, tt.err)
+		}
+	}
+}
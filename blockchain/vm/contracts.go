@@ -154,6 +154,21 @@ func init() {
 	}
 }
 
+// ActivePrecompiledContracts returns the precompiled contracts enabled with the current configuration.
+// The returned map is shared, so it must be copied before being modified.
+func ActivePrecompiledContracts(rules params.Rules) map[common.Address]PrecompiledContract {
+	switch {
+	case rules.IsCancun:
+		return PrecompiledContractsCancun
+	case rules.IsKore:
+		return PrecompiledContractsKore
+	case rules.IsIstanbul:
+		return PrecompiledContractsIstanbul
+	default:
+		return PrecompiledContractsByzantium
+	}
+}
+
 // ActivePrecompiles returns the precompiles enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
 	var precompiledContractAddrs []common.Address
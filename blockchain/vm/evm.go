@@ -158,6 +158,9 @@ type EVM struct {
 
 	// opcodeComputationCostSum is the sum of computation cost of opcodes.
 	opcodeComputationCostSum uint64
+
+	// precompiles overrides the precompiled contracts of the chain rules if set.
+	precompiles map[common.Address]PrecompiledContract
 }
 
 // NewEVM returns a new EVM. The returned EVM is not thread safe and should
@@ -639,6 +642,9 @@ func (evm *EVM) CreateWithAddress(caller types.ContractRef, code []byte, gas uin
 }
 
 func (evm *EVM) GetPrecompiledContractMap(addr common.Address) map[common.Address]PrecompiledContract {
+	if evm.precompiles != nil {
+		return evm.precompiles
+	}
 	// VmVersion means that the contract uses the precompiled contract map at the deployment time.
 	// Also, it follows old map's gas price & computation cost.
 
@@ -650,16 +656,13 @@ func (evm *EVM) GetPrecompiledContractMap(addr common.Address) map[common.Addres
 		return PrecompiledContractsByzantium
 	}
 
-	switch {
-	case evm.chainRules.IsCancun:
-		return PrecompiledContractsCancun
-	case evm.chainRules.IsKore:
-		return PrecompiledContractsKore
-	case evm.chainRules.IsIstanbul:
-		return PrecompiledContractsIstanbul
-	default:
-		return PrecompiledContractsByzantium
-	}
+	return ActivePrecompiledContracts(evm.chainRules)
+}
+
+// SetPrecompiles overrides the precompiled contracts of the chain rules. It is
+// used to simulate calls with relocated precompiled contracts.
+func (evm *EVM) SetPrecompiles(precompiles map[common.Address]PrecompiledContract) {
+	evm.precompiles = precompiles
 }
 
 // ChainConfig returns the environment's chain configuration
@@ -0,0 +1,43 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+istsim runs a network of Istanbul validators in a process and checks the safety
+and the liveness of the consensus under the injected faults.
+It doesn't need any running node, and exits with an error if the validators
+fail to reach the target block height in time or commit conflicting blocks.
+
+# Options
+
+All available options are as follows.
+
+	--validators value  The number of validators (default: 4)
+	--twins value       Index of a validator having a twin node signing with the same key (may be repeated)
+	--silent value      Index of a validator sending no messages (may be repeated)
+	--skew value        Clock skew of a node in the form of index:duration, e.g. 1:500ms (may be repeated)
+	--blocks value      The block height to be reached by all the nodes (default: 10)
+	--period value      The minimum block period in seconds (default: 1)
+	--timeout value     The round change timeout in milliseconds (default: 3000)
+	--delay value       The delay of delivering messages (default: 10ms)
+	--jitter value      The maximum random delay added to each message (default: 0s)
+	--drop value        The probability of dropping a message (default: 0)
+	--deadline value    The time limit to reach the block height (default: 5m0s)
+	--seed value        The seed of the random delays and drops (default: 1)
+	--blsseal           Aggregate the committed seals with BLS signatures (default: false)
+	--verbosity value   Logging verbosity: 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=detail (default: 1)
+	--help, -h          Show help
+*/
+package main
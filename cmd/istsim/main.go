@@ -0,0 +1,213 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klaytn/klaytn/consensus/istanbul/simulation"
+	"github.com/klaytn/klaytn/log"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	logger         = log.NewModuleLogger(log.CMDISTSIM)
+	validatorsFlag = &cli.IntFlag{
+		Name:  "validators",
+		Usage: "The number of validators",
+		Value: simulation.DefaultConfig.NumValidators,
+	}
+	twinsFlag = &cli.IntSliceFlag{
+		Name:  "twins",
+		Usage: "Index of a validator having a twin node signing with the same key (may be repeated)",
+	}
+	silentFlag = &cli.IntSliceFlag{
+		Name:  "silent",
+		Usage: "Index of a validator sending no messages (may be repeated)",
+	}
+	skewFlag = &cli.StringSliceFlag{
+		Name:  "skew",
+		Usage: "Clock skew of a node in the form of index:duration, e.g. 1:500ms (may be repeated)",
+	}
+	blocksFlag = &cli.Uint64Flag{
+		Name:  "blocks",
+		Usage: "The block height to be reached by all the nodes",
+		Value: 10,
+	}
+	periodFlag = &cli.Uint64Flag{
+		Name:  "period",
+		Usage: "The minimum block period in seconds",
+		Value: simulation.DefaultConfig.BlockPeriod,
+	}
+	timeoutFlag = &cli.Uint64Flag{
+		Name:  "timeout",
+		Usage: "The round change timeout in milliseconds",
+		Value: simulation.DefaultConfig.Timeout,
+	}
+	delayFlag = &cli.DurationFlag{
+		Name:  "delay",
+		Usage: "The delay of delivering messages",
+		Value: 10 * time.Millisecond,
+	}
+	jitterFlag = &cli.DurationFlag{
+		Name:  "jitter",
+		Usage: "The maximum random delay added to each message",
+	}
+	dropFlag = &cli.Float64Flag{
+		Name:  "drop",
+		Usage: "The probability of dropping a message",
+	}
+	deadlineFlag = &cli.DurationFlag{
+		Name:  "deadline",
+		Usage: "The time limit to reach the block height",
+		Value: 5 * time.Minute,
+	}
+	seedFlag = &cli.Int64Flag{
+		Name:  "seed",
+		Usage: "The seed of the random delays and drops",
+		Value: simulation.DefaultConfig.Seed,
+	}
+	blsSealFlag = &cli.BoolFlag{
+		Name:  "blsseal",
+		Usage: "Aggregate the committed seals with BLS signatures",
+	}
+	verbosityFlag = &cli.IntFlag{
+		Name:  "verbosity",
+		Usage: "Logging verbosity: 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=detail",
+		Value: int(log.LvlError),
+	}
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "istsim"
+	app.Usage = "The command line interface to simulate Istanbul validators with faults"
+	app.Copyright = "Copyright 2018-2024 The Kaia Authors"
+	app.Action = simulate
+	app.Flags = []cli.Flag{
+		validatorsFlag,
+		twinsFlag,
+		silentFlag,
+		skewFlag,
+		blocksFlag,
+		periodFlag,
+		timeoutFlag,
+		delayFlag,
+		jitterFlag,
+		dropFlag,
+		deadlineFlag,
+		seedFlag,
+		blsSealFlag,
+		verbosityFlag,
+	}
+	app.HideVersion = true
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// simulate runs the simulation until all the nodes reach the block height,
+// and checks that no conflicting blocks are committed.
+func simulate(ctx *cli.Context) error {
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	if err := log.ChangeGlobalLogLevel(glogger, log.Lvl(ctx.Int(verbosityFlag.Name))); err != nil {
+		return err
+	}
+	log.Root().SetHandler(glogger)
+
+	skews, err := parseSkews(ctx.StringSlice(skewFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	sim, err := simulation.New(simulation.Config{
+		NumValidators: ctx.Int(validatorsFlag.Name),
+		Twins:         ctx.IntSlice(twinsFlag.Name),
+		BlockPeriod:   ctx.Uint64(periodFlag.Name),
+		Timeout:       ctx.Uint64(timeoutFlag.Name),
+		Seed:          ctx.Int64(seedFlag.Name),
+		BlsSeal:       ctx.Bool(blsSealFlag.Name),
+	})
+	if err != nil {
+		return err
+	}
+	defer sim.Stop()
+
+	sim.SetDelay(ctx.Duration(delayFlag.Name), ctx.Duration(jitterFlag.Name))
+	sim.SetDropRate(ctx.Float64(dropFlag.Name))
+	for _, node := range ctx.IntSlice(silentFlag.Name) {
+		if node < 0 || node >= len(sim.Nodes()) {
+			return fmt.Errorf("unknown node %d", node)
+		}
+		sim.SetInterceptor(node, func(msg *simulation.Message) []*simulation.Message { return nil })
+	}
+	for node, skew := range skews {
+		if node < 0 || node >= len(sim.Nodes()) {
+			return fmt.Errorf("unknown node %d", node)
+		}
+		sim.Node(node).SetClockSkew(skew)
+	}
+
+	if err := sim.Start(); err != nil {
+		return err
+	}
+	start := time.Now()
+	liveness := sim.WaitForHeight(ctx.Uint64(blocksFlag.Name), ctx.Duration(deadlineFlag.Name))
+	safety := sim.CheckSafety()
+
+	fmt.Printf("elapsed: %v, dropped: %d\n", time.Since(start).Round(time.Millisecond), sim.Dropped())
+	for _, node := range sim.Nodes() {
+		fmt.Printf("node %d (%s): height %d, misbehaviours %d\n",
+			node.Index(), node.Address().String(), node.Height(), len(node.Misbehaviours()))
+	}
+	if safety != nil {
+		logger.Error("Safety violated", "err", safety)
+		return safety
+	}
+	if liveness != nil {
+		logger.Error("Liveness violated", "err", liveness)
+		return liveness
+	}
+	fmt.Println("OK")
+	return nil
+}
+
+// parseSkews parses the clock skews in the form of index:duration.
+func parseSkews(values []string) (map[int]time.Duration, error) {
+	skews := make(map[int]time.Duration)
+	for _, value := range values {
+		parts := strings.SplitN(value, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid clock skew %q", value)
+		}
+		node, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid clock skew %q: %v", value, err)
+		}
+		skew, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid clock skew %q: %v", value, err)
+		}
+		skews[node] = skew
+	}
+	return skews, nil
+}
@@ -49,6 +49,8 @@ func init() {
 		// See utils/nodecmd/chaincmd.go:
 		nodecmd.InitCommand,
 		nodecmd.DumpGenesisCommand,
+		nodecmd.ImportCommand,
+		nodecmd.ExportCommand,
 
 		// See utils/nodecmd/accountcmd.go
 		nodecmd.AccountCommand,
@@ -68,6 +70,12 @@ func init() {
 
 		// See utils/nodecmd/snapshot.go:
 		nodecmd.SnapshotCommand,
+
+		// See utils/nodecmd/dbcmd.go:
+		nodecmd.DBCommand,
+
+		// See utils/nodecmd/historycmd.go:
+		nodecmd.HistoryCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
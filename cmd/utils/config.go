@@ -373,6 +373,7 @@ func (kCfg *KaiaConfig) SetNodeConfig(ctx *cli.Context) {
 
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
+	setRPCAuth(ctx, cfg)
 	setgRPC(ctx, cfg)
 	setAPIConfig(ctx)
 	setNodeUserIdent(ctx, cfg)
@@ -422,6 +423,23 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 		rpc.ConcurrencyLimit = ctx.Int(RPCConcurrencyLimit.Name)
 		logger.Info("Set the concurrency limit of RPC-HTTP server", "limit", rpc.ConcurrencyLimit)
 	}
+	if ctx.IsSet(RPCBatchRequestLimitFlag.Name) {
+		rpc.BatchRequestLimit = ctx.Int(RPCBatchRequestLimitFlag.Name)
+		logger.Info("Set the batch request limit of RPC servers", "limit", rpc.BatchRequestLimit)
+	}
+	if ctx.IsSet(RPCBatchResponseMaxSizeFlag.Name) {
+		rpc.BatchResponseMaxSize = ctx.Int(RPCBatchResponseMaxSizeFlag.Name)
+		logger.Info("Set the batch response size limit of RPC servers", "limit", rpc.BatchResponseMaxSize)
+	}
+	if limit := ctx.Float64(RPCRateLimitFlag.Name); limit > 0 {
+		weights, err := rpc.ParseMethodWeights(ctx.String(RPCRateLimitWeightsFlag.Name))
+		if err != nil {
+			log.Fatalf("Option %q: %v", RPCRateLimitWeightsFlag.Name, err)
+		}
+		burst := ctx.Int(RPCRateLimitBurstFlag.Name)
+		rpc.DefaultRateLimiter = rpc.NewRateLimiter(limit, burst, weights)
+		logger.Info("Enabled the rate limit of RPC clients", "limit", limit, "burst", burst)
+	}
 	if ctx.IsSet(RPCReadTimeout.Name) {
 		cfg.HTTPTimeouts.ReadTimeout = time.Duration(ctx.Int(RPCReadTimeout.Name)) * time.Second
 	}
@@ -434,10 +452,25 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(RPCExecutionTimeoutFlag.Name) {
 		cfg.HTTPTimeouts.ExecutionTimeout = time.Duration(ctx.Int(RPCExecutionTimeoutFlag.Name)) * time.Second
 	}
+	CheckExclusive(ctx, RPCUpstreamArchiveENFlag, RPCUpstreamRoutesFlag)
 	if ctx.IsSet(RPCUpstreamArchiveENFlag.Name) {
 		rpc.UpstreamArchiveEN = ctx.String(RPCUpstreamArchiveENFlag.Name)
 		cfg.UpstreamArchiveEN = rpc.UpstreamArchiveEN
 	}
+	if ctx.IsSet(RPCUpstreamRoutesFlag.Name) {
+		cfg.UpstreamRoutes = ctx.String(RPCUpstreamRoutesFlag.Name)
+	}
+}
+
+// setRPCAuth applies the JWT authentication configuration of the HTTP and
+// WebSocket RPC endpoints from the set command line flags.
+func setRPCAuth(ctx *cli.Context, cfg *node.Config) {
+	if ctx.IsSet(RPCJWTSecretFlag.Name) {
+		cfg.JWTSecret = ctx.String(RPCJWTSecretFlag.Name)
+	}
+	if ctx.IsSet(RPCJWTPolicyFlag.Name) {
+		cfg.JWTPolicy = ctx.String(RPCJWTPolicyFlag.Name)
+	}
 }
 
 // setWS creates the WebSocket RPC listener interface string from the set
@@ -496,6 +529,7 @@ func setgRPC(ctx *cli.Context, cfg *node.Config) {
 func setAPIConfig(ctx *cli.Context) {
 	filters.GetLogsDeadline = ctx.Duration(APIFilterGetLogsDeadlineFlag.Name)
 	filters.GetLogsMaxItems = ctx.Int(APIFilterGetLogsMaxItemsFlag.Name)
+	filters.GetLogsPageSize = ctx.Int(APIFilterGetLogsPageSizeFlag.Name)
 }
 
 // setNodeUserIdent creates the user identifier from CLI flags.
@@ -280,12 +280,20 @@ var FlagGroups = []FlagGroup{
 			RPCGlobalEVMTimeoutFlag,
 			RPCGlobalEthTxFeeCapFlag,
 			RPCConcurrencyLimit,
+			RPCBatchRequestLimitFlag,
+			RPCBatchResponseMaxSizeFlag,
+			RPCRateLimitFlag,
+			RPCRateLimitBurstFlag,
+			RPCRateLimitWeightsFlag,
 			RPCNonEthCompatibleFlag,
 			RPCExecutionTimeoutFlag,
 			RPCIdleTimeoutFlag,
 			RPCReadTimeout,
 			RPCWriteTimeoutFlag,
 			RPCUpstreamArchiveENFlag,
+			RPCUpstreamRoutesFlag,
+			RPCJWTSecretFlag,
+			RPCJWTPolicyFlag,
 			UnsafeDebugDisableFlag,
 			IPCDisabledFlag,
 			IPCPathFlag,
@@ -307,6 +315,7 @@ var FlagGroups = []FlagGroup{
 			MaxRequestContentLengthFlag,
 			APIFilterGetLogsDeadlineFlag,
 			APIFilterGetLogsMaxItemsFlag,
+			APIFilterGetLogsPageSizeFlag,
 		},
 	},
 	{
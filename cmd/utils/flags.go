@@ -41,6 +41,7 @@ import (
 	"github.com/klaytn/klaytn/node/sc"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/era"
 	"github.com/klaytn/klaytn/storage/statedb"
 	"github.com/urfave/cli/v2"
 )
@@ -478,6 +479,74 @@ var (
 		EnvVars:  []string{"KLAYTN_DB_NO_PERF_METRICS", "KAIA_DB_NO_PERF_METRICS"},
 		Category: "DATABASE",
 	}
+	DBJSONOutputFlag = &cli.BoolFlag{
+		Name:     "json",
+		Usage:    "Prints the output of the db command in JSON format",
+		Category: "DATABASE",
+	}
+	DBWriteFlag = &cli.BoolFlag{
+		Name:     "write",
+		Usage:    "Opens the database in read-write mode to allow the db command to modify it",
+		Category: "DATABASE",
+	}
+	DBPrefixFlag = &cli.StringFlag{
+		Name:     "prefix",
+		Usage:    "Hex encoded prefix of the keys to dump",
+		Category: "DATABASE",
+	}
+	DBStartFlag = &cli.StringFlag{
+		Name:     "start",
+		Usage:    "Hex encoded key (excluding the prefix) to start the dump from",
+		Category: "DATABASE",
+	}
+	DBLimitFlag = &cli.Uint64Flag{
+		Name:     "limit",
+		Usage:    "Maximum number of entries to dump (0 = unlimited)",
+		Value:    100,
+		Category: "DATABASE",
+	}
+	HistoryStepFlag = &cli.Uint64Flag{
+		Name:     "history.step",
+		Usage:    "Number of blocks stored in an era file",
+		Value:    era.MaxEra1Size,
+		Category: "DATABASE",
+	}
+	HistoryNetworkFlag = &cli.StringFlag{
+		Name:     "history.network",
+		Usage:    "Network name prefixed to the era file names (default: derived from the chain ID)",
+		Category: "DATABASE",
+	}
+	PruneStateBloomSizeFlag = &cli.Uint64Flag{
+		Name:     "bloomfilter.size",
+		Usage:    "Megabytes of memory allocated to the bloom filter of the live state trie nodes for pruning",
+		Value:    2048,
+		Category: "DATABASE",
+	}
+	PruneStateDryRunFlag = &cli.BoolFlag{
+		Name:     "dry-run",
+		Usage:    "Reports the state trie nodes to be pruned without deleting them",
+		Category: "DATABASE",
+	}
+	SnapshotDumpStartFlag = &cli.StringFlag{
+		Name:     "start",
+		Usage:    "Hex encoded address or account hash to start the dump from",
+		Category: "DATABASE",
+	}
+	SnapshotDumpLimitFlag = &cli.Uint64Flag{
+		Name:     "limit",
+		Usage:    "Maximum number of accounts to dump (0 = unlimited)",
+		Category: "DATABASE",
+	}
+	SnapshotDumpStorageFlag = &cli.BoolFlag{
+		Name:     "storage",
+		Usage:    "Includes the storage slots of the contracts in the dump",
+		Category: "DATABASE",
+	}
+	SnapshotDumpCodeFlag = &cli.BoolFlag{
+		Name:     "code",
+		Usage:    "Includes the code of the contracts in the dump",
+		Category: "DATABASE",
+	}
 	SnapshotFlag = &cli.BoolFlag{
 		Name:     "snapshot",
 		Usage:    "Enables snapshot-database mode",
@@ -857,6 +926,44 @@ var (
 		EnvVars:  []string{"KLAYTN_RPC_CONCURRENCYLIMIT", "KAIA_RPC_CONCURRENCYLIMIT"},
 		Category: "API AND CONSOLE",
 	}
+	RPCBatchRequestLimitFlag = &cli.IntFlag{
+		Name:     "rpc.batchlimit",
+		Usage:    "Maximum number of calls in a JSON-RPC batch request (0 = no limit)",
+		Value:    rpc.BatchRequestLimit,
+		Aliases:  []string{"http-rpc.batch-limit"},
+		EnvVars:  []string{"KLAYTN_RPC_BATCHLIMIT", "KAIA_RPC_BATCHLIMIT"},
+		Category: "API AND CONSOLE",
+	}
+	RPCBatchResponseMaxSizeFlag = &cli.IntFlag{
+		Name:     "rpc.batchresponsemaxsize",
+		Usage:    "Maximum number of bytes of the results in a JSON-RPC batch response (0 = no limit)",
+		Value:    rpc.BatchResponseMaxSize,
+		Aliases:  []string{"http-rpc.batch-response-max-size"},
+		EnvVars:  []string{"KLAYTN_RPC_BATCHRESPONSEMAXSIZE", "KAIA_RPC_BATCHRESPONSEMAXSIZE"},
+		Category: "API AND CONSOLE",
+	}
+	RPCRateLimitFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit",
+		Usage:    "Number of call weights per second allowed to each HTTP-RPC and WS-RPC client (0 = no limit)",
+		Aliases:  []string{"http-rpc.rate-limit"},
+		EnvVars:  []string{"KLAYTN_RPC_RATELIMIT", "KAIA_RPC_RATELIMIT"},
+		Category: "API AND CONSOLE",
+	}
+	RPCRateLimitBurstFlag = &cli.IntFlag{
+		Name:     "rpc.ratelimit.burst",
+		Usage:    "Maximum call weights of the burst allowed to each HTTP-RPC and WS-RPC client",
+		Value:    100,
+		Aliases:  []string{"http-rpc.rate-limit.burst"},
+		EnvVars:  []string{"KLAYTN_RPC_RATELIMIT_BURST", "KAIA_RPC_RATELIMIT_BURST"},
+		Category: "API AND CONSOLE",
+	}
+	RPCRateLimitWeightsFlag = &cli.StringFlag{
+		Name:     "rpc.ratelimit.weights",
+		Usage:    "Comma separated list of method=weight pairs overriding the default call weights (e.g. debug_trace*=50,eth_getLogs=10)",
+		Aliases:  []string{"http-rpc.rate-limit.weights"},
+		EnvVars:  []string{"KLAYTN_RPC_RATELIMIT_WEIGHTS", "KAIA_RPC_RATELIMIT_WEIGHTS"},
+		Category: "API AND CONSOLE",
+	}
 	RPCNonEthCompatibleFlag = &cli.BoolFlag{
 		Name:     "rpc.eth.noncompatible",
 		Usage:    "Disables the eth namespace API return formatting for compatibility",
@@ -903,6 +1010,27 @@ var (
 		EnvVars:  []string{"KLAYTN_RPC_UPSTREAM_EN", "KAIA_RPC_UPSTREAM_EN"},
 		Category: "API AND CONSOLE",
 	}
+	RPCJWTSecretFlag = &cli.StringFlag{
+		Name:     "rpc.jwtsecret",
+		Usage:    "Path to a hex-encoded JWT secret to authenticate the HTTP-RPC and WS-RPC requests (generated if missing)",
+		Aliases:  []string{"http-rpc.jwt-secret"},
+		EnvVars:  []string{"KLAYTN_RPC_JWTSECRET", "KAIA_RPC_JWTSECRET"},
+		Category: "API AND CONSOLE",
+	}
+	RPCJWTPolicyFlag = &cli.StringFlag{
+		Name:     "rpc.jwtpolicy",
+		Usage:    "Path to a JSON file mapping the roles of the JWT tokens to the allowed namespaces and methods",
+		Aliases:  []string{"http-rpc.jwt-policy"},
+		EnvVars:  []string{"KLAYTN_RPC_JWTPOLICY", "KAIA_RPC_JWTPOLICY"},
+		Category: "API AND CONSOLE",
+	}
+	RPCUpstreamRoutesFlag = &cli.StringFlag{
+		Name:     "rpc.upstream-routes",
+		Usage:    "Path to a JSON file routing the RPC calls to the upstream EN endpoints by the methods and the block ages",
+		Aliases:  []string{"http-rpc.upstream-routes"},
+		EnvVars:  []string{"KLAYTN_RPC_UPSTREAM_ROUTES", "KAIA_RPC_UPSTREAM_ROUTES"},
+		Category: "API AND CONSOLE",
+	}
 
 	WSEnabledFlag = &cli.BoolFlag{
 		Name:     "ws",
@@ -1052,6 +1180,14 @@ var (
 		EnvVars:  []string{"KLAYTN_API_FILTER_GETLOGS_MAXITEMS", "KAIA_API_FILTER_GETLOGS_MAXITEMS"},
 		Category: "API AND CONSOLE",
 	}
+	APIFilterGetLogsPageSizeFlag = &cli.IntFlag{
+		Name:     "api.filter.getLogs.pagesize",
+		Usage:    "Default number of logs in a page of paginated log collecting filter APIs",
+		Value:    filters.GetLogsPageSize,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_API_FILTER_GETLOGS_PAGESIZE", "KAIA_API_FILTER_GETLOGS_PAGESIZE"},
+		Category: "API AND CONSOLE",
+	}
 	UnsafeDebugDisableFlag = &cli.BoolFlag{
 		Name:     "rpc.unsafe-debug.disable",
 		Usage:    "Disable unsafe debug APIs (traceTransaction, traceChain, ...).",
@@ -23,14 +23,20 @@ package nodecmd
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/governance"
 	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/node"
+	"github.com/klaytn/klaytn/node/cn"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/rlp"
 	"github.com/klaytn/klaytn/storage/database"
@@ -92,6 +98,42 @@ It expects the genesis file as argument.`,
 		Description: `
 The dumpgenesis command dumps the genesis block configuration in JSON format to stdout.`,
 	}
+
+	ImportCommand = &cli.Command{
+		Action:    utils.MigrateFlags(importChain),
+		Name:      "import",
+		Usage:     "Import blocks from RLP encoded files",
+		ArgsUsage: "<filename> (<filename 2> ... <filename N>)",
+		Flags:     utils.ChainImportExportFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The import command imports blocks from RLP encoded files into the chaindata of
+a stopped node. The files are imported in the given order, so that an exported
+chain split into several segments can be imported at once. If a file name ends
+with ".gz", the file is read as a gzip stream.
+
+The imported blocks are verified and executed as if they were received from
+the network. The blocks already stored in the chaindata are skipped, so that an
+interrupted import resumes from the current head block.`,
+	}
+
+	ExportCommand = &cli.Command{
+		Action:    utils.MigrateFlags(exportChain),
+		Name:      "export",
+		Usage:     "Export blocks into an RLP encoded file",
+		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
+		Flags:     utils.ChainImportExportFlags,
+		Category:  "BLOCKCHAIN COMMANDS",
+		Description: `
+The export command exports the blocks of the chaindata of a stopped node into
+an RLP encoded file. If a file name ends with ".gz", the file is written as a
+gzip stream.
+
+If only the file name is given, the whole chain from the genesis block to the
+current head block is exported, truncating the file. If the first and the last
+block numbers are given, the blocks in the range are appended to the file, so
+that a large chain can be exported in several segments.`,
+	}
 )
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
@@ -283,3 +325,100 @@ func ValidateGenesisConfig(g *blockchain.Genesis) error {
 	}
 	return nil
 }
+
+// makeOfflineChain creates a block chain on top of the chaindata after locking
+// the instance directory so that the chaindata of a running node is not opened.
+// The returned function stops the block chain and releases the lock.
+func makeOfflineChain(ctx *cli.Context) (*blockchain.BlockChain, func(), error) {
+	stack, cfg := utils.MakeConfigNode(ctx)
+	if stack.DataDir() == "" {
+		return nil, nil, errors.New("datadir is not given")
+	}
+	lock, err := stack.LockInstanceDir()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock %s: %v", stack.InstanceDir(), err)
+	}
+	releaseLock := func() {
+		if err := lock.Release(); err != nil {
+			logger.Error("Failed to release the lock of the instance directory", "err", err)
+		}
+	}
+
+	svcCtx := node.NewServiceContext(&cfg.Node, nil, stack.EventMux(), stack.AccountManager())
+	chain, stopChain, err := cn.MakeChain(svcCtx, &cfg.CN)
+	if err != nil {
+		releaseLock()
+		return nil, nil, err
+	}
+	return chain, func() {
+		stopChain()
+		releaseLock()
+	}, nil
+}
+
+// importChain imports the blocks of the given files in order.
+func importChain(ctx *cli.Context) error {
+	if ctx.Args().Len() < 1 {
+		return errors.New("at least one file name is required")
+	}
+	chain, stop, err := makeOfflineChain(ctx)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	var (
+		start = time.Now()
+		head  = chain.CurrentBlock().NumberU64()
+	)
+	logger.Info("Importing blocks", "files", ctx.Args().Len(), "head", head)
+	for _, fn := range ctx.Args().Slice() {
+		if err := utils.ImportChain(chain, fn); err != nil {
+			return fmt.Errorf("failed to import %s: %v", fn, err)
+		}
+	}
+	logger.Info("Imported blocks", "from", head, "head", chain.CurrentBlock().NumberU64(),
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// exportChain exports the whole chain or the blocks in the given range.
+func exportChain(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 && ctx.Args().Len() != 3 {
+		return errors.New("a file name and optionally the first and the last block numbers are required")
+	}
+	var first, last uint64
+	if ctx.Args().Len() == 3 {
+		var err error
+		if first, err = strconv.ParseUint(ctx.Args().Get(1), 10, 64); err != nil {
+			return fmt.Errorf("invalid first block number: %v", err)
+		}
+		if last, err = strconv.ParseUint(ctx.Args().Get(2), 10, 64); err != nil {
+			return fmt.Errorf("invalid last block number: %v", err)
+		}
+		if first > last {
+			return fmt.Errorf("the first block number %d is greater than the last %d", first, last)
+		}
+	}
+	chain, stop, err := makeOfflineChain(ctx)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	start := time.Now()
+	fn := ctx.Args().First()
+	if ctx.Args().Len() == 1 {
+		err = utils.ExportChain(chain, fn)
+	} else {
+		if head := chain.CurrentBlock().NumberU64(); last > head {
+			return fmt.Errorf("the last block number %d is greater than the head %d", last, head)
+		}
+		err = utils.ExportAppendChain(chain, fn, first, last)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to export %s: %v", fn, err)
+	}
+	logger.Info("Exported blocks", "file", fn, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
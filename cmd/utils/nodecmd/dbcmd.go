@@ -0,0 +1,363 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package nodecmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/node"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/urfave/cli/v2"
+)
+
+var errDBWriteNotAllowed = errors.New("the database is opened in read-only mode, use --write to modify it")
+
+var DBCommand = &cli.Command{
+	Name:     "db",
+	Usage:    "Low level database operations",
+	Category: "DATABASE COMMANDS",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "inspect",
+			Usage:  "Inspect the storage size of each data category",
+			Action: utils.MigrateFlags(inspectDB),
+			Flags:  utils.DBInspectFlags,
+			Description: `
+Kaia db inspect
+traverses every database of the chaindata (misc, header, body, receipts,
+statetrie, txlookup, snapshot, etc.) and reports the number of keys and the
+total size of each data category such as headers, bodies, receipts,
+transaction lookups, trie nodes, snapshot accounts and storage, governance
+and staking info. The node must be stopped before running this command.
+`,
+		},
+		{
+			Name:      "stats",
+			Usage:     "Print the internal statistics of each database",
+			ArgsUsage: "[property]",
+			Action:    utils.MigrateFlags(dbStats),
+			Flags:     utils.SnapshotFlags,
+			Description: `
+Kaia db stats [property]
+prints the statistics reported by the database backend for the given property.
+If the property is not given, the default statistics of the backend are printed
+(e.g. "leveldb.stats" for LevelDB).
+`,
+		},
+		{
+			Name:      "get",
+			Usage:     "Print the value of the given key",
+			ArgsUsage: "<database> <hex-key>",
+			Action:    utils.MigrateFlags(dbGet),
+			Flags:     utils.SnapshotFlags,
+			Description: `
+Kaia db get <database> <hex-key>
+prints the hex encoded value of the given key stored in the database.
+The database is one of misc, header, body, receipts, statetrie,
+statetrie_migrated, txlookup, bridgeservice and snapshot.
+The keys of a sharded statetrie are looked up in the shard storing them.
+`,
+		},
+		{
+			Name:      "put",
+			Usage:     "Store the given key-value pair",
+			ArgsUsage: "<database> <hex-key> <hex-value>",
+			Action:    utils.MigrateFlags(dbPut),
+			Flags:     utils.DBWriteFlags,
+			Description: `
+Kaia db put --write <database> <hex-key> <hex-value>
+stores the given key-value pair in the database.
+The databases are opened in read-only mode unless --write is given.
+`,
+		},
+		{
+			Name:      "delete",
+			Usage:     "Delete the given key",
+			ArgsUsage: "<database> <hex-key>",
+			Action:    utils.MigrateFlags(dbDelete),
+			Flags:     utils.DBWriteFlags,
+			Description: `
+Kaia db delete --write <database> <hex-key>
+deletes the given key from the database.
+The databases are opened in read-only mode unless --write is given.
+`,
+		},
+		{
+			Name:      "dump-range",
+			Usage:     "Print the key-value pairs with the given prefix",
+			ArgsUsage: "<database>",
+			Action:    utils.MigrateFlags(dbDumpRange),
+			Flags:     utils.DBDumpRangeFlags,
+			Description: `
+Kaia db dump-range [--prefix <hex>] [--start <hex>] [--limit <n>] <database>
+prints the key-value pairs of the database whose key has the given prefix,
+in ascending order of the keys starting from the given start key.
+With --json, each pair is printed as a JSON object on its own line.
+The shards of a sharded statetrie are merged in order.
+`,
+		},
+	},
+}
+
+// openDB opens the databases of the chaindata after locking the instance
+// directory so that the databases of a running node are not opened.
+// The returned function closes the databases and releases the lock.
+func openDB(ctx *cli.Context, readOnly bool) (database.DBManager, func(), error) {
+	_, dbm, closeDB, err := openNodeDB(ctx, readOnly)
+	return dbm, closeDB, err
+}
+
+// openNodeDB is the same as openDB, but it also returns the node to resolve
+// the paths in the instance directory.
+func openNodeDB(ctx *cli.Context, readOnly bool) (*node.Node, database.DBManager, func(), error) {
+	stack, _ := utils.MakeConfigNode(ctx)
+	if stack.DataDir() == "" {
+		return nil, nil, nil, errors.New("datadir is not given")
+	}
+	lock, err := stack.LockInstanceDir()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to lock %s: %v", stack.InstanceDir(), err)
+	}
+	dbc := getConfig(ctx)
+	dbc.ReadOnly = readOnly
+	dbm := stack.OpenDatabase(dbc)
+	return stack, dbm, func() {
+		dbm.Close()
+		if err := lock.Release(); err != nil {
+			logger.Error("Failed to release the lock of the instance directory", "err", err)
+		}
+	}, nil
+}
+
+// openDBEntry opens the database of the given name and returns it with
+// the parsed hex arguments following the name.
+func openDBEntry(ctx *cli.Context, readOnly bool, numArgs int) (database.Database, [][]byte, func(), error) {
+	if ctx.Args().Len() != numArgs {
+		return nil, nil, nil, fmt.Errorf("%d arguments are required, but %d are given", numArgs, ctx.Args().Len())
+	}
+	et, err := database.ParseDBEntryType(ctx.Args().First())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	args := make([][]byte, 0, numArgs-1)
+	for _, arg := range ctx.Args().Tail() {
+		b, err := hexutil.Decode(arg)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid hex argument %q: %v", arg, err)
+		}
+		args = append(args, b)
+	}
+	dbm, closeDB, err := openDB(ctx, readOnly)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	db := dbm.GetDatabase(et)
+	if db == nil {
+		closeDB()
+		return nil, nil, nil, fmt.Errorf("%s database is not opened", et)
+	}
+	return db, args, closeDB, nil
+}
+
+// inspectDB prints the number of keys and the size of each data category
+// stored in the chaindata.
+func inspectDB(ctx *cli.Context) error {
+	dbm, closeDB, err := openDB(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	entries, err := database.InspectDatabase(dbm)
+	if err != nil {
+		return err
+	}
+	if ctx.Bool(utils.DBJSONOutputFlag.Name) {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+	return printInspectEntries(os.Stdout, entries)
+}
+
+// printInspectEntries writes the entries in a table with the subtotal of
+// each database and the total of all databases.
+func printInspectEntries(w io.Writer, entries []*database.InspectEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "DATABASE\tCATEGORY\tCOUNT\tSIZE\t")
+
+	var (
+		count, size       uint64
+		totCount, totSize uint64
+	)
+	for i, entry := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t\n", entry.Database, entry.Category, entry.Count, common.StorageSize(entry.Size))
+		count += entry.Count
+		size += entry.Size
+		if i == len(entries)-1 || entries[i+1].Database != entry.Database {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t\n", entry.Database, "Subtotal", count, common.StorageSize(size))
+			totCount += count
+			totSize += size
+			count, size = 0, 0
+		}
+	}
+	fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t\n", "", "Total", totCount, common.StorageSize(totSize))
+	return tw.Flush()
+}
+
+// dbStats prints the statistics of each database reported by its backend.
+func dbStats(ctx *cli.Context) error {
+	dbm, closeDB, err := openDB(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	stats, err := dbm.Stat(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	fmt.Println(stats)
+	return nil
+}
+
+// dbGet prints the value of the given key.
+func dbGet(ctx *cli.Context) error {
+	db, args, closeDB, err := openDBEntry(ctx, true, 2)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	key := args[0]
+	if shard, ok := database.ShardIndex(db, key); ok {
+		logger.Info("Reading the key from the shard", "key", hexutil.Bytes(key), "shard", shard)
+	}
+	value, err := db.Get(key)
+	if err != nil {
+		return fmt.Errorf("failed to read %#x: %v", key, err)
+	}
+	fmt.Println(hexutil.Encode(value))
+	return nil
+}
+
+// dbPut stores the given key-value pair.
+func dbPut(ctx *cli.Context) error {
+	if !ctx.Bool(utils.DBWriteFlag.Name) {
+		return errDBWriteNotAllowed
+	}
+	db, args, closeDB, err := openDBEntry(ctx, false, 3)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	key, value := args[0], args[1]
+	if old, err := db.Get(key); err == nil {
+		logger.Info("Overwriting the previous value", "key", hexutil.Bytes(key), "old", hexutil.Bytes(old))
+	}
+	if err := db.Put(key, value); err != nil {
+		return fmt.Errorf("failed to write %#x: %v", key, err)
+	}
+	logger.Info("Stored the value", "key", hexutil.Bytes(key), "value", hexutil.Bytes(value))
+	return nil
+}
+
+// dbDelete deletes the given key.
+func dbDelete(ctx *cli.Context) error {
+	if !ctx.Bool(utils.DBWriteFlag.Name) {
+		return errDBWriteNotAllowed
+	}
+	db, args, closeDB, err := openDBEntry(ctx, false, 2)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	key := args[0]
+	if old, err := db.Get(key); err == nil {
+		logger.Info("Deleting the value", "key", hexutil.Bytes(key), "old", hexutil.Bytes(old))
+	}
+	if err := db.Delete(key); err != nil {
+		return fmt.Errorf("failed to delete %#x: %v", key, err)
+	}
+	return nil
+}
+
+// dbEntry is a key-value pair printed by dump-range.
+type dbEntry struct {
+	Key   hexutil.Bytes `json:"key"`
+	Value hexutil.Bytes `json:"value"`
+}
+
+// dbDumpRange prints the key-value pairs with the given prefix as they are
+// read from the iterator.
+func dbDumpRange(ctx *cli.Context) error {
+	var prefix, start []byte
+	if ctx.IsSet(utils.DBPrefixFlag.Name) {
+		b, err := hexutil.Decode(ctx.String(utils.DBPrefixFlag.Name))
+		if err != nil {
+			return fmt.Errorf("invalid prefix: %v", err)
+		}
+		prefix = b
+	}
+	if ctx.IsSet(utils.DBStartFlag.Name) {
+		b, err := hexutil.Decode(ctx.String(utils.DBStartFlag.Name))
+		if err != nil {
+			return fmt.Errorf("invalid start: %v", err)
+		}
+		start = b
+	}
+	if dbType := getConfig(ctx).DBType; !dbType.Iterable() {
+		return fmt.Errorf("%s does not support the iteration of the database", dbType)
+	}
+	db, _, closeDB, err := openDBEntry(ctx, true, 1)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	var (
+		limit   = ctx.Uint64(utils.DBLimitFlag.Name)
+		jsonOut = ctx.Bool(utils.DBJSONOutputFlag.Name)
+		enc     = json.NewEncoder(os.Stdout)
+		it      = db.NewIterator(prefix, start)
+	)
+	defer it.Release()
+	for count := uint64(0); (limit == 0 || count < limit) && it.Next(); count++ {
+		entry := dbEntry{Key: it.Key(), Value: it.Value()}
+		if jsonOut {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("%s %s\n", entry.Key, entry.Value)
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -21,8 +21,10 @@ Package nodecmd contains command definitions and related functions used for node
 
 Each file contains following contents
   - accountcmd.go		: Provides functions for creating, updating and importing an account.
-  - chaincmd.go		: Provides functions to `init` a block chain,
+  - chaincmd.go		: Provides functions to `init` a block chain and to `import` and `export` blocks
   - consolecmd.go		: Provides console functions `attach` and `console`
+  - dbcmd.go		: Provides functions to inspect and edit the chain database offline
+  - historycmd.go		: Provides functions to export, import and verify the block history in era files
   - migrationcmd.go		: Provides functions of DB migration
   - defaultcmd.go		: Provides functions to start a node
   - dumpconfigcmd.go		: Provides functions to dump and print current config to stdout
@@ -0,0 +1,222 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package nodecmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/era"
+	"github.com/urfave/cli/v2"
+)
+
+var HistoryCommand = &cli.Command{
+	Name:     "history",
+	Usage:    "Export, import and verify the block history in era files",
+	Category: "BLOCKCHAIN COMMANDS",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "export",
+			Usage:     "Export the block history into era files",
+			ArgsUsage: "<dir> [<blockNumFirst> <blockNumLast>]",
+			Action:    utils.MigrateFlags(exportHistory),
+			Flags:     utils.HistoryExportFlags,
+			Description: `
+Kaia history export <dir> [<blockNumFirst> <blockNumLast>]
+exports the headers, bodies, receipts and total block scores of the canonical
+blocks into era files in the directory. Each era file stores the blocks of a
+fixed range given by --history.step with the accumulator root of the header
+records. If the block range is not given, the blocks from the genesis block to
+the head block are exported.
+`,
+		},
+		{
+			Name:      "import",
+			Usage:     "Import the block history from era files",
+			ArgsUsage: "<dir>",
+			Action:    utils.MigrateFlags(importHistory),
+			Flags:     utils.HistoryFlags,
+			Description: `
+Kaia history import <dir>
+verifies the era files in the directory and writes their headers, bodies and
+receipts into the chaindata without executing the blocks. The chaindata must
+be initialized with the same genesis block. The blocks already stored are
+skipped. Since the states of the imported blocks are not available, the head
+block is kept and only the head header is moved to the last imported block.
+`,
+		},
+		{
+			Name:      "verify",
+			Usage:     "Verify the era files",
+			ArgsUsage: "<dir>",
+			Action:    utils.MigrateFlags(verifyHistory),
+			Flags:     utils.HistoryFlags,
+			Description: `
+Kaia history verify <dir>
+verifies the era files in the directory. It checks the chain of the headers,
+the transaction and receipt roots of the headers, and the accumulator root of
+each file. The chain config stored in the chaindata is used to derive the roots.
+`,
+		},
+	},
+}
+
+// historyNetwork returns the network name prefixed to the era file names.
+func historyNetwork(ctx *cli.Context, config *params.ChainConfig) string {
+	if ctx.IsSet(utils.HistoryNetworkFlag.Name) {
+		return ctx.String(utils.HistoryNetworkFlag.Name)
+	}
+	switch config.ChainID.Uint64() {
+	case params.CypressNetworkId:
+		return "cypress"
+	case params.BaobabNetworkId:
+		return "baobab"
+	default:
+		return fmt.Sprintf("kaia%d", config.ChainID)
+	}
+}
+
+// readChainConfig reads the chain config of the genesis block stored in the
+// chaindata and initializes DeriveSha with it.
+func readChainConfig(dbm database.DBManager) (*params.ChainConfig, error) {
+	genesisHash := dbm.ReadCanonicalHash(0)
+	if common.EmptyHash(genesisHash) {
+		return nil, errors.New("genesis block not found, the chaindata should be initialized first")
+	}
+	config := dbm.ReadChainConfig(genesisHash)
+	if config == nil {
+		return nil, fmt.Errorf("chain config of the genesis block %s not found", genesisHash.Hex())
+	}
+	blockchain.InitDeriveSha(config)
+	return config, nil
+}
+
+// readEraFiles returns the era files of the network in the given directory.
+func readEraFiles(ctx *cli.Context, network string) ([]string, error) {
+	if ctx.Args().Len() != 1 {
+		return nil, errors.New("the directory of the era files is required")
+	}
+	files, err := era.ReadDir(ctx.Args().First(), network)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no era file of %s found in %s", network, ctx.Args().First())
+	}
+	return files, nil
+}
+
+// exportHistory exports the canonical blocks into era files.
+func exportHistory(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 && ctx.Args().Len() != 3 {
+		return errors.New("a directory and optionally the first and the last block numbers are required")
+	}
+	dbm, closeDB, err := openDB(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	config, err := readChainConfig(dbm)
+	if err != nil {
+		return err
+	}
+	first, last := uint64(0), uint64(0)
+	if number := dbm.ReadHeaderNumber(dbm.ReadHeadBlockHash()); number != nil {
+		last = *number
+	}
+	if ctx.Args().Len() == 3 {
+		if first, err = strconv.ParseUint(ctx.Args().Get(1), 10, 64); err != nil {
+			return fmt.Errorf("invalid first block number: %v", err)
+		}
+		if last, err = strconv.ParseUint(ctx.Args().Get(2), 10, 64); err != nil {
+			return fmt.Errorf("invalid last block number: %v", err)
+		}
+	}
+
+	start := time.Now()
+	files, err := era.Export(dbm, ctx.Args().First(), historyNetwork(ctx, config), first, last, ctx.Uint64(utils.HistoryStepFlag.Name))
+	if err != nil {
+		return err
+	}
+	logger.Info("Exported the block history", "files", len(files), "first", first, "last", last,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// importHistory imports the blocks of the era files without executing them.
+func importHistory(ctx *cli.Context) error {
+	dbm, closeDB, err := openDB(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	config, err := readChainConfig(dbm)
+	if err != nil {
+		return err
+	}
+	files, err := readEraFiles(ctx, historyNetwork(ctx, config))
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	if err := era.Import(dbm, files); err != nil {
+		return err
+	}
+	logger.Info("Imported the block history", "files", len(files), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// verifyHistory verifies the era files.
+func verifyHistory(ctx *cli.Context) error {
+	dbm, closeDB, err := openDB(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	config, err := readChainConfig(dbm)
+	if err != nil {
+		return err
+	}
+	files, err := readEraFiles(ctx, historyNetwork(ctx, config))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		e, err := era.Open(file)
+		if err != nil {
+			return err
+		}
+		root, err := era.Verify(e)
+		e.Close()
+		if err != nil {
+			return fmt.Errorf("failed to verify %s: %v", file, err)
+		}
+		logger.Info("Verified era file", "file", filepath.Base(file), "first", e.Start(), "count", e.Count(), "accumulator", root)
+	}
+	return nil
+}
@@ -22,14 +22,25 @@ package nodecmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/state/pruner"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/types/account"
 	"github.com/klaytn/klaytn/cmd/utils"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/rlp"
 	"github.com/klaytn/klaytn/snapshot"
 	"github.com/klaytn/klaytn/storage/database"
 	"github.com/klaytn/klaytn/storage/statedb"
@@ -66,6 +77,52 @@ trace all account and storage nodes to find missing data
 during the migration process.
 Start tracing from the state root of the last block,
 reading all nodes and logging the missing nodes.
+`,
+		},
+		{
+			Name:      "prune-state",
+			Usage:     "Prune stale state trie nodes based on the snapshot",
+			ArgsUsage: "<root>",
+			Action:    utils.MigrateFlags(pruneState),
+			Flags:     utils.PruneStateFlags,
+			Description: `
+Kaia snapshot prune-state <state-root>
+will prune historical state data with the help of the state snapshot.
+All trie nodes and contract codes that do not belong to the specified
+version state will be deleted from the database. After pruning, only
+two version states are available: genesis and the specific one.
+
+The default pruning target is the latest state whose trie is stored in
+the database among the snapshot layers, usually the state of the head
+block.
+
+The trie node cache saved in the data directory with
+--state.trie-cache-save-period is deleted after the pruning since it
+may contain the deleted trie nodes.
+
+The live pruning (--state.live-pruning) and the state migration should
+not be in progress. The command builds a bloom filter of the trie nodes
+of the target state by regenerating the trie from the snapshot, and
+writes it into the data directory as a checkpoint. If the pruning is
+interrupted, running the command again resumes it with the checkpoint.
+Use --dry-run to report the number and the size of the stale entries
+without deleting them.
+`,
+		},
+		{
+			Name:      "dump",
+			Usage:     "Dump the accounts of the state based on the snapshot",
+			ArgsUsage: "[<root>]",
+			Action:    utils.MigrateFlags(dumpState),
+			Flags:     utils.SnapshotDumpFlags,
+			Description: `
+Kaia snapshot dump [--start <hex>] [--limit <n>] [--storage] [--code] [<state-root>]
+prints the accounts of the state as JSON lines in ascending order of the
+account hashes, iterating the snapshot instead of the state trie. The first
+line holds the state root. If the state root isn't given, the state of the
+head block is dumped. The dump starts from the given address or account hash.
+The addresses and the storage keys are resolved from the stored preimages;
+if a preimage is not stored, the hash is printed instead.
 `,
 		},
 		{
@@ -285,6 +342,214 @@ func doTraceTrie(db state.Database, root common.Hash) (resultErr error) {
 	return nil
 }
 
+// pruneState deletes the state trie nodes and the contract codes which don't
+// belong to the target state and the genesis state.
+func pruneState(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		logger.Error("Too many arguments given")
+		return errors.New("too many arguments")
+	}
+	var (
+		root common.Hash
+		err  error
+	)
+	if ctx.NArg() == 1 {
+		root, err = parseRoot(ctx.Args().First())
+		if err != nil {
+			logger.Error("Failed to resolve state root", "err", err)
+			return err
+		}
+	}
+	dryRun := ctx.Bool(utils.PruneStateDryRunFlag.Name)
+	stack, dbm, closeDB, err := openNodeDB(ctx, dryRun)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	config := pruner.Config{
+		Datadir:   stack.ResolvePath(""),
+		BloomSize: ctx.Uint64(utils.PruneStateBloomSizeFlag.Name),
+		DryRun:    dryRun,
+	}
+	if config.BloomSize < 256 {
+		logger.Warn("Sanitizing bloomfilter size", "provided(MB)", config.BloomSize, "updated(MB)", 256)
+		config.BloomSize = 256
+	}
+	p, err := pruner.NewPruner(dbm, config)
+	if err != nil {
+		logger.Error("Failed to open snapshot tree", "err", err)
+		return err
+	}
+	if err := p.Prune(root); err != nil {
+		logger.Error("Failed to prune state", "err", err)
+		return err
+	}
+	if !dryRun {
+		// The saved trie node cache may contain the deleted trie nodes.
+		cacheDir := filepath.Join(ctx.String(utils.DataDirFlag.Name), "fastcache")
+		if _, err := os.Stat(cacheDir); err == nil {
+			logger.Info("Deleting the saved trie node cache", "dir", cacheDir)
+			if err := os.RemoveAll(cacheDir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// snapshotDumpConfig is the options of dumping the accounts from the snapshot.
+type snapshotDumpConfig struct {
+	Start   common.Hash // Account hash to start the dump from
+	Limit   uint64      // Maximum number of accounts to dump, 0 means unlimited
+	Storage bool        // Includes the storage slots of the contracts
+	Code    bool        // Includes the code of the contracts
+}
+
+// dumpState prints the accounts of the state as JSON lines based on the snapshot.
+func dumpState(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		logger.Error("Too many arguments given")
+		return errors.New("too many arguments")
+	}
+	conf := snapshotDumpConfig{
+		Limit:   ctx.Uint64(utils.SnapshotDumpLimitFlag.Name),
+		Storage: ctx.Bool(utils.SnapshotDumpStorageFlag.Name),
+		Code:    ctx.Bool(utils.SnapshotDumpCodeFlag.Name),
+	}
+	if ctx.IsSet(utils.SnapshotDumpStartFlag.Name) {
+		start, err := hexutil.Decode(ctx.String(utils.SnapshotDumpStartFlag.Name))
+		if err != nil {
+			return fmt.Errorf("invalid start key: %v", err)
+		}
+		switch len(start) {
+		case common.AddressLength:
+			conf.Start = crypto.Keccak256Hash(start)
+		case common.HashLength:
+			conf.Start = common.BytesToHash(start)
+		default:
+			return fmt.Errorf("the start key should be an address or a hash, but %d bytes are given", len(start))
+		}
+	}
+
+	dbm, closeDB, err := openDB(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	headBlock := dbm.ReadBlockByHash(dbm.ReadHeadBlockHash())
+	if headBlock == nil {
+		return errors.New("head block missing")
+	}
+	root := headBlock.Root()
+	if ctx.NArg() == 1 {
+		root, err = parseRoot(ctx.Args().First())
+		if err != nil {
+			logger.Error("Failed to resolve state root", "err", err)
+			return err
+		}
+	}
+	snaptree, err := snapshot.New(dbm, statedb.NewDatabase(dbm), 256, headBlock.Root(), false, false, false)
+	if err != nil {
+		logger.Error("Failed to open snapshot tree", "err", err)
+		return err
+	}
+	return dumpSnapshot(os.Stdout, dbm, snaptree, root, conf)
+}
+
+// dumpSnapshot writes the accounts of the state of the given root into w as
+// JSON lines. The first line holds the state root.
+func dumpSnapshot(w io.Writer, dbm database.DBManager, snaptree *snapshot.Tree, root common.Hash, conf snapshotDumpConfig) error {
+	accIt, err := snaptree.AccountIterator(root, conf.Start)
+	if err != nil {
+		return err
+	}
+	defer accIt.Release()
+
+	var (
+		enc          = json.NewEncoder(w)
+		start        = time.Now()
+		logged       = time.Now()
+		accounts     uint64
+		emptyCodeHex = common.Bytes2Hex(crypto.Keccak256(nil))
+	)
+	logger.Info("Snapshot dumping started", "root", root)
+	if err := enc.Encode(struct {
+		Root common.Hash `json:"root"`
+	}{root}); err != nil {
+		return err
+	}
+	for accIt.Next() {
+		if conf.Limit > 0 && accounts >= conf.Limit {
+			break
+		}
+		serializer := account.NewAccountSerializer()
+		if err := rlp.DecodeBytes(accIt.Account(), serializer); err != nil {
+			return fmt.Errorf("failed to decode account %x: %v", accIt.Hash(), err)
+		}
+		acc := serializer.GetAccount()
+		da := state.DumpAccount{
+			Balance:  acc.GetBalance().String(),
+			Nonce:    acc.GetNonce(),
+			Root:     common.Bytes2Hex(types.EmptyRootHashOriginal.Bytes()),
+			CodeHash: emptyCodeHex,
+		}
+		if preimage := dbm.ReadPreimage(accIt.Hash()); len(preimage) == common.AddressLength {
+			addr := common.BytesToAddress(preimage)
+			da.Address = &addr
+		} else {
+			da.SecureKey = common.Bytes2Hex(accIt.Hash().Bytes())
+		}
+		if pa := account.GetProgramAccount(acc); pa != nil {
+			da.Root = common.Bytes2Hex(pa.GetStorageRoot().Unextend().Bytes())
+			da.CodeHash = common.Bytes2Hex(pa.GetCodeHash())
+			if conf.Code && da.CodeHash != emptyCodeHex {
+				da.Code = common.Bytes2Hex(dbm.ReadCode(common.BytesToHash(pa.GetCodeHash())))
+			}
+			if conf.Storage {
+				if da.Storage, err = dumpStorage(dbm, snaptree, root, accIt.Hash()); err != nil {
+					return err
+				}
+			}
+		}
+		if err := enc.Encode(da); err != nil {
+			return err
+		}
+		accounts++
+		if time.Since(logged) > log.StatsReportLimit {
+			logger.Info("Snapshot dumping in progress", "at", accIt.Hash(), "accounts", accounts,
+				"elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := accIt.Error(); err != nil {
+		return err
+	}
+	logger.Info("Snapshot dumping complete", "accounts", accounts, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// dumpStorage returns the storage slots of the account from the snapshot.
+// The keys are resolved from the stored preimages if possible.
+func dumpStorage(dbm database.DBManager, snaptree *snapshot.Tree, root, accountHash common.Hash) (map[string]string, error) {
+	stIt, err := snaptree.StorageIterator(root, accountHash, common.Hash{})
+	if err != nil {
+		return nil, err
+	}
+	defer stIt.Release()
+
+	storage := make(map[string]string)
+	for stIt.Next() {
+		key := stIt.Hash().Bytes()
+		if preimage := dbm.ReadPreimage(stIt.Hash()); len(preimage) > 0 {
+			key = preimage
+		}
+		storage[common.Bytes2Hex(key)] = common.Bytes2Hex(stIt.Slot())
+	}
+	return storage, stIt.Error()
+}
+
 func iterateTrie(ctx *cli.Context) error {
 	stack := MakeFullNode(ctx)
 	dbm := stack.OpenDatabase(getConfig(ctx))
@@ -0,0 +1,137 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package nodecmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/snapshot"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readDump parses the JSON lines written by dumpSnapshot.
+func readDump(t *testing.T, buf *bytes.Buffer) (common.Hash, []state.DumpAccount) {
+	var (
+		scanner  = bufio.NewScanner(buf)
+		accounts []state.DumpAccount
+		header   struct {
+			Root common.Hash `json:"root"`
+		}
+	)
+	scanner.Buffer(nil, 1024*1024)
+	require.True(t, scanner.Scan())
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &header))
+	for scanner.Scan() {
+		var da state.DumpAccount
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &da))
+		accounts = append(accounts, da)
+	}
+	require.NoError(t, scanner.Err())
+	return header.Root, accounts
+}
+
+func TestDumpSnapshot(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.Address{0xc}
+		to       = common.Address{0x1}
+		signer   = types.LatestSignerForChainID(params.TestChainConfig.ChainID)
+		engine   = gxhash.NewFaker()
+		gspec    = &blockchain.Genesis{Config: params.TestChainConfig, Alloc: blockchain.GenesisAlloc{
+			addr:     {Balance: big.NewInt(params.KAIA)},
+			contract: {Code: []byte{0x1}, Storage: map[common.Hash]common.Hash{{0x1}: {0x2}}, Balance: common.Big0},
+		}}
+		gendb   = database.NewMemoryDBManager()
+		genesis = gspec.MustCommit(gendb)
+	)
+	blocks, _ := blockchain.GenerateChain(gspec.Config, genesis, engine, gendb, 2, func(i int, b *blockchain.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), to, big.NewInt(1), params.TxGas, big.NewInt(0), nil), signer, key)
+		require.NoError(t, err)
+		b.AddTx(tx)
+	})
+	db := database.NewMemoryDBManager()
+	gspec.MustCommit(db)
+	cacheConfig := &blockchain.CacheConfig{
+		ArchiveMode:         true,
+		CacheSize:           512,
+		BlockInterval:       blockchain.DefaultBlockInterval,
+		TriesInMemory:       blockchain.DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+		SnapshotCacheSize:   512,
+	}
+	chain, err := blockchain.NewBlockChain(db, cacheConfig, gspec.Config, engine, vm.Config{})
+	require.NoError(t, err)
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+	chain.Stop()
+
+	head := blocks[len(blocks)-1]
+	snaptree, err := snapshot.New(db, statedb.NewDatabase(db), 256, head.Root(), false, false, false)
+	require.NoError(t, err)
+
+	// All accounts including the rewardbase are dumped with the resolved addresses.
+	var buf bytes.Buffer
+	require.NoError(t, dumpSnapshot(&buf, db, snaptree, head.Root(), snapshotDumpConfig{Storage: true, Code: true}))
+	root, accounts := readDump(t, &buf)
+	assert.Equal(t, head.Root(), root)
+	require.Len(t, accounts, 4)
+	dumped := make(map[common.Address]state.DumpAccount)
+	for _, da := range accounts {
+		require.NotNil(t, da.Address)
+		dumped[*da.Address] = da
+	}
+	assert.Equal(t, "2", dumped[to].Balance)
+	assert.Equal(t, uint64(2), dumped[addr].Nonce)
+	assert.Equal(t, "01", dumped[contract].Code)
+	assert.Len(t, dumped[contract].Storage, 1)
+
+	// The storage and the code are excluded by default.
+	buf.Reset()
+	require.NoError(t, dumpSnapshot(&buf, db, snaptree, genesis.Root(), snapshotDumpConfig{}))
+	_, accounts = readDump(t, &buf)
+	require.Len(t, accounts, 2)
+	for _, da := range accounts {
+		assert.Empty(t, da.Code)
+		assert.Empty(t, da.Storage)
+	}
+
+	// The dump starts from the given account hash and stops at the limit.
+	start := crypto.Keccak256Hash(to.Bytes())
+	buf.Reset()
+	require.NoError(t, dumpSnapshot(&buf, db, snaptree, head.Root(), snapshotDumpConfig{Start: start, Limit: 1}))
+	_, accounts = readDump(t, &buf)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, to, *accounts[0].Address)
+
+	assert.Error(t, dumpSnapshot(&buf, db, snaptree, common.Hash{0x1}, snapshotDumpConfig{}))
+}
@@ -216,6 +216,10 @@ func decodeExtra(headerFile string) (map[string]interface{}, error) {
 	m["committers"] = committers
 	m["validatorSize"] = len(validators)
 	m["committedSealSize"] = len(cSeals)
+	if len(istanbulExtra.AggregatedSeal) != 0 {
+		m["aggregatedSeal"] = hexutil.Encode(istanbulExtra.AggregatedSeal)
+		m["committerBitmap"] = hexutil.Encode(istanbulExtra.CommitterBitmap)
+	}
 	m["proposer"] = proposer.String()
 	m["round"] = header.Round()
 	return m, nil
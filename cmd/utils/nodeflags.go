@@ -263,6 +263,7 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewStringFlag(DaemonPathFlag),
 	altsrc.NewStringFlag(ConfigFileFlag),
 	altsrc.NewIntFlag(APIFilterGetLogsMaxItemsFlag),
+	altsrc.NewIntFlag(APIFilterGetLogsPageSizeFlag),
 	altsrc.NewDurationFlag(APIFilterGetLogsDeadlineFlag),
 	altsrc.NewUint64Flag(OpcodeComputationCostLimitFlag),
 	altsrc.NewBoolFlag(SnapshotFlag),
@@ -292,6 +293,11 @@ var CommonRPCFlags = []cli.Flag{
 	altsrc.NewStringFlag(GRPCListenAddrFlag),
 	altsrc.NewIntFlag(GRPCPortFlag),
 	altsrc.NewIntFlag(RPCConcurrencyLimit),
+	altsrc.NewIntFlag(RPCBatchRequestLimitFlag),
+	altsrc.NewIntFlag(RPCBatchResponseMaxSizeFlag),
+	altsrc.NewFloat64Flag(RPCRateLimitFlag),
+	altsrc.NewIntFlag(RPCRateLimitBurstFlag),
+	altsrc.NewStringFlag(RPCRateLimitWeightsFlag),
 	altsrc.NewStringFlag(WSApiFlag),
 	altsrc.NewStringFlag(WSAllowedOriginsFlag),
 	altsrc.NewIntFlag(WSMaxSubscriptionPerConn),
@@ -308,6 +314,9 @@ var CommonRPCFlags = []cli.Flag{
 	altsrc.NewIntFlag(HeavyDebugRequestLimitFlag),
 	altsrc.NewDurationFlag(StateRegenerationTimeLimitFlag),
 	altsrc.NewStringFlag(RPCUpstreamArchiveENFlag),
+	altsrc.NewStringFlag(RPCUpstreamRoutesFlag),
+	altsrc.NewStringFlag(RPCJWTSecretFlag),
+	altsrc.NewStringFlag(RPCJWTPolicyFlag),
 }
 
 var BNFlags = []cli.Flag{
@@ -507,6 +516,35 @@ var SnapshotFlags = []cli.Flag{
 	altsrc.NewBoolFlag(RocksDBCacheIndexAndFilterFlag),
 }
 
+var DBInspectFlags = append([]cli.Flag{DBJSONOutputFlag}, SnapshotFlags...)
+
+var DBWriteFlags = append([]cli.Flag{DBWriteFlag}, SnapshotFlags...)
+
+var DBDumpRangeFlags = append([]cli.Flag{DBJSONOutputFlag, DBPrefixFlag, DBStartFlag, DBLimitFlag}, SnapshotFlags...)
+
+var HistoryFlags = append([]cli.Flag{HistoryNetworkFlag}, SnapshotFlags...)
+
+var HistoryExportFlags = append([]cli.Flag{HistoryNetworkFlag, HistoryStepFlag}, SnapshotFlags...)
+
+var PruneStateFlags = append([]cli.Flag{PruneStateBloomSizeFlag, PruneStateDryRunFlag}, SnapshotFlags...)
+
+var SnapshotDumpFlags = append([]cli.Flag{
+	SnapshotDumpStartFlag,
+	SnapshotDumpLimitFlag,
+	SnapshotDumpStorageFlag,
+	SnapshotDumpCodeFlag,
+}, SnapshotFlags...)
+
+var ChainImportExportFlags = append([]cli.Flag{
+	altsrc.NewBoolFlag(CypressFlag),
+	altsrc.NewBoolFlag(BaobabFlag),
+	altsrc.NewUint64Flag(NetworkIdFlag),
+	altsrc.NewStringFlag(GCModeFlag),
+	altsrc.NewIntFlag(LevelDBCacheSizeFlag),
+	altsrc.NewIntFlag(TrieMemoryCacheSizeFlag),
+	altsrc.NewUintFlag(TrieBlockIntervalFlag),
+}, SnapshotFlags...)
+
 var DBMigrationSrcFlags = []cli.Flag{
 	altsrc.NewStringFlag(DbTypeFlag),
 	altsrc.NewPathFlag(DataDirFlag),
@@ -49,9 +49,10 @@ type Backend interface {
 
 	GossipSubPeer(prevHash common.Hash, valSet ValidatorSet, payload []byte) map[common.Address]bool
 
-	// Commit delivers an approved proposal to backend.
+	// Commit delivers an approved proposal to backend with the committed seals
+	// and the validators who signed them.
 	// The delivered proposal will be put into blockchain.
-	Commit(proposal Proposal, seals [][]byte) error
+	Commit(proposal Proposal, committers []common.Address, seals [][]byte) error
 
 	// Verify verifies the proposal. If a consensus.ErrFutureBlock error is returned,
 	// the time difference of the proposal and current time is also returned.
@@ -64,6 +65,14 @@ type Backend interface {
 	// the given validator
 	CheckSignature(data []byte, addr common.Address, sig []byte) error
 
+	// SignCommittedSeal signs the committed seal of the proposal. It is a BLS
+	// signature after the BlsSeal fork, and an ECDSA signature before.
+	SignCommittedSeal(proposal Proposal) ([]byte, error)
+
+	// VerifyCommittedSeal verifies the committed seal of the proposal by
+	// checking if it's signed by the given validator
+	VerifyCommittedSeal(proposal Proposal, addr common.Address, seal []byte) error
+
 	// LastProposal retrieves latest committed proposal and the address of proposer
 	LastProposal() (Proposal, common.Address)
 
@@ -83,5 +92,14 @@ type Backend interface {
 
 	SetCurrentView(view *View)
 
+	// RecordMisbehaviour stores the evidence of conflicting messages signed by a validator
+	RecordMisbehaviour(m *Misbehaviour)
+
+	// WriteRoundState persists the round state of the core
+	WriteRoundState(blob []byte)
+
+	// ReadRoundState returns the persisted round state of the core
+	ReadRoundState() []byte
+
 	NodeType() common.ConnType
 }
@@ -21,6 +21,7 @@
 package backend
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -195,6 +196,61 @@ func (api *API) Discard(address common.Address) {
 	delete(api.istanbul.candidates, address)
 }
 
+// GetMisbehaviours returns the evidences of conflicting consensus messages signed
+// by validators, detected in the given block range. If toBlock is not given or
+// latest, the evidences up to the current consensus round are returned.
+func (api *API) GetMisbehaviours(fromBlock, toBlock *rpc.BlockNumber) ([]*istanbul.Misbehaviour, error) {
+	if (fromBlock != nil && *fromBlock == rpc.PendingBlockNumber) || (toBlock != nil && *toBlock == rpc.PendingBlockNumber) {
+		return nil, errPendingNotAllowed
+	}
+
+	start, end := uint64(0), uint64(0)
+	if fromBlock != nil {
+		if *fromBlock == rpc.LatestBlockNumber {
+			start = api.chain.CurrentHeader().Number.Uint64()
+		} else {
+			start = uint64(fromBlock.Int64())
+		}
+	}
+	if toBlock != nil && *toBlock != rpc.LatestBlockNumber {
+		end = uint64(toBlock.Int64()) + 1
+		if start >= end {
+			return nil, errStartLargerThanEnd
+		}
+	}
+	return api.istanbul.misbehaviours(start, end), nil
+}
+
+// Misbehaviours creates a subscription that is notified of the evidences of
+// conflicting consensus messages when they are detected.
+func (api *API) Misbehaviours(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		misbehaviours := make(chan istanbul.Misbehaviour, misbehaviourChanSize)
+		sub := api.istanbul.SubscribeMisbehaviour(misbehaviours)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case m := <-misbehaviours:
+				notifier.Notify(rpcSub.ID, m)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // API extended by Kaia developers
 type APIExtension struct {
 	chain    consensus.ChainReader
@@ -341,6 +397,10 @@ func (api *APIExtension) makeRPCBlockOutput(b *types.Block,
 }
 
 func RecoverCommittedSeals(extra *types.IstanbulExtra, headerHash common.Hash) ([]common.Address, error) {
+	// The committers of the aggregated seal are in the bitmap
+	if len(extra.AggregatedSeal) != 0 {
+		return extra.Committers()
+	}
 	committers := make([]common.Address, len(extra.CommittedSeal))
 	for idx, cs := range extra.CommittedSeal {
 		committer, err := istanbul.GetSignatureAddress(istanbulCore.PrepareCommittedSeal(headerHash), cs)
@@ -5,8 +5,10 @@ import (
 
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/consensus/istanbul"
 	istanbulCore "github.com/klaytn/klaytn/consensus/istanbul/core"
+	"github.com/klaytn/klaytn/networks/rpc"
 	"github.com/klaytn/klaytn/rlp"
 	"github.com/stretchr/testify/assert"
 )
@@ -48,3 +50,72 @@ func TestRecoverCommittedSeals(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, validators, expectedValidators)
 }
+
+func TestAPI_GetMisbehaviours(t *testing.T) {
+	ctx := newTestContext(1, nil, nil)
+	defer ctx.Cleanup()
+
+	api := &API{chain: ctx.chain, istanbul: ctx.engine}
+
+	misbehaviours := make(chan istanbul.Misbehaviour, 3)
+	sub := ctx.engine.SubscribeMisbehaviour(misbehaviours)
+	defer sub.Unsubscribe()
+
+	var recorded []*istanbul.Misbehaviour
+	for _, seq := range []uint64{1, 3, 5} {
+		m := &istanbul.Misbehaviour{
+			Type:      istanbul.DoubleCommit,
+			Validator: ctx.nodeAddrs[0],
+			Sequence:  seq,
+			Messages:  [2]hexutil.Bytes{{0x1, byte(seq)}, {0x2, byte(seq)}},
+			Time:      1234,
+		}
+		ctx.engine.RecordMisbehaviour(m)
+		assert.Equal(t, *m, <-misbehaviours)
+		recorded = append(recorded, m)
+	}
+
+	num := func(n int64) *rpc.BlockNumber {
+		bn := rpc.BlockNumber(n)
+		return &bn
+	}
+	testcases := []struct {
+		from, to *rpc.BlockNumber
+		expected []*istanbul.Misbehaviour
+		err      error
+	}{
+		{nil, nil, recorded, nil},
+		{num(3), nil, recorded[1:], nil},
+		{num(0), num(3), recorded[:2], nil},
+		{num(2), num(4), recorded[1:2], nil},
+		{num(6), num(rpc.LatestBlockNumber.Int64()), []*istanbul.Misbehaviour{}, nil},
+		{num(4), num(3), nil, errStartLargerThanEnd},
+		{nil, num(rpc.PendingBlockNumber.Int64()), nil, errPendingNotAllowed},
+	}
+	for i, tc := range testcases {
+		got, err := api.GetMisbehaviours(tc.from, tc.to)
+		assert.Equal(t, tc.err, err, i)
+		assert.Equal(t, tc.expected, got, i)
+	}
+}
+
+func TestRecordMisbehaviour_SlowSubscriber(t *testing.T) {
+	ctx := newTestContext(1, nil, nil)
+	defer ctx.Cleanup()
+
+	// The subscriber doesn't receive the evidences until all are recorded
+	misbehaviours := make(chan istanbul.Misbehaviour)
+	sub := ctx.engine.SubscribeMisbehaviour(misbehaviours)
+	defer sub.Unsubscribe()
+
+	for seq := uint64(0); seq < misbehaviourQueueSize+2; seq++ {
+		ctx.engine.RecordMisbehaviour(&istanbul.Misbehaviour{
+			Type:      istanbul.DoubleCommit,
+			Validator: ctx.nodeAddrs[0],
+			Sequence:  seq,
+			Messages:  [2]hexutil.Bytes{{0x1}, {0x2}},
+		})
+	}
+	assert.Len(t, ctx.engine.misbehaviours(0, 0), misbehaviourQueueSize+2)
+	assert.Equal(t, uint64(0), (<-misbehaviours).Sequence)
+}
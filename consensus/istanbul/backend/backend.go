@@ -60,6 +60,7 @@ type BackendOpts struct {
 	Governance        governance.Engine // Governance parameter provider
 	BlsPubkeyProvider BlsPubkeyProvider // If not nil, override the default BLS public key provider
 	NodeType          common.ConnType
+	Clock             func() time.Time // If not nil, override the local clock, e.g. to simulate a clock skew
 }
 
 func New(opts *BackendOpts) consensus.Istanbul {
@@ -85,6 +86,8 @@ func New(opts *BackendOpts) consensus.Istanbul {
 		blsPubkeyProvider: opts.BlsPubkeyProvider,
 		nodetype:          opts.NodeType,
 		rewardDistributor: reward.NewRewardDistributor(opts.Governance),
+		clock:             opts.Clock,
+		misbehaviourCh:    make(chan istanbul.Misbehaviour, misbehaviourQueueSize),
 	}
 	if backend.blsPubkeyProvider == nil {
 		backend.blsPubkeyProvider = newChainBlsPubkeyProvider()
@@ -145,6 +148,15 @@ type backend struct {
 	nodetype common.ConnType
 
 	isRestoringSnapshots atomic.Bool
+
+	// the feed of the detected conflicting consensus messages, and the queue
+	// delivering them to the feed without blocking the core
+	misbehaviourFeed event.Feed
+	misbehaviourCh   chan istanbul.Misbehaviour
+	misbehaviourQuit chan struct{}
+
+	// the local clock overriding the system clock, nil if not overridden
+	clock func() time.Time
 }
 
 func (sb *backend) NodeType() common.ConnType {
@@ -159,6 +171,14 @@ func (sb *backend) SetCurrentView(view *istanbul.View) {
 	sb.currentView.Store(view)
 }
 
+// currentTime returns the current time of the local clock.
+func (sb *backend) currentTime() time.Time {
+	if sb.clock != nil {
+		return sb.clock()
+	}
+	return now()
+}
+
 // Address implements istanbul.Backend.Address
 func (sb *backend) Address() common.Address {
 	return sb.address
@@ -292,7 +312,7 @@ func (sb *backend) GossipSubPeer(prevHash common.Hash, valSet istanbul.Validator
 }
 
 // Commit implements istanbul.Backend.Commit
-func (sb *backend) Commit(proposal istanbul.Proposal, seals [][]byte) error {
+func (sb *backend) Commit(proposal istanbul.Proposal, committers []common.Address, seals [][]byte) error {
 	// Check if the proposal is a valid block
 	block, ok := proposal.(*types.Block)
 	if !ok {
@@ -303,7 +323,12 @@ func (sb *backend) Commit(proposal istanbul.Proposal, seals [][]byte) error {
 	round := sb.currentView.Load().(*istanbul.View).Round.Int64()
 	h = types.SetRoundToHeader(h, round)
 	// Append seals into extra-data
-	err := writeCommittedSeals(h, seals)
+	var err error
+	if sb.blsSealEnabled(h.Number) {
+		err = writeAggregatedSeal(h, committers, seals)
+	} else {
+		err = writeCommittedSeals(h, seals)
+	}
 	if err != nil {
 		return err
 	}
@@ -360,7 +385,7 @@ func (sb *backend) Verify(proposal istanbul.Proposal) (time.Duration, error) {
 	if err == nil || err == errEmptyCommittedSeals {
 		return 0, nil
 	} else if err == consensus.ErrFutureBlock {
-		return time.Unix(block.Header().Time.Int64(), 0).Sub(now()), consensus.ErrFutureBlock
+		return time.Unix(block.Header().Time.Int64(), 0).Sub(sb.currentTime()), consensus.ErrFutureBlock
 	}
 	return 0, err
 }
@@ -385,6 +410,27 @@ func (sb *backend) CheckSignature(data []byte, address common.Address, sig []byt
 	return nil
 }
 
+// SignCommittedSeal implements istanbul.Backend.SignCommittedSeal
+func (sb *backend) SignCommittedSeal(proposal istanbul.Proposal) ([]byte, error) {
+	if sb.blsSealEnabled(proposal.Number()) {
+		return sb.signBlsSeal(proposal.Hash())
+	}
+	return sb.Sign(istanbulCore.PrepareCommittedSeal(proposal.Hash()))
+}
+
+// VerifyCommittedSeal implements istanbul.Backend.VerifyCommittedSeal
+func (sb *backend) VerifyCommittedSeal(proposal istanbul.Proposal, addr common.Address, seal []byte) error {
+	block, ok := proposal.(*types.Block)
+	if !ok {
+		sb.logger.Error("Invalid proposal, %v", proposal)
+		return errInvalidProposal
+	}
+	if sb.blsSealEnabled(block.Number()) {
+		return sb.verifyBlsSeal(sb.chain, block.Header(), addr, seal)
+	}
+	return sb.CheckSignature(istanbulCore.PrepareCommittedSeal(block.Hash()), addr, seal)
+}
+
 // HasPropsal implements istanbul.Backend.HashBlock
 func (sb *backend) HasPropsal(hash common.Hash, number *big.Int) bool {
 	return sb.chain.GetHeader(hash, number.Uint64()) != nil
@@ -448,3 +494,17 @@ func (sb *backend) HasBadProposal(hash common.Hash) bool {
 	}
 	return sb.hasBadBlock(hash)
 }
+
+// WriteRoundState implements istanbul.Backend.WriteRoundState
+func (sb *backend) WriteRoundState(blob []byte) {
+	sb.db.WriteIstanbulRoundState(blob)
+}
+
+// ReadRoundState implements istanbul.Backend.ReadRoundState
+func (sb *backend) ReadRoundState() []byte {
+	blob, err := sb.db.ReadIstanbulRoundState()
+	if err != nil {
+		return nil
+	}
+	return blob
+}
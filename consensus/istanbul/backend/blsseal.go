@@ -0,0 +1,160 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	istanbulCore "github.com/klaytn/klaytn/consensus/istanbul/core"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/crypto/bls"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+var (
+	// errInvalidAggregatedSeal is returned if the aggregated seal is not signed by the committers in the bitmap.
+	errInvalidAggregatedSeal = errors.New("invalid aggregated seal")
+	// errUnexpectedAggregatedSeal is returned if the aggregated seal fields are present before the BlsSeal fork.
+	errUnexpectedAggregatedSeal = errors.New("unexpected aggregated seal")
+)
+
+// After the BlsSeal fork, validators sign the committed seals with their BLS keys
+// instead of the ECDSA keys. The proposer aggregates the committed seals into
+// a single BLS signature, and records who signed it in the committer bitmap
+// over the validators of the header.
+
+// blsSealEnabled returns whether the committed seals of the block are BLS signatures.
+func (sb *backend) blsSealEnabled(number *big.Int) bool {
+	return sb.chain != nil && sb.chain.Config().IsBlsSealForkEnabled(number)
+}
+
+// calcBlsSealMsg returns the message signed by the BLS committed seal of the proposal.
+func calcBlsSealMsg(hash common.Hash) common.Hash {
+	return crypto.Keccak256Hash(istanbulCore.PrepareCommittedSeal(hash))
+}
+
+// signBlsSeal signs the BLS committed seal of the proposal.
+func (sb *backend) signBlsSeal(hash common.Hash) ([]byte, error) {
+	if sb.blsSecretKey == nil {
+		return nil, errNoBlsKey
+	}
+	msg := calcBlsSealMsg(hash)
+	return bls.Sign(sb.blsSecretKey, msg[:]).Marshal(), nil
+}
+
+// verifyBlsSeal checks whether the BLS committed seal of the proposal is signed by the validator.
+func (sb *backend) verifyBlsSeal(chain consensus.ChainReader, header *types.Header, addr common.Address, seal []byte) error {
+	pub, err := sb.blsPubkeyProvider.GetBlsPubkey(chain, addr, header.Number)
+	if err != nil {
+		return err
+	}
+	ok, err := bls.VerifySignature(seal, calcBlsSealMsg(header.Hash()), pub)
+	if err != nil {
+		return err
+	} else if !ok {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// writeAggregatedSeal writes the extra-data field of the given header with the
+// aggregation of the BLS committed seals and the bitmap of the committers.
+func writeAggregatedSeal(h *types.Header, committers []common.Address, seals [][]byte) error {
+	if len(seals) == 0 || len(seals) != len(committers) {
+		return errInvalidCommittedSeals
+	}
+
+	istanbulExtra, err := types.ExtractIstanbulExtra(h)
+	if err != nil {
+		return err
+	}
+
+	bitmap, err := types.NewCommitterBitmap(istanbulExtra.Validators, committers)
+	if err != nil {
+		return err
+	}
+	aggregated, err := bls.AggregateCompressedSignatures(seals)
+	if err != nil {
+		return errInvalidCommittedSeals
+	}
+	istanbulExtra.CommittedSeal = [][]byte{}
+	istanbulExtra.AggregatedSeal = aggregated.Marshal()
+	istanbulExtra.CommitterBitmap = bitmap
+
+	payload, err := rlp.EncodeToBytes(&istanbulExtra)
+	if err != nil {
+		return err
+	}
+
+	h.Extra = append(h.Extra[:types.IstanbulExtraVanity], payload...)
+	return nil
+}
+
+// verifyAggregatedSeal checks whether the aggregated seal is signed by the
+// committers in the bitmap, and the committers are a quorum of the parent's validators.
+func (sb *backend) verifyAggregatedSeal(chain consensus.ChainReader, header *types.Header, snap *Snapshot, extra *types.IstanbulExtra) error {
+	if len(extra.CommittedSeal) != 0 {
+		return errInvalidCommittedSeals
+	}
+	if len(extra.AggregatedSeal) == 0 {
+		return errEmptyCommittedSeals
+	}
+
+	committers, err := extra.Committers()
+	if err != nil {
+		return err
+	}
+
+	// Every committer should be one of the parent's validators
+	validators := snap.ValSet.Copy()
+	pubs := make([]bls.PublicKey, 0, len(committers))
+	for _, addr := range committers {
+		if !validators.RemoveValidator(addr) {
+			return errInvalidCommittedSeals
+		}
+		pub, err := sb.blsPubkeyProvider.GetBlsPubkey(chain, addr, header.Number)
+		if err != nil {
+			return err
+		}
+		pubs = append(pubs, pub)
+	}
+
+	// The number of committers should be larger than number of faulty node + 1
+	if len(committers) <= 2*snap.ValSet.F() {
+		return errInvalidCommittedSeals
+	}
+
+	return VerifyAggregatedSeal(header.Hash(), extra.AggregatedSeal, pubs)
+}
+
+// VerifyAggregatedSeal checks whether the aggregated seal of the block is signed
+// by all the owners of the given BLS public keys.
+func VerifyAggregatedSeal(hash common.Hash, seal []byte, pubs []bls.PublicKey) error {
+	aggregatedPub, err := bls.AggregateMultiplePubkeys(pubs)
+	if err != nil {
+		return err
+	}
+	ok, err := bls.VerifySignature(seal, calcBlsSealMsg(hash), aggregatedPub)
+	if err != nil || !ok {
+		return errInvalidAggregatedSeal
+	}
+	return nil
+}
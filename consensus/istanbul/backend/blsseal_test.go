@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto/bls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Block with proposer seal and the aggregation of the BLS committed seals of the given nodes.
+func (ctx *testContext) MakeBlockWithAggregatedSeal(parent *types.Block, signers []int) *types.Block {
+	block, err := ctx.engine.updateBlock(ctx.MakeBlock(parent))
+	if err != nil {
+		panic(err)
+	}
+
+	header := block.Header()
+	committers, seals := ctx.MakeBlsSeals(block.Hash(), signers)
+	if err := writeAggregatedSeal(header, committers, seals); err != nil {
+		panic(err)
+	}
+	return block.WithSeal(header)
+}
+
+func (ctx *testContext) MakeBlsSeals(hash common.Hash, signers []int) ([]common.Address, [][]byte) {
+	committers := make([]common.Address, len(signers))
+	seals := make([][]byte, len(signers))
+	msg := calcBlsSealMsg(hash)
+	for i, idx := range signers {
+		committers[i] = ctx.nodeAddrs[idx]
+		seals[i] = bls.Sign(ctx.nodeBlsKeys[idx], msg[:]).Marshal()
+	}
+	return committers, seals
+}
+
+func TestBlsSeal_SignAndVerify(t *testing.T) {
+	ctx := newTestContext(4, testBlsSealConfig.Copy(), nil)
+	chain, engine := ctx.chain, ctx.engine
+	defer ctx.Cleanup()
+
+	block := ctx.MakeBlock(chain.Genesis())
+	seal, err := engine.SignCommittedSeal(block)
+	require.NoError(t, err)
+	assert.Len(t, seal, 96)
+
+	assert.NoError(t, engine.VerifyCommittedSeal(block, ctx.nodeAddrs[0], seal))
+	assert.Equal(t, errInvalidSignature, engine.VerifyCommittedSeal(block, ctx.nodeAddrs[1], seal))
+
+	// The ECDSA committed seal is not accepted after the fork
+	ecdsaSeal := ctx.MakeCommittedSeals(block.Hash())[0]
+	assert.Error(t, engine.VerifyCommittedSeal(block, ctx.nodeAddrs[0], ecdsaSeal))
+}
+
+func TestBlsSeal_VerifyHeader(t *testing.T) {
+	ctx := newTestContext(4, testBlsSealConfig.Copy(), nil)
+	chain, engine := ctx.chain, ctx.engine
+	defer ctx.Cleanup()
+
+	// A quorum of the validators
+	block := ctx.MakeBlockWithAggregatedSeal(chain.Genesis(), []int{0, 1, 3})
+	assert.NoError(t, engine.VerifyHeader(chain, block.Header(), false))
+
+	extra, err := types.ExtractIstanbulExtra(block.Header())
+	require.NoError(t, err)
+	assert.Empty(t, extra.CommittedSeal)
+	assert.Len(t, extra.CommitterBitmap, 1)
+
+	// The committers are recovered from the bitmap
+	committers, err := RecoverCommittedSeals(extra, block.Hash())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []common.Address{ctx.nodeAddrs[0], ctx.nodeAddrs[1], ctx.nodeAddrs[3]}, committers)
+
+	// Less than a quorum of the validators
+	block = ctx.MakeBlockWithAggregatedSeal(chain.Genesis(), []int{0, 1})
+	assert.Equal(t, errInvalidCommittedSeals, engine.VerifyHeader(chain, block.Header(), false))
+
+	// The bitmap doesn't match the signers of the aggregated seal
+	block = ctx.MakeBlockWithAggregatedSeal(chain.Genesis(), []int{0, 1, 2})
+	header := block.Header()
+	_, seals := ctx.MakeBlsSeals(block.Hash(), []int{0, 1, 3})
+	require.NoError(t, writeAggregatedSeal(header, ctx.nodeAddrs[:3], seals))
+	assert.Equal(t, errInvalidAggregatedSeal, engine.VerifyHeader(chain, header, false))
+
+	// The ECDSA committed seals are not accepted after the fork
+	block = ctx.MakeBlockWithCommittedSeals(chain.Genesis())
+	assert.Equal(t, errInvalidCommittedSeals, engine.VerifyHeader(chain, block.Header(), false))
+}
+
+func TestBlsSeal_BeforeFork(t *testing.T) {
+	ctx := newTestContext(4, testRandaoConfig.Copy(), nil)
+	chain, engine := ctx.chain, ctx.engine
+	defer ctx.Cleanup()
+
+	block := ctx.MakeBlock(chain.Genesis())
+	seal, err := engine.SignCommittedSeal(block)
+	require.NoError(t, err)
+	assert.Len(t, seal, types.IstanbulExtraSeal)
+	assert.NoError(t, engine.VerifyCommittedSeal(block, ctx.nodeAddrs[0], seal))
+
+	block = ctx.MakeBlockWithAggregatedSeal(chain.Genesis(), []int{0, 1, 2, 3})
+	assert.Equal(t, errUnexpectedAggregatedSeal, engine.VerifyHeader(chain, block.Header(), false))
+}
@@ -24,8 +24,10 @@ required objects to get information for making a consensus, recent messages and
 Implementation of Backend interface and APIs are included in this package
   - `api.go`: Implements APIs which provide the states of Istanbul
   - `backend.go`: Defines backend struct which implements Backend interface working as a backbone of the consensus engine
+  - `blsseal.go`: Implements signing, aggregating and verifying the BLS committed seals after the BlsSeal fork
   - `engine.go`: Implements various backend methods especially for verifying and building header information
   - `handler.go`: Implements backend methods for handling messages and broadcaster
+  - `misbehaviour.go`: Implements backend methods storing and notifying the evidences of conflicting consensus messages
   - `snapshot.go`: Defines snapshot struct which handles votes from nodes and makes governance changes
 */
 package backend
@@ -219,7 +219,7 @@ func (sb *backend) verifyHeader(chain consensus.ChainReader, header *types.Heade
 	}
 
 	// Don't waste time checking blocks from the future
-	if header.Time.Cmp(big.NewInt(now().Add(allowedFutureBlockTime).Unix())) > 0 {
+	if header.Time.Cmp(big.NewInt(sb.currentTime().Add(allowedFutureBlockTime).Unix())) > 0 {
 		return consensus.ErrFutureBlock
 	}
 
@@ -362,6 +362,12 @@ func (sb *backend) verifyCommittedSeals(chain consensus.ChainReader, header *typ
 	if err != nil {
 		return err
 	}
+	// After the BlsSeal fork, the committed seals are aggregated into a BLS signature
+	if chain.Config().IsBlsSealForkEnabled(header.Number) {
+		return sb.verifyAggregatedSeal(chain, header, snap, extra)
+	} else if len(extra.AggregatedSeal) != 0 || len(extra.CommitterBitmap) != 0 {
+		return errUnexpectedAggregatedSeal
+	}
 	// The length of Committed seals should be larger than 0
 	if len(extra.CommittedSeal) == 0 {
 		return errEmptyCommittedSeals
@@ -477,8 +483,7 @@ func (sb *backend) Prepare(chain consensus.ChainReader, header *types.Header) er
 	// set header's timestamp
 	header.Time = new(big.Int).Add(parent.Time, new(big.Int).SetUint64(sb.config.BlockPeriod))
 	header.TimeFoS = parent.TimeFoS
-	if header.Time.Int64() < time.Now().Unix() {
-		t := time.Now()
+	if t := sb.currentTime(); header.Time.Int64() < t.Unix() {
 		header.Time = big.NewInt(t.Unix())
 		header.TimeFoS = uint8((t.UnixNano() / 1000 / 1000 / 10) % 100)
 	}
@@ -607,7 +612,7 @@ func (sb *backend) Seal(chain consensus.ChainReader, block *types.Block, stop <-
 	}
 
 	// wait for the timestamp of header, use this to adjust the block period
-	delay := time.Unix(block.Header().Time.Int64(), 0).Sub(now())
+	delay := time.Unix(block.Header().Time.Int64(), 0).Sub(sb.currentTime())
 	select {
 	case <-time.After(delay):
 	case <-stop:
@@ -711,6 +716,8 @@ func (sb *backend) Start(chain consensus.ChainReader, currentBlock func() *types
 	if err := sb.core.Start(); err != nil {
 		return err
 	}
+	sb.misbehaviourQuit = make(chan struct{})
+	go sb.sendMisbehaviours(sb.misbehaviourQuit)
 
 	sb.coreStarted = true
 	return nil
@@ -726,6 +733,7 @@ func (sb *backend) Stop() error {
 	if err := sb.core.Stop(); err != nil {
 		return err
 	}
+	close(sb.misbehaviourQuit)
 	sb.coreStarted = false
 	return nil
 }
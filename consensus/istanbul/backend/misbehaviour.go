@@ -0,0 +1,82 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+const (
+	// misbehaviourChanSize is the size of the channel listening to the evidences.
+	misbehaviourChanSize = 16
+	// misbehaviourQueueSize is the number of the evidences queued for the feed.
+	misbehaviourQueueSize = 256
+)
+
+// RecordMisbehaviour implements istanbul.Backend.RecordMisbehaviour. The
+// evidence is queued for the subscribers without blocking the core, so it's
+// not sent if the queue is full.
+func (sb *backend) RecordMisbehaviour(m *istanbul.Misbehaviour) {
+	blob, err := rlp.EncodeToBytes(m)
+	if err != nil {
+		sb.logger.Error("Failed to encode misbehaviour", "hash", m.Hash(), "err", err)
+		return
+	}
+	sb.db.WriteIstanbulMisbehaviour(m.Sequence, m.Hash(), blob)
+
+	select {
+	case sb.misbehaviourCh <- *m:
+	default:
+		sb.logger.Warn("Dropped misbehaviour notification", "hash", m.Hash())
+	}
+}
+
+// sendMisbehaviours sends the queued evidences to the subscribers until quit is closed.
+func (sb *backend) sendMisbehaviours(quit chan struct{}) {
+	for {
+		select {
+		case m := <-sb.misbehaviourCh:
+			sb.misbehaviourFeed.Send(m)
+		case <-quit:
+			return
+		}
+	}
+}
+
+// SubscribeMisbehaviour registers a subscription of the evidences of
+// conflicting consensus messages detected by the core.
+func (sb *backend) SubscribeMisbehaviour(ch chan<- istanbul.Misbehaviour) event.Subscription {
+	return sb.misbehaviourFeed.Subscribe(ch)
+}
+
+// misbehaviours returns the stored evidences in the sequence range
+// [startSequence, endSequence). If endSequence is 0, there is no upper bound.
+func (sb *backend) misbehaviours(startSequence, endSequence uint64) []*istanbul.Misbehaviour {
+	blobs := sb.db.ReadIstanbulMisbehaviours(startSequence, endSequence)
+	misbehaviours := make([]*istanbul.Misbehaviour, 0, len(blobs))
+	for _, blob := range blobs {
+		m := new(istanbul.Misbehaviour)
+		if err := rlp.DecodeBytes(blob, m); err != nil {
+			sb.logger.Error("Corrupt misbehaviour", "err", err)
+			continue
+		}
+		misbehaviours = append(misbehaviours, m)
+	}
+	return misbehaviours
+}
@@ -42,9 +42,10 @@ import (
 )
 
 var (
-	testBaseConfig   *params.ChainConfig
-	testKoreConfig   *params.ChainConfig
-	testRandaoConfig *params.ChainConfig
+	testBaseConfig    *params.ChainConfig
+	testKoreConfig    *params.ChainConfig
+	testRandaoConfig  *params.ChainConfig
+	testBlsSealConfig *params.ChainConfig
 )
 
 func init() {
@@ -65,6 +66,9 @@ func init() {
 	testRandaoConfig.CancunCompatibleBlock = common.Big0
 	testRandaoConfig.RandaoCompatibleBlock = common.Big0
 	testRandaoConfig.RandaoRegistry = &params.RegistryConfig{}
+
+	testBlsSealConfig = testRandaoConfig.Copy()
+	testBlsSealConfig.BlsSealCompatibleBlock = common.Big0
 }
 
 type testOverrides struct {
@@ -200,9 +204,10 @@ func newTestContext(numNodes int, config *params.ChainConfig, overrides *testOve
 	}
 
 	return &testContext{
-		config:    config,
-		nodeKeys:  nodeKeys,
-		nodeAddrs: nodeAddrs,
+		config:      config,
+		nodeKeys:    nodeKeys,
+		nodeAddrs:   nodeAddrs,
+		nodeBlsKeys: nodeBlsKeys,
 
 		chain:  chain,
 		engine: engine,
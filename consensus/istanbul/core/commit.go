@@ -78,8 +78,8 @@ func (c *core) handleCommit(msg *message, src istanbul.Validator) error {
 		return errInvalidMessage
 	}
 
-	if vrank != nil {
-		vrank.AddCommit(commit, src)
+	if c.vrank != nil {
+		c.vrank.AddCommit(commit, src)
 	}
 
 	// logger.Error("receive handle commit","num", commit.View.Sequence)
@@ -92,6 +92,12 @@ func (c *core) handleCommit(msg *message, src istanbul.Validator) error {
 		return err
 	}
 
+	// An invalid committed seal would make the seals of the proposal invalid
+	if err := c.backend.VerifyCommittedSeal(c.current.Proposal(), src.Address(), msg.CommittedSeal); err != nil {
+		logger.Warn("Invalid committed seal", "sender", src.Address(), "err", err)
+		return errInvalidCommittedSeal
+	}
+
 	if !c.valSet.CheckInSubList(msg.Hash, commit.View, src.Address()) {
 		logger.Warn("received an istanbul commit message from non-committee",
 			"currentSequence", c.current.sequence.Uint64(), "sender", src.Address().String(), "msgView", commit.View.String())
@@ -111,7 +117,7 @@ func (c *core) handleCommit(msg *message, src istanbul.Validator) error {
 			c.sendCommit()
 		} else if c.current.GetPrepareOrCommitSize() >= RequiredMessageCount(c.valSet) {
 			logger.Info("received a quorum of the messages and change state to prepared", "msgType", msgCommit, "valSet", c.valSet.Size())
-			c.current.LockHash()
+			c.lockHash()
 			c.setState(StatePrepared)
 			c.sendCommit()
 		}
@@ -124,7 +130,7 @@ func (c *core) handleCommit(msg *message, src istanbul.Validator) error {
 	//logger.Error("### consensus check","len(commits)",c.current.Commits.Size(),"f(2/3)",2*c.valSet.F(),"state",c.state.Cmp(StateCommitted))
 	if c.state.Cmp(StateCommitted) < 0 && c.current.Commits.Size() >= RequiredMessageCount(c.valSet) {
 		// Still need to call LockHash here since state can skip Prepared state and jump directly to the Committed state.
-		c.current.LockHash()
+		c.lockHash()
 		c.commit()
 	}
 
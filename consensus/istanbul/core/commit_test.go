@@ -52,6 +52,7 @@ func TestCore_sendCommit(t *testing.T) {
 		mockCtrl := gomock.NewController(t)
 		mockBackend := mock_istanbul.NewMockBackend(mockCtrl)
 		mockBackend.EXPECT().Sign(gomock.Any()).Return(nil, nil).Times(0)
+		mockBackend.EXPECT().SignCommittedSeal(gomock.Any()).Return(nil, nil).Times(0)
 		mockBackend.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(0)
 
 		istCore.backend = mockBackend
@@ -71,8 +72,10 @@ func TestCore_sendCommit(t *testing.T) {
 
 		mockCtrl := gomock.NewController(t)
 		mockBackend := mock_istanbul.NewMockBackend(mockCtrl)
-		mockBackend.EXPECT().Sign(gomock.Any()).Return(nil, nil).Times(2)
+		mockBackend.EXPECT().Sign(gomock.Any()).Return(nil, nil).Times(1)
+		mockBackend.EXPECT().SignCommittedSeal(gomock.Any()).Return(nil, nil).Times(1)
 		mockBackend.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		mockBackend.EXPECT().WriteRoundState(gomock.Any()).Times(1)
 
 		istCore.backend = mockBackend
 		istCore.sendCommit()
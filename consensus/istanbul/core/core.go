@@ -28,7 +28,7 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/klaytn/klaytn/blockchain/types"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/prque"
 	"github.com/klaytn/klaytn/consensus/istanbul"
@@ -41,6 +41,7 @@ var logger = log.NewModuleLogger(log.ConsensusIstanbulCore)
 
 // New creates an Istanbul consensus core
 func New(backend istanbul.Backend, config *istanbul.Config) Engine {
+	signedMsgs, _ := lru.New(signedMsgCacheSize)
 	c := &core{
 		config:             config,
 		address:            backend.Address(),
@@ -53,6 +54,7 @@ func New(backend istanbul.Backend, config *istanbul.Config) Engine {
 		pendingRequests:    prque.New(),
 		pendingRequestsMu:  new(sync.Mutex),
 		consensusTimestamp: time.Time{},
+		signedMsgs:         signedMsgs,
 
 		roundMeter:         metrics.NewRegisteredMeter("consensus/istanbul/core/round", nil),
 		currentRoundGauge:  metrics.NewRegisteredGauge("consensus/istanbul/core/currentRound", nil),
@@ -61,6 +63,7 @@ func New(backend istanbul.Backend, config *istanbul.Config) Engine {
 		councilSizeGauge:   metrics.NewRegisteredGauge("consensus/istanbul/core/councilSize", nil),
 		committeeSizeGauge: metrics.NewRegisteredGauge("consensus/istanbul/core/committeeSize", nil),
 		hashLockGauge:      metrics.NewRegisteredGauge("consensus/istanbul/core/hashLock", nil),
+		misbehaviourMeter:  metrics.NewRegisteredMeter("consensus/istanbul/core/misbehaviour", nil),
 	}
 	c.validateFn = c.checkValidatorSignature
 	return c
@@ -96,6 +99,17 @@ type core struct {
 	pendingRequestsMu *sync.Mutex
 
 	consensusTimestamp time.Time
+
+	// the recent signed messages to detect conflicting ones
+	signedMsgs *lru.Cache
+
+	// the payloads of PREPARE and COMMIT sent in the current view
+	sentPrepare []byte
+	sentCommit  []byte
+
+	// the commit arrival times of the current view
+	vrank *Vrank
+
 	// the meter to record the round change rate
 	roundMeter metrics.Meter
 	// the gauge to record the current round
@@ -109,6 +123,8 @@ type core struct {
 
 	councilSizeGauge   metrics.Gauge
 	committeeSizeGauge metrics.Gauge
+	// the meter to record the detected conflicting messages
+	misbehaviourMeter metrics.Meter
 }
 
 func (c *core) finalizeMessage(msg *message) ([]byte, error) {
@@ -120,8 +136,7 @@ func (c *core) finalizeMessage(msg *message) ([]byte, error) {
 	msg.CommittedSeal = []byte{}
 	// Assign the CommittedSeal if it's a COMMIT message and proposal is not nil
 	if msg.Code == msgCommit && c.current.Proposal() != nil {
-		seal := PrepareCommittedSeal(c.current.Proposal().Hash())
-		msg.CommittedSeal, err = c.backend.Sign(seal)
+		msg.CommittedSeal, err = c.backend.SignCommittedSeal(c.current.Proposal())
 		if err != nil {
 			return nil, err
 		}
@@ -155,6 +170,14 @@ func (c *core) broadcast(msg *message) {
 		return
 	}
 
+	// Journal the vote before sending it, so that it is kept after restart
+	if msg.Code == msgPrepare || msg.Code == msgCommit {
+		if err = c.journalSentVote(msg, payload); err != nil {
+			logger.Error("Failed to journal message", "msg", msg, "err", err)
+			return
+		}
+	}
+
 	// Broadcast payload
 	if err = c.backend.Broadcast(msg.Hash, c.valSet, payload); err != nil {
 		logger.Error("Failed to broadcast message", "msg", msg, "err", err)
@@ -182,25 +205,26 @@ func (c *core) commit() {
 
 	proposal := c.current.Proposal()
 	if proposal != nil {
+		committers := make([]common.Address, c.current.Commits.Size())
 		committedSeals := make([][]byte, c.current.Commits.Size())
 		for i, v := range c.current.Commits.Values() {
-			committedSeals[i] = make([]byte, types.IstanbulExtraSeal)
-			copy(committedSeals[i][:], v.CommittedSeal[:])
+			committers[i] = v.Address
+			committedSeals[i] = common.CopyBytes(v.CommittedSeal)
 		}
 
-		if err := c.backend.Commit(proposal, committedSeals); err != nil {
-			c.current.UnlockHash() // Unlock block when insertion fails
+		if err := c.backend.Commit(proposal, committers, committedSeals); err != nil {
+			c.unlockHash() // Unlock block when insertion fails
 			c.sendNextRoundChange("commit failure")
 			return
 		}
 
-		if vrank != nil {
-			vrank.HandleCommitted(proposal.Number())
+		if c.vrank != nil {
+			c.vrank.HandleCommitted(proposal.Number())
 		}
 	} else {
 		// TODO-Kaia never happen, but if proposal is nil, mining is not working.
 		logger.Error("istanbul.core current.Proposal is NULL")
-		c.current.UnlockHash() // Unlock block when insertion fails
+		c.unlockHash() // Unlock block when insertion fails
 		c.sendNextRoundChange("commit failure. proposal is nil")
 		return
 	}
@@ -326,9 +350,11 @@ func (c *core) updateRoundState(view *istanbul.View, validatorSet istanbul.Valid
 	} else {
 		c.current = newRoundState(view, validatorSet, common.Hash{}, nil, nil, c.backend.HasBadProposal)
 	}
+	c.sentPrepare, c.sentCommit = nil, nil
 	c.currentRoundGauge.Update(c.current.round.Int64())
 	if c.current.IsHashLocked() {
 		c.hashLockGauge.Update(1)
+		c.journalRoundState()
 	} else {
 		c.hashLockGauge.Update(0)
 	}
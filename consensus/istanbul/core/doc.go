@@ -34,7 +34,9 @@ Core package is composed of following files
   - `events.go`: Defines backlog event and timeout event
   - `final_committed.go`: Start a new round when a final committed proposal is stored
   - `handler.go`: Implements core.Engine.Start and Stop. Provides event and message hendlers
+  - `journal.go`: Journals the round state, the hash lock and the sent votes, and restores them when the core starts
   - `message_set.go`: Defines messageSet struct which has a validator set and messages from other nodes
+  - `misbehaviour.go`: Detects conflicting messages signed by a validator for the same view
   - `prepare.go`: Implements core methods which send, receive, handle, verify and accept prepare phase messages
   - `preprepare.go`: Implements core methods which send, handle and accept preprepare messages
   - `request.go`: Implements core methods which handle, check, store and process preprepare messages
@@ -39,6 +39,12 @@ var (
 	errInvalidMessage = errors.New("invalid message")
 	// errFailedDecodeMessageSet is returned when the message set is malformed.
 	errFailedDecodeMessageSet = errors.New("failed to decode message set")
+	// errInvalidCommittedSeal is returned when the committed seal of a COMMIT message
+	// is not signed by the sender for the current proposal.
+	errInvalidCommittedSeal = errors.New("invalid committed seal")
 	// errInvalidSigner is returned when the message is signed by a validator different than message sender
 	errInvalidSigner = errors.New("message not signed by the sender")
+	// errConflictingVote is returned when a PREPARE or COMMIT message is about to be
+	// sent for a proposal different from the one voted in the same view
+	errConflictingVote = errors.New("conflicting vote in the same view")
 )
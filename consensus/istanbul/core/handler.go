@@ -33,6 +33,9 @@ func (c *core) Start() error {
 	// Tests will handle events itself, so we have to make subscribeEvents()
 	// be able to call in test.
 	c.subscribeEvents()
+	// Restore the round state journaled before the restart. It is done after
+	// subscribing events since the proposer may broadcast the locked proposal.
+	c.restoreRoundState()
 	c.handlerWg.Add(1)
 	go c.handleEvents()
 
@@ -181,6 +184,8 @@ func (c *core) handleMsg(payload []byte) error {
 		return istanbul.ErrUnauthorizedAddress
 	}
 
+	c.checkMisbehaviour(msg, payload)
+
 	return c.handleCheckedMsg(msg, src)
 }
 
@@ -74,11 +74,18 @@ func newMockBackend(t *testing.T, validatorAddrs []common.Address) (*mock_istanb
 
 	// Always return nil for broadcasting related functions
 	mockBackend.EXPECT().Sign(gomock.Any()).Return(nil, nil).AnyTimes()
+	mockBackend.EXPECT().SignCommittedSeal(gomock.Any()).Return(nil, nil).AnyTimes()
 	mockBackend.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockBackend.EXPECT().GossipSubPeer(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// Verify checks whether the proposal of the preprepare message is a valid block. Consider it valid.
 	mockBackend.EXPECT().Verify(gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+	mockBackend.EXPECT().VerifyCommittedSeal(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	// Keep the journaled round state in memory
+	var roundState []byte
+	mockBackend.EXPECT().WriteRoundState(gomock.Any()).Do(func(blob []byte) { roundState = blob }).AnyTimes()
+	mockBackend.EXPECT().ReadRoundState().DoAndReturn(func() []byte { return roundState }).AnyTimes()
 
 	return mockBackend, mockCtrl
 }
@@ -534,7 +541,7 @@ func simulateMaliciousCN(t *testing.T, numValidators int, numMalicious int) Stat
 
 	// Add more EXPECT()s to remove unexpected call error
 	mockBackend, mockCtrl := newMockBackend(t, validatorAddrs)
-	mockBackend.EXPECT().Commit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockBackend.EXPECT().Commit(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockBackend.EXPECT().HasBadProposal(gomock.Any()).Return(true).AnyTimes()
 	defer mockCtrl.Finish()
 
@@ -630,7 +637,7 @@ func simulateChainSplit(t *testing.T, numValidators int) (State, State) {
 
 	// Add more EXPECT()s to remove unexpected call error
 	mockBackend, mockCtrl := newMockBackend(t, validatorAddrs)
-	mockBackend.EXPECT().Commit(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockBackend.EXPECT().Commit(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockBackend.EXPECT().HasBadProposal(gomock.Any()).Return(true).AnyTimes()
 	defer mockCtrl.Finish()
 
@@ -0,0 +1,149 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// journaledRoundState is the record of the round state written ahead of sending
+// PREPARE and COMMIT messages and locking a proposal. It is restored when the
+// core starts, so that a restarted validator keeps the hash lock and doesn't
+// vote for another proposal in the same view.
+type journaledRoundState struct {
+	View           *istanbul.View
+	LockedHash     common.Hash
+	LockedProposal []byte // encoded preprepare of the locked proposal, empty if not locked
+	SentPrepare    []byte // payload of the PREPARE sent in the view, empty if not sent
+	SentCommit     []byte // payload of the COMMIT sent in the view, empty if not sent
+}
+
+// journalRoundState writes the current view, the locked proposal and the votes
+// sent in the view to the backend.
+func (c *core) journalRoundState() {
+	record := &journaledRoundState{
+		View:        c.currentView(),
+		LockedHash:  c.current.GetLockedHash(),
+		SentPrepare: c.sentPrepare,
+		SentCommit:  c.sentCommit,
+	}
+	if !common.EmptyHash(record.LockedHash) && c.current.Preprepare != nil {
+		lockedProposal, err := Encode(c.current.Preprepare)
+		if err != nil {
+			c.logger.Error("Failed to encode the locked proposal", "err", err)
+			return
+		}
+		record.LockedProposal = lockedProposal
+	}
+
+	blob, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		c.logger.Error("Failed to encode the round state", "err", err)
+		return
+	}
+	c.backend.WriteRoundState(blob)
+}
+
+// restoreRoundState restores the journaled round state if the sequence of it is
+// not committed yet. The hash lock is restored with the locked proposal, and the
+// core moves to the journaled round.
+func (c *core) restoreRoundState() {
+	blob := c.backend.ReadRoundState()
+	if len(blob) == 0 {
+		return
+	}
+	record := new(journaledRoundState)
+	if err := rlp.DecodeBytes(blob, record); err != nil {
+		c.logger.Error("Failed to decode the journaled round state", "err", err)
+		return
+	}
+	if record.View == nil || record.View.Sequence.Cmp(c.current.Sequence()) != 0 || record.View.Round.Cmp(c.current.Round()) < 0 {
+		return
+	}
+
+	if len(record.LockedProposal) > 0 {
+		var preprepare *istanbul.Preprepare
+		if err := rlp.DecodeBytes(record.LockedProposal, &preprepare); err != nil {
+			c.logger.Error("Failed to decode the locked proposal", "err", err)
+			return
+		}
+		c.current = newRoundState(c.currentView(), c.valSet, record.LockedHash, preprepare, nil, c.backend.HasBadProposal)
+	}
+	if record.View.Round.Cmp(c.current.Round()) > 0 {
+		// The hash lock is kept by the round change
+		c.startNewRound(record.View.Round)
+	}
+	c.sentPrepare, c.sentCommit = record.SentPrepare, record.SentCommit
+	c.journalRoundState()
+
+	c.logger.Warn("Restored the journaled round state", "view", record.View, "lockedHash", record.LockedHash,
+		"sentPrepare", len(record.SentPrepare) > 0, "sentCommit", len(record.SentCommit) > 0)
+}
+
+// lockHash locks the current proposal and journals the lock.
+func (c *core) lockHash() {
+	prevLockedHash := c.current.GetLockedHash()
+	c.current.LockHash()
+	if c.current.GetLockedHash() != prevLockedHash {
+		c.journalRoundState()
+	}
+}
+
+// unlockHash unlocks the current proposal and journals the unlock.
+func (c *core) unlockHash() {
+	c.current.UnlockHash()
+	c.journalRoundState()
+}
+
+// journalSentVote records the PREPARE or COMMIT message about to be sent in the
+// current view before it is sent. It returns errConflictingVote if another
+// proposal was voted by the same kind of message in the view.
+func (c *core) journalSentVote(msg *message, payload []byte) error {
+	var sub *istanbul.Subject
+	if err := msg.Decode(&sub); err != nil {
+		return err
+	}
+	// Commits for the old blocks are not for the current view
+	if sub.View.Cmp(c.currentView()) != 0 {
+		return nil
+	}
+
+	sent := &c.sentPrepare
+	if msg.Code == msgCommit {
+		sent = &c.sentCommit
+	}
+	if len(*sent) > 0 {
+		sentMsg := new(message)
+		if err := sentMsg.FromPayload(*sent, nil); err != nil {
+			return err
+		}
+		var sentSub *istanbul.Subject
+		if err := sentMsg.Decode(&sentSub); err != nil {
+			return err
+		}
+		if sentSub.Digest != sub.Digest {
+			return errConflictingVote
+		}
+		return nil
+	}
+
+	*sent = payload
+	c.journalRoundState()
+	return nil
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	mock_istanbul "github.com/klaytn/klaytn/consensus/istanbul/mocks"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type journalTestContext struct {
+	t           *testing.T
+	mockBackend *mock_istanbul.MockBackend
+	keys        map[common.Address]*ecdsa.PrivateKey
+	lastBlock   *types.Block
+	proposer    common.Address
+	committee   []istanbul.Validator
+}
+
+// newJournalTestContext starts a core and makes it lock on the proposal after
+// receiving a quorum of PREPARE messages. It returns the core which is about to
+// receive COMMIT messages.
+func newJournalTestContext(t *testing.T) (*journalTestContext, *core, *types.Block) {
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	t.Cleanup(fork.ClearHardForkBlockNumberConfig)
+
+	validatorAddrs, validatorKeyMap := genValidators(10)
+	mockBackend, mockCtrl := newMockBackend(t, validatorAddrs)
+	t.Cleanup(mockCtrl.Finish)
+	mockBackend.EXPECT().HasBadProposal(gomock.Any()).Return(false).AnyTimes()
+
+	ctx := &journalTestContext{t: t, mockBackend: mockBackend, keys: validatorKeyMap}
+	istCore := ctx.startCore()
+
+	lastProposal, _ := mockBackend.LastProposal()
+	ctx.lastBlock = lastProposal.(*types.Block)
+	ctx.proposer = istCore.valSet.GetProposer().Address()
+	ctx.committee = istCore.valSet.SubList(ctx.lastBlock.Hash(), istCore.currentView())
+
+	proposal, err := genBlock(ctx.lastBlock, ctx.keys[ctx.proposer])
+	require.NoError(t, err)
+
+	// PREPREPARE and a quorum of PREPARE lock the proposal
+	require.NoError(t, istCore.handleMsg(ctx.payload(msgPreprepare, proposal, ctx.proposer)))
+	for _, val := range ctx.committee {
+		require.NoError(t, istCore.handleMsg(ctx.payload(msgPrepare, proposal, val.Address())))
+	}
+	require.True(t, istCore.current.IsHashLocked())
+	require.Equal(t, proposal.Hash(), istCore.current.GetLockedHash())
+	require.Equal(t, StatePrepared, istCore.state)
+
+	return ctx, istCore, proposal
+}
+
+func (ctx *journalTestContext) startCore() *core {
+	istConfig := istanbul.DefaultConfig
+	istConfig.ProposerPolicy = istanbul.WeightedRandom
+
+	istCore := New(ctx.mockBackend, istConfig).(*core)
+	require.NoError(ctx.t, istCore.Start())
+	ctx.t.Cleanup(func() { istCore.Stop() })
+	return istCore
+}
+
+// restart kills the core and starts a new one with the same backend.
+func (ctx *journalTestContext) restart(istCore *core) *core {
+	require.NoError(ctx.t, istCore.Stop())
+	return ctx.startCore()
+}
+
+func (ctx *journalTestContext) payload(msgType uint64, proposal *types.Block, signer common.Address) []byte {
+	msg, err := genIstanbulMsg(msgType, ctx.lastBlock.Hash(), proposal, signer, ctx.keys[signer])
+	require.NoError(ctx.t, err)
+	return msg.Payload
+}
+
+func TestCore_restoreRoundState_commit(t *testing.T) {
+	ctx, istCore, proposal := newJournalTestContext(t)
+
+	var committed istanbul.Proposal
+	ctx.mockBackend.EXPECT().Commit(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(p istanbul.Proposal, committers []common.Address, seals [][]byte) {
+		committed = p
+	}).Return(nil).Times(1)
+
+	// Kill the validator between PREPARE and COMMIT
+	istCore = ctx.restart(istCore)
+	assert.True(t, istCore.current.IsHashLocked())
+	assert.Equal(t, proposal.Hash(), istCore.current.GetLockedHash())
+	assert.Equal(t, proposal.Hash(), istCore.current.Proposal().Hash())
+	assert.Equal(t, 0, istCore.currentView().Cmp(&istanbul.View{Sequence: proposal.Number(), Round: common.Big0}))
+
+	// The locked proposal is prepared directly, and committed with a quorum of COMMIT
+	require.NoError(t, istCore.handleMsg(ctx.payload(msgPreprepare, proposal, ctx.proposer)))
+	assert.Equal(t, StatePrepared, istCore.state)
+	for _, val := range ctx.committee {
+		require.NoError(t, istCore.handleMsg(ctx.payload(msgCommit, proposal, val.Address())))
+	}
+	assert.Equal(t, StateCommitted, istCore.state)
+	require.NotNil(t, committed)
+	assert.Equal(t, proposal.Hash(), committed.Hash())
+}
+
+func TestCore_restoreRoundState_conflictingProposal(t *testing.T) {
+	ctx, istCore, proposal := newJournalTestContext(t)
+
+	// Kill the validator between PREPARE and COMMIT
+	istCore = ctx.restart(istCore)
+	require.True(t, istCore.current.IsHashLocked())
+
+	// Another proposal for the same view is not prepared, but a round change happens
+	conflicting, err := genBlockParams(ctx.lastBlock, ctx.keys[ctx.proposer], 1, 1, 1)
+	require.NoError(t, err)
+	require.NoError(t, istCore.handleMsg(ctx.payload(msgPreprepare, conflicting, ctx.proposer)))
+	assert.Equal(t, StateAcceptRequest, istCore.state)
+	assert.Equal(t, proposal.Hash(), istCore.current.Proposal().Hash())
+	assert.Equal(t, common.Big1, istCore.currentView().Round)
+
+	// The lock and the round are kept after another restart
+	istCore = ctx.restart(istCore)
+	assert.True(t, istCore.current.IsHashLocked())
+	assert.Equal(t, proposal.Hash(), istCore.current.GetLockedHash())
+	assert.Equal(t, common.Big1, istCore.currentView().Round)
+}
+
+func TestCore_journalSentVote(t *testing.T) {
+	ctx, istCore, proposal := newJournalTestContext(t)
+
+	conflicting, err := genBlockParams(ctx.lastBlock, ctx.keys[ctx.proposer], 1, 1, 1)
+	require.NoError(t, err)
+
+	vote := func(code uint64, proposal *types.Block) error {
+		payload := ctx.payload(code, proposal, ctx.proposer)
+		msg := new(message)
+		require.NoError(t, msg.FromPayload(payload, nil))
+		return istCore.journalSentVote(msg, payload)
+	}
+
+	for _, code := range []uint64{msgPrepare, msgCommit} {
+		assert.NoError(t, vote(code, proposal))
+		assert.NoError(t, vote(code, proposal))
+		assert.Equal(t, errConflictingVote, vote(code, conflicting))
+	}
+
+	// The sent votes are restored after restart
+	istCore = ctx.restart(istCore)
+	assert.NotEmpty(t, istCore.sentPrepare)
+	assert.NotEmpty(t, istCore.sentCommit)
+	assert.Equal(t, errConflictingVote, vote(msgCommit, conflicting))
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+)
+
+// signedMsgCacheSize is the number of the recent signed messages kept to
+// detect the conflicting messages.
+const signedMsgCacheSize = 4096
+
+// signedMsgKey identifies the message which a validator can sign only once.
+type signedMsgKey struct {
+	code     uint64
+	sequence uint64
+	round    uint64
+	address  common.Address
+}
+
+// signedMsg is the proposal hash of a signed message and the message itself.
+// reported is set once the conflicting message is recorded, so the evidence of
+// a key is recorded only once.
+type signedMsg struct {
+	digest   common.Hash
+	payload  []byte
+	reported bool
+}
+
+// checkMisbehaviour compares the message with the one of the same code and view
+// previously signed by the same validator. If they are for different proposals,
+// the evidence containing both messages is recorded to the backend, only for the
+// first conflicting message of the code and view.
+func (c *core) checkMisbehaviour(msg *message, payload []byte) {
+	var (
+		typ    istanbul.MisbehaviourType
+		view   *istanbul.View
+		digest common.Hash
+	)
+	switch msg.Code {
+	case msgPreprepare:
+		var preprepare *istanbul.Preprepare
+		if err := msg.Decode(&preprepare); err != nil || preprepare.Proposal == nil {
+			return
+		}
+		typ, view, digest = istanbul.DoubleProposal, preprepare.View, preprepare.Proposal.Hash()
+	case msgCommit:
+		var commit *istanbul.Subject
+		if err := msg.Decode(&commit); err != nil {
+			return
+		}
+		typ, view, digest = istanbul.DoubleCommit, commit.View, commit.Digest
+	default:
+		return
+	}
+	if view == nil || view.Sequence == nil || view.Round == nil {
+		return
+	}
+
+	key := signedMsgKey{code: msg.Code, sequence: view.Sequence.Uint64(), round: view.Round.Uint64(), address: msg.Address}
+	prev, ok := c.signedMsgs.Get(key)
+	if !ok {
+		c.signedMsgs.Add(key, signedMsg{digest: digest, payload: common.CopyBytes(payload)})
+		return
+	}
+	first := prev.(signedMsg)
+	if first.reported || first.digest == digest {
+		return
+	}
+	first.reported = true
+	c.signedMsgs.Add(key, first)
+
+	m := &istanbul.Misbehaviour{
+		Type:      typ,
+		Validator: msg.Address,
+		Sequence:  key.sequence,
+		Round:     key.round,
+		Messages:  [2]hexutil.Bytes{first.payload, common.CopyBytes(payload)},
+		Time:      uint64(time.Now().Unix()),
+	}
+	c.logger.Warn("Detected conflicting consensus messages", "type", typ, "validator", msg.Address, "view", view, "hash", m.Hash())
+	c.misbehaviourMeter.Mark(1)
+	c.backend.RecordMisbehaviour(m)
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCore_checkMisbehaviour(t *testing.T) {
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	validatorAddrs, validatorKeyMap := genValidators(10)
+	mockBackend, mockCtrl := newMockBackend(t, validatorAddrs)
+	defer mockCtrl.Finish()
+
+	var recorded []*istanbul.Misbehaviour
+	mockBackend.EXPECT().RecordMisbehaviour(gomock.Any()).Do(func(m *istanbul.Misbehaviour) {
+		recorded = append(recorded, m)
+	}).Times(2)
+
+	istConfig := istanbul.DefaultConfig
+	istConfig.ProposerPolicy = istanbul.WeightedRandom
+
+	istCore := New(mockBackend, istConfig).(*core)
+	require.NoError(t, istCore.Start())
+	defer istCore.Stop()
+
+	lastProposal, _ := mockBackend.LastProposal()
+	lastBlock := lastProposal.(*types.Block)
+	validators := mockBackend.Validators(lastBlock)
+
+	proposer := validators.GetProposer().Address()
+	proposerKey := validatorKeyMap[proposer]
+
+	proposal1, err := genBlockParams(lastBlock, proposerKey, 0, 1, 1)
+	require.NoError(t, err)
+	proposal2, err := genBlockParams(lastBlock, proposerKey, 1, 1, 1)
+	require.NoError(t, err)
+	require.NotEqual(t, proposal1.Hash(), proposal2.Hash())
+
+	genPayload := func(msgType uint64, proposal *types.Block) []byte {
+		msg, err := genIstanbulMsg(msgType, lastBlock.Hash(), proposal, proposer, proposerKey)
+		require.NoError(t, err)
+		return msg.Payload
+	}
+
+	// The same messages are not conflicting
+	preprepare1 := genPayload(msgPreprepare, proposal1)
+	istCore.handleMsg(preprepare1)
+	istCore.handleMsg(preprepare1)
+	assert.Empty(t, recorded)
+
+	// Preprepare of another proposal for the same view
+	preprepare2 := genPayload(msgPreprepare, proposal2)
+	istCore.handleMsg(preprepare2)
+	require.Len(t, recorded, 1)
+	assert.Equal(t, istanbul.DoubleProposal, recorded[0].Type)
+	assert.Equal(t, proposer, recorded[0].Validator)
+	assert.Equal(t, proposal1.NumberU64(), recorded[0].Sequence)
+	assert.Equal(t, uint64(0), recorded[0].Round)
+	assert.Equal(t, preprepare1, []byte(recorded[0].Messages[0]))
+	assert.Equal(t, preprepare2, []byte(recorded[0].Messages[1]))
+
+	// Only the first conflicting message of the view is recorded
+	proposal3, err := genBlockParams(lastBlock, proposerKey, 2, 1, 1)
+	require.NoError(t, err)
+	require.NotEqual(t, proposal2.Hash(), proposal3.Hash())
+	istCore.handleMsg(genPayload(msgPreprepare, proposal3))
+	assert.Len(t, recorded, 1)
+
+	// Prepares are not checked
+	istCore.handleMsg(genPayload(msgPrepare, proposal1))
+	istCore.handleMsg(genPayload(msgPrepare, proposal2))
+	assert.Len(t, recorded, 1)
+
+	// Commit of another proposal for the same view
+	commit1 := genPayload(msgCommit, proposal1)
+	commit2 := genPayload(msgCommit, proposal2)
+	istCore.handleMsg(commit1)
+	istCore.handleMsg(commit2)
+	require.Len(t, recorded, 2)
+	assert.Equal(t, istanbul.DoubleCommit, recorded[1].Type)
+	assert.Equal(t, commit1, []byte(recorded[1].Messages[0]))
+	assert.Equal(t, commit2, []byte(recorded[1].Messages[1]))
+
+	// The signatures of the evidence can be verified
+	for _, payload := range recorded[1].Messages {
+		msg := new(message)
+		require.NoError(t, msg.FromPayload(payload, istCore.validateFn))
+		assert.Equal(t, proposer, msg.Address)
+	}
+}
@@ -78,6 +78,7 @@ func TestCore_sendPrepare(t *testing.T) {
 		mockBackend := mock_istanbul.NewMockBackend(mockCtrl)
 		mockBackend.EXPECT().Sign(gomock.Any()).Return(nil, nil).Times(1)
 		mockBackend.EXPECT().Broadcast(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		mockBackend.EXPECT().WriteRoundState(gomock.Any()).Times(1)
 
 		istCore.backend = mockBackend
 		istCore.sendPrepare()
@@ -123,10 +123,10 @@ func (c *core) handlePreprepare(msg *message, src istanbul.Validator) error {
 				c.setState(StatePrepared)
 				c.sendCommit()
 
-				if vrank != nil {
-					vrank.Log()
+				if c.vrank != nil {
+					c.vrank.Log()
 				}
-				vrank = NewVrank(*c.currentView(), c.valSet.SubList(preprepare.Proposal.ParentHash(), c.currentView()))
+				c.vrank = NewVrank(*c.currentView(), c.valSet.SubList(preprepare.Proposal.ParentHash(), c.currentView()))
 			} else {
 				// Send round change
 				c.sendNextRoundChange("handlePreprepare. HashLocked, but received hash is different from locked hash")
@@ -139,10 +139,10 @@ func (c *core) handlePreprepare(msg *message, src istanbul.Validator) error {
 			c.setState(StatePreprepared)
 			c.sendPrepare()
 
-			if vrank != nil {
-				vrank.Log()
+			if c.vrank != nil {
+				c.vrank.Log()
 			}
-			vrank = NewVrank(*c.currentView(), c.valSet.SubList(preprepare.Proposal.ParentHash(), c.currentView()))
+			c.vrank = NewVrank(*c.currentView(), c.valSet.SubList(preprepare.Proposal.ParentHash(), c.currentView()))
 		}
 	}
 
@@ -49,8 +49,6 @@ var (
 	vrankLastCommitArrivalTimeGauge            = metrics.NewRegisteredGauge("vrank/last_commit", nil)
 
 	vrankDefaultThreshold = "300ms" // the time to receive 2f+1 commits in an ideal network
-
-	vrank *Vrank
 )
 
 const (
@@ -22,6 +22,8 @@ Istanbul engine is using 3-phase consensus and it can tolerate F faulty nodes wh
 
 In Kaia, it is being used as the main consensus engine after modification for supports of Committee, Reward and Governance.
 Package istanbul has three sub-packages, core, backend, and validator. Please refer to each package's doc.go for more information.
+The sub-package simulation runs a network of the validators in a process for testing.
+The sub-package light verifies the finality of the headers without running a full node.
 
 # Source Files
 
@@ -30,6 +32,7 @@ Various interfaces, constants and utility functions for Istanbul consensus engin
   - `config.go`: Provides default configuration for Istanbul engine
   - `errors.go`: Defines three errors used in Istanbul engine
   - `events.go`: Defines events which are used for Istanbul engine communication
+  - `misbehaviour.go`: Defines Misbehaviour, the evidence of conflicting messages signed by a validator
   - `types.go`: Defines message structs such as Proposal, Request, View, Preprepare, Subject and ConsensusMsg
   - `utils.go`: Provides three utility functions: RLPHash, GetSignatureAddress and CheckValidatorSignature
   - `validator.go`: Defines Validator, ValidatorSet interfaces and Validators, ProposalSelector types
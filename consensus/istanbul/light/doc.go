@@ -0,0 +1,44 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package light verifies the finality of Istanbul headers without running a full node,
+e.g. for the bridges and the service chains verifying the headers of the parent chain.
+
+Starting from a trusted checkpoint, which is a header and the Istanbul snapshot
+taken at it, a Verifier verifies a stream of the following headers. A header is
+accepted if it's the child of the last accepted header, signed by one of the
+validators and committed by more than 2/3 of the committee of the block. The
+committee is composed from the validators by SubListWithProposer of the validator
+set, in the same way as the consensus. After the BlsSeal fork, the aggregated
+seal is verified with the BLS public keys given by the user.
+
+The verifier tracks the validators by the votes adding and removing validators
+and the governance parameters in the accepted headers. The parameters are kept
+in the verifier, so the globals of the process are not changed. The changes of the
+validators and the proposers by the staking amounts and the parameters changed
+by the governance contract are not tracked, because they need the state of the
+chain. If the validators in a header don't match the tracked validators, the
+header is rejected with ErrValidatorsMismatch and a new checkpoint is needed.
+
+Note that the validator package reads the hard fork blocks from the fork
+package, which should be set to the config of the verified chain.
+
+# Source Files
+
+  - `verifier.go`: Defines Checkpoint and Verifier verifying the headers and tracking the validators
+*/
+package light
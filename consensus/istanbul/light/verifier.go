@@ -0,0 +1,344 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package light
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/consensus/istanbul/backend"
+	"github.com/klaytn/klaytn/crypto/bls"
+	"github.com/klaytn/klaytn/crypto/sha3"
+	"github.com/klaytn/klaytn/governance"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+var logger = log.NewModuleLogger(log.ConsensusIstanbulLight)
+
+var (
+	// ErrInvalidCheckpoint is returned if the snapshot of the checkpoint is not taken at the header.
+	ErrInvalidCheckpoint = errors.New("invalid checkpoint")
+	// ErrUnauthorized is returned if the header is not signed by one of the validators.
+	ErrUnauthorized = errors.New("unauthorized proposer")
+	// ErrValidatorsMismatch is returned if the validators in the header differ from the tracked validators.
+	// It happens if the validators are changed by the staking amounts, which are not known to the verifier.
+	ErrValidatorsMismatch = errors.New("validators mismatch")
+	// ErrNotFromCommittee is returned if a committed seal is not signed by a member of the committee.
+	ErrNotFromCommittee = errors.New("committed seal not from the committee")
+	// ErrInsufficientCommittedSeals is returned if the committed seals are not signed by a quorum of the committee.
+	ErrInsufficientCommittedSeals = errors.New("insufficient committed seals")
+	// ErrNoBlsPubkey is returned if the header has an aggregated seal but no BlsPubkeyFunc is given.
+	ErrNoBlsPubkey = errors.New("no bls pubkey function to verify the aggregated seal")
+	// ErrUnexpectedSeals is returned if the kind of the committed seals doesn't match the BlsSeal fork.
+	ErrUnexpectedSeals = errors.New("unexpected kind of committed seals")
+)
+
+// BlsPubkeyFunc returns the BLS public key of the validator to verify the block
+// of the given number, e.g. from the KIP-113 contract of a trusted full node.
+type BlsPubkeyFunc func(addr common.Address, number *big.Int) (bls.PublicKey, error)
+
+// Checkpoint is a trusted header and the Istanbul snapshot taken at the header.
+// The snapshot of a full node can be retrieved by istanbul_getSnapshotAtHash.
+type Checkpoint struct {
+	Header   *types.Header     `json:"header"`
+	Snapshot *backend.Snapshot `json:"snapshot"`
+}
+
+// Verifier verifies that the headers following a checkpoint are finalized by
+// the committees, and tracks the validators of the chain with the votes and
+// the governance changes in the verified headers.
+type Verifier struct {
+	config    *params.ChainConfig
+	blsPubkey BlsPubkeyFunc
+	db        database.DBManager     // memory database storing the governance parameters of the epochs
+	gov       *governance.Governance // tallies the validator votes with the parameters in db
+
+	mu       sync.RWMutex // protects the fields below
+	head     *types.Header
+	snap     *backend.Snapshot
+	govItems map[string]interface{} // the latest governance parameters stored in db
+}
+
+// NewVerifier returns a verifier of the headers following the checkpoint. The
+// chain config should have the governance parameters effective at the checkpoint.
+// blsPubkey can be nil if the chain doesn't reach the BlsSeal fork. The snapshot
+// of the checkpoint is updated as the headers are verified.
+//
+// The verifier doesn't track the staking information. On the chains of the
+// WeightedRandom policy like Cypress and Baobab, the headers after the
+// validators are changed by a staking update are rejected with
+// ErrValidatorsMismatch, and a new verifier from a later checkpoint is needed.
+//
+// Note that the validator package reads the hard fork blocks from the fork
+// package, so it should be set to the config of the verified chain.
+func NewVerifier(config *params.ChainConfig, checkpoint *Checkpoint, blsPubkey BlsPubkeyFunc) (*Verifier, error) {
+	if checkpoint == nil || checkpoint.Header == nil || checkpoint.Snapshot == nil || checkpoint.Snapshot.ValSet == nil {
+		return nil, ErrInvalidCheckpoint
+	}
+	header, snap := checkpoint.Header, checkpoint.Snapshot
+	if snap.Number != header.Number.Uint64() || snap.Hash != header.Hash() {
+		return nil, ErrInvalidCheckpoint
+	}
+	if _, err := types.ExtractIstanbulExtra(header); err != nil {
+		return nil, err
+	}
+	// The governance parameters are kept only in the verifier. Unlike the
+	// governance of a node, the changes are not applied to the globals.
+	db := database.NewMemoryDBManager()
+	govSet := governance.GetGovernanceItemsFromChainConfig(config)
+	items := govSet.Items()
+	if err := db.WriteGovernance(items, 0); err != nil {
+		return nil, err
+	}
+	return &Verifier{
+		config:    config,
+		blsPubkey: blsPubkey,
+		db:        db,
+		gov:       governance.NewGovernance(config, db),
+		head:      types.CopyHeader(header),
+		snap:      snap,
+		govItems:  items,
+	}, nil
+}
+
+// Head returns the last verified header.
+func (v *Verifier) Head() *types.Header {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return types.CopyHeader(v.head)
+}
+
+// Validators returns the validators of the next block in ascending order.
+func (v *Verifier) Validators() []common.Address {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return validatorAddrs(v.snap.ValSet)
+}
+
+// VerifyHeaders verifies the headers in order. It returns the number of the
+// verified headers and the error which stopped the verification.
+func (v *Verifier) VerifyHeaders(headers []*types.Header) (int, error) {
+	for i, header := range headers {
+		if err := v.VerifyHeader(header); err != nil {
+			return i, err
+		}
+	}
+	return len(headers), nil
+}
+
+// VerifyHeader verifies that the header is the child of the last verified
+// header, and it's signed by one of the validators and committed by a quorum
+// of the committee. The header becomes the last verified header if it's valid.
+func (v *Verifier) VerifyHeader(header *types.Header) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	parent, valSet := v.head, v.snap.ValSet
+	if header.Number == nil || header.Number.Uint64() != parent.Number.Uint64()+1 || header.ParentHash != parent.Hash() {
+		return consensus.ErrUnknownAncestor
+	}
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return err
+	}
+	if !equalAddrs(extra.Validators, validatorAddrs(valSet)) {
+		return ErrValidatorsMismatch
+	}
+
+	proposer, err := istanbul.GetSignatureAddress(sigHash(header).Bytes(), extra.Seal)
+	if err != nil {
+		return err
+	}
+	if _, val := valSet.GetByAddress(proposer); val == nil {
+		return ErrUnauthorized
+	}
+
+	committers, err := v.committers(header, extra)
+	if err != nil {
+		return err
+	}
+	view := &istanbul.View{Sequence: header.Number, Round: new(big.Int).SetUint64(uint64(header.Round()))}
+	committee := make(map[common.Address]bool)
+	for _, val := range valSet.SubListWithProposer(parent.Hash(), proposer, view) {
+		committee[val.Address()] = true
+	}
+	for _, addr := range committers {
+		// Every member of the committee can have only one seal
+		if !committee[addr] {
+			return ErrNotFromCommittee
+		}
+		committee[addr] = false
+	}
+	// The number of committers should be larger than number of faulty node + 1
+	if len(committers) <= 2*valSet.F() {
+		return ErrInsufficientCommittedSeals
+	}
+
+	v.apply(header, proposer)
+	v.head = types.CopyHeader(header)
+	return nil
+}
+
+// committers returns the signers of the committed seals or the aggregated seal of the header.
+func (v *Verifier) committers(header *types.Header, extra *types.IstanbulExtra) ([]common.Address, error) {
+	if !v.config.IsBlsSealForkEnabled(header.Number) {
+		if len(extra.AggregatedSeal) != 0 || len(extra.CommitterBitmap) != 0 {
+			return nil, ErrUnexpectedSeals
+		}
+		return backend.RecoverCommittedSeals(extra, header.Hash())
+	}
+
+	if len(extra.CommittedSeal) != 0 || len(extra.AggregatedSeal) == 0 {
+		return nil, ErrUnexpectedSeals
+	}
+	if v.blsPubkey == nil {
+		return nil, ErrNoBlsPubkey
+	}
+	committers, err := extra.Committers()
+	if err != nil {
+		return nil, err
+	}
+	pubs := make([]bls.PublicKey, len(committers))
+	for i, addr := range committers {
+		if pubs[i], err = v.blsPubkey(addr, header.Number); err != nil {
+			return nil, err
+		}
+	}
+	if err := backend.VerifyAggregatedSeal(header.Hash(), extra.AggregatedSeal, pubs); err != nil {
+		return nil, err
+	}
+	return committers, nil
+}
+
+// apply updates the snapshot with the governance changes and the vote of the
+// verified header in the same way as the snapshot of the Istanbul backend.
+// The validators are not refreshed with the staking amounts, so the verifier
+// needs a new checkpoint if they are changed by the staking amounts.
+func (v *Verifier) apply(header *types.Header, proposer common.Address) {
+	snap, number := v.snap, header.Number.Uint64()
+
+	if number%snap.Epoch == 0 {
+		if len(header.Governance) > 0 {
+			if err := v.writeGovernance(number, header.Governance); err != nil {
+				logger.Error("Failed to apply the governance of the header", "number", number, "err", err)
+			}
+		}
+		snap.Votes = make([]governance.GovernanceVote, 0)
+		snap.Tally = make([]governance.GovernanceTallyItem, 0)
+	}
+	if pset, err := v.gov.EffectiveParams(number + 1); err == nil {
+		snap.Epoch, snap.Policy, snap.CommitteeSize = pset.Epoch(), pset.Policy(), pset.CommitteeSize()
+	}
+
+	// The parameter votes take effect by the governance of the epoch blocks, and
+	// handling them in the governance can change the globals like the round
+	// change timeout, so only the validator votes are handled.
+	if isValidatorVote(header) {
+		snap.ValSet, snap.Votes, snap.Tally = v.gov.HandleGovernanceVote(snap.ValSet, snap.Votes, snap.Tally, header, proposer, common.Address{}, false)
+	}
+	snap.Number, snap.Hash = number, header.Hash()
+
+	if snap.ValSet.Policy() == istanbul.WeightedRandom {
+		snap.ValSet.SetBlockNum(number)
+		if v.config.IsRandaoForkBlockParent(header.Number) {
+			snap.ValSet.SetMixHash(params.ZeroMixHash)
+		} else if v.config.IsRandaoForkEnabled(header.Number) {
+			snap.ValSet.SetMixHash(header.MixHash)
+		}
+	}
+	snap.ValSet.SetSubGroupSize(snap.CommitteeSize)
+}
+
+// writeGovernance stores the parameters changed by the governance of the epoch
+// header on top of the latest parameters, in the same way as the governance of
+// a node.
+func (v *Verifier) writeGovernance(number uint64, data []byte) error {
+	var encoded []byte
+	if err := rlp.DecodeBytes(data, &encoded); err != nil {
+		return err
+	}
+	changes := make(map[string]interface{})
+	if err := json.Unmarshal(encoded, &changes); err != nil {
+		return err
+	}
+	items := make(map[string]interface{}, len(v.govItems)+len(changes))
+	for key, value := range v.govItems {
+		items[key] = value
+	}
+	for key, value := range changes {
+		items[key] = value
+	}
+	if err := v.db.WriteGovernance(items, number); err != nil {
+		return err
+	}
+	v.govItems = items
+	return nil
+}
+
+// isValidatorVote returns whether the header has a vote adding or removing validators.
+func isValidatorVote(header *types.Header) bool {
+	if len(header.Vote) == 0 {
+		return false
+	}
+	vote := new(governance.GovernanceVote)
+	if err := rlp.DecodeBytes(header.Vote, vote); err != nil {
+		return false
+	}
+	key, ok := governance.GovernanceKeyMap[vote.Key]
+	return ok && (key == params.AddValidator || key == params.RemoveValidator)
+}
+
+// sigHash returns the hash signed by the proposer of the header.
+func sigHash(header *types.Header) (hash common.Hash) {
+	hasher := sha3.NewKeccak256()
+	rlp.Encode(hasher, types.IstanbulFilteredHeader(header, false))
+	hasher.Sum(hash[:0])
+	return hash
+}
+
+// validatorAddrs returns the addresses of the validators in ascending order.
+func validatorAddrs(valSet istanbul.ValidatorSet) []common.Address {
+	addrs := make([]common.Address, 0, valSet.Size())
+	for _, val := range valSet.List() {
+		addrs = append(addrs, val.Address())
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	return addrs
+}
+
+func equalAddrs(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,349 @@
+package light
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/consensus/istanbul/backend"
+	istanbulCore "github.com/klaytn/klaytn/consensus/istanbul/core"
+	"github.com/klaytn/klaytn/consensus/istanbul/simulation"
+	"github.com/klaytn/klaytn/consensus/istanbul/validator"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/crypto/bls"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/governance"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testChain makes the headers signed by the validators of the given keys.
+type testChain struct {
+	t          *testing.T
+	config     *params.ChainConfig
+	keys       map[common.Address]*ecdsa.PrivateKey
+	checkpoint *Checkpoint
+}
+
+func newTestChain(t *testing.T, numValidators int, committeeSize uint64, blsSeal bool) *testChain {
+	keys := make(map[common.Address]*ecdsa.PrivateKey)
+	addrs := make([]common.Address, numValidators)
+	for i := range addrs {
+		key, _ := crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(key.PublicKey)
+		keys[addrs[i]] = key
+	}
+	addrs = sortedAddrs(addrs)
+
+	config := &params.ChainConfig{
+		Istanbul:   params.GetDefaultIstanbulConfig(),
+		Governance: params.GetDefaultGovernanceConfig(),
+	}
+	config.Istanbul.ProposerPolicy = uint64(istanbul.RoundRobin)
+	config.Istanbul.SubGroupSize = committeeSize
+	config.Governance.GovernanceMode = "single"
+	config.Governance.GoverningNode = addrs[0]
+	if blsSeal {
+		config.BlsSealCompatibleBlock = common.Big0
+	}
+	fork.SetHardForkBlockNumberConfig(config)
+	t.Cleanup(fork.ClearHardForkBlockNumberConfig)
+
+	chain := &testChain{t: t, config: config, keys: keys}
+	genesis := &types.Header{
+		Number:     common.Big0,
+		Time:       big.NewInt(1),
+		BlockScore: common.Big1,
+		Extra:      chain.extra(&types.IstanbulExtra{Validators: addrs, Seal: []byte{}, CommittedSeal: [][]byte{}}),
+	}
+	chain.checkpoint = &Checkpoint{
+		Header: genesis,
+		Snapshot: &backend.Snapshot{
+			Epoch:         config.Istanbul.Epoch,
+			Number:        0,
+			Hash:          genesis.Hash(),
+			ValSet:        validator.NewSubSet(addrs, istanbul.RoundRobin, committeeSize),
+			Policy:        config.Istanbul.ProposerPolicy,
+			CommitteeSize: committeeSize,
+			Votes:         []governance.GovernanceVote{},
+			Tally:         []governance.GovernanceTallyItem{},
+		},
+	}
+	return chain
+}
+
+func (c *testChain) extra(extra *types.IstanbulExtra) []byte {
+	payload, err := rlp.EncodeToBytes(extra)
+	require.NoError(c.t, err)
+	return append(make([]byte, types.IstanbulExtraVanity), payload...)
+}
+
+// makeHeader makes a child of the parent proposed by the proposer and committed by the committers.
+func (c *testChain) makeHeader(parent *types.Header, validators []common.Address, proposer common.Address, committers []common.Address, vote []byte) *types.Header {
+	return c.makeGovernanceHeader(parent, validators, proposer, committers, vote, nil)
+}
+
+// makeGovernanceHeader makes a header like makeHeader with the given governance changes.
+func (c *testChain) makeGovernanceHeader(parent *types.Header, validators []common.Address, proposer common.Address, committers []common.Address, vote []byte, governance []byte) *types.Header {
+	extra := &types.IstanbulExtra{Validators: validators, Seal: []byte{}, CommittedSeal: [][]byte{}}
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       new(big.Int).Add(parent.Time, common.Big1),
+		BlockScore: common.Big1,
+		Vote:       vote,
+		Governance: governance,
+		Extra:      c.extra(extra),
+	}
+
+	seal, err := crypto.Sign(crypto.Keccak256(sigHash(header).Bytes()), c.keys[proposer])
+	require.NoError(c.t, err)
+	extra.Seal = seal
+	header.Extra = c.extra(extra)
+
+	msg := istanbulCore.PrepareCommittedSeal(header.Hash())
+	if !c.config.IsBlsSealForkEnabled(header.Number) {
+		for _, addr := range committers {
+			committedSeal, err := crypto.Sign(crypto.Keccak256(msg), c.keys[addr])
+			require.NoError(c.t, err)
+			extra.CommittedSeal = append(extra.CommittedSeal, committedSeal)
+		}
+	} else {
+		blsMsg := crypto.Keccak256(msg)
+		var blsSeals [][]byte
+		for _, addr := range committers {
+			blsSeals = append(blsSeals, bls.Sign(c.blsKey(addr), blsMsg).Marshal())
+		}
+		aggregated, err := bls.AggregateCompressedSignatures(blsSeals)
+		require.NoError(c.t, err)
+		extra.AggregatedSeal = aggregated.Marshal()
+		extra.CommitterBitmap, err = types.NewCommitterBitmap(validators, committers)
+		require.NoError(c.t, err)
+	}
+	header.Extra = c.extra(extra)
+	return header
+}
+
+func (c *testChain) blsKey(addr common.Address) bls.SecretKey {
+	sk, err := bls.DeriveFromECDSA(c.keys[addr])
+	require.NoError(c.t, err)
+	return sk
+}
+
+func (c *testChain) blsPubkey(addr common.Address, number *big.Int) (bls.PublicKey, error) {
+	return c.blsKey(addr).PublicKey(), nil
+}
+
+func (c *testChain) committee(verifier *Verifier, proposer common.Address) []common.Address {
+	head := verifier.Head()
+	view := &istanbul.View{Sequence: new(big.Int).Add(head.Number, common.Big1), Round: common.Big0}
+	var committee []common.Address
+	for _, val := range verifier.snap.ValSet.SubListWithProposer(head.Hash(), proposer, view) {
+		committee = append(committee, val.Address())
+	}
+	return committee
+}
+
+func sortedAddrs(addrs []common.Address) []common.Address {
+	return validatorAddrs(validator.NewSubSet(addrs, istanbul.RoundRobin, uint64(len(addrs))))
+}
+
+func TestVerifier_Simulation(t *testing.T) {
+	sim, err := simulation.New(simulation.DefaultConfig)
+	require.NoError(t, err)
+	require.NoError(t, sim.Start())
+	defer sim.Stop()
+	require.NoError(t, sim.WaitForHeight(4, 30*time.Second))
+	sim.Stop()
+
+	// The checkpoint is retrieved by RPC from a full node
+	node := sim.Node(0)
+	genesis := node.Chain().Genesis().Header()
+	api := node.Engine().APIs(node.Chain())[0].Service.(*backend.API)
+	snap, err := api.GetSnapshotAtHash(genesis.Hash())
+	require.NoError(t, err)
+	encoded, err := json.Marshal(&Checkpoint{Header: genesis, Snapshot: snap})
+	require.NoError(t, err)
+	checkpoint := new(Checkpoint)
+	require.NoError(t, json.Unmarshal(encoded, checkpoint))
+
+	var headers []*types.Header
+	for i := uint64(1); i <= node.Height(); i++ {
+		headers = append(headers, node.Chain().GetHeaderByNumber(i))
+	}
+
+	verifier, err := NewVerifier(node.Chain().Config(), checkpoint, nil)
+	require.NoError(t, err)
+	n, err := verifier.VerifyHeaders(headers)
+	require.NoError(t, err)
+	assert.Equal(t, len(headers), n)
+	assert.Equal(t, headers[len(headers)-1].Hash(), verifier.Head().Hash())
+
+	// The header is not the child of the last verified header
+	assert.Equal(t, consensus.ErrUnknownAncestor, verifier.VerifyHeader(headers[0]))
+}
+
+func TestVerifier_CommittedSeals(t *testing.T) {
+	chain := newTestChain(t, 7, 4, false)
+	verifier, err := NewVerifier(chain.config, chain.checkpoint, nil)
+	require.NoError(t, err)
+
+	parent := chain.checkpoint.Header
+	validators := verifier.Validators()
+	proposer := validators[0]
+	committee := chain.committee(verifier, proposer)
+	require.Len(t, committee, 4)
+	var nonCommittee common.Address
+	for _, addr := range validators {
+		if !containsAddr(committee, addr) {
+			nonCommittee = addr
+		}
+	}
+
+	// Not signed by a validator
+	stranger, _ := crypto.GenerateKey()
+	chain.keys[crypto.PubkeyToAddress(stranger.PublicKey)] = stranger
+	header := chain.makeHeader(parent, validators, crypto.PubkeyToAddress(stranger.PublicKey), committee[:3], nil)
+	assert.Equal(t, ErrUnauthorized, verifier.VerifyHeader(header))
+
+	// Less than a quorum of the committee
+	header = chain.makeHeader(parent, validators, proposer, committee[:2], nil)
+	assert.Equal(t, ErrInsufficientCommittedSeals, verifier.VerifyHeader(header))
+
+	// Committed by a validator out of the committee
+	header = chain.makeHeader(parent, validators, proposer, append(committee[:2:2], nonCommittee), nil)
+	assert.Equal(t, ErrNotFromCommittee, verifier.VerifyHeader(header))
+
+	// Duplicated committed seals
+	header = chain.makeHeader(parent, validators, proposer, []common.Address{committee[0], committee[1], committee[1]}, nil)
+	assert.Equal(t, ErrNotFromCommittee, verifier.VerifyHeader(header))
+
+	// The validators in the header differ from the tracked validators
+	header = chain.makeHeader(parent, validators[1:], proposer, committee[:3], nil)
+	assert.Equal(t, ErrValidatorsMismatch, verifier.VerifyHeader(header))
+
+	// A quorum of the committee
+	header = chain.makeHeader(parent, validators, proposer, committee[:3], nil)
+	require.NoError(t, verifier.VerifyHeader(header))
+	assert.Equal(t, header.Hash(), verifier.Head().Hash())
+}
+
+func TestVerifier_Votes(t *testing.T) {
+	chain := newTestChain(t, 4, 21, false)
+	verifier, err := NewVerifier(chain.config, chain.checkpoint, nil)
+	require.NoError(t, err)
+
+	validators := verifier.Validators()
+	governingNode := chain.config.Governance.GoverningNode
+	newKey, _ := crypto.GenerateKey()
+	newAddr := crypto.PubkeyToAddress(newKey.PublicKey)
+	chain.keys[newAddr] = newKey
+
+	vote := func(key string, value interface{}) []byte {
+		encoded, err := rlp.EncodeToBytes(&governance.GovernanceVote{Validator: governingNode, Key: key, Value: value})
+		require.NoError(t, err)
+		return encoded
+	}
+
+	// The governing node adds a validator
+	header1 := chain.makeHeader(chain.checkpoint.Header, validators, governingNode, validators[:3], vote("governance.addvalidator", newAddr))
+	require.NoError(t, verifier.VerifyHeader(header1))
+	added := sortedAddrs(append(append([]common.Address{}, validators...), newAddr))
+	assert.Equal(t, added, verifier.Validators())
+
+	// The new validator commits the next block
+	header2 := chain.makeHeader(header1, added, governingNode, []common.Address{validators[0], validators[1], newAddr}, nil)
+	require.NoError(t, verifier.VerifyHeader(header2))
+
+	// A header with the previous validators is rejected
+	header3 := chain.makeHeader(header2, validators, governingNode, validators[:3], nil)
+	assert.Equal(t, ErrValidatorsMismatch, verifier.VerifyHeader(header3))
+
+	// The governing node removes the validator
+	header3 = chain.makeHeader(header2, added, governingNode, added[:4], vote("governance.removevalidator", newAddr))
+	require.NoError(t, verifier.VerifyHeader(header3))
+	assert.Equal(t, validators, verifier.Validators())
+}
+
+func TestVerifier_Governance(t *testing.T) {
+	chain := newTestChain(t, 4, 21, false)
+	chain.config.Istanbul.Epoch = 2
+	chain.checkpoint.Snapshot.Epoch = 2
+	verifier, err := NewVerifier(chain.config, chain.checkpoint, nil)
+	require.NoError(t, err)
+
+	var (
+		validators       = verifier.Validators()
+		proposer         = chain.config.Governance.GoverningNode
+		proposerInterval = params.ProposerUpdateInterval()
+	)
+	changes, err := json.Marshal(map[string]interface{}{
+		"istanbul.committeesize":        3,
+		"reward.proposerupdateinterval": proposerInterval + 1,
+	})
+	require.NoError(t, err)
+	governanceData, err := rlp.EncodeToBytes(changes)
+	require.NoError(t, err)
+
+	// The changes in the epoch block 2 take effect from the block 5 before the Kore fork
+	parent := chain.checkpoint.Header
+	for number := 1; number <= 4; number++ {
+		var data []byte
+		if number == 2 {
+			data = governanceData
+		}
+		parent = chain.makeGovernanceHeader(parent, validators, proposer, validators[:3], nil, data)
+		require.NoError(t, verifier.VerifyHeader(parent))
+		if number < 4 {
+			assert.Equal(t, uint64(21), verifier.snap.CommitteeSize)
+		}
+	}
+	assert.Equal(t, uint64(3), verifier.snap.CommitteeSize)
+
+	// The parameters are not applied to the globals
+	assert.Equal(t, proposerInterval, params.ProposerUpdateInterval())
+}
+
+func TestVerifier_AggregatedSeal(t *testing.T) {
+	chain := newTestChain(t, 4, 21, true)
+	validators := validatorAddrs(chain.checkpoint.Snapshot.ValSet)
+
+	// The aggregated seal can't be verified without the BLS public keys
+	verifier, err := NewVerifier(chain.config, chain.checkpoint, nil)
+	require.NoError(t, err)
+	header := chain.makeHeader(chain.checkpoint.Header, validators, validators[0], validators[:3], nil)
+	assert.Equal(t, ErrNoBlsPubkey, verifier.VerifyHeader(header))
+
+	verifier, err = NewVerifier(chain.config, chain.checkpoint, chain.blsPubkey)
+	require.NoError(t, err)
+	require.NoError(t, verifier.VerifyHeader(header))
+
+	// Less than a quorum of the validators
+	next := chain.makeHeader(header, validators, validators[1], validators[:2], nil)
+	assert.Equal(t, ErrInsufficientCommittedSeals, verifier.VerifyHeader(next))
+
+	// The bitmap doesn't match the signers of the aggregated seal
+	extra, err := types.ExtractIstanbulExtra(next)
+	require.NoError(t, err)
+	extra.CommitterBitmap, err = types.NewCommitterBitmap(validators, validators[1:])
+	require.NoError(t, err)
+	next.Extra = chain.extra(extra)
+	assert.Error(t, verifier.VerifyHeader(next))
+}
+
+func containsAddr(addrs []common.Address, addr common.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package istanbul
+
+import (
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+// MisbehaviourType is the kind of conflicting messages signed by a validator.
+type MisbehaviourType string
+
+const (
+	// DoubleProposal means a proposer signed preprepare messages of different
+	// proposals for the same view.
+	DoubleProposal MisbehaviourType = "doubleProposal"
+	// DoubleCommit means a validator signed commit messages of different
+	// proposals for the same view.
+	DoubleCommit MisbehaviourType = "doubleCommit"
+)
+
+// Misbehaviour is the evidence that a validator signed two conflicting
+// consensus messages for the same view. Messages are the signed consensus
+// messages as received, so that anyone can verify the signatures of them.
+type Misbehaviour struct {
+	Type      MisbehaviourType `json:"type"`
+	Validator common.Address   `json:"validator"`
+	Sequence  uint64           `json:"sequence"`
+	Round     uint64           `json:"round"`
+	Messages  [2]hexutil.Bytes `json:"messages"`
+	Time      uint64           `json:"time"` // unix time when the conflict is detected
+}
+
+// Hash returns the hash identifying the evidence. The detection time is not
+// included, so the same conflict has the same hash.
+func (m *Misbehaviour) Hash() common.Hash {
+	return RLPHash([]interface{}{m.Type, m.Validator, m.Sequence, m.Round, m.Messages})
+}
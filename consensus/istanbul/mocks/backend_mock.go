@@ -81,17 +81,17 @@ func (mr *MockBackendMockRecorder) CheckSignature(arg0, arg1, arg2 interface{})
 }
 
 // Commit mocks base method
-func (m *MockBackend) Commit(arg0 istanbul.Proposal, arg1 [][]byte) error {
+func (m *MockBackend) Commit(arg0 istanbul.Proposal, arg1 []common.Address, arg2 [][]byte) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Commit", arg0, arg1)
+	ret := m.ctrl.Call(m, "Commit", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Commit indicates an expected call of Commit
-func (mr *MockBackendMockRecorder) Commit(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockBackendMockRecorder) Commit(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockBackend)(nil).Commit), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockBackend)(nil).Commit), arg0, arg1, arg2)
 }
 
 // EventMux mocks base method
@@ -249,6 +249,32 @@ func (mr *MockBackendMockRecorder) ParentValidators(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParentValidators", reflect.TypeOf((*MockBackend)(nil).ParentValidators), arg0)
 }
 
+// ReadRoundState mocks base method
+func (m *MockBackend) ReadRoundState() []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadRoundState")
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// ReadRoundState indicates an expected call of ReadRoundState
+func (mr *MockBackendMockRecorder) ReadRoundState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadRoundState", reflect.TypeOf((*MockBackend)(nil).ReadRoundState))
+}
+
+// RecordMisbehaviour mocks base method
+func (m *MockBackend) RecordMisbehaviour(arg0 *istanbul.Misbehaviour) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordMisbehaviour", arg0)
+}
+
+// RecordMisbehaviour indicates an expected call of RecordMisbehaviour
+func (mr *MockBackendMockRecorder) RecordMisbehaviour(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordMisbehaviour", reflect.TypeOf((*MockBackend)(nil).RecordMisbehaviour), arg0)
+}
+
 // SetCurrentView mocks base method
 func (m *MockBackend) SetCurrentView(arg0 *istanbul.View) {
 	m.ctrl.T.Helper()
@@ -276,6 +302,21 @@ func (mr *MockBackendMockRecorder) Sign(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockBackend)(nil).Sign), arg0)
 }
 
+// SignCommittedSeal mocks base method
+func (m *MockBackend) SignCommittedSeal(arg0 istanbul.Proposal) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignCommittedSeal", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignCommittedSeal indicates an expected call of SignCommittedSeal
+func (mr *MockBackendMockRecorder) SignCommittedSeal(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignCommittedSeal", reflect.TypeOf((*MockBackend)(nil).SignCommittedSeal), arg0)
+}
+
 // Validators mocks base method
 func (m *MockBackend) Validators(arg0 istanbul.Proposal) istanbul.ValidatorSet {
 	m.ctrl.T.Helper()
@@ -304,3 +345,29 @@ func (mr *MockBackendMockRecorder) Verify(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockBackend)(nil).Verify), arg0)
 }
+
+// VerifyCommittedSeal mocks base method
+func (m *MockBackend) VerifyCommittedSeal(arg0 istanbul.Proposal, arg1 common.Address, arg2 []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyCommittedSeal", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyCommittedSeal indicates an expected call of VerifyCommittedSeal
+func (mr *MockBackendMockRecorder) VerifyCommittedSeal(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyCommittedSeal", reflect.TypeOf((*MockBackend)(nil).VerifyCommittedSeal), arg0, arg1, arg2)
+}
+
+// WriteRoundState mocks base method
+func (m *MockBackend) WriteRoundState(arg0 []byte) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "WriteRoundState", arg0)
+}
+
+// WriteRoundState indicates an expected call of WriteRoundState
+func (mr *MockBackendMockRecorder) WriteRoundState(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteRoundState", reflect.TypeOf((*MockBackend)(nil).WriteRoundState), arg0)
+}
@@ -0,0 +1,44 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package simulation runs a network of Istanbul validators in a single process.
+
+Each validator runs the Istanbul backend and a blockchain on the memory database,
+and produces empty blocks like the worker of a consensus node. The validators
+exchange the consensus messages and the committed blocks through an in-memory
+network instead of the p2p protocol, so that the faults of the network and the
+validators can be injected:
+  - message delay with jitter and random message drop
+  - partitions and cut links between the nodes
+  - interceptors which can modify, drop or inject the messages sent by a node
+  - twins, the nodes signing with the same key as a validator to equivocate
+  - clock skew of each node
+
+After or while running the simulation, the safety (no conflicting blocks are
+committed at the same height) and the liveness (block heights progress) can be
+checked. The package is used by the tests of Istanbul and the `istsim` command.
+
+Note that the round change timeout of Istanbul is a global variable, so the
+simulations in a process share it.
+
+# Source Files
+
+  - `network.go`: Implements the in-memory network delivering messages and blocks between the nodes with the faults
+  - `node.go`: Defines Node which runs an Istanbul backend and a blockchain, and produces blocks
+  - `simulation.go`: Defines Simulation which creates the nodes and checks the safety and the liveness
+*/
+package simulation
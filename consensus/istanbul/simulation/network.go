@@ -0,0 +1,253 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/networks/p2p"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// Message is a message sent from a node to another through the network.
+type Message struct {
+	From    int    // index of the sender node
+	To      int    // index of the receiver node
+	Code    uint64 // message code, istanbul backend.IstanbulMsg for the consensus messages
+	Payload []byte // rlp-encoded message, istanbul.ConsensusMsg for the consensus messages
+}
+
+// Interceptor is called for every message sent by a node. It returns the
+// messages to be delivered instead, so that it can modify or drop the message
+// and inject other messages. Returning nil drops the message.
+type Interceptor func(msg *Message) []*Message
+
+// SetDelay sets the delay of delivering messages and blocks. Each delivery is
+// delayed by delay plus a random duration less than jitter.
+func (sim *Simulation) SetDelay(delay, jitter time.Duration) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.delay, sim.jitter = delay, jitter
+}
+
+// SetDropRate sets the probability of dropping a message or a block.
+func (sim *Simulation) SetDropRate(rate float64) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.dropRate = rate
+}
+
+// SetInterceptor sets the interceptor of the messages sent by the node.
+// A nil interceptor removes it.
+func (sim *Simulation) SetInterceptor(node int, interceptor Interceptor) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	if interceptor == nil {
+		delete(sim.interceptors, node)
+		return
+	}
+	sim.interceptors[node] = interceptor
+}
+
+// Partition cuts the links between the nodes of different groups. The nodes
+// not in any group are isolated from all the others.
+func (sim *Simulation) Partition(groups ...[]int) {
+	group := make(map[int]int)
+	for i, nodes := range groups {
+		for _, node := range nodes {
+			group[node] = i
+		}
+	}
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	for a := range sim.nodes {
+		for b := range sim.nodes {
+			ga, okA := group[a]
+			gb, okB := group[b]
+			if a != b && (!okA || !okB || ga != gb) {
+				sim.cutLinks[link{a, b}] = true
+			}
+		}
+	}
+}
+
+// Disconnect cuts the link between two nodes in both directions.
+func (sim *Simulation) Disconnect(a, b int) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.cutLinks[link{a, b}] = true
+	sim.cutLinks[link{b, a}] = true
+}
+
+// Heal restores all the cut links.
+func (sim *Simulation) Heal() {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.cutLinks = make(map[link]bool)
+}
+
+// link is a directed link between two nodes.
+type link struct {
+	from, to int
+}
+
+// transmit returns the delay of delivering a message from a node to another,
+// or false if the message is lost.
+func (sim *Simulation) transmit(from, to int) (time.Duration, bool) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	if sim.cutLinks[link{from, to}] || sim.rand.Float64() < sim.dropRate {
+		return 0, false
+	}
+	delay := sim.delay
+	if sim.jitter > 0 {
+		delay += time.Duration(sim.rand.Int63n(int64(sim.jitter)))
+	}
+	return delay, true
+}
+
+// send delivers a message from a node to the nodes of the given address,
+// which are more than one if the validator has twins.
+func (sim *Simulation) send(from *Node, to common.Address, code uint64, data interface{}) error {
+	payload, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return err
+	}
+
+	sim.mu.RLock()
+	interceptor := sim.interceptors[from.index]
+	sim.mu.RUnlock()
+
+	for _, node := range sim.nodes {
+		if node == from || node.address != to {
+			continue
+		}
+		msgs := []*Message{{From: from.index, To: node.index, Code: code, Payload: payload}}
+		if interceptor != nil {
+			msgs = interceptor(msgs[0])
+		}
+		for _, msg := range msgs {
+			sim.deliver(msg)
+		}
+	}
+	return nil
+}
+
+// deliver delivers a message to the receiver after the delay of the link.
+func (sim *Simulation) deliver(msg *Message) {
+	if msg.From < 0 || msg.From >= len(sim.nodes) || msg.To < 0 || msg.To >= len(sim.nodes) {
+		logger.Warn("Dropped a message of unknown nodes", "from", msg.From, "to", msg.To)
+		return
+	}
+	delay, ok := sim.transmit(msg.From, msg.To)
+	if !ok {
+		atomic.AddInt64(&sim.dropped, 1)
+		return
+	}
+	from, to := sim.nodes[msg.From], sim.nodes[msg.To]
+	time.AfterFunc(delay, func() {
+		to.handleMsg(from.address, msg.Code, msg.Payload)
+	})
+}
+
+// propagate delivers a block inserted by a node to the other nodes, which
+// fetch the missing ancestors from the node like the block synchronization.
+func (sim *Simulation) propagate(from *Node, block *types.Block) {
+	for _, to := range sim.nodes {
+		if to == from {
+			continue
+		}
+		delay, ok := sim.transmit(from.index, to.index)
+		if !ok {
+			atomic.AddInt64(&sim.dropped, 1)
+			continue
+		}
+		to := to
+		time.AfterFunc(delay, func() {
+			to.enqueue(block, from)
+		})
+	}
+}
+
+// peer is the connection of a node to the nodes of an address.
+type peer struct {
+	sim  *Simulation
+	from *Node
+	to   common.Address
+}
+
+// Send implements consensus.Peer.Send
+func (p *peer) Send(msgcode uint64, data interface{}) error {
+	return p.sim.send(p.from, p.to, msgcode, data)
+}
+
+// RegisterConsensusMsgCode implements consensus.Peer.RegisterConsensusMsgCode
+func (p *peer) RegisterConsensusMsgCode(msgCode uint64) error {
+	return nil
+}
+
+// broadcaster is the consensus.Broadcaster of a node connected to all the
+// other validators through the network.
+type broadcaster struct {
+	sim  *Simulation
+	node *Node
+}
+
+// Enqueue implements consensus.Broadcaster.Enqueue
+func (b *broadcaster) Enqueue(id string, block *types.Block) {
+	b.node.enqueue(block, nil)
+}
+
+// FindPeers implements consensus.Broadcaster.FindPeers
+func (b *broadcaster) FindPeers(targets map[common.Address]bool) map[common.Address]consensus.Peer {
+	return b.FindCNPeers(targets)
+}
+
+// FindCNPeers implements consensus.Broadcaster.FindCNPeers
+func (b *broadcaster) FindCNPeers(targets map[common.Address]bool) map[common.Address]consensus.Peer {
+	peers := make(map[common.Address]consensus.Peer)
+	for addr, p := range b.GetCNPeers() {
+		if targets[addr] {
+			peers[addr] = p
+		}
+	}
+	return peers
+}
+
+// GetCNPeers implements consensus.Broadcaster.GetCNPeers
+func (b *broadcaster) GetCNPeers() map[common.Address]consensus.Peer {
+	peers := make(map[common.Address]consensus.Peer)
+	for _, node := range b.sim.nodes {
+		if node.address != b.node.address {
+			peers[node.address] = &peer{sim: b.sim, from: b.node, to: node.address}
+		}
+	}
+	return peers
+}
+
+// GetENPeers implements consensus.Broadcaster.GetENPeers
+func (b *broadcaster) GetENPeers() map[common.Address]consensus.Peer {
+	return nil
+}
+
+// RegisterValidator implements consensus.Broadcaster.RegisterValidator
+func (b *broadcaster) RegisterValidator(conType common.ConnType, validator p2p.PeerTypeValidator) {}
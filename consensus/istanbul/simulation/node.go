@@ -0,0 +1,381 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/consensus/istanbul/backend"
+	"github.com/klaytn/klaytn/consensus/misc"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/crypto/bls"
+	"github.com/klaytn/klaytn/governance"
+	"github.com/klaytn/klaytn/networks/p2p"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+// importChanSize is the size of the channel queueing the blocks to be inserted.
+const importChanSize = 256
+
+// blockImport is a block to be inserted and the node which sent it.
+type blockImport struct {
+	block *types.Block
+	from  *Node // nil if the block is committed by the node itself
+}
+
+// Node is a validator of the simulation running an Istanbul backend and a
+// blockchain on the memory database.
+type Node struct {
+	index   int
+	sim     *Simulation
+	address common.Address
+
+	db      database.DBManager
+	chain   *blockchain.BlockChain
+	engine  consensus.Istanbul
+	handler consensus.Handler
+
+	clockSkew int64 // time.Duration added to the local clock, accessed atomically
+
+	committedMu sync.RWMutex
+	committed   map[uint64][]common.Hash // hashes of the blocks inserted to the canonical chain by height
+
+	importCh chan blockImport
+	started  int32 // accessed atomically
+	quit     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// staticBlsPubkeyProvider provides the BLS public keys of the validators
+// without the KIP-113 contract.
+type staticBlsPubkeyProvider map[common.Address]bls.PublicKey
+
+func newStaticBlsPubkeyProvider(keys []*ecdsa.PrivateKey) (staticBlsPubkeyProvider, error) {
+	pubkeys := make(staticBlsPubkeyProvider, len(keys))
+	for _, key := range keys {
+		blsKey, err := bls.DeriveFromECDSA(key)
+		if err != nil {
+			return nil, err
+		}
+		pubkeys[crypto.PubkeyToAddress(key.PublicKey)] = blsKey.PublicKey()
+	}
+	return pubkeys, nil
+}
+
+func (p staticBlsPubkeyProvider) GetBlsPubkey(chain consensus.ChainReader, proposer common.Address, num *big.Int) (bls.PublicKey, error) {
+	if pub, ok := p[proposer]; ok {
+		return pub, nil
+	}
+	return nil, errNoBlsPubkey
+}
+
+func (p staticBlsPubkeyProvider) ResetBlsCache() {}
+
+// newNode creates a node signing with the given key and initializes the
+// blockchain with the genesis block.
+func newNode(sim *Simulation, index int, key *ecdsa.PrivateKey, genesis *blockchain.Genesis, blsPubkeys backend.BlsPubkeyProvider) (*Node, error) {
+	var (
+		config = genesis.Config
+		db     = database.NewMemoryDBManager()
+		gov    = governance.NewMixedEngine(config, db)
+	)
+	genesis.MustCommit(db)
+
+	blsKey, err := bls.DeriveFromECDSA(key)
+	if err != nil {
+		return nil, err
+	}
+	// Each node has its own rewardbase, so that twins propose different blocks.
+	rewardbaseKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &Node{
+		index:     index,
+		sim:       sim,
+		address:   crypto.PubkeyToAddress(key.PublicKey),
+		db:        db,
+		committed: make(map[uint64][]common.Hash),
+		importCh:  make(chan blockImport, importChanSize),
+		quit:      make(chan struct{}),
+	}
+	node.engine = backend.New(&backend.BackendOpts{
+		IstanbulConfig: &istanbul.Config{
+			Timeout:        sim.config.Timeout,
+			BlockPeriod:    sim.config.BlockPeriod,
+			ProposerPolicy: istanbul.ProposerPolicy(config.Istanbul.ProposerPolicy),
+			Epoch:          config.Istanbul.Epoch,
+			SubGroupSize:   config.Istanbul.SubGroupSize,
+		},
+		Rewardbase:        crypto.PubkeyToAddress(rewardbaseKey.PublicKey),
+		PrivateKey:        key,
+		BlsSecretKey:      blsKey,
+		BlsPubkeyProvider: blsPubkeys,
+		DB:                db,
+		Governance:        gov,
+		NodeType:          common.CONSENSUSNODE,
+		Clock:             node.now,
+	})
+	node.handler = node.engine.(consensus.Handler)
+	gov.SetNodeAddress(node.address)
+
+	cacheConfig := &blockchain.CacheConfig{
+		ArchiveMode:       false,
+		CacheSize:         512,
+		BlockInterval:     blockchain.DefaultBlockInterval,
+		TriesInMemory:     blockchain.DefaultTriesInMemory,
+		SnapshotCacheSize: 0, // Disable state snapshot
+	}
+	node.chain, err = blockchain.NewBlockChain(db, cacheConfig, config, node.engine, vm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	gov.SetBlockchain(node.chain)
+	node.handler.SetBroadcaster(&broadcaster{sim: sim, node: node}, common.CONSENSUSNODE)
+	return node, nil
+}
+
+// Index returns the index of the node in the simulation.
+func (n *Node) Index() int {
+	return n.index
+}
+
+// Address returns the validator address of the node.
+func (n *Node) Address() common.Address {
+	return n.address
+}
+
+// Chain returns the blockchain of the node.
+func (n *Node) Chain() *blockchain.BlockChain {
+	return n.chain
+}
+
+// Engine returns the Istanbul engine of the node.
+func (n *Node) Engine() consensus.Istanbul {
+	return n.engine
+}
+
+// Height returns the number of the current block of the node.
+func (n *Node) Height() uint64 {
+	return n.chain.CurrentBlock().NumberU64()
+}
+
+// SetClockSkew sets the difference of the local clock of the node from the
+// system clock.
+func (n *Node) SetClockSkew(skew time.Duration) {
+	atomic.StoreInt64(&n.clockSkew, int64(skew))
+}
+
+// now returns the current time of the local clock of the node.
+func (n *Node) now() time.Time {
+	return time.Now().Add(time.Duration(atomic.LoadInt64(&n.clockSkew)))
+}
+
+// Misbehaviours returns the evidences of conflicting consensus messages
+// detected by the node.
+func (n *Node) Misbehaviours() []*istanbul.Misbehaviour {
+	var misbehaviours []*istanbul.Misbehaviour
+	for _, blob := range n.db.ReadIstanbulMisbehaviours(0, 0) {
+		m := new(istanbul.Misbehaviour)
+		if err := rlp.DecodeBytes(blob, m); err != nil {
+			logger.Error("Failed to decode misbehaviour", "node", n.index, "err", err)
+			continue
+		}
+		misbehaviours = append(misbehaviours, m)
+	}
+	return misbehaviours
+}
+
+// Committed returns the hashes of the blocks inserted to the canonical chain
+// at the given height. It has more than one hash if the chain is reorganized.
+func (n *Node) Committed(height uint64) []common.Hash {
+	n.committedMu.RLock()
+	defer n.committedMu.RUnlock()
+	return append([]common.Hash(nil), n.committed[height]...)
+}
+
+// start starts the engine and the block production of the node.
+func (n *Node) start() error {
+	if err := n.engine.Start(n.chain, n.chain.CurrentBlock, n.chain.HasBadBlock); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&n.started, 1)
+
+	n.wg.Add(2)
+	go n.loop()
+	go n.importLoop()
+	return nil
+}
+
+// stop stops the block production, the engine and the blockchain of the node.
+func (n *Node) stop() {
+	if atomic.CompareAndSwapInt32(&n.started, 1, 0) {
+		close(n.quit)
+		n.wg.Wait()
+		n.engine.Stop()
+	}
+	n.chain.Stop()
+}
+
+// handleMsg handles a message delivered by the network.
+func (n *Node) handleMsg(from common.Address, code uint64, payload []byte) {
+	if atomic.LoadInt32(&n.started) == 0 {
+		return
+	}
+	msg := p2p.Msg{Code: code, Size: uint32(len(payload)), Payload: bytes.NewReader(payload), ReceivedAt: time.Now()}
+	if _, err := n.handler.HandleMsg(from, msg); err != nil {
+		logger.Trace("Failed to handle message", "node", n.index, "from", from, "err", err)
+	}
+}
+
+// enqueue queues a block to be inserted.
+func (n *Node) enqueue(block *types.Block, from *Node) {
+	select {
+	case n.importCh <- blockImport{block: block, from: from}:
+	case <-n.quit:
+	}
+}
+
+// loop produces a block on every new chain head like the worker of a
+// consensus node, and propagates the inserted blocks to the other nodes.
+func (n *Node) loop() {
+	defer n.wg.Done()
+
+	var (
+		chainCh      = make(chan blockchain.ChainEvent, 16)
+		chainHeadCh  = make(chan blockchain.ChainHeadEvent, 16)
+		chainSub     = n.chain.SubscribeChainEvent(chainCh)
+		chainHeadSub = n.chain.SubscribeChainHeadEvent(chainHeadCh)
+		resultCh     = make(chan *types.Block, 1)
+		stop         chan struct{}
+	)
+	defer chainSub.Unsubscribe()
+	defer chainHeadSub.Unsubscribe()
+
+	commitNewWork := func() {
+		if stop != nil {
+			close(stop)
+		}
+		stop = make(chan struct{})
+
+		block, err := n.newBlock()
+		if err != nil {
+			logger.Error("Failed to make a block", "node", n.index, "err", err)
+			return
+		}
+		go func(stop chan struct{}) {
+			result, err := n.engine.Seal(n.chain, block, stop)
+			if err != nil {
+				logger.Error("Failed to seal a block", "node", n.index, "number", block.Number(), "err", err)
+				return
+			}
+			if result != nil {
+				select {
+				case resultCh <- result:
+				case <-stop:
+				}
+			}
+		}(stop)
+	}
+	commitNewWork()
+
+	for {
+		select {
+		case ev := <-chainCh:
+			n.committedMu.Lock()
+			n.committed[ev.Block.NumberU64()] = append(n.committed[ev.Block.NumberU64()], ev.Hash)
+			n.committedMu.Unlock()
+			n.sim.propagate(n, ev.Block)
+
+		case <-chainHeadCh:
+			n.handler.NewChainHead()
+			commitNewWork()
+
+		case block := <-resultCh:
+			n.enqueue(block, nil)
+
+		case <-n.quit:
+			close(stop)
+			return
+		}
+	}
+}
+
+// newBlock makes an empty block on top of the current block.
+func (n *Node) newBlock() (*types.Block, error) {
+	parent := n.chain.CurrentBlock()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+	}
+	if config := n.chain.Config(); config.IsMagmaForkEnabled(header.Number) {
+		header.BaseFee = misc.NextMagmaBlockBaseFee(parent.Header(), config.Governance.KIP71)
+	}
+	if err := n.engine.Prepare(n.chain, header); err != nil {
+		return nil, err
+	}
+	state, err := n.chain.StateAt(parent.Root())
+	if err != nil {
+		return nil, err
+	}
+	return n.engine.Finalize(n.chain, header, state, nil, nil)
+}
+
+// importLoop inserts the queued blocks to the blockchain.
+func (n *Node) importLoop() {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case imp := <-n.importCh:
+			n.insert(imp.block, imp.from)
+		case <-n.quit:
+			return
+		}
+	}
+}
+
+// insert inserts a block with the missing ancestors fetched from the node
+// which sent the block.
+func (n *Node) insert(block *types.Block, from *Node) {
+	if n.chain.HasBlock(block.Hash(), block.NumberU64()) {
+		return
+	}
+	blocks := types.Blocks{block}
+	for parent := block; from != nil && !n.chain.HasBlock(parent.ParentHash(), parent.NumberU64()-1); {
+		if parent = from.chain.GetBlock(parent.ParentHash(), parent.NumberU64()-1); parent == nil {
+			return
+		}
+		blocks = append(types.Blocks{parent}, blocks...)
+	}
+	if _, err := n.chain.InsertChain(blocks); err != nil {
+		logger.Debug("Failed to insert blocks", "node", n.index, "number", block.Number(), "hash", block.Hash(), "err", err)
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+var logger = log.NewModuleLogger(log.ConsensusIstanbulSimulation)
+
+var (
+	errNoValidators   = errors.New("no validators")
+	errInvalidTwin    = errors.New("twin of unknown validator")
+	errAlreadyStarted = errors.New("simulation already started")
+	errNoBlsPubkey    = errors.New("bls pubkey not found")
+)
+
+// Config is the configuration of a simulation.
+type Config struct {
+	NumValidators int    // The number of validators
+	Twins         []int  // The indexes of the validators having a twin node which signs with the same key
+	BlockPeriod   uint64 // The minimum difference between two consecutive block's timestamps in second
+	Timeout       uint64 // The timeout for each Istanbul round in milliseconds. It is set to the global istanbul.DefaultConfig.
+	Seed          int64  // The seed of the random delays and drops
+	BlsSeal       bool   // Enable the hardforks up to BlsSeal from the genesis, so that the committed seals are aggregated
+}
+
+// DefaultConfig is the default configuration of a simulation.
+var DefaultConfig = Config{
+	NumValidators: 4,
+	BlockPeriod:   1,
+	Timeout:       3000,
+	Seed:          1,
+}
+
+// Simulation is a network of Istanbul validators running in a process.
+// The nodes 0 to NumValidators-1 are the validators and the following nodes
+// are the twins of the validators given in the config.
+type Simulation struct {
+	config Config
+	nodes  []*Node
+
+	mu           sync.RWMutex // protects the network faults below
+	delay        time.Duration
+	jitter       time.Duration
+	dropRate     float64
+	cutLinks     map[link]bool
+	interceptors map[int]Interceptor
+	rand         *rand.Rand
+
+	dropped     int64 // the number of lost messages and blocks, accessed atomically
+	started     int32 // accessed atomically
+	prevTimeout uint64
+}
+
+// New creates a simulation with the validators of the given config sharing a
+// genesis block.
+func New(config Config) (*Simulation, error) {
+	if config.NumValidators <= 0 {
+		return nil, errNoValidators
+	}
+	sim := &Simulation{
+		config:       config,
+		cutLinks:     make(map[link]bool),
+		interceptors: make(map[int]Interceptor),
+		rand:         rand.New(rand.NewSource(config.Seed)),
+	}
+
+	keys := make([]*ecdsa.PrivateKey, config.NumValidators)
+	addrs := make([]common.Address, config.NumValidators)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		keys[i], addrs[i] = key, crypto.PubkeyToAddress(key.PublicKey)
+	}
+	for _, twin := range config.Twins {
+		if twin < 0 || twin >= config.NumValidators {
+			return nil, errInvalidTwin
+		}
+		keys = append(keys, keys[twin])
+	}
+
+	extra, err := genesisExtra(addrs)
+	if err != nil {
+		return nil, err
+	}
+	chainConfig := &params.ChainConfig{
+		Istanbul:   params.GetDefaultIstanbulConfig(),
+		Governance: params.GetDefaultGovernanceConfig(),
+	}
+	chainConfig.Istanbul.ProposerPolicy = uint64(istanbul.RoundRobin)
+	if config.BlsSeal {
+		enableBlsSeal(chainConfig)
+	}
+	timestamp := uint64(time.Now().Unix())
+
+	blsPubkeys, err := newStaticBlsPubkeyProvider(keys)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		genesis := &blockchain.Genesis{
+			Config:     chainConfig,
+			Timestamp:  timestamp,
+			ExtraData:  extra,
+			BlockScore: big.NewInt(1),
+			Alloc:      blockchain.GenesisAlloc{},
+		}
+		node, err := newNode(sim, i, key, genesis, blsPubkeys)
+		if err != nil {
+			sim.Stop()
+			return nil, err
+		}
+		sim.nodes = append(sim.nodes, node)
+	}
+	return sim, nil
+}
+
+// enableBlsSeal enables the hardforks required by BlsSeal and BlsSeal itself from the genesis.
+func enableBlsSeal(config *params.ChainConfig) {
+	config.IstanbulCompatibleBlock = common.Big0
+	config.LondonCompatibleBlock = common.Big0
+	config.EthTxTypeCompatibleBlock = common.Big0
+	config.MagmaCompatibleBlock = common.Big0
+	config.KoreCompatibleBlock = common.Big0
+	config.ShanghaiCompatibleBlock = common.Big0
+	config.CancunCompatibleBlock = common.Big0
+	config.RandaoCompatibleBlock = common.Big0
+	config.RandaoRegistry = &params.RegistryConfig{}
+	config.BlsSealCompatibleBlock = common.Big0
+}
+
+// genesisExtra returns the extra data of the genesis block with the validators.
+func genesisExtra(addrs []common.Address) ([]byte, error) {
+	extra, err := rlp.EncodeToBytes(&types.IstanbulExtra{
+		Validators:    addrs,
+		Seal:          []byte{},
+		CommittedSeal: [][]byte{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(make([]byte, types.IstanbulExtraVanity), extra...), nil
+}
+
+// Start sets the round change timeout and starts all the nodes.
+func (sim *Simulation) Start() error {
+	if !atomic.CompareAndSwapInt32(&sim.started, 0, 1) {
+		return errAlreadyStarted
+	}
+	sim.prevTimeout = atomic.SwapUint64(&istanbul.DefaultConfig.Timeout, sim.config.Timeout)
+	for _, node := range sim.nodes {
+		if err := node.start(); err != nil {
+			return err
+		}
+	}
+	logger.Info("Started Istanbul simulation", "validators", sim.config.NumValidators, "twins", sim.config.Twins)
+	return nil
+}
+
+// Stop stops all the nodes and restores the round change timeout.
+func (sim *Simulation) Stop() {
+	for _, node := range sim.nodes {
+		node.stop()
+	}
+	if atomic.CompareAndSwapInt32(&sim.started, 1, 0) {
+		atomic.StoreUint64(&istanbul.DefaultConfig.Timeout, sim.prevTimeout)
+	}
+}
+
+// Nodes returns all the nodes including the twins.
+func (sim *Simulation) Nodes() []*Node {
+	return sim.nodes
+}
+
+// Node returns the node of the given index.
+func (sim *Simulation) Node(index int) *Node {
+	return sim.nodes[index]
+}
+
+// Dropped returns the number of the messages and blocks lost in the network.
+func (sim *Simulation) Dropped() int64 {
+	return atomic.LoadInt64(&sim.dropped)
+}
+
+// Heights returns the current block numbers of the nodes.
+func (sim *Simulation) Heights() []uint64 {
+	heights := make([]uint64, len(sim.nodes))
+	for i, node := range sim.nodes {
+		heights[i] = node.Height()
+	}
+	return heights
+}
+
+// WaitForHeight waits until the given nodes, or all the nodes if not given,
+// reach the height. It returns an error if they don't in the timeout.
+func (sim *Simulation) WaitForHeight(height uint64, timeout time.Duration, nodes ...int) error {
+	if len(nodes) == 0 {
+		for i := range sim.nodes {
+			nodes = append(nodes, i)
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		reached := true
+		for _, i := range nodes {
+			if sim.nodes[i].Height() < height {
+				reached = false
+				break
+			}
+		}
+		if reached {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("height %d not reached in %v, heights: %v", height, timeout, sim.Heights())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// CheckSafety returns an error if different blocks have been inserted to the
+// canonical chains of the nodes at the same height.
+func (sim *Simulation) CheckSafety() error {
+	var maxHeight uint64
+	for _, node := range sim.nodes {
+		if h := node.Height(); h > maxHeight {
+			maxHeight = h
+		}
+	}
+	for height := uint64(1); height <= maxHeight; height++ {
+		var (
+			first     common.Hash
+			firstNode int
+		)
+		for _, node := range sim.nodes {
+			for _, hash := range node.Committed(height) {
+				if common.EmptyHash(first) {
+					first, firstNode = hash, node.index
+				} else if hash != first {
+					return fmt.Errorf("conflicting blocks at height %d: %v by node %d, %v by node %d",
+						height, first.String(), firstNode, hash.String(), node.index)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSimulation(t *testing.T, config Config) *Simulation {
+	sim, err := New(config)
+	require.NoError(t, err)
+	require.NoError(t, sim.Start())
+	t.Cleanup(sim.Stop)
+	return sim
+}
+
+func TestSimulation_Progress(t *testing.T) {
+	sim := newTestSimulation(t, DefaultConfig)
+	sim.SetDelay(10*time.Millisecond, 20*time.Millisecond)
+
+	require.NoError(t, sim.WaitForHeight(5, 20*time.Second))
+	assert.NoError(t, sim.CheckSafety())
+}
+
+func TestSimulation_Faults(t *testing.T) {
+	sim := newTestSimulation(t, DefaultConfig)
+	sim.SetDelay(10*time.Millisecond, 50*time.Millisecond)
+	sim.SetDropRate(0.05)
+	sim.Node(1).SetClockSkew(500 * time.Millisecond)
+
+	// A silent validator doesn't stop the others having a quorum
+	sim.SetInterceptor(3, func(msg *Message) []*Message { return nil })
+
+	require.NoError(t, sim.WaitForHeight(5, 60*time.Second))
+	assert.NoError(t, sim.CheckSafety())
+	assert.NotZero(t, sim.Dropped())
+}
+
+func TestSimulation_Partition(t *testing.T) {
+	sim := newTestSimulation(t, DefaultConfig)
+	require.NoError(t, sim.WaitForHeight(2, 20*time.Second))
+
+	// No group has a quorum, so the nodes stop once the blocks in flight are
+	// committed, and don't progress even after the round changes
+	sim.Partition([]int{0, 1}, []int{2, 3})
+	heights := sim.Heights()
+	require.Eventually(t, func() bool {
+		prev := heights
+		heights = sim.Heights()
+		return assert.ObjectsAreEqual(prev, heights)
+	}, 20*time.Second, 2*time.Second)
+	assert.Never(t, func() bool {
+		return !assert.ObjectsAreEqual(heights, sim.Heights())
+	}, 2*time.Duration(DefaultConfig.Timeout)*time.Millisecond, 100*time.Millisecond)
+
+	// The progress resumes after the partition is healed
+	sim.Heal()
+	var max uint64
+	for _, h := range heights {
+		if h > max {
+			max = h
+		}
+	}
+	require.NoError(t, sim.WaitForHeight(max+2, 60*time.Second))
+	assert.NoError(t, sim.CheckSafety())
+}
+
+func TestSimulation_Twins(t *testing.T) {
+	config := DefaultConfig
+	config.Twins = []int{0}
+	sim := newTestSimulation(t, config)
+
+	// The validator 0 and its twin send conflicting messages to the validator 2
+	twin := sim.Node(config.NumValidators)
+	require.Equal(t, sim.Node(0).Address(), twin.Address())
+	sim.Disconnect(0, 3)
+	sim.Disconnect(twin.Index(), 1)
+
+	require.NoError(t, sim.WaitForHeight(6, 60*time.Second))
+	assert.NoError(t, sim.CheckSafety())
+
+	// The conflicting messages may not meet in a round, so wait until they do
+	var misbehaviours []*istanbul.Misbehaviour
+	require.Eventually(t, func() bool {
+		misbehaviours = nil
+		for _, node := range sim.Nodes() {
+			misbehaviours = append(misbehaviours, node.Misbehaviours()...)
+		}
+		return len(misbehaviours) > 0
+	}, 60*time.Second, 50*time.Millisecond)
+	for _, m := range misbehaviours {
+		assert.Equal(t, twin.Address(), m.Validator)
+	}
+	assert.NoError(t, sim.CheckSafety())
+}
+
+func TestSimulation_BlsSeal(t *testing.T) {
+	config := DefaultConfig
+	config.BlsSeal = true
+	sim := newTestSimulation(t, config)
+	sim.SetDelay(10*time.Millisecond, 20*time.Millisecond)
+
+	require.NoError(t, sim.WaitForHeight(5, 30*time.Second))
+	assert.NoError(t, sim.CheckSafety())
+
+	// The committed seals are aggregated
+	for _, node := range sim.Nodes() {
+		header := node.Chain().CurrentHeader()
+		extra, err := types.ExtractIstanbulExtra(header)
+		require.NoError(t, err)
+		assert.Empty(t, extra.CommittedSeal)
+		assert.NotEmpty(t, extra.AggregatedSeal)
+		committers, err := extra.Committers()
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, len(committers), 3)
+	}
+}
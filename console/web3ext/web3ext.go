@@ -29,6 +29,7 @@ var Modules = map[string]string{
 	"personal":         Personal_JS,
 	"rpc":              RPC_JS,
 	"txpool":           TxPool_JS,
+	"trace":            Trace_JS,
 	"istanbul":         Istanbul_JS,
 	"mainbridge":       MainBridge_JS,
 	"subbridge":        SubBridge_JS,
@@ -135,6 +136,12 @@ web3._extend({
 			params: 3,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'simulateV1',
+			call: 'eth_simulateV1',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter],
+		}),
 		new web3._extend.Method({
 			name: 'createAccessList',
 			call: 'eth_createAccessList',
@@ -1069,6 +1076,12 @@ var klayMethods = [
 		call: 'klay_decodeAccountKey',
 		params: 1,
 	}),
+	new web3._extend.Method({
+		name: 'simulateV1',
+		call: 'klay_simulateV1',
+		params: 2,
+		inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter],
+	}),
 	new web3._extend.Method({
 		name: 'createAccessList',
 		call: 'klay_createAccessList',
@@ -1256,10 +1269,59 @@ web3._extend({
 });
 `
 
+const Trace_JS = `
+web3._extend({
+	property: 'trace',
+	methods: [
+		new web3._extend.Method({
+			name: 'block',
+			call: 'trace_block',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'transaction',
+			call: 'trace_transaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'filter',
+			call: 'trace_filter',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'call',
+			call: 'trace_call',
+			params: 3,
+			inputFormatter: [null, null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'replayTransaction',
+			call: 'trace_replayTransaction',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'replayBlockTransactions',
+			call: 'trace_replayBlockTransactions',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+	],
+	properties: []
+});
+`
+
 const TxPool_JS = `
 web3._extend({
 	property: 'txpool',
-	methods: [],
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'getTxStatus',
+			call: 'txpool_status',
+			params: 1
+		}),
+	],
 	properties:
 	[
 		new web3._extend.Property({
@@ -1321,6 +1383,12 @@ web3._extend({
 			call: 'istanbul_getDemotedValidatorsAtHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getMisbehaviours',
+			call: 'istanbul_getMisbehaviours',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'discard',
 			call: 'istanbul_discard',
@@ -58,7 +58,7 @@ var (
 //go:generate mockgen -destination=./mocks/blockchain_api_mock.go -package=mocks github.com/klaytn/klaytn/datasync/chaindatafetcher/kas BlockchainAPI
 type BlockchainAPI interface {
 	GetCode(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error)
-	Call(ctx context.Context, args api.CallArgs, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error)
+	Call(ctx context.Context, args api.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, blockOverrides *api.BlockOverrides) (hexutil.Bytes, error)
 }
 
 // contractCaller performs kip13 method `supportsInterface` to detect the deployed contracts are KIP7 or KIP17.
@@ -89,7 +89,7 @@ func (f *contractCaller) CallContract(ctx context.Context, call kaia.CallMsg, bl
 		To:   call.To,
 		Data: hexutil.Bytes(call.Data),
 	}
-	return f.blockchainAPI.Call(ctx, callArgs, rpc.NewBlockNumberOrHashWithNumber(num))
+	return f.blockchainAPI.Call(ctx, callArgs, rpc.NewBlockNumberOrHashWithNumber(num), nil)
 }
 
 func getCallOpts(blockNumber *big.Int, timeout time.Duration) (*bind.CallOpts, context.CancelFunc) {
@@ -39,18 +39,18 @@ func (m *MockBlockchainAPI) EXPECT() *MockBlockchainAPIMockRecorder {
 }
 
 // Call mocks base method
-func (m *MockBlockchainAPI) Call(arg0 context.Context, arg1 api.CallArgs, arg2 rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+func (m *MockBlockchainAPI) Call(arg0 context.Context, arg1 api.CallArgs, arg2 rpc.BlockNumberOrHash, arg3 *api.BlockOverrides) (hexutil.Bytes, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Call", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Call", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(hexutil.Bytes)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Call indicates an expected call of Call
-func (mr *MockBlockchainAPIMockRecorder) Call(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockBlockchainAPIMockRecorder) Call(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Call", reflect.TypeOf((*MockBlockchainAPI)(nil).Call), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Call", reflect.TypeOf((*MockBlockchainAPI)(nil).Call), arg0, arg1, arg2, arg3)
 }
 
 // GetCode mocks base method
@@ -126,6 +126,13 @@ const (
 	KAS
 	FORK
 	NodeCnGasPrice
+	StorageEra
+	BlockchainStatePruner
+	ConsensusIstanbulSimulation
+
+	// 61~70
+	CMDISTSIM
+	ConsensusIstanbulLight
 
 	// ModuleNameLen should be placed at the end of the list.
 	ModuleNameLen
@@ -203,4 +210,9 @@ var moduleNames = [ModuleNameLen]string{
 	"kas",
 	"fork",
 	"node/cn/gasprice",
+	"storage/era",
+	"blockchain/state/pruner",
+	"consensus/istanbul/simulation",
+	"cmd/istsim",
+	"consensus/istanbul/light",
 }
@@ -0,0 +1,300 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// JWTSecretLength is the length of the secret used to sign the tokens.
+	JWTSecretLength = 32
+
+	// jwtIssuedAtWindow is the allowed difference between the issued-at time
+	// of a token and the local time. The tokens are expected to be issued
+	// right before the requests, so that a leaked token can't be reused later.
+	jwtIssuedAtWindow = 60 * time.Second
+
+	// AccessAll is the wildcard allowing all namespaces in an access rule.
+	AccessAll = "*"
+)
+
+var (
+	errMissingToken     = errors.New("missing bearer token")
+	errMalformedToken   = errors.New("malformed token")
+	errInvalidSignature = errors.New("invalid token signature")
+	errMissingIssuedAt  = errors.New("missing issued-at claim")
+	errStaleToken       = errors.New("stale token")
+	errExpiredToken     = errors.New("token is expired")
+)
+
+// AccessRule lists the namespaces and the methods allowed to a role. A method
+// is allowed if its namespace is listed in Namespaces or the method itself is
+// listed in Methods. The wildcard "*" in Namespaces allows all methods.
+type AccessRule struct {
+	Namespaces []string `json:"namespaces"`
+	Methods    []string `json:"methods"`
+}
+
+// AccessPolicy maps the roles claimed by the tokens to the access rules.
+type AccessPolicy struct {
+	Roles map[string]AccessRule `json:"roles"`
+}
+
+// LoadAccessPolicy reads the access policy from the given JSON file.
+func LoadAccessPolicy(file string) (*AccessPolicy, error) {
+	blob, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	policy := new(AccessPolicy)
+	if err := json.Unmarshal(blob, policy); err != nil {
+		return nil, fmt.Errorf("invalid access policy %s: %v", file, err)
+	}
+	if len(policy.Roles) == 0 {
+		return nil, fmt.Errorf("no role in the access policy %s", file)
+	}
+	return policy, nil
+}
+
+// rpcAccess is the set of the methods allowed to a request.
+type rpcAccess struct {
+	subject    string // identity claimed by the token, if any
+	all        bool
+	namespaces map[string]bool
+	methods    map[string]bool
+}
+
+type accessContextKey struct{}
+
+func newRPCAccess(rule AccessRule) *rpcAccess {
+	access := &rpcAccess{namespaces: make(map[string]bool), methods: make(map[string]bool)}
+	for _, namespace := range rule.Namespaces {
+		// for backward compatibility
+		if namespace == "klay" {
+			namespace = "kaia"
+		}
+		if namespace == AccessAll {
+			access.all = true
+		}
+		access.namespaces[namespace] = true
+	}
+	for _, method := range rule.Methods {
+		if strings.HasPrefix(method, "klay"+serviceMethodSeparator) {
+			method = "kaia" + strings.TrimPrefix(method, "klay")
+		}
+		access.methods[method] = true
+	}
+	return access
+}
+
+// allows returns true if the given method can be called.
+func (a *rpcAccess) allows(method string) bool {
+	if a.all {
+		return true
+	}
+	elem := strings.SplitN(method, serviceMethodSeparator, 2)
+	if len(elem) != 2 {
+		return false
+	}
+	// for backward compatibility
+	if elem[0] == "klay" {
+		elem[0] = "kaia"
+	}
+	return a.namespaces[elem[0]] || a.methods[elem[0]+serviceMethodSeparator+elem[1]]
+}
+
+// accessFromContext returns the access of the request. If the request is not
+// authenticated, nil is returned.
+func accessFromContext(ctx context.Context) *rpcAccess {
+	access, _ := ctx.Value(accessContextKey{}).(*rpcAccess)
+	return access
+}
+
+// JWTAuth authenticates the RPC requests with the HS256 JSON web tokens given
+// in the Authorization header, and authorizes the calls with the access rule
+// of the role claimed by the token.
+type JWTAuth struct {
+	secret []byte
+	policy *AccessPolicy // nil if all methods are allowed to the valid tokens
+}
+
+// jwtHeader is the JOSE header of a token.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// jwtClaims is the claim set of a token.
+type jwtClaims struct {
+	IssuedAt  *int64 `json:"iat"`
+	ExpiresAt *int64 `json:"exp"`
+	Subject   string `json:"sub"`
+	Role      string `json:"role"`
+}
+
+// NewJWTAuth creates a JWTAuth verifying the tokens with the given secret.
+// If the policy is nil, the valid tokens are allowed to call all methods.
+func NewJWTAuth(secret []byte, policy *AccessPolicy) (*JWTAuth, error) {
+	if len(secret) != JWTSecretLength {
+		return nil, fmt.Errorf("invalid JWT secret length %d, expected %d", len(secret), JWTSecretLength)
+	}
+	return &JWTAuth{secret: secret, policy: policy}, nil
+}
+
+// ObtainJWTSecret reads the hex-encoded secret from the given file. If the file
+// doesn't exist, a random secret is generated and stored in it.
+func ObtainJWTSecret(file string) ([]byte, error) {
+	if data, err := os.ReadFile(file); err == nil {
+		secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT secret %s: %v", file, err)
+		}
+		if len(secret) != JWTSecretLength {
+			return nil, fmt.Errorf("invalid JWT secret length %d in %s, expected %d", len(secret), file, JWTSecretLength)
+		}
+		return secret, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	secret := make([]byte, JWTSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(file, []byte(hex.EncodeToString(secret)), 0o600); err != nil {
+		return nil, err
+	}
+	logger.Info("Generated JWT secret", "path", file)
+	return secret, nil
+}
+
+// authenticate verifies the bearer token in the given Authorization header
+// value. It returns the access of the token or the HTTP status code with the
+// reason of the rejection.
+func (a *JWTAuth) authenticate(header string) (*rpcAccess, int, error) {
+	token := strings.TrimPrefix(header, "Bearer ")
+	if header == "" || token == header {
+		return nil, http.StatusUnauthorized, errMissingToken
+	}
+	claims, err := a.verify(token, time.Now())
+	if err != nil {
+		return nil, http.StatusUnauthorized, err
+	}
+	if a.policy == nil {
+		return &rpcAccess{subject: claims.Subject, all: true}, 0, nil
+	}
+	rule, ok := a.policy.Roles[claims.Role]
+	if !ok {
+		return nil, http.StatusForbidden, fmt.Errorf("unknown role %q", claims.Role)
+	}
+	access := newRPCAccess(rule)
+	access.subject = claims.Subject
+	return access, 0, nil
+}
+
+// verify checks the signature and the time claims of the token.
+func (a *JWTAuth) verify(token string, now time.Time) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errMalformedToken
+	}
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != "HS256" {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errMalformedToken
+	}
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errInvalidSignature
+	}
+	claims := new(jwtClaims)
+	if err := decodeJWTPart(parts[1], claims); err != nil {
+		return nil, err
+	}
+	if claims.IssuedAt == nil {
+		return nil, errMissingIssuedAt
+	}
+	if diff := now.Sub(time.Unix(*claims.IssuedAt, 0)); diff > jwtIssuedAtWindow || diff < -jwtIssuedAtWindow {
+		return nil, errStaleToken
+	}
+	if claims.ExpiresAt != nil && !now.Before(time.Unix(*claims.ExpiresAt, 0)) {
+		return nil, errExpiredToken
+	}
+	return claims, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	blob, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return errMalformedToken
+	}
+	if err := json.Unmarshal(blob, v); err != nil {
+		return errMalformedToken
+	}
+	return nil
+}
+
+// SetJWTAuth enables the authentication of the HTTP and websocket requests.
+// It should be called before the server starts to serve the requests.
+func (s *Server) SetJWTAuth(auth *JWTAuth) {
+	s.auth = auth
+}
+
+// authenticate verifies the Authorization header of a request if the
+// authentication is enabled. The returned access is nil if it's disabled.
+func (s *Server) authenticate(header string) (*rpcAccess, int, error) {
+	if s.auth == nil {
+		return nil, 0, nil
+	}
+	access, code, err := s.auth.authenticate(header)
+	if err != nil {
+		rpcUnauthorizedRequestsCounter.Inc(1)
+		logger.Debug("Rejected unauthorized RPC request", "err", err)
+	}
+	return access, code, err
+}
+
+// withAccess returns a copy of the context carrying the access of the request.
+func withAccess(ctx context.Context, access *rpcAccess) context.Context {
+	if access == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, accessContextKey{}, access)
+}
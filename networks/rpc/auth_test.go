@@ -0,0 +1,224 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testJWTSecret = bytes.Repeat([]byte{0x1}, JWTSecretLength)
+
+// newTestToken signs the given claims with the secret.
+func newTestToken(t *testing.T, secret []byte, alg string, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newTestJWTAuth(t *testing.T) *JWTAuth {
+	auth, err := NewJWTAuth(testJWTSecret, &AccessPolicy{Roles: map[string]AccessRule{
+		"admin":  {Namespaces: []string{AccessAll}},
+		"client": {Namespaces: []string{"test"}, Methods: []string{"rpc_modules"}},
+	}})
+	require.NoError(t, err)
+	return auth
+}
+
+func TestJWTAuth_Verify(t *testing.T) {
+	auth := newTestJWTAuth(t)
+	now := time.Now()
+	iat := now.Unix()
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"valid", newTestToken(t, testJWTSecret, "HS256", map[string]interface{}{"iat": iat}), nil},
+		{"valid with exp", newTestToken(t, testJWTSecret, "HS256", map[string]interface{}{"iat": iat, "exp": iat + 10}), nil},
+		{"malformed", "abc.def", errMalformedToken},
+		{"wrong secret", newTestToken(t, bytes.Repeat([]byte{0x2}, JWTSecretLength), "HS256", map[string]interface{}{"iat": iat}), errInvalidSignature},
+		{"missing iat", newTestToken(t, testJWTSecret, "HS256", map[string]interface{}{}), errMissingIssuedAt},
+		{"stale iat", newTestToken(t, testJWTSecret, "HS256", map[string]interface{}{"iat": iat - 61}), errStaleToken},
+		{"future iat", newTestToken(t, testJWTSecret, "HS256", map[string]interface{}{"iat": iat + 61}), errStaleToken},
+		{"expired", newTestToken(t, testJWTSecret, "HS256", map[string]interface{}{"iat": iat, "exp": iat}), errExpiredToken},
+	}
+	for _, tt := range tests {
+		_, err := auth.verify(tt.token, now)
+		assert.Equal(t, tt.err, err, tt.name)
+	}
+
+	// The tokens signed with the other algorithms are rejected.
+	_, err := auth.verify(newTestToken(t, testJWTSecret, "none", map[string]interface{}{"iat": iat}), now)
+	assert.Error(t, err)
+}
+
+func TestJWTAuth_Authenticate(t *testing.T) {
+	auth := newTestJWTAuth(t)
+	iat := time.Now().Unix()
+
+	_, code, err := auth.authenticate("")
+	assert.Equal(t, errMissingToken, err)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	_, code, err = auth.authenticate(newTestToken(t, testJWTSecret, "HS256", map[string]interface{}{"iat": iat}))
+	assert.Equal(t, errMissingToken, err)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	_, code, err = auth.authenticate("Bearer " + newTestToken(t, testJWTSecret, "HS256", map[string]interface{}{"iat": iat, "role": "unknown"}))
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	access, _, err := auth.authenticate("Bearer " + newTestToken(t, testJWTSecret, "HS256", map[string]interface{}{"iat": iat, "role": "client"}))
+	require.NoError(t, err)
+	assert.True(t, access.allows("test_echo"))
+	assert.True(t, access.allows("rpc_modules"))
+	assert.False(t, access.allows("rpc_other"))
+	assert.False(t, access.allows("admin_nodeInfo"))
+
+	access, _, err = auth.authenticate("Bearer " + newTestToken(t, testJWTSecret, "HS256", map[string]interface{}{"iat": iat, "role": "admin"}))
+	require.NoError(t, err)
+	assert.True(t, access.allows("admin_nodeInfo"))
+
+	// All methods are allowed to the valid tokens without the policy.
+	auth, err = NewJWTAuth(testJWTSecret, nil)
+	require.NoError(t, err)
+	access, _, err = auth.authenticate("Bearer " + newTestToken(t, testJWTSecret, "HS256", map[string]interface{}{"iat": iat}))
+	require.NoError(t, err)
+	assert.True(t, access.allows("admin_nodeInfo"))
+}
+
+func TestObtainJWTSecret(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "jwtsecret")
+
+	// A new secret is generated and reused.
+	secret, err := ObtainJWTSecret(file)
+	require.NoError(t, err)
+	assert.Len(t, secret, JWTSecretLength)
+	loaded, err := ObtainJWTSecret(file)
+	require.NoError(t, err)
+	assert.Equal(t, secret, loaded)
+
+	require.NoError(t, os.WriteFile(file, []byte("0x"+hex.EncodeToString(testJWTSecret)+"\n"), 0o600))
+	loaded, err = ObtainJWTSecret(file)
+	require.NoError(t, err)
+	assert.Equal(t, testJWTSecret, loaded)
+
+	require.NoError(t, os.WriteFile(file, []byte("0x1234"), 0o600))
+	_, err = ObtainJWTSecret(file)
+	assert.Error(t, err)
+}
+
+func TestLoadAccessPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"roles": {"client": {"namespaces": ["klay"], "methods": ["debug_traceTransaction"]}}}`), 0o600))
+	policy, err := LoadAccessPolicy(file)
+	require.NoError(t, err)
+	access := newRPCAccess(policy.Roles["client"])
+	assert.True(t, access.allows("kaia_blockNumber"))
+	assert.True(t, access.allows("klay_blockNumber"))
+	assert.True(t, access.allows("debug_traceTransaction"))
+	assert.False(t, access.allows("debug_traceBlockByNumber"))
+
+	require.NoError(t, os.WriteFile(file, []byte(`{"roles": {}}`), 0o600))
+	_, err = LoadAccessPolicy(file)
+	assert.Error(t, err)
+}
+
+func TestServeHTTP_JWTAuth(t *testing.T) {
+	server := newTestServer("test", new(Service))
+	server.SetJWTAuth(newTestJWTAuth(t))
+	defer server.Stop()
+	hs := httptest.NewServer(server)
+	defer hs.Close()
+
+	client, err := DialHTTP(hs.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// The requests without a token are rejected.
+	var result Result
+	err = client.Call(&result, "test_echo", "hello", 10, &Args{"world"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), errMissingToken.Error())
+
+	// The token allows the methods of the role.
+	token := newTestToken(t, testJWTSecret, "HS256", map[string]interface{}{"iat": time.Now().Unix(), "role": "client"})
+	client.SetHeader("Authorization", "Bearer "+token)
+	require.NoError(t, client.Call(&result, "test_echo", "hello", 10, &Args{"world"}))
+	assert.Equal(t, Result{"hello", 10, &Args{"world"}}, result)
+	var modules map[string]string
+	require.NoError(t, client.Call(&modules, "rpc_modules"))
+
+	// The other methods are not allowed.
+	server.RegisterName("other", new(Service))
+	err = client.Call(&result, "other_echo", "hello", 10, &Args{"world"})
+	require.Error(t, err)
+	assert.Equal(t, (&methodNotAllowedError{method: "other_echo"}).Error(), err.Error())
+}
+
+func TestWebsocketHandler_JWTAuth(t *testing.T) {
+	server := newTestServer("test", new(Service))
+	server.SetJWTAuth(newTestJWTAuth(t))
+	defer server.Stop()
+	hs := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer hs.Close()
+	wsURL := "ws:" + strings.TrimPrefix(hs.URL, "http:")
+
+	// The handshake without a token is rejected.
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	token := newTestToken(t, testJWTSecret, "HS256", map[string]interface{}{"iat": time.Now().Unix(), "role": "client"})
+	header := http.Header{"Authorization": []string{"Bearer " + token}}
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	call := func(method string) *jsonrpcMessage {
+		require.NoError(t, conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": []interface{}{}}))
+		var resp jsonrpcMessage
+		require.NoError(t, conn.ReadJSON(&resp))
+		return &resp
+	}
+	assert.Nil(t, call("test_noArgsRets").Error)
+	if resp := call("admin_nodeInfo"); assert.NotNil(t, resp.Error) {
+		assert.Equal(t, (&methodNotAllowedError{}).ErrorCode(), resp.Error.Code)
+	}
+}
@@ -87,6 +87,11 @@ type Client struct {
 	idCounter uint32
 	isHTTP    bool
 
+	// connCtx is the parent context of the handlers of the connections.
+	connCtx context.Context
+	// batchLimits is applied to the batch requests served by the handlers.
+	batchLimits batchLimits
+
 	// This function, if non-nil, is called when the connection is lost.
 	reconnectFunc reconnectFunc
 
@@ -117,8 +122,8 @@ type clientConn struct {
 }
 
 func (c *Client) newClientConn(conn ServerCodec) *clientConn {
-	ctx := context.WithValue(context.Background(), clientContextKey{}, c)
-	handler := newHandler(ctx, conn, c.idgen, c.services)
+	ctx := context.WithValue(c.connCtx, clientContextKey{}, c)
+	handler := newHandler(ctx, conn, c.idgen, c.services, c.batchLimits)
 	return &clientConn{conn, handler}
 }
 
@@ -204,14 +209,16 @@ func NewClient(initctx context.Context, connect reconnectFunc) (*Client, error)
 	if err != nil {
 		return nil, err
 	}
-	c := initClient(conn, randomIDGenerator(), new(serviceRegistry))
+	c := initClient(context.Background(), conn, randomIDGenerator(), new(serviceRegistry), batchLimits{})
 	c.reconnectFunc = connect
 	return c, nil
 }
 
-func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry) *Client {
+func initClient(connCtx context.Context, conn ServerCodec, idgen func() ID, services *serviceRegistry, limits batchLimits) *Client {
 	_, isHTTP := conn.(*httpConn)
 	c := &Client{
+		connCtx:     connCtx,
+		batchLimits: limits,
 		idgen:       idgen,
 		isHTTP:      isHTTP,
 		services:    services,
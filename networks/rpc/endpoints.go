@@ -24,8 +24,10 @@ import (
 	"net"
 )
 
-// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
-func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts) (net.Listener, *Server, error) {
+// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules.
+// If auth is not nil, the requests are authenticated with it. If upstream is
+// not nil, the calls are routed to the upstream endpoints with it.
+func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts, auth *JWTAuth, upstream *UpstreamRouter) (net.Listener, *Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -37,6 +39,8 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 	}
 	// Register all the APIs exposed by the services
 	handler := NewServer()
+	handler.SetJWTAuth(auth)
+	handler.SetUpstreamRouter(upstream)
 	for _, api := range apis {
 		if api.Namespace == "klay" {
 			api.Namespace = "kaia"
@@ -61,8 +65,10 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 	return listener, handler, err
 }
 
-// StartWSEndpoint starts a websocket endpoint
-func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool) (net.Listener, *Server, error) {
+// StartWSEndpoint starts a websocket endpoint.
+// If auth is not nil, the connections are authenticated with it. If upstream
+// is not nil, the calls are routed to the upstream endpoints with it.
+func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool, auth *JWTAuth, upstream *UpstreamRouter) (net.Listener, *Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -73,6 +79,8 @@ func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []
 	}
 	// Register all the APIs exposed by the services
 	handler := NewServer()
+	handler.SetJWTAuth(auth)
+	handler.SetUpstreamRouter(upstream)
 	for _, api := range apis {
 		if api.Namespace == "klay" {
 			api.Namespace = "kaia"
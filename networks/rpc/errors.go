@@ -28,6 +28,14 @@ func (e *methodNotFoundError) Error() string {
 	return fmt.Sprintf("the method %s does not exist/is not available", e.method)
 }
 
+type methodNotAllowedError struct{ method string }
+
+func (e *methodNotAllowedError) ErrorCode() int { return -32601 }
+
+func (e *methodNotAllowedError) Error() string {
+	return fmt.Sprintf("the method %s is not allowed", e.method)
+}
+
 type subscriptionNotFoundError struct{ namespace, subscription string }
 
 func (e *subscriptionNotFoundError) ErrorCode() int { return -32601 }
@@ -71,6 +79,20 @@ func (e *callbackError) ErrorCode() int { return defaultErrorCode }
 
 func (e *callbackError) Error() string { return e.message }
 
+// issued when a call is rejected by the rate limiter.
+type rateLimitedError struct{}
+
+func (e *rateLimitedError) ErrorCode() int { return -32005 }
+
+func (e *rateLimitedError) Error() string { return "rate limit exceeded" }
+
+// issued when the results of a batch exceed the response size limit.
+type responseTooLargeError struct{}
+
+func (e *responseTooLargeError) ErrorCode() int { return -32003 }
+
+func (e *responseTooLargeError) Error() string { return "response too large" }
+
 // issued when a request is received after the server is issued to stop.
 type shutdownError struct{}
 
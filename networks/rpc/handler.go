@@ -31,6 +31,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/storage/statedb"
 )
@@ -66,17 +67,24 @@ type handler struct {
 	cancelRoot     func()                         // cancel function for rootCtx
 	conn           jsonWriter                     // where responses will be sent
 	allowSubscribe bool
+	batchLimits    batchLimits
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
 }
 
+// batchLimits are the limits applied to the batch requests. Zero means no limit.
+type batchLimits struct {
+	itemLimit    int // maximum number of the calls in a batch
+	responseSize int // maximum total size of the results in a batch response in bytes
+}
+
 type callProc struct {
 	ctx       context.Context
 	notifiers []*Notifier
 }
 
-func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry) *handler {
+func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *serviceRegistry, limits batchLimits) *handler {
 	rootCtx, cancelRoot := context.WithCancel(connCtx)
 	h := &handler{
 		reg:            reg,
@@ -88,6 +96,7 @@ func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *
 		cancelRoot:     cancelRoot,
 		allowSubscribe: true,
 		serverSubs:     make(map[ID]*Subscription),
+		batchLimits:    limits,
 	}
 	h.unsubscribeCb = newCallback(reflect.Value{}, reflect.ValueOf(h.unsubscribe))
 	return h
@@ -117,6 +126,24 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 		return
 	}
 
+	// Reject all calls of the batch exceeding the item limit:
+	if h.batchLimits.itemLimit != 0 && len(calls) > h.batchLimits.itemLimit {
+		logger.Debug("Rejected too large batch", "calls", len(calls), "limit", h.batchLimits.itemLimit)
+		h.startCallProc(func(cp *callProc) {
+			answers := make([]*jsonrpcMessage, 0, len(calls))
+			for _, msg := range calls {
+				if answer := batchErrorResponse(msg, &invalidRequestError{"batch too large"}); answer != nil {
+					answers = append(answers, answer)
+				}
+			}
+			rpcErrorResponsesCounter.Inc(int64(len(answers)))
+			if len(answers) > 0 {
+				h.conn.writeJSON(cp.ctx, answers)
+			}
+		})
+		return
+	}
+
 	if atomic.LoadInt64(&pendingRequestCount) > pendingRequestLimit {
 		rpcErrorResponsesCounter.Inc(int64(len(calls)))
 		err := &invalidRequestError{"server requests exceed the limit"}
@@ -129,11 +156,34 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 
 	// Process calls on a goroutine because they may block indefinitely:
 	h.startCallProc(func(cp *callProc) {
-		answers := make([]*jsonrpcMessage, 0, len(msgs))
+		var (
+			answers       = make([]*jsonrpcMessage, 0, len(msgs))
+			responseBytes = 0
+			tooLarge      = false
+		)
 		for _, msg := range calls {
-			if answer := h.handleCallMsg(cp, msg); answer != nil {
-				answers = append(answers, answer)
+			// Once the results exceed the size limit, the remaining calls are
+			// answered with the error without being processed.
+			if tooLarge {
+				if answer := batchErrorResponse(msg, &responseTooLargeError{}); answer != nil {
+					rpcErrorResponsesCounter.Inc(1)
+					answers = append(answers, answer)
+				}
+				continue
+			}
+			answer := h.handleCallMsg(cp, msg)
+			if answer == nil {
+				continue
+			}
+			if h.batchLimits.responseSize != 0 {
+				responseBytes += len(answer.Result)
+				if responseBytes > h.batchLimits.responseSize {
+					logger.Debug("Truncated too large batch response", "size", responseBytes, "limit", h.batchLimits.responseSize)
+					rpcErrorResponsesCounter.Inc(1)
+					answer, tooLarge = msg.errorResponse(&responseTooLargeError{}), true
+				}
 			}
+			answers = append(answers, answer)
 		}
 		h.addSubscriptions(cp.notifiers)
 		if len(answers) > 0 {
@@ -145,6 +195,19 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	})
 }
 
+// batchErrorResponse returns the error response of a call rejected by the batch
+// limits. Nil is returned for a notification since it's not answered.
+func batchErrorResponse(msg *jsonrpcMessage, err error) *jsonrpcMessage {
+	switch {
+	case msg.isNotification():
+		return nil
+	case msg.hasValidID():
+		return msg.errorResponse(err)
+	default:
+		return errorMessage(err)
+	}
+}
+
 // handleMsg handles a single message.
 func (h *handler) handleMsg(msg *jsonrpcMessage) {
 	rpcTotalRequestsCounter.Inc(1)
@@ -355,9 +418,24 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if access := accessFromContext(cp.ctx); access != nil && !access.allows(msg.Method) {
+		rpcForbiddenCallsCounter.Inc(1)
+		rpcErrorResponsesCounter.Inc(1)
+		return msg.errorResponse(&methodNotAllowedError{method: msg.Method})
+	}
+	if rl := rateLimitFromContext(cp.ctx); rl != nil && !rl.allow(msg.Method) {
+		rpcRateLimitedCallsCounter.Inc(1)
+		rpcErrorResponsesCounter.Inc(1)
+		return msg.errorResponse(&rateLimitedError{})
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
+	if router := upstreamFromContext(cp.ctx); router != nil && !msg.isUnsubscribe() {
+		if answer := router.forward(cp.ctx, msg, h.localHead); answer != nil {
+			return answer
+		}
+	}
 	var callb *callback
 	if msg.isUnsubscribe() {
 		callb = h.unsubscribeCb
@@ -377,6 +455,26 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	return h.runMethod(cp.ctx, msg, callb, args)
 }
 
+// localHead returns the head block number of the local chain by calling the
+// blockNumber method registered to the handler. It returns false if the method
+// is not available.
+func (h *handler) localHead(ctx context.Context) (uint64, bool) {
+	for _, method := range []string{"kaia_blockNumber", "klay_blockNumber", "eth_blockNumber"} {
+		callb := h.reg.callback(method)
+		if callb == nil || len(callb.argTypes) != 0 {
+			continue
+		}
+		result, err := callb.call(ctx, method, nil)
+		if err != nil {
+			continue
+		}
+		if number, ok := result.(hexutil.Uint64); ok {
+			return uint64(number), true
+		}
+	}
+	return 0, false
+}
+
 // handleSubscribe processes *_subscribe method calls.
 func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
 	if !h.allowSubscribe {
@@ -428,13 +526,11 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
 	result, err := callb.call(ctx, msg.Method, args)
 	if err != nil {
-		// TODO-Kaia:
-		// 1. The single URL may be extended to the list of URL.
-		// 2. The URL (list or single) seems not modifiable at runtime.
-		// 3. Any request can be relayed as well as the state lack.
-		// 4. Make a new rpcErrorResponse struct.
-		if UpstreamArchiveEN != "" && shouldRequestUpstream(err) {
-			return requestUpstream(ctx, msg, args)
+		// TODO-Kaia: The upstream routes seem not modifiable at runtime.
+		if router := upstreamFromContext(ctx); router != nil && shouldRequestUpstream(err) {
+			if answer := router.retry(ctx, msg); answer != nil {
+				return answer
+			}
 		}
 		rpcErrorResponsesCounter.Inc(1)
 		return msg.errorResponse(err)
@@ -454,30 +550,6 @@ func shouldRequestUpstream(err error) bool {
 	}
 }
 
-// requestUpstream is the function to request upstream archive en
-func requestUpstream(ctx context.Context, msg *jsonrpcMessage, args []reflect.Value) *jsonrpcMessage {
-	ctx, cancel := context.WithTimeout(ctx, DefaultHTTPTimeouts.ExecutionTimeout)
-	defer cancel()
-
-	var result interface{}
-	c, err := DialContext(ctx, UpstreamArchiveEN)
-	if err == nil {
-		defer c.Close()
-
-		var params []interface{}
-		for _, a := range args {
-			params = append(params, a.Interface())
-		}
-		if err = c.CallContext(ctx, &result, msg.Method, params...); err == nil {
-			rpcSuccessResponsesCounter.Inc(1)
-			return msg.response(result)
-		}
-	}
-
-	rpcErrorResponsesCounter.Inc(1)
-	return msg.errorResponse(err)
-}
-
 // unsubscribe is the callback function for all *_unsubscribe calls.
 func (h *handler) unsubscribe(ctx context.Context, id ID) (bool, error) {
 	h.subLock.Lock()
@@ -353,6 +353,11 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), code)
 		return
 	}
+	access, code, err := s.authenticate(r.Header.Get("Authorization"))
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
 	// All checks passed, create a codec that reads direct from the request body
 	// untilEOF and writes the response to w and order the server to process a
 	// single request.
@@ -366,6 +371,8 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if origin := r.Header.Get("Origin"); origin != "" {
 		ctx = context.WithValue(ctx, "Origin", origin)
 	}
+	ctx = withAccess(ctx, access)
+	ctx = s.withRateLimit(ctx, r.RemoteAddr, access)
 
 	w.Header().Set("content-type", contentType)
 	codec := newHTTPServerConn(r, w)
@@ -382,10 +389,12 @@ func (srv *Server) HandleFastHTTP(requestCtx *fasthttp.RequestCtx) {
 		return
 	}
 	if code, err := validateFastRequest(requestCtx); err != nil {
-		w.Header.Set("Content-Type", "text/plain; charset=utf-8")
-		w.Header.Set("X-Content-Type-Options", "nosniff")
-		w.Header.SetStatusCode(code)
-		fmt.Fprintf(requestCtx, err.Error())
+		writeFastError(requestCtx, code, err)
+		return
+	}
+	access, code, err := srv.authenticate(string(r.Header.Peek("Authorization")))
+	if err != nil {
+		writeFastError(requestCtx, code, err)
 		return
 	}
 	// All checks passed, create a codec that reads direct from the request body
@@ -396,6 +405,8 @@ func (srv *Server) HandleFastHTTP(requestCtx *fasthttp.RequestCtx) {
 	ctx = context.WithValue(ctx, "remote", requestCtx.RemoteAddr().String())
 	ctx = context.WithValue(ctx, "scheme", string(requestCtx.URI().Scheme()))
 	ctx = context.WithValue(ctx, "local", requestCtx.LocalAddr().String())
+	ctx = withAccess(ctx, access)
+	ctx = srv.withRateLimit(ctx, requestCtx.RemoteAddr().String(), access)
 
 	reader := bufio.NewReaderSize(bytes.NewReader(r.Body()), common.MaxRequestContentLength)
 	codec := NewCodec(&httpReadWriteNopCloser{reader, w.BodyWriter()})
@@ -405,6 +416,16 @@ func (srv *Server) HandleFastHTTP(requestCtx *fasthttp.RequestCtx) {
 	srv.ServeSingleRequest(ctx, codec)
 }
 
+// writeFastError replies to the request with the given status code and error
+// message in plain text.
+func writeFastError(requestCtx *fasthttp.RequestCtx, code int, err error) {
+	w := &requestCtx.Response
+	w.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header.Set("X-Content-Type-Options", "nosniff")
+	w.Header.SetStatusCode(code)
+	fmt.Fprint(requestCtx, err.Error())
+}
+
 // validateRequest returns a non-zero response code and error message if the
 // request is invalid.
 func validateRequest(r *http.Request) (int, error) {
@@ -54,3 +54,21 @@ func testHTTPErrorResponse(t *testing.T, method, contentType, body string, expec
 		t.Fatalf("response code should be %d not %d", expected, code)
 	}
 }
+
+func TestHTTPBatchRequestLimit(t *testing.T) {
+	server := newTestServer("test", new(Service))
+	server.SetBatchLimits(1, 0)
+	defer server.Stop()
+
+	body := `[{"jsonrpc":"2.0","id":1,"method":"rpc_modules"},{"jsonrpc":"2.0","id":2,"method":"rpc_modules"}]`
+	request := httptest.NewRequest(http.MethodPost, "http://url.com", strings.NewReader(body))
+	request.Header.Set("content-type", contentType)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+
+	want := `[{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"batch too large"}},` +
+		`{"jsonrpc":"2.0","id":2,"error":{"code":-32600,"message":"batch too large"}}]` + "\n"
+	if recorder.Body.String() != want {
+		t.Fatalf("wrong response: %s", recorder.Body.String())
+	}
+}
@@ -8,6 +8,11 @@ var (
 	rpcErrorResponsesCounter   = metrics.NewRegisteredCounter("rpc/counts/errors", nil)
 	rpcPendingRequestsCount    = metrics.NewRegisteredCounter("rpc/counts/pending", nil)
 
+	rpcUnauthorizedRequestsCounter = metrics.NewRegisteredCounter("rpc/counts/unauthorized", nil)
+	rpcForbiddenCallsCounter       = metrics.NewRegisteredCounter("rpc/counts/forbidden", nil)
+	rpcRateLimitedCallsCounter     = metrics.NewRegisteredCounter("rpc/counts/ratelimited", nil)
+	rpcRateLimitClientsGauge       = metrics.NewRegisteredGauge("rpc/ratelimit/clients", nil)
+
 	wsSubscriptionReqCounter   = metrics.NewRegisteredCounter("ws/counts/subscription/request", nil)
 	wsUnsubscriptionReqCounter = metrics.NewRegisteredCounter("ws/counts/unsubscription/request", nil)
 	wsConnCounter              = metrics.NewRegisteredCounter("ws/counts/connections/total", nil)
@@ -0,0 +1,229 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitSweepInterval is the interval to remove the buckets of idle clients.
+const rateLimitSweepInterval = time.Minute
+
+// DefaultMethodWeights are the costs of the heavy methods charged by the rate
+// limiter. The other methods cost 1. A trailing "*" matches any suffix.
+var DefaultMethodWeights = map[string]int{
+	"debug_trace*":         50,
+	"debug_standardTrace*": 50,
+	"eth_getLogs":          10,
+	"kaia_getLogs":         10,
+	"klay_getLogs":         10,
+}
+
+// ParseMethodWeights parses the comma separated list of method=weight pairs,
+// e.g. "debug_trace*=50,eth_getLogs=10".
+func ParseMethodWeights(s string) (map[string]int, error) {
+	weights := make(map[string]int)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid method weight %q", entry)
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid method weight %q", entry)
+		}
+		weights[strings.TrimSpace(kv[0])] = weight
+	}
+	return weights, nil
+}
+
+// tokenBucket is the budget of a client. It's refilled at the rate of the
+// limiter up to the burst size.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type methodPrefix struct {
+	prefix string
+	weight float64
+}
+
+// RateLimiter is a token bucket rate limiter of the RPC calls keyed by the
+// clients. Each call takes the tokens of its method weight from the bucket
+// of the client, and is rejected if the bucket doesn't have enough tokens.
+type RateLimiter struct {
+	rate     float64 // tokens refilled per second
+	burst    float64 // capacity of a bucket
+	weights  map[string]float64
+	prefixes []methodPrefix // sorted by the length in descending order
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a rate limiter refilling the buckets with the given
+// rate of tokens per second up to the burst size. The weights override the
+// default method weights.
+func NewRateLimiter(rate float64, burst int, weights map[string]int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	l := &RateLimiter{
+		rate:      rate,
+		burst:     float64(burst),
+		weights:   make(map[string]float64),
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+	merged := make(map[string]int)
+	for method, weight := range DefaultMethodWeights {
+		merged[method] = weight
+	}
+	for method, weight := range weights {
+		merged[method] = weight
+	}
+	for method, weight := range merged {
+		if strings.HasSuffix(method, "*") {
+			l.prefixes = append(l.prefixes, methodPrefix{strings.TrimSuffix(method, "*"), float64(weight)})
+		} else {
+			l.weights[method] = float64(weight)
+		}
+	}
+	sort.Slice(l.prefixes, func(i, j int) bool { return len(l.prefixes[i].prefix) > len(l.prefixes[j].prefix) })
+	return l
+}
+
+// weight returns the cost of the given method. A weight larger than the burst
+// size is capped so that the method can be called with a full bucket.
+func (l *RateLimiter) weight(method string) float64 {
+	weight, ok := l.weights[method]
+	if !ok {
+		weight = 1
+		for _, p := range l.prefixes {
+			if strings.HasPrefix(method, p.prefix) {
+				weight = p.weight
+				break
+			}
+		}
+	}
+	if weight > l.burst {
+		return l.burst
+	}
+	return weight
+}
+
+// allow takes the tokens of the method weight from the bucket of the client
+// and returns true if the bucket had enough tokens.
+func (l *RateLimiter) allow(client, method string, now time.Time) bool {
+	weight := l.weight(method)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweep(now)
+	}
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = bucket
+		rpcRateLimitClientsGauge.Update(int64(len(l.buckets)))
+	}
+	if elapsed := now.Sub(bucket.last); elapsed > 0 {
+		bucket.tokens += elapsed.Seconds() * l.rate
+		if bucket.tokens > l.burst {
+			bucket.tokens = l.burst
+		}
+		bucket.last = now
+	}
+	if bucket.tokens < weight {
+		return false
+	}
+	bucket.tokens -= weight
+	return true
+}
+
+// sweep removes the buckets refilled up to the burst size, since they are
+// the same as the new ones.
+func (l *RateLimiter) sweep(now time.Time) {
+	for client, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+	l.lastSweep = now
+	rpcRateLimitClientsGauge.Update(int64(len(l.buckets)))
+}
+
+// clientRateLimit is the rate limiter bound to the client of a request.
+type clientRateLimit struct {
+	limiter *RateLimiter
+	client  string
+}
+
+type rateLimitContextKey struct{}
+
+func (c *clientRateLimit) allow(method string) bool {
+	return c.limiter.allow(c.client, method, time.Now())
+}
+
+// rateLimitFromContext returns the rate limit of the request. If the request
+// is not rate limited, nil is returned.
+func rateLimitFromContext(ctx context.Context) *clientRateLimit {
+	rl, _ := ctx.Value(rateLimitContextKey{}).(*clientRateLimit)
+	return rl
+}
+
+// SetRateLimiter sets the rate limiter of the HTTP and websocket requests. The
+// clients are identified by the subject of the token if the requests are
+// authenticated, or by the remote IP address otherwise. A nil limiter disables
+// the rate limiting.
+//
+// This method should be called before the server starts to serve the requests.
+func (s *Server) SetRateLimiter(limiter *RateLimiter) {
+	s.rateLimiter = limiter
+}
+
+// withRateLimit returns a copy of the context carrying the rate limit of the
+// client identified by the given access or remote address.
+func (s *Server) withRateLimit(ctx context.Context, remote string, access *rpcAccess) context.Context {
+	if s.rateLimiter == nil {
+		return ctx
+	}
+	var client string
+	if access != nil && access.subject != "" {
+		client = "sub:" + access.subject
+	} else if host, _, err := net.SplitHostPort(remote); err == nil {
+		client = host
+	} else {
+		client = remote
+	}
+	return context.WithValue(ctx, rateLimitContextKey{}, &clientRateLimit{limiter: s.rateLimiter, client: client})
+}
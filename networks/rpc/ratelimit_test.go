@@ -0,0 +1,102 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMethodWeights(t *testing.T) {
+	weights, err := ParseMethodWeights(" debug_trace*=100, eth_call=3,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"debug_trace*": 100, "eth_call": 3}, weights)
+
+	for _, s := range []string{"eth_call", "=3", "eth_call=x", "eth_call=-1"} {
+		_, err := ParseMethodWeights(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(10, 20, map[string]int{"eth_call": 5, "debug_traceBlock*": 100})
+	assert.Equal(t, float64(1), l.weight("eth_blockNumber"))
+	assert.Equal(t, float64(5), l.weight("eth_call"))
+	assert.Equal(t, float64(10), l.weight("kaia_getLogs"))
+	assert.Equal(t, float64(20), l.weight("debug_traceBlockByNumber")) // capped by the burst
+	assert.Equal(t, float64(20), l.weight("debug_traceTransaction"))   // capped by the burst
+
+	now := time.Now()
+	// The burst is allowed, then the calls are rejected until the bucket is refilled.
+	for i := 0; i < 4; i++ {
+		assert.True(t, l.allow("a", "eth_call", now))
+	}
+	assert.False(t, l.allow("a", "eth_blockNumber", now))
+	// The other clients have their own buckets.
+	assert.True(t, l.allow("b", "debug_traceTransaction", now))
+	assert.False(t, l.allow("b", "eth_blockNumber", now))
+
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, l.allow("a", "eth_call", now))
+	assert.False(t, l.allow("a", "eth_call", now))
+
+	// The idle clients are removed after they are refilled.
+	now = now.Add(rateLimitSweepInterval)
+	assert.True(t, l.allow("c", "eth_call", now))
+	assert.Len(t, l.buckets, 1)
+}
+
+func newRateLimitedTestClient(t *testing.T, limiter *RateLimiter) *Client {
+	server := newTestServer("test", new(Service))
+	server.SetRateLimiter(limiter)
+	hs := httptest.NewServer(server)
+	client, err := DialHTTP(hs.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		client.Close()
+		hs.Close()
+		server.Stop()
+	})
+	return client
+}
+
+func TestServeHTTP_RateLimit(t *testing.T) {
+	client := newRateLimitedTestClient(t, NewRateLimiter(0.001, 2, nil))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, client.Call(nil, "test_noArgsRets"))
+	}
+	err := client.Call(nil, "test_noArgsRets")
+	require.Error(t, err)
+	rpcErr, ok := err.(Error)
+	require.True(t, ok)
+	assert.Equal(t, (&rateLimitedError{}).ErrorCode(), rpcErr.ErrorCode())
+
+	// The batched calls are charged one by one.
+	client = newRateLimitedTestClient(t, NewRateLimiter(0.001, 2, nil))
+	batch := make([]BatchElem, 3)
+	for i := range batch {
+		batch[i] = BatchElem{Method: "test_noArgsRets", Result: new(interface{})}
+	}
+	require.NoError(t, client.BatchCall(batch))
+	assert.NoError(t, batch[0].Error)
+	assert.NoError(t, batch[1].Error)
+	assert.Error(t, batch[2].Error)
+}
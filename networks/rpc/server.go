@@ -73,6 +73,18 @@ var (
 
 	// UpstreamArchiveEN is the upstream archive mode EN endpoint
 	UpstreamArchiveEN string
+
+	// BatchRequestLimit is the default maximum number of the calls in a batch request. 0 means no limit.
+	// It can be overwritten by rpc.batchlimit flag
+	BatchRequestLimit = 1000
+
+	// BatchResponseMaxSize is the default maximum total size of the results in a batch response in bytes.
+	// 0 means no limit. It can be overwritten by rpc.batchresponsemaxsize flag
+	BatchResponseMaxSize = 25 * 1000 * 1000
+
+	// DefaultRateLimiter is the rate limiter shared by the HTTP and websocket servers. nil means no limit.
+	// It can be overwritten by rpc.ratelimit flag
+	DefaultRateLimiter *RateLimiter
 )
 
 // Server is an RPC server.
@@ -82,11 +94,25 @@ type Server struct {
 	codecs      mapset.Set
 	run         int32
 	wsConnCount int32
+	auth        *JWTAuth // nil if the requests are not authenticated
+
+	batchItemLimit    int
+	batchResponseSize int
+	rateLimiter       *RateLimiter    // nil if the requests are not rate limited
+	upstream          *UpstreamRouter // nil if all calls are processed locally
 }
 
 // NewServer creates a new server instance with no registered handlers.
 func NewServer() *Server {
-	server := &Server{idgen: randomIDGenerator(), codecs: mapset.NewSet(), run: 1, wsConnCount: 0}
+	server := &Server{
+		idgen:             randomIDGenerator(),
+		codecs:            mapset.NewSet(),
+		run:               1,
+		wsConnCount:       0,
+		batchItemLimit:    BatchRequestLimit,
+		batchResponseSize: BatchResponseMaxSize,
+		rateLimiter:       DefaultRateLimiter,
+	}
 	// Register the default service providing meta information about the RPC service such
 	// as the services and methods it offers.
 	rpcService := &RPCService{server}
@@ -116,6 +142,17 @@ func (s *Server) GetServices() map[string]service {
 	return s.services.services
 }
 
+// SetBatchLimits sets the limits applied to the batch requests. There are two limits:
+// 'itemLimit' is the maximum number of the calls in a batch. 'maxResponseSize' is the
+// maximum total size of the results in a batch response in bytes. A zero limit
+// disables the check.
+//
+// This method should be called before the server starts to serve the requests.
+func (s *Server) SetBatchLimits(itemLimit, maxResponseSize int) {
+	s.batchItemLimit = itemLimit
+	s.batchResponseSize = maxResponseSize
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -134,6 +171,11 @@ func GetNullServices() service {
 //
 // Note that codec options are no longer supported.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
+	s.serveCodec(context.Background(), codec)
+}
+
+// serveCodec serves the codec with the handlers derived from the given context.
+func (s *Server) serveCodec(ctx context.Context, codec ServerCodec) {
 	defer codec.close()
 
 	// Don't serve if server is stopped.
@@ -145,11 +187,15 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
-	c := initClient(codec, s.idgen, &s.services)
+	c := initClient(s.withUpstream(ctx), codec, s.idgen, &s.services, s.batchLimits())
 	<-codec.closed()
 	c.Close()
 }
 
+func (s *Server) batchLimits() batchLimits {
+	return batchLimits{itemLimit: s.batchItemLimit, responseSize: s.batchResponseSize}
+}
+
 // ServeSingleRequest reads and processes a single RPC request from the given codec. This
 // is used to serve HTTP connections. Subscriptions and reverse calls are not allowed in
 // this mode.
@@ -158,7 +204,7 @@ func (s *Server) ServeSingleRequest(ctx context.Context, codec ServerCodec) {
 	if atomic.LoadInt32(&s.run) == 0 {
 		return
 	}
-	h := newHandler(ctx, codec, s.idgen, &s.services)
+	h := newHandler(s.withUpstream(ctx), codec, s.idgen, &s.services, s.batchLimits())
 	h.allowSubscribe = false
 	defer h.close(io.EOF, nil)
 
@@ -20,7 +20,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -190,3 +193,57 @@ func TestServerShortLivedConn(t *testing.T) {
 		}
 	}
 }
+
+func TestServerBatchLimits(t *testing.T) {
+	server := newTestServer("test", new(Service))
+	server.SetBatchLimits(3, 80)
+	defer server.Stop()
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go server.ServeCodec(NewCodec(serverConn), 0)
+	in := json.NewDecoder(clientConn)
+
+	call := func(batch string) []*jsonrpcMessage {
+		go clientConn.Write([]byte(batch))
+		var resp []*jsonrpcMessage
+		if err := in.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	echo := func(id int) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"test_echo","params":["abcdefghijklmnopqrstuvwxyz",1,{"S":"x"}]}`, id)
+	}
+
+	// All calls of the batch exceeding the item limit are rejected.
+	resp := call("[" + strings.Join([]string{echo(1), echo(2), echo(3), echo(4)}, ",") + "]")
+	if len(resp) != 4 {
+		t.Fatalf("wrong number of responses: %d", len(resp))
+	}
+	for i, msg := range resp {
+		if msg.Error == nil || msg.Error.Message != "batch too large" || string(msg.ID) != strconv.Itoa(i+1) {
+			t.Fatalf("wrong response %d: %v", i, msg)
+		}
+	}
+
+	// The calls after the results exceed the size limit are answered with the error.
+	resp = call("[" + strings.Join([]string{echo(1), echo(2), echo(3)}, ",") + "]")
+	if len(resp) != 3 {
+		t.Fatalf("wrong number of responses: %d", len(resp))
+	}
+	if resp[0].Error != nil {
+		t.Fatalf("unexpected error: %v", resp[0].Error)
+	}
+	for _, msg := range resp[1:] {
+		if msg.Error == nil || msg.Error.Code != (&responseTooLargeError{}).ErrorCode() {
+			t.Fatalf("wrong response: %v", msg)
+		}
+	}
+
+	// The batches within the limits are served.
+	resp = call("[" + echo(1) + "]")
+	if len(resp) != 1 || resp[0].Error != nil {
+		t.Fatalf("wrong response: %v", resp)
+	}
+}
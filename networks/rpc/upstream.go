@@ -0,0 +1,566 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/rcrowley/go-metrics"
+)
+
+// defaultHealthCheckInterval is the interval of the health checks of the
+// upstream endpoints if it's not configured.
+const defaultHealthCheckInterval = 10 * time.Second
+
+// blockParamIndex is the position of the block number parameter of the
+// methods in the kaia, klay and eth namespaces. It's used to decide the age
+// of the requested block.
+var blockParamIndex = map[string]int{
+	"getBalance":                          1,
+	"getCode":                             1,
+	"getStorageAt":                        2,
+	"getTransactionCount":                 1,
+	"getAccount":                          1,
+	"getAccountKey":                       1,
+	"isContractAccount":                   1,
+	"call":                                1,
+	"estimateGas":                         1,
+	"estimateComputationCost":             1,
+	"getProof":                            2,
+	"getBlockByNumber":                    0,
+	"getHeaderByNumber":                   0,
+	"getBlockReceipts":                    0,
+	"getBlockTransactionCountByNumber":    0,
+	"getTransactionByBlockNumberAndIndex": 0,
+}
+
+// debugBlockParamIndex is the position of the block number parameter of the
+// methods in the debug namespace.
+var debugBlockParamIndex = map[string]int{
+	"traceBlockByNumber":          0,
+	"traceCall":                   1,
+	"dumpBlock":                   0,
+	"getModifiedAccountsByNumber": 0,
+}
+
+// UpstreamConfig configures the routing of the RPC calls to the upstream
+// endpoints, typically the archive mode ENs.
+type UpstreamConfig struct {
+	// Upstreams maps the group names to the endpoints. The calls routed to a
+	// group are sent to the first healthy endpoint, and fail over to the next
+	// endpoints on the connection errors.
+	Upstreams map[string][]string `json:"upstreams"`
+
+	// Rules are evaluated in order, and the call is sent to the group of the
+	// first matching rule instead of being processed locally.
+	Rules []UpstreamRule `json:"rules"`
+
+	// Fallback is the group retrying the calls failed locally because of the
+	// missing trie nodes. If empty, the failed calls are not retried.
+	Fallback string `json:"fallback"`
+
+	// HealthCheckInterval is the interval of the health checks in seconds.
+	HealthCheckInterval uint64 `json:"healthCheckInterval"`
+}
+
+// UpstreamRule routes the calls of the matching methods to an upstream group.
+type UpstreamRule struct {
+	// Methods are the names of the routed methods. A trailing "*" matches any
+	// suffix, e.g. "debug_trace*".
+	Methods []string `json:"methods"`
+
+	// OlderThan limits the rule to the calls requesting the blocks older than
+	// the given number of blocks from the head of the local chain. If zero, all
+	// calls of the methods are routed.
+	OlderThan uint64 `json:"olderThan"`
+
+	// BlockParam is the position of the block number parameter. If nil, the
+	// known position of the method is used.
+	BlockParam *int `json:"blockParam"`
+
+	// Upstream is the name of the upstream group.
+	Upstream string `json:"upstream"`
+}
+
+// LoadUpstreamConfig reads the upstream routing configuration from the given JSON file.
+func LoadUpstreamConfig(file string) (*UpstreamConfig, error) {
+	blob, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	config := new(UpstreamConfig)
+	if err := json.Unmarshal(blob, config); err != nil {
+		return nil, fmt.Errorf("invalid upstream config %s: %v", file, err)
+	}
+	return config, nil
+}
+
+// upstreamEndpoint is an upstream endpoint with its health status.
+type upstreamEndpoint struct {
+	url     string
+	healthy int32 // 1 if the last request or health check succeeded
+
+	mu     sync.Mutex
+	client *Client
+}
+
+// dial returns the client connected to the endpoint.
+func (e *upstreamEndpoint) dial(ctx context.Context) (*Client, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.client == nil {
+		client, err := DialContext(ctx, e.url)
+		if err != nil {
+			return nil, err
+		}
+		e.client = client
+	}
+	return e.client, nil
+}
+
+// call sends the call to the endpoint. If the call fails with an error other
+// than the JSON-RPC errors returned by the endpoint, the endpoint is marked as
+// unhealthy and the connection is reset.
+func (e *upstreamEndpoint) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	client, err := e.dial(ctx)
+	if err == nil {
+		err = client.CallContext(ctx, result, method, args...)
+	}
+	if _, ok := err.(Error); err != nil && !ok {
+		e.setHealthy(false)
+		e.mu.Lock()
+		if e.client == client && client != nil {
+			e.client.Close()
+			e.client = nil
+		}
+		e.mu.Unlock()
+		return err
+	}
+	e.setHealthy(true)
+	return err
+}
+
+func (e *upstreamEndpoint) setHealthy(healthy bool) {
+	if healthy {
+		atomic.StoreInt32(&e.healthy, 1)
+	} else {
+		atomic.StoreInt32(&e.healthy, 0)
+	}
+}
+
+func (e *upstreamEndpoint) isHealthy() bool {
+	return atomic.LoadInt32(&e.healthy) == 1
+}
+
+func (e *upstreamEndpoint) close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.client != nil {
+		e.client.Close()
+		e.client = nil
+	}
+}
+
+// upstreamGroup is a list of the endpoints failing over to each other.
+type upstreamGroup struct {
+	name      string
+	endpoints []*upstreamEndpoint
+
+	requestsCounter  metrics.Counter
+	errorsCounter    metrics.Counter
+	failoversCounter metrics.Counter
+	healthyGauge     metrics.Gauge
+}
+
+func newUpstreamGroup(name string, urls []string) *upstreamGroup {
+	g := &upstreamGroup{
+		name:             name,
+		requestsCounter:  metrics.GetOrRegisterCounter("rpc/upstream/"+name+"/requests", nil),
+		errorsCounter:    metrics.GetOrRegisterCounter("rpc/upstream/"+name+"/errors", nil),
+		failoversCounter: metrics.GetOrRegisterCounter("rpc/upstream/"+name+"/failovers", nil),
+		healthyGauge:     metrics.GetOrRegisterGauge("rpc/upstream/"+name+"/healthy", nil),
+	}
+	for _, url := range urls {
+		// The endpoints are assumed to be healthy until the first failure.
+		g.endpoints = append(g.endpoints, &upstreamEndpoint{url: url, healthy: 1})
+	}
+	g.healthyGauge.Update(int64(len(g.endpoints)))
+	return g
+}
+
+// candidates returns the endpoints in the order to try, the healthy ones first.
+func (g *upstreamGroup) candidates() []*upstreamEndpoint {
+	candidates := make([]*upstreamEndpoint, 0, len(g.endpoints))
+	for _, e := range g.endpoints {
+		if e.isHealthy() {
+			candidates = append(candidates, e)
+		}
+	}
+	for _, e := range g.endpoints {
+		if !e.isHealthy() {
+			candidates = append(candidates, e)
+		}
+	}
+	return candidates
+}
+
+// forward sends the call to the endpoints of the group and returns the response.
+func (g *upstreamGroup) forward(ctx context.Context, msg *jsonrpcMessage) *jsonrpcMessage {
+	ctx, cancel := context.WithTimeout(ctx, DefaultHTTPTimeouts.ExecutionTimeout)
+	defer cancel()
+
+	var params []interface{}
+	if len(msg.Params) > 0 {
+		var raw []json.RawMessage
+		if err := json.Unmarshal(msg.Params, &raw); err != nil {
+			rpcErrorResponsesCounter.Inc(1)
+			return msg.errorResponse(&invalidParamsError{err.Error()})
+		}
+		for _, p := range raw {
+			params = append(params, p)
+		}
+	}
+
+	g.requestsCounter.Inc(1)
+	var err error
+	for i, e := range g.candidates() {
+		if i > 0 {
+			g.failoversCounter.Inc(1)
+		}
+		var result json.RawMessage
+		if err = e.call(ctx, &result, msg.Method, params...); err == nil {
+			rpcSuccessResponsesCounter.Inc(1)
+			return &jsonrpcMessage{Version: vsn, ID: msg.ID, Result: result}
+		}
+		if _, ok := err.(Error); ok {
+			// The endpoint is healthy, the call itself failed.
+			break
+		}
+		logger.Debug("Failed to request upstream", "group", g.name, "url", e.url, "method", msg.Method, "err", err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	g.errorsCounter.Inc(1)
+	rpcErrorResponsesCounter.Inc(1)
+	return msg.errorResponse(err)
+}
+
+// healthCheckMethods are tried in order to request the head block number, so
+// that the endpoints serving only the klay namespace are checked as well.
+var healthCheckMethods = []string{"kaia_blockNumber", "klay_blockNumber"}
+
+// checkHealth requests the head block number to the endpoints of the group.
+func (g *upstreamGroup) checkHealth(timeout time.Duration) {
+	var wg sync.WaitGroup
+	for _, e := range g.endpoints {
+		wg.Add(1)
+		go func(e *upstreamEndpoint) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			var err error
+			for _, method := range healthCheckMethods {
+				var head hexutil.Uint64
+				if err = e.call(ctx, &head, method); err == nil {
+					return
+				}
+				if _, ok := err.(Error); !ok {
+					break
+				}
+			}
+			e.setHealthy(false)
+			logger.Debug("Upstream health check failed", "group", g.name, "url", e.url, "err", err)
+		}(e)
+	}
+	wg.Wait()
+
+	healthy := 0
+	for _, e := range g.endpoints {
+		if e.isHealthy() {
+			healthy++
+		}
+	}
+	g.healthyGauge.Update(int64(healthy))
+}
+
+// upstreamRule is a parsed UpstreamRule.
+type upstreamRule struct {
+	methods    map[string]bool
+	prefixes   []string
+	olderThan  uint64
+	blockParam *int
+	group      *upstreamGroup
+}
+
+func (r *upstreamRule) matches(method string) bool {
+	if r.methods[method] {
+		return true
+	}
+	for _, prefix := range r.prefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockParamOf returns the position of the block number parameter of the method.
+func (r *upstreamRule) blockParamOf(method string) (int, bool) {
+	if r.blockParam != nil {
+		return *r.blockParam, true
+	}
+	elem := strings.SplitN(method, serviceMethodSeparator, 2)
+	if len(elem) != 2 {
+		return 0, false
+	}
+	switch elem[0] {
+	case "kaia", "klay", "eth":
+		index, ok := blockParamIndex[elem[1]]
+		return index, ok
+	case "debug":
+		index, ok := debugBlockParamIndex[elem[1]]
+		return index, ok
+	}
+	return 0, false
+}
+
+// UpstreamRouter routes the RPC calls to the upstream endpoints by the rules,
+// and retries the calls failed locally because of the missing trie nodes.
+type UpstreamRouter struct {
+	groups   map[string]*upstreamGroup
+	rules    []*upstreamRule
+	fallback *upstreamGroup
+	interval time.Duration
+
+	mu   sync.Mutex
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewUpstreamRouter creates an UpstreamRouter from the given configuration.
+func NewUpstreamRouter(config *UpstreamConfig) (*UpstreamRouter, error) {
+	r := &UpstreamRouter{
+		groups:   make(map[string]*upstreamGroup),
+		interval: defaultHealthCheckInterval,
+	}
+	if config.HealthCheckInterval != 0 {
+		r.interval = time.Duration(config.HealthCheckInterval) * time.Second
+	}
+	for name, urls := range config.Upstreams {
+		if len(urls) == 0 {
+			return nil, fmt.Errorf("no endpoint in upstream %q", name)
+		}
+		r.groups[name] = newUpstreamGroup(name, urls)
+	}
+	for i, rule := range config.Rules {
+		group, ok := r.groups[rule.Upstream]
+		if !ok {
+			return nil, fmt.Errorf("unknown upstream %q in rule %d", rule.Upstream, i)
+		}
+		if len(rule.Methods) == 0 {
+			return nil, fmt.Errorf("no method in rule %d", i)
+		}
+		parsed := &upstreamRule{methods: make(map[string]bool), olderThan: rule.OlderThan, blockParam: rule.BlockParam, group: group}
+		for _, method := range rule.Methods {
+			if strings.HasSuffix(method, "*") {
+				parsed.prefixes = append(parsed.prefixes, strings.TrimSuffix(method, "*"))
+			} else {
+				parsed.methods[method] = true
+			}
+		}
+		r.rules = append(r.rules, parsed)
+	}
+	if config.Fallback != "" {
+		group, ok := r.groups[config.Fallback]
+		if !ok {
+			return nil, fmt.Errorf("unknown fallback upstream %q", config.Fallback)
+		}
+		r.fallback = group
+	}
+	return r, nil
+}
+
+// Start starts the health checks of the upstream endpoints.
+func (r *UpstreamRouter) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.quit != nil {
+		return
+	}
+	r.quit = make(chan struct{})
+	r.wg.Add(1)
+	go r.loop(r.quit)
+}
+
+// Stop stops the health checks and closes the connections to the upstream endpoints.
+func (r *UpstreamRouter) Stop() {
+	r.mu.Lock()
+	if r.quit != nil {
+		close(r.quit)
+		r.quit = nil
+	}
+	r.mu.Unlock()
+	r.wg.Wait()
+
+	for _, g := range r.groups {
+		for _, e := range g.endpoints {
+			e.close()
+		}
+	}
+}
+
+func (r *UpstreamRouter) loop(quit chan struct{}) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.checkHealth()
+	for {
+		select {
+		case <-ticker.C:
+			r.checkHealth()
+		case <-quit:
+			return
+		}
+	}
+}
+
+func (r *UpstreamRouter) checkHealth() {
+	for _, g := range r.groups {
+		g.checkHealth(r.interval)
+	}
+}
+
+// route returns the upstream group of the call, or nil if it should be
+// processed locally. The age of the requested block is measured against the
+// local head returned by localHead, and the rules with the block age are
+// skipped if the local head is not available.
+func (r *UpstreamRouter) route(ctx context.Context, msg *jsonrpcMessage, localHead func(context.Context) (uint64, bool)) *upstreamGroup {
+	for _, rule := range r.rules {
+		if !rule.matches(msg.Method) {
+			continue
+		}
+		if rule.olderThan == 0 {
+			return rule.group
+		}
+		index, ok := rule.blockParamOf(msg.Method)
+		if !ok {
+			continue
+		}
+		number, ok := requestedBlockNumber(msg.Params, index)
+		if !ok {
+			continue
+		}
+		if head, ok := localHead(ctx); ok && head > number && head-number > rule.olderThan {
+			return rule.group
+		}
+	}
+	return nil
+}
+
+// requestedBlockNumber parses the block number parameter at the given position.
+// It returns false if the parameter is missing, a block hash or the latest or
+// pending block tag.
+func requestedBlockNumber(params json.RawMessage, index int) (uint64, bool) {
+	var args []json.RawMessage
+	if err := json.Unmarshal(params, &args); err != nil || index < 0 || index >= len(args) {
+		return 0, false
+	}
+	arg := args[index]
+
+	// BlockNumberOrHash may be given as an object.
+	var obj struct {
+		BlockNumber *json.RawMessage `json:"blockNumber"`
+	}
+	if len(arg) > 0 && arg[0] == '{' {
+		if err := json.Unmarshal(arg, &obj); err != nil || obj.BlockNumber == nil {
+			return 0, false
+		}
+		arg = *obj.BlockNumber
+	}
+
+	var tag string
+	if err := json.Unmarshal(arg, &tag); err != nil {
+		// A plain JSON number.
+		number, err := strconv.ParseUint(string(arg), 10, 64)
+		return number, err == nil
+	}
+	switch tag {
+	case "earliest":
+		return 0, true
+	case "latest", "pending":
+		return 0, false
+	}
+	number, err := hexutil.DecodeUint64(tag)
+	return number, err == nil
+}
+
+// forward sends the call to the routed upstream group. It returns nil if the
+// call should be processed locally.
+func (r *UpstreamRouter) forward(ctx context.Context, msg *jsonrpcMessage, localHead func(context.Context) (uint64, bool)) *jsonrpcMessage {
+	if group := r.route(ctx, msg, localHead); group != nil {
+		return group.forward(ctx, msg)
+	}
+	return nil
+}
+
+// retry sends the call failed locally to the fallback upstream group. It
+// returns nil if the fallback is not configured.
+func (r *UpstreamRouter) retry(ctx context.Context, msg *jsonrpcMessage) *jsonrpcMessage {
+	if r.fallback == nil {
+		return nil
+	}
+	return r.fallback.forward(ctx, msg)
+}
+
+type upstreamContextKey struct{}
+
+// SetUpstreamRouter sets the router of the calls served by the server. If the
+// router is nil, all calls are processed locally.
+//
+// This method should be called before the server starts to serve the requests.
+func (s *Server) SetUpstreamRouter(router *UpstreamRouter) {
+	s.upstream = router
+}
+
+// withUpstream returns a copy of the context carrying the upstream router of
+// the server.
+func (s *Server) withUpstream(ctx context.Context) context.Context {
+	if s.upstream == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, upstreamContextKey{}, s.upstream)
+}
+
+// upstreamFromContext returns the upstream router of the call, or nil if the
+// call is processed locally.
+func upstreamFromContext(ctx context.Context) *UpstreamRouter {
+	router, _ := ctx.Value(upstreamContextKey{}).(*UpstreamRouter)
+	return router
+}
@@ -0,0 +1,225 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upstreamTestService is the local service whose calls may be routed to the
+// upstream.
+type upstreamTestService struct{}
+
+func (s *upstreamTestService) BlockNumber() hexutil.Uint64 { return 1000 }
+
+func (s *upstreamTestService) GetBalance(addr string, block json.RawMessage) string { return "local" }
+
+func (s *upstreamTestService) GetCode(addr string, block json.RawMessage) (string, error) {
+	return "", &statedb.MissingNodeError{}
+}
+
+func (s *upstreamTestService) Fail() error { return errors.New("failed in local") }
+
+// newUpstreamTestServer starts a plain JSON-RPC server standing for an
+// upstream EN. If klayOnly is true, the methods of the kaia namespace are not
+// served.
+func newUpstreamTestServer(t *testing.T, klayOnly bool) *httptest.Server {
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg jsonrpcMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var resp *jsonrpcMessage
+		switch {
+		case klayOnly && msg.namespace() == "kaia":
+			resp = msg.errorResponse(&methodNotFoundError{method: msg.Method})
+		case msg.Method == "kaia_blockNumber" || msg.Method == "klay_blockNumber":
+			resp = msg.response(hexutil.Uint64(1000))
+		case msg.Method == "kaia_fail":
+			resp = msg.errorResponse(errors.New("failed in upstream"))
+		default:
+			resp = msg.response("upstream")
+		}
+		w.Header().Set("content-type", contentType)
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(hs.Close)
+	return hs
+}
+
+func TestRequestedBlockNumber(t *testing.T) {
+	tests := []struct {
+		params string
+		index  int
+		number uint64
+		ok     bool
+	}{
+		{`["0xabc", "0x10"]`, 1, 16, true},
+		{`["0xabc", 16]`, 1, 16, true},
+		{`["0xabc", "earliest"]`, 1, 0, true},
+		{`["0xabc", "latest"]`, 1, 0, false},
+		{`["0xabc", "pending"]`, 1, 0, false},
+		{`["0xabc", {"blockNumber": "0x10"}]`, 1, 16, true},
+		{`["0xabc", {"blockHash": "0x1234"}]`, 1, 0, false},
+		{`["0xabc"]`, 1, 0, false},
+		{`{}`, 0, 0, false},
+	}
+	for _, tt := range tests {
+		number, ok := requestedBlockNumber(json.RawMessage(tt.params), tt.index)
+		assert.Equal(t, tt.ok, ok, tt.params)
+		assert.Equal(t, tt.number, number, tt.params)
+	}
+}
+
+func TestUpstreamRouter_Route(t *testing.T) {
+	blockParam := 0
+	router, err := NewUpstreamRouter(&UpstreamConfig{
+		Upstreams: map[string][]string{"trace": {"http://trace"}, "archive": {"http://archive"}},
+		Rules: []UpstreamRule{
+			{Methods: []string{"debug_trace*"}, Upstream: "trace"},
+			{Methods: []string{"kaia_getBalance", "eth_getBalance"}, OlderThan: 100, Upstream: "archive"},
+			{Methods: []string{"kaia_custom"}, OlderThan: 100, BlockParam: &blockParam, Upstream: "archive"},
+		},
+	})
+	require.NoError(t, err)
+	head, headOK := uint64(1000), true
+	localHead := func(context.Context) (uint64, bool) { return head, headOK }
+
+	route := func(method, params string) string {
+		group := router.route(context.Background(), &jsonrpcMessage{Method: method, Params: json.RawMessage(params)}, localHead)
+		if group == nil {
+			return ""
+		}
+		return group.name
+	}
+	assert.Equal(t, "trace", route("debug_traceTransaction", `["0x1"]`))
+	assert.Equal(t, "archive", route("kaia_getBalance", `["0xabc", "0x1"]`))
+	assert.Equal(t, "archive", route("eth_getBalance", `["0xabc", "earliest"]`))
+	assert.Equal(t, "", route("kaia_getBalance", `["0xabc", "0x3e8"]`))
+	assert.Equal(t, "", route("kaia_getBalance", `["0xabc", "latest"]`))
+	assert.Equal(t, "archive", route("kaia_custom", `["0x1"]`))
+	assert.Equal(t, "", route("kaia_blockNumber", `[]`))
+
+	// The block ages are measured against the local head.
+	head = 50
+	assert.Equal(t, "", route("kaia_getBalance", `["0xabc", "0x1"]`))
+
+	// The block ages are not decided without the local head.
+	head, headOK = 1000, false
+	assert.Equal(t, "", route("kaia_getBalance", `["0xabc", "0x1"]`))
+	assert.Equal(t, "trace", route("debug_traceTransaction", `["0x1"]`))
+
+	_, err = NewUpstreamRouter(&UpstreamConfig{Rules: []UpstreamRule{{Methods: []string{"debug_trace*"}, Upstream: "unknown"}}})
+	assert.Error(t, err)
+	_, err = NewUpstreamRouter(&UpstreamConfig{Fallback: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestUpstreamRouter_Forward(t *testing.T) {
+	upstream := newUpstreamTestServer(t, false)
+	router, err := NewUpstreamRouter(&UpstreamConfig{
+		// The first endpoint is not reachable, so the calls fail over to the second one.
+		Upstreams: map[string][]string{"archive": {"http://127.0.0.1:1", upstream.URL}},
+		Rules: []UpstreamRule{
+			{Methods: []string{"kaia_getBalance"}, OlderThan: 100, Upstream: "archive"},
+			{Methods: []string{"kaia_fail"}, Upstream: "archive"},
+		},
+		Fallback: "archive",
+	})
+	require.NoError(t, err)
+	router.checkHealth()
+	defer router.Stop()
+	group := router.groups["archive"]
+	assert.False(t, group.endpoints[0].isHealthy())
+	assert.True(t, group.endpoints[1].isHealthy())
+
+	server := newTestServer("kaia", new(upstreamTestService))
+	server.SetUpstreamRouter(router)
+	defer server.Stop()
+	local := httptest.NewServer(server)
+	defer local.Close()
+	client, err := DialHTTP(local.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	var result string
+	require.NoError(t, client.Call(&result, "kaia_getBalance", "0xabc", "0x1"))
+	assert.Equal(t, "upstream", result)
+	require.NoError(t, client.Call(&result, "kaia_getBalance", "0xabc", "latest"))
+	assert.Equal(t, "local", result)
+
+	// The calls failed with the missing trie nodes are retried in the fallback.
+	require.NoError(t, client.Call(&result, "kaia_getCode", "0xabc", "latest"))
+	assert.Equal(t, "upstream", result)
+
+	// The errors of the upstream are returned as they are.
+	err = client.Call(&result, "kaia_fail")
+	require.Error(t, err)
+	assert.Equal(t, "failed in upstream", err.Error())
+
+	// All endpoints are tried before returning the connection errors.
+	group.endpoints[1].url = "http://127.0.0.1:1"
+	group.endpoints[1].close()
+	failovers := group.failoversCounter.Count()
+	err = client.Call(&result, "kaia_getBalance", "0xabc", "0x1")
+	require.Error(t, err)
+	assert.Equal(t, failovers+1, group.failoversCounter.Count())
+}
+
+func TestUpstreamRouter_LocalServer(t *testing.T) {
+	upstream := newUpstreamTestServer(t, false)
+	router, err := NewUpstreamRouter(&UpstreamConfig{
+		Upstreams: map[string][]string{"archive": {upstream.URL}},
+		Rules:     []UpstreamRule{{Methods: []string{"kaia_*"}, Upstream: "archive"}},
+	})
+	require.NoError(t, err)
+	defer router.Stop()
+
+	// The router is applied only to the servers it's set to.
+	server := newTestServer("kaia", new(upstreamTestService))
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var result string
+	require.NoError(t, client.Call(&result, "kaia_getBalance", "0xabc", "0x1"))
+	assert.Equal(t, "local", result)
+}
+
+func TestUpstreamRouter_CheckHealthKlay(t *testing.T) {
+	upstream := newUpstreamTestServer(t, true)
+	router, err := NewUpstreamRouter(&UpstreamConfig{
+		Upstreams: map[string][]string{"archive": {upstream.URL}},
+	})
+	require.NoError(t, err)
+	defer router.Stop()
+
+	// The endpoints serving only the klay namespace are healthy.
+	router.checkHealth()
+	assert.True(t, router.groups["archive"].endpoints[0].isHealthy())
+}
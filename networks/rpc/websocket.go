@@ -81,12 +81,17 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			atomic.AddInt32(&srv.wsConnCount, -1)
 			wsConnCounter.Dec(1)
 		}()
+		access, code, err := srv.authenticate(r.Header.Get("Authorization"))
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			return
 		}
 		codec := newWebsocketCodec(conn)
-		srv.ServeCodec(codec, 0)
+		srv.serveCodec(srv.withRateLimit(withAccess(context.Background(), access), r.RemoteAddr, access), codec)
 	})
 }
 
@@ -102,7 +107,13 @@ func (srv *Server) FastWebsocketHandler(ctx *fasthttp.RequestCtx) {
 		ctx.Response.Header.Set("Sec-WebSocket-Protocol", string(protocol))
 	}
 
-	err := upgrader.Upgrade(ctx, func(conn *fastws.Conn) {
+	access, code, err := srv.authenticate(string(ctx.Request.Header.Peek("Authorization")))
+	if err != nil {
+		writeFastError(ctx, code, err)
+		return
+	}
+
+	err = upgrader.Upgrade(ctx, func(conn *fastws.Conn) {
 		if atomic.LoadInt32(&srv.wsConnCount) >= MaxWebsocketConnections {
 			return
 		}
@@ -141,7 +152,8 @@ func (srv *Server) FastWebsocketHandler(ctx *fasthttp.RequestCtx) {
 		}
 
 		reader := bufio.NewReaderSize(bytes.NewReader(ctx.Request.Body()), common.MaxRequestContentLength)
-		srv.ServeCodec(NewFuncCodec(&httpReadWriteNopCloser{reader, ctx.Response.BodyWriter()}, encoder, decoder), 0)
+		connCtx := srv.withRateLimit(withAccess(context.Background(), access), ctx.RemoteAddr().String(), access)
+		srv.serveCodec(connCtx, NewFuncCodec(&httpReadWriteNopCloser{reader, ctx.Response.BodyWriter()}, encoder, decoder))
 	})
 	if err != nil {
 		logger.Error("FastWebsocketHandler fail to upgrade message", "err", err)
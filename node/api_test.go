@@ -184,6 +184,8 @@ func runTestWithServerType(t *testing.T, test test, httpServerType string) {
 	// Setting test node config
 	config := test.cfg
 	config.P2P.NoDiscovery = true
+	// Keep the generated node key out of the working directory.
+	config.DataDir = t.TempDir()
 
 	// Create Node.
 	stack, err := New(&config)
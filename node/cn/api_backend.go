@@ -22,6 +22,7 @@ package cn
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -45,6 +46,14 @@ import (
 	"github.com/klaytn/klaytn/work"
 )
 
+// The errors returned if a bundle or a conditional transaction is sent to a
+// node which doesn't mine blocks. They are not propagated to the peers, so only
+// the consensus nodes can include them.
+var (
+	errBundleNotSupported        = errors.New("bundles are only accepted by consensus nodes")
+	errConditionalTxNotSupported = errors.New("conditional transactions are only accepted by consensus nodes")
+)
+
 // CNAPIBackend implements api.Backend for full nodes
 type CNAPIBackend struct {
 	cn  *CN
@@ -265,9 +274,28 @@ func (b *CNAPIBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscrip
 }
 
 func (b *CNAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
+	if signedTx.Conditional() != nil && b.cn.protocolManager.NodeType() != common.CONSENSUSNODE {
+		return errConditionalTxNotSupported
+	}
 	return b.cn.txPool.AddLocal(signedTx)
 }
 
+func (b *CNAPIBackend) SendBundle(ctx context.Context, bundle *blockchain.Bundle) (common.Hash, error) {
+	if b.cn.protocolManager.NodeType() != common.CONSENSUSNODE {
+		return common.Hash{}, errBundleNotSupported
+	}
+	head := b.cn.blockchain.CurrentBlock()
+	statedb, err := b.cn.blockchain.StateAt(head.Root())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	signer := types.LatestSignerForChainID(b.ChainConfig().ChainID)
+	if err := bundle.ValidateTxs(signer, statedb, head.NumberU64()); err != nil {
+		return common.Hash{}, err
+	}
+	return b.cn.bundlePool.Add(bundle, head.NumberU64())
+}
+
 func (b *CNAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending, err := b.cn.txPool.Pending()
 	if err != nil {
@@ -296,10 +324,18 @@ func (b *CNAPIBackend) TxPoolContent() (map[common.Address]types.Transactions, m
 	return b.cn.TxPool().Content()
 }
 
+func (b *CNAPIBackend) TxPoolLifecycle(hash common.Hash) (blockchain.TxStatus, []blockchain.TxLifecycleEvent) {
+	return b.cn.TxPool().Status([]common.Hash{hash})[0], b.cn.TxPool().TxLifecycle(hash)
+}
+
 func (b *CNAPIBackend) SubscribeNewTxsEvent(ch chan<- blockchain.NewTxsEvent) event.Subscription {
 	return b.cn.TxPool().SubscribeNewTxsEvent(ch)
 }
 
+func (b *CNAPIBackend) SubscribeTxLifecycleEvent(ch chan<- blockchain.TxLifecycleEvent) event.Subscription {
+	return b.cn.TxPool().SubscribeTxLifecycleEvent(ch)
+}
+
 func (b *CNAPIBackend) Progress() kaia.SyncProgress {
 	return b.cn.Progress()
 }
@@ -585,6 +585,47 @@ func TestCNAPIBackend_SendTx(t *testing.T) {
 	assert.Equal(t, expectedErr, api.SendTx(context.Background(), tx1))
 }
 
+func TestCNAPIBackend_SendTx_Conditional(t *testing.T) {
+	tx := types.NewTransaction(0, addrs[0], common.Big0, 21000, common.Big0, nil)
+	tx.SetConditional(&types.TransactionConditional{})
+
+	for _, nodeType := range []common.ConnType{common.PROXYNODE, common.ENDPOINTNODE} {
+		mockCtrl, _, _, api := newCNAPIBackend(t)
+		mockPM := NewMockBackendProtocolManager(mockCtrl)
+		mockPM.EXPECT().NodeType().Return(nodeType).Times(1)
+		api.cn.protocolManager = mockPM
+
+		assert.ErrorIs(t, api.SendTx(context.Background(), tx), errConditionalTxNotSupported)
+		mockCtrl.Finish()
+	}
+
+	// The consensus nodes add it to the pool.
+	mockCtrl, _, _, api := newCNAPIBackend(t)
+	defer mockCtrl.Finish()
+	mockPM := NewMockBackendProtocolManager(mockCtrl)
+	mockPM.EXPECT().NodeType().Return(common.CONSENSUSNODE).Times(1)
+	mockTxPool := mocks.NewMockTxPool(mockCtrl)
+	mockTxPool.EXPECT().AddLocal(tx).Return(nil).Times(1)
+	api.cn.protocolManager = mockPM
+	api.cn.txPool = mockTxPool
+
+	assert.NoError(t, api.SendTx(context.Background(), tx))
+}
+
+func TestCNAPIBackend_SendBundle_NotConsensusNode(t *testing.T) {
+	for _, nodeType := range []common.ConnType{common.PROXYNODE, common.ENDPOINTNODE} {
+		mockCtrl, _, _, api := newCNAPIBackend(t)
+		mockPM := NewMockBackendProtocolManager(mockCtrl)
+		mockPM.EXPECT().NodeType().Return(nodeType).Times(1)
+		api.cn.protocolManager = mockPM
+
+		bundle := &blockchain.Bundle{Txs: types.Transactions{tx1}, MinBlock: 1, MaxBlock: 1}
+		_, err := api.SendBundle(context.Background(), bundle)
+		assert.ErrorIs(t, err, errBundleNotSupported)
+		mockCtrl.Finish()
+	}
+}
+
 func TestCNAPIBackend_GetPoolTransactions(t *testing.T) {
 	{
 		mockCtrl, _, _, api := newCNAPIBackend(t)
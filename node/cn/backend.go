@@ -51,6 +51,7 @@ import (
 	"github.com/klaytn/klaytn/node/cn/filters"
 	"github.com/klaytn/klaytn/node/cn/gasprice"
 	"github.com/klaytn/klaytn/node/cn/tracers"
+	_ "github.com/klaytn/klaytn/node/cn/tracers/native"
 	"github.com/klaytn/klaytn/params"
 	"github.com/klaytn/klaytn/reward"
 	"github.com/klaytn/klaytn/rlp"
@@ -105,6 +106,7 @@ type CN struct {
 
 	// Handlers
 	txPool          work.TxPool
+	bundlePool      *blockchain.BundlePool
 	blockchain      work.BlockChain
 	protocolManager BackendProtocolManager
 	lesServer       LesServer
@@ -253,17 +255,7 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 	}
 	var (
 		vmConfig    = config.getVMConfig()
-		cacheConfig = &blockchain.CacheConfig{
-			ArchiveMode:          config.NoPruning,
-			CacheSize:            config.TrieCacheSize,
-			BlockInterval:        config.TrieBlockInterval,
-			TriesInMemory:        config.TriesInMemory,
-			LivePruningRetention: config.LivePruningRetention,
-			TrieNodeCacheConfig:  &config.TrieNodeCacheConfig,
-			SenderTxHashIndexing: config.SenderTxHashIndexing,
-			SnapshotCacheSize:    config.SnapshotCacheSize,
-			SnapshotAsyncGen:     config.SnapshotAsyncGen,
-		}
+		cacheConfig = config.getCacheConfig()
 	)
 
 	bc, err := blockchain.NewBlockChain(chainDB, cacheConfig, cn.chainConfig, cn.engine, vmConfig)
@@ -293,23 +285,9 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 	}
 
 	cn.blockchain = bc
-	governance.SetBlockchain(cn.blockchain)
-	if err := governance.UpdateParams(cn.blockchain.CurrentBlock().NumberU64()); err != nil {
-		return nil, err
-	}
-	blockchain.InitDeriveShaWithGov(cn.chainConfig, governance)
-
-	// Synchronize proposerpolicy & useGiniCoeff
-	pset, err := governance.EffectiveParams(bc.CurrentBlock().NumberU64() + 1)
-	if err != nil {
+	if err := setupGovernance(bc, governance, chainDB); err != nil {
 		return nil, err
 	}
-	if cn.blockchain.Config().Istanbul != nil {
-		cn.blockchain.Config().Istanbul.ProposerPolicy = pset.Policy()
-	}
-	if cn.blockchain.Config().Governance.Reward != nil {
-		cn.blockchain.Config().Governance.Reward.UseGiniCoeff = pset.UseGiniCoeff()
-	}
 
 	if config.SenderTxHashIndexing {
 		ch := make(chan blockchain.ChainEvent, 255)
@@ -331,6 +309,7 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 	// TODO-Kaia-ServiceChain: add account creation prevention in the txPool if TxTypeAccountCreation is supported.
 	config.TxPool.NoAccountCreation = config.NoAccountCreation
 	cn.txPool = blockchain.NewTxPool(config.TxPool, cn.chainConfig, bc)
+	cn.bundlePool = blockchain.NewBundlePool(blockchain.DefaultBundlePoolSize)
 	governance.SetTxPool(cn.txPool)
 
 	// Permit the downloader to use the trie cache allowance during fast sync
@@ -357,10 +336,6 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 	}
 
 	// Setup reward related components
-	if pset.Policy() == uint64(istanbul.WeightedRandom) {
-		// NewStakingManager is called with proper non-nil parameters
-		reward.NewStakingManager(cn.blockchain, governance, cn.chainDB)
-	}
 	cn.supplyManager = reward.NewSupplyManager(cn.blockchain, cn.governance, cn.chainDB, config.TrieBlockInterval)
 
 	// Governance states which are not yet applied to the db remains at in-memory storage
@@ -519,6 +494,79 @@ func CreateConsensusEngine(ctx *node.ServiceContext, config *Config, chainConfig
 	})
 }
 
+// MakeChain creates a block chain on top of the chaindata without starting the
+// protocol stack. It is used by the offline commands which import or export
+// blocks while the node is stopped. The returned function stops the block chain
+// and closes the database.
+func MakeChain(ctx *node.ServiceContext, config *Config) (*blockchain.BlockChain, func(), error) {
+	chainDB := CreateDB(ctx, config, "chaindata")
+	fail := func(err error) (*blockchain.BlockChain, func(), error) {
+		chainDB.Close()
+		return nil, nil, err
+	}
+
+	chainConfig, _, genesisErr := blockchain.SetupGenesisBlock(chainDB, config.Genesis, config.NetworkId, config.IsPrivate, false)
+	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
+		return fail(genesisErr)
+	}
+	setEngineType(chainConfig)
+	chainConfig.SetDefaults()
+	gov := governance.NewMixedEngine(chainConfig, chainDB)
+	engine := CreateConsensusEngine(ctx, config, chainConfig, chainDB, gov, ctx.NodeType())
+
+	bc, err := blockchain.NewBlockChain(chainDB, config.getCacheConfig(), chainConfig, engine, config.getVMConfig())
+	if err != nil {
+		return fail(err)
+	}
+	stop := func() {
+		bc.Stop()
+		chainDB.Close()
+	}
+
+	// Same as New, the governance and the reward parameters are synchronized
+	// with the head block before any block is inserted.
+	if err := setupGovernance(bc, gov, chainDB); err != nil {
+		stop()
+		return nil, nil, err
+	}
+	if istBackend, ok := engine.(consensus.Istanbul); ok {
+		istBackend.SetChain(bc)
+	}
+	if err := engine.CreateSnapshot(bc, bc.CurrentBlock().NumberU64(), bc.CurrentBlock().Hash(), nil); err != nil {
+		logger.Error("CreateSnapshot failed", "err", err)
+	}
+	return bc, stop, nil
+}
+
+// setupGovernance synchronizes the governance parameters, the proposer policy
+// and the reward configuration with the head block of the given chain, and
+// sets up the staking manager if the weighted random proposer policy is used.
+func setupGovernance(bc *blockchain.BlockChain, gov governance.Engine, chainDB database.DBManager) error {
+	gov.SetBlockchain(bc)
+	if err := gov.UpdateParams(bc.CurrentBlock().NumberU64()); err != nil {
+		return err
+	}
+	blockchain.InitDeriveShaWithGov(bc.Config(), gov)
+
+	// Synchronize proposerpolicy & useGiniCoeff
+	pset, err := gov.EffectiveParams(bc.CurrentBlock().NumberU64() + 1)
+	if err != nil {
+		return err
+	}
+	if bc.Config().Istanbul != nil {
+		bc.Config().Istanbul.ProposerPolicy = pset.Policy()
+	}
+	if bc.Config().Governance.Reward != nil {
+		bc.Config().Governance.Reward.UseGiniCoeff = pset.UseGiniCoeff()
+	}
+
+	if pset.Policy() == uint64(istanbul.WeightedRandom) {
+		// NewStakingManager is called with proper non-nil parameters
+		reward.NewStakingManager(bc, gov, chainDB)
+	}
+	return nil
+}
+
 // APIs returns the collection of RPC services the ethereum package offers.
 // NOTE, some of these services probably need to be moved to somewhere else.
 func (s *CN) APIs() []rpc.API {
@@ -583,6 +631,11 @@ func (s *CN) APIs() []rpc.API {
 			Service:   tracers.NewUnsafeAPI(s.APIBackend),
 			Public:    false,
 			IPCOnly:   s.config.DisableUnsafeDebug,
+		}, {
+			Namespace: "trace",
+			Version:   "1.0",
+			Service:   tracers.NewTraceAPI(s.APIBackend),
+			Public:    false,
 		}, {
 			Namespace: "net",
 			Version:   "1.0",
@@ -662,6 +715,7 @@ func (s *CN) Miner() Miner   { return s.miner }
 func (s *CN) AccountManager() accounts.AccountManager { return s.accountManager }
 func (s *CN) BlockChain() work.BlockChain             { return s.blockchain }
 func (s *CN) TxPool() work.TxPool                     { return s.txPool }
+func (s *CN) BundlePool() *blockchain.BundlePool      { return s.bundlePool }
 func (s *CN) EventMux() *event.TypeMux                { return s.eventMux }
 func (s *CN) Engine() consensus.Engine                { return s.engine }
 func (s *CN) ChainDB() database.DBManager             { return s.chainDB }
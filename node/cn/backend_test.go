@@ -23,6 +23,8 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/klaytn/klaytn/blockchain/types"
 	"github.com/klaytn/klaytn/datasync/downloader"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/node"
 	"github.com/klaytn/klaytn/node/cn/mocks"
 	"github.com/klaytn/klaytn/params"
 	mocks2 "github.com/klaytn/klaytn/work/mocks"
@@ -73,6 +75,18 @@ func TestCN_SetEngineType(t *testing.T) {
 	assert.Equal(t, types.Engine_IBFT, types.EngineType)
 }
 
+func TestMakeChain(t *testing.T) {
+	// The default genesis block is written to the empty chaindata.
+	ctx := node.NewServiceContext(&node.Config{Name: "test", DataDir: t.TempDir()}, nil, new(event.TypeMux), nil)
+	chain, stop, err := MakeChain(ctx, GetDefaultConfig())
+	assert.NoError(t, err)
+	defer stop()
+
+	assert.Equal(t, uint64(0), chain.CurrentBlock().NumberU64())
+	assert.Equal(t, params.CypressGenesisHash, chain.Genesis().Hash())
+	assert.Equal(t, types.Engine_IBFT, types.EngineType)
+}
+
 func TestCN_SetAcceptTxs(t *testing.T) {
 	{
 		mockCtrl, _, _, cn := newCN(t)
@@ -204,3 +204,17 @@ func (c *Config) getVMConfig() vm.Config {
 		EnableOpDebug:           c.EnableOpDebug,
 	}
 }
+
+func (c *Config) getCacheConfig() *blockchain.CacheConfig {
+	return &blockchain.CacheConfig{
+		ArchiveMode:          c.NoPruning,
+		CacheSize:            c.TrieCacheSize,
+		BlockInterval:        c.TrieBlockInterval,
+		TriesInMemory:        c.TriesInMemory,
+		LivePruningRetention: c.LivePruningRetention,
+		TrieNodeCacheConfig:  &c.TrieNodeCacheConfig,
+		SenderTxHashIndexing: c.SenderTxHashIndexing,
+		SnapshotCacheSize:    c.SnapshotCacheSize,
+		SnapshotAsyncGen:     c.SnapshotAsyncGen,
+	}
+}
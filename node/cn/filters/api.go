@@ -157,7 +157,23 @@ func (api *PublicFilterAPI) NewPendingTransactionFilter() rpc.ID {
 
 // NewPendingTransactions creates a subscription that is triggered each time a transaction
 // enters the transaction pool and was signed from one of the transactions this nodes manages.
-func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+//
+// If fullTx is true, the full transactions are sent instead of the hashes. Only the
+// transactions matching the criteria are sent if it is given.
+func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool, crit *PendingTxCriteria) (*rpc.Subscription, error) {
+	if fullTx != nil && *fullTx {
+		return api.subscribePendingTxs(ctx, crit, func(tx *types.Transaction) interface{} {
+			return newRPCPendingTransaction(tx)
+		})
+	}
+	return api.subscribePendingTxs(ctx, crit, func(tx *types.Transaction) interface{} {
+		return tx.Hash()
+	})
+}
+
+// subscribePendingTxs creates a subscription that sends the pending transactions
+// matching the criteria in the output of the given marshal function.
+func (api *PublicFilterAPI) subscribePendingTxs(ctx context.Context, crit *PendingTxCriteria, marshal func(*types.Transaction) interface{}) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -166,16 +182,17 @@ func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Su
 	rpcSub := notifier.CreateSubscription()
 
 	go func() {
-		txHashes := make(chan []common.Hash, 128)
-		pendingTxSub := api.events.SubscribePendingTxs(txHashes)
+		pendingTxs := make(chan []*types.Transaction, 128)
+		pendingTxSub := api.events.SubscribeFullPendingTxs(pendingTxs)
 
 		for {
 			select {
-			case hashes := <-txHashes:
-				// To keep the original behaviour, send a single tx hash in one notification.
-				// TODO(rjl493456442) Send a batch of tx hashes in one notification
-				for _, h := range hashes {
-					notifier.Notify(rpcSub.ID, h)
+			case txs := <-pendingTxs:
+				// To keep the original behaviour, send a single tx in one notification.
+				for _, tx := range txs {
+					if crit.Matches(tx) {
+						notifier.Notify(rpcSub.ID, marshal(tx))
+					}
 				}
 			case <-rpcSub.Err():
 				pendingTxSub.Unsubscribe()
@@ -291,11 +308,18 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
-func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
+//
+// If the fromBlock is a block number or the cursor is given, the historical logs from
+// the resume point are replayed before the new logs. The logs of the reorganised blocks
+// are sent again with the removed flag set.
+func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria, cursor *LogCursor) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
+	if cursor != nil || (crit.FromBlock != nil && crit.FromBlock.Sign() >= 0) {
+		return api.replayLogs(ctx, notifier, crit, cursor)
+	}
 
 	var (
 		rpcSub      = notifier.CreateSubscription()
@@ -380,32 +404,34 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 	ctx, cancelFnc := context.WithTimeout(ctx, GetLogsDeadline)
 	defer cancelFnc()
 
-	var filter *Filter
-	if crit.BlockHash != nil {
-		// Block filter requested, construct a single-shot filter
-		filter = NewBlockFilter(api.backend, *crit.BlockHash, crit.Addresses, crit.Topics)
-	} else {
-		// Convert the RPC block numbers into internal representations
-		begin := rpc.LatestBlockNumber.Int64()
-		if crit.FromBlock != nil {
-			begin = crit.FromBlock.Int64()
-		}
-		end := rpc.LatestBlockNumber.Int64()
-		if crit.ToBlock != nil {
-			end = crit.ToBlock.Int64()
-		}
-		// Construct the range filter
-		filter = NewRangeFilter(api.backend, begin, end, crit.Addresses, crit.Topics)
-	}
-
 	// Run the filter and return all the logs
-	logs, err := filter.Logs(ctx)
+	logs, err := api.newCriteriaFilter(crit).Logs(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return returnLogs(logs), err
 }
 
+// newCriteriaFilter creates a block filter if the criteria has the block hash,
+// or a range filter otherwise.
+func (api *PublicFilterAPI) newCriteriaFilter(crit FilterCriteria) *Filter {
+	if crit.BlockHash != nil {
+		// Block filter requested, construct a single-shot filter
+		return NewBlockFilter(api.backend, *crit.BlockHash, crit.Addresses, crit.Topics)
+	}
+	// Convert the RPC block numbers into internal representations
+	begin := rpc.LatestBlockNumber.Int64()
+	if crit.FromBlock != nil {
+		begin = crit.FromBlock.Int64()
+	}
+	end := rpc.LatestBlockNumber.Int64()
+	if crit.ToBlock != nil {
+		end = crit.ToBlock.Int64()
+	}
+	// Construct the range filter
+	return NewRangeFilter(api.backend, begin, end, crit.Addresses, crit.Topics)
+}
+
 // UninstallFilter removes the filter with the given filter id.
 func (api *PublicFilterAPI) UninstallFilter(id rpc.ID) bool {
 	api.filtersMu.Lock()
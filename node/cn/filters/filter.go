@@ -68,6 +68,9 @@ type Filter struct {
 	topics     [][]common.Hash
 
 	matcher *bloombits.Matcher
+
+	cursor *LogCursor // logs before the cursor are skipped (nil = no cursor)
+	limit  int        // logs are gathered up to one more than the limit (0 = no limit)
 }
 
 // NewBlockFilter creates a new filter which directly inspects the contents of
@@ -164,7 +167,7 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		} else {
 			logs, err = f.indexedLogs(ctx, indexed-1)
 		}
-		if err != nil {
+		if err != nil || f.exceedsLimit(logs) {
 			return logs, err
 		}
 	}
@@ -215,8 +218,6 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 				}
 				return logs, err
 			}
-			f.begin = int64(number) + 1
-
 			// Retrieve the suggested block and pull any truly matching logs
 			header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
 			if header == nil || err != nil {
@@ -226,7 +227,11 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 			if err != nil {
 				return logs, err
 			}
+			f.begin = int64(number) + 1
 			logs = append(logs, found...)
+			if f.exceedsLimit(logs) {
+				return logs, nil
+			}
 			if len(logs) > maxItems {
 				return logs, errors.New("query returned more than " + strconv.Itoa(maxItems) + " results")
 			}
@@ -257,6 +262,10 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, e
 				return logs, err
 			}
 			logs = append(logs, found...)
+			if f.exceedsLimit(logs) {
+				f.begin++
+				return logs, nil
+			}
 			if len(logs) > maxItems {
 				return logs, errors.New("query returned more than " + strconv.Itoa(maxItems) + " results")
 			}
@@ -296,11 +305,19 @@ func (f *Filter) checkMatches(ctx context.Context, header *types.Header) (logs [
 			}
 			logs = filterLogs(unfiltered, nil, nil, f.addresses, f.topics)
 		}
+		if f.cursor != nil {
+			logs = f.cursor.skip(logs)
+		}
 		return logs, nil
 	}
 	return nil, nil
 }
 
+// exceedsLimit returns true if the gathered logs are more than the limit of the filter.
+func (f *Filter) exceedsLimit(logs []*types.Log) bool {
+	return f.limit > 0 && len(logs) > f.limit
+}
+
 func includes(addresses []common.Address, a common.Address) bool {
 	for _, addr := range addresses {
 		if addr == a {
@@ -83,6 +83,7 @@ type subscription struct {
 	logsCrit  klaytn.FilterQuery
 	logs      chan []*types.Log
 	hashes    chan []common.Hash
+	txs       chan []*types.Transaction // nil if the hashes of pending transactions are subscribed
 	headers   chan *types.Header
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
@@ -176,6 +177,7 @@ func (sub *Subscription) Unsubscribe() {
 				break uninstallLoop
 			case <-sub.f.logs:
 			case <-sub.f.hashes:
+			case <-sub.f.txs:
 			case <-sub.f.headers:
 			}
 		}
@@ -316,6 +318,23 @@ func (es *EventSystem) SubscribePendingTxs(hashes chan []common.Hash) *Subscript
 	return es.subscribe(sub)
 }
 
+// SubscribeFullPendingTxs creates a subscription that writes transactions for
+// transactions that enter the transaction pool.
+func (es *EventSystem) SubscribeFullPendingTxs(txs chan []*types.Transaction) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       PendingTransactionsSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan []common.Hash),
+		txs:       txs,
+		headers:   make(chan *types.Header),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
 type filterIndex map[Type]map[rpc.ID]*subscription
 
 // broadcast event to filters that match criteria.
@@ -356,7 +375,11 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 			hashes = append(hashes, tx.Hash())
 		}
 		for _, f := range filters[PendingTransactionsSubscription] {
-			f.hashes <- hashes
+			if f.txs != nil {
+				f.txs <- e.Txs
+			} else {
+				f.hashes <- hashes
+			}
 		}
 	case blockchain.ChainEvent:
 		for _, f := range filters[BlocksSubscription] {
@@ -374,6 +397,21 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 	}
 }
 
+// broadcastRemovedLogs broadcasts the removed logs received so far, so that
+// they are delivered before the logs received after them. The blockchain
+// sends the removed logs of a reorg synchronously before the logs of the new
+// chain, so they are already buffered in rmLogsCh when the logs arrive.
+func (es *EventSystem) broadcastRemovedLogs(filters filterIndex) {
+	for {
+		select {
+		case ev := <-es.rmLogsCh:
+			es.broadcast(filters, ev)
+		default:
+			return
+		}
+	}
+}
+
 func (es *EventSystem) lightFilterNewHead(newHeader *types.Header, callBack func(*types.Header, bool)) {
 	oldh := es.lastHead
 	es.lastHead = newHeader
@@ -466,6 +504,8 @@ func (es *EventSystem) eventLoop() {
 		case ev := <-es.txsCh:
 			es.broadcast(index, ev)
 		case ev := <-es.logsCh:
+			// The removed logs of a reorg are sent before the logs of the new chain.
+			es.broadcastRemovedLogs(index)
 			es.broadcast(index, ev)
 		case ev := <-es.rmLogsCh:
 			es.broadcast(index, ev)
@@ -0,0 +1,279 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/klaytn/klaytn"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+)
+
+const (
+	// logCursorLength is the length of the encoded log cursor.
+	logCursorLength = 24
+	// maxReplayReorgDepth is the number of the recent blocks whose replayed logs
+	// are compared with the new logs received during the replay.
+	maxReplayReorgDepth = 128
+)
+
+var (
+	GetLogsPageSize = int(1000) // default number of logs in a page of getLogsPaged API and replayed logs subscription
+
+	errInvalidLogCursor = errors.New("invalid log cursor")
+	errReplayBlockHash  = errors.New("logs subscription does not support blockHash")
+)
+
+// LogCursor is the position of a log in the chain. It is encoded as an opaque
+// hex string in the API.
+type LogCursor struct {
+	BlockNumber uint64
+	TxIndex     uint
+	LogIndex    uint
+}
+
+// cursorOf returns the cursor pointing to the given log.
+func cursorOf(log *types.Log) *LogCursor {
+	return &LogCursor{BlockNumber: log.BlockNumber, TxIndex: log.TxIndex, LogIndex: log.Index}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c LogCursor) MarshalText() ([]byte, error) {
+	b := make([]byte, logCursorLength)
+	binary.BigEndian.PutUint64(b[0:], c.BlockNumber)
+	binary.BigEndian.PutUint64(b[8:], uint64(c.TxIndex))
+	binary.BigEndian.PutUint64(b[16:], uint64(c.LogIndex))
+	return hexutil.Bytes(b).MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *LogCursor) UnmarshalText(input []byte) error {
+	var b hexutil.Bytes
+	if err := b.UnmarshalText(input); err != nil || len(b) != logCursorLength {
+		return errInvalidLogCursor
+	}
+	c.BlockNumber = binary.BigEndian.Uint64(b[0:])
+	c.TxIndex = uint(binary.BigEndian.Uint64(b[8:]))
+	c.LogIndex = uint(binary.BigEndian.Uint64(b[16:]))
+	return nil
+}
+
+// precedes returns true if the given log is before the cursor.
+func (c *LogCursor) precedes(log *types.Log) bool {
+	if log.BlockNumber != c.BlockNumber {
+		return log.BlockNumber < c.BlockNumber
+	}
+	if log.TxIndex != c.TxIndex {
+		return log.TxIndex < c.TxIndex
+	}
+	return log.Index < c.LogIndex
+}
+
+// skip removes the logs before the cursor from the given sorted logs.
+func (c *LogCursor) skip(logs []*types.Log) []*types.Log {
+	for i, log := range logs {
+		if !c.precedes(log) {
+			return logs[i:]
+		}
+	}
+	return nil
+}
+
+// PagedLogs is a page of the logs matching the filter criteria.
+type PagedLogs struct {
+	Logs   []*types.Log `json:"logs"`
+	Cursor *LogCursor   `json:"cursor"` // position of the next page, nil if it is the last page
+}
+
+// GetLogsPaged returns at most limit logs matching the given argument from the
+// cursor. If more logs remain, the page has the cursor to be passed to get the
+// next page. If the deadline is exceeded while scanning a wide range, the logs
+// found so far are returned with the cursor to continue the scan.
+//
+// The default limit is used if the limit is 0, and the limit is capped by the
+// maximum number of items of getLogs.
+func (api *PublicFilterAPI) GetLogsPaged(ctx context.Context, crit FilterCriteria, limit int, cursor *LogCursor) (*PagedLogs, error) {
+	ctx = context.WithValue(ctx, getLogsCxtKeyMaxItems, GetLogsMaxItems)
+	ctx, cancelFnc := context.WithTimeout(ctx, GetLogsDeadline)
+	defer cancelFnc()
+
+	return api.logsPage(ctx, crit, limit, cursor)
+}
+
+// logsPage runs the filter of the criteria from the cursor up to the limit.
+func (api *PublicFilterAPI) logsPage(ctx context.Context, crit FilterCriteria, limit int, cursor *LogCursor) (*PagedLogs, error) {
+	if limit <= 0 {
+		limit = GetLogsPageSize
+	}
+	if limit > GetLogsMaxItems {
+		limit = GetLogsMaxItems
+	}
+	filter := api.newCriteriaFilter(crit)
+	filter.limit = limit
+	if cursor != nil {
+		filter.cursor = cursor
+		if crit.BlockHash == nil && (filter.begin < 0 || int64(cursor.BlockNumber) > filter.begin) {
+			filter.begin = int64(cursor.BlockNumber)
+		}
+	}
+	begin := filter.begin
+
+	logs, err := filter.Logs(ctx)
+	if err != nil {
+		// The blocks scanned before the deadline are not scanned again.
+		if ctx.Err() == context.DeadlineExceeded && begin >= 0 && filter.begin > begin {
+			return &PagedLogs{Logs: returnLogs(logs), Cursor: &LogCursor{BlockNumber: uint64(filter.begin)}}, nil
+		}
+		return nil, err
+	}
+	page := &PagedLogs{Logs: returnLogs(logs)}
+	if len(logs) > limit {
+		page.Logs, page.Cursor = logs[:limit], cursorOf(logs[limit])
+	}
+	return page, nil
+}
+
+// replayLogs creates a subscription that streams the logs matching the given
+// criteria from the fromBlock or the cursor up to the current block, and then
+// continues with the new logs like the logs subscription.
+//
+// The historical logs are found in pages in the background, and the new logs
+// received meanwhile are sent after them. The logs of the reorganised blocks
+// are sent with the removed flag only if they were sent before, and the logs
+// of the new blocks are sent unless they were sent by the replay. If the replay
+// fails, the error is logged and the subscription continues with the new logs.
+func (api *PublicFilterAPI) replayLogs(ctx context.Context, notifier *rpc.Notifier, crit FilterCriteria, cursor *LogCursor) (*rpc.Subscription, error) {
+	if crit.BlockHash != nil {
+		return nil, errReplayBlockHash
+	}
+	if cursor == nil {
+		cursor = &LogCursor{BlockNumber: crit.FromBlock.Uint64()}
+	}
+
+	// The new logs are subscribed before reading the current block, so that
+	// no logs are missed between the historical and the new ones.
+	matchedLogs := make(chan []*types.Log)
+	logsSub, err := api.events.SubscribeLogs(klaytn.FilterQuery(crit), matchedLogs)
+	if err != nil {
+		return nil, err
+	}
+	header, err := api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if header == nil || err != nil {
+		logsSub.Unsubscribe()
+		if err == nil {
+			err = errors.New("unknown current block")
+		}
+		return nil, err
+	}
+	head := header.Number.Uint64()
+	if crit.ToBlock == nil || crit.ToBlock.Sign() < 0 || crit.ToBlock.Uint64() > head {
+		crit.ToBlock = new(big.Int).SetUint64(head)
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		var (
+			pages     = make(chan []*types.Log)
+			done      = make(chan error, 1)
+			quit      = make(chan struct{})
+			newLogs   []*types.Log                 // new logs received while replaying the historical logs
+			replayed  = make(map[common.Hash]bool) // hashes of the recent blocks whose logs are replayed
+			streaming = true
+		)
+		defer func() {
+			close(quit)
+			logsSub.Unsubscribe()
+		}()
+		go func() {
+			done <- api.streamLogs(crit, cursor, pages, quit)
+		}()
+
+		for {
+			select {
+			case logs := <-pages:
+				for _, log := range logs {
+					if log.BlockNumber+maxReplayReorgDepth > head {
+						replayed[log.BlockHash] = true
+					}
+					notifier.Notify(rpcSub.ID, &log)
+				}
+			case err := <-done:
+				if err != nil {
+					logger.Warn("Failed to replay historical logs", "id", rpcSub.ID, "err", err)
+				}
+				for _, log := range unreplayedLogs(newLogs, head, replayed) {
+					notifier.Notify(rpcSub.ID, &log)
+				}
+				newLogs, replayed, streaming = nil, nil, false
+			case logs := <-matchedLogs:
+				if streaming {
+					newLogs = append(newLogs, logs...)
+					continue
+				}
+				for _, log := range logs {
+					notifier.Notify(rpcSub.ID, &log)
+				}
+			case <-rpcSub.Err(): // client send an unsubscribe request
+				return
+			case <-notifier.Closed(): // connection dropped
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// unreplayedLogs returns the new logs to be sent after replaying the logs up to
+// the head. The logs of the replayed blocks are not sent again, and the removed
+// logs are sent only if their blocks were replayed.
+func unreplayedLogs(logs []*types.Log, head uint64, replayed map[common.Hash]bool) []*types.Log {
+	var ret []*types.Log
+	for _, log := range logs {
+		if log.BlockNumber > head || log.Removed == replayed[log.BlockHash] {
+			ret = append(ret, log)
+		}
+	}
+	return ret
+}
+
+// streamLogs sends the pages of the logs matching the criteria from the cursor
+// until the last page or the quit channel is closed.
+func (api *PublicFilterAPI) streamLogs(crit FilterCriteria, cursor *LogCursor, pages chan<- []*types.Log, quit <-chan struct{}) error {
+	for cursor != nil {
+		ctx := context.WithValue(context.Background(), getLogsCxtKeyMaxItems, GetLogsMaxItems)
+		ctx, cancelFnc := context.WithTimeout(ctx, GetLogsDeadline)
+		page, err := api.logsPage(ctx, crit, GetLogsPageSize, cursor)
+		cancelFnc()
+		if err != nil {
+			return err
+		}
+		select {
+		case pages <- page.Logs:
+		case <-quit:
+			return nil
+		}
+		cursor = page.Cursor
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pagingTestAddr = common.HexToAddress("0x1234")
+
+// newPagingTestBackend creates a chain of 10 blocks having the logs of
+// pagingTestAddr in the given counts per transaction of the blocks.
+func newPagingTestBackend(t *testing.T, logCounts map[int][]int) *testBackend {
+	var (
+		db      = database.NewMemoryDBManager()
+		backend = &testBackend{new(event.TypeMux), db, 0, new(event.Feed), new(event.Feed), new(event.Feed), new(event.Feed), params.TestChainConfig}
+		genesis = new(blockchain.Genesis).MustCommit(db)
+	)
+	t.Cleanup(db.Close)
+
+	chain, receipts := blockchain.GenerateChain(params.TestChainConfig, genesis, gxhash.NewFaker(), db, 10, func(i int, gen *blockchain.BlockGen) {
+		number := uint64(i + 1)
+		logIndex := uint(0)
+		for txIndex, count := range logCounts[int(number)] {
+			tx := types.NewTransaction(uint64(txIndex), pagingTestAddr, big.NewInt(1), 1, big.NewInt(1), nil)
+			receipt := genReceipt(false, 0)
+			for j := 0; j < count; j++ {
+				receipt.Logs = append(receipt.Logs, &types.Log{
+					Address:     pagingTestAddr,
+					Topics:      []common.Hash{{}},
+					BlockNumber: number,
+					TxHash:      tx.Hash(),
+					TxIndex:     uint(txIndex),
+					Index:       logIndex,
+				})
+				logIndex++
+			}
+			receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+			gen.AddUncheckedReceipt(receipt)
+			gen.AddUncheckedTx(tx)
+		}
+	})
+	for i, block := range chain {
+		for _, receipt := range receipts[i] {
+			for _, log := range receipt.Logs {
+				log.BlockHash = block.Hash()
+			}
+		}
+		db.WriteBlock(block)
+		db.WriteCanonicalHash(block.Hash(), block.NumberU64())
+		db.WriteHeadBlockHash(block.Hash())
+		db.WriteReceipts(block.Hash(), block.NumberU64(), receipts[i])
+	}
+	return backend
+}
+
+func TestLogCursor_JSON(t *testing.T) {
+	cursor := &LogCursor{BlockNumber: 1000, TxIndex: 2, LogIndex: 3}
+	data, err := json.Marshal(cursor)
+	require.NoError(t, err)
+
+	var decoded *LogCursor
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, cursor, decoded)
+
+	assert.Error(t, json.Unmarshal([]byte(`"0x1234"`), &decoded))
+}
+
+func TestGetLogsPaged(t *testing.T) {
+	backend := newPagingTestBackend(t, map[int][]int{2: {2, 1}, 3: {1}, 5: {0, 2}})
+	api := NewPublicFilterAPI(backend, false)
+	crit := FilterCriteria{FromBlock: big.NewInt(0), Addresses: []common.Address{pagingTestAddr}}
+
+	// All logs are returned in a page with the default limit.
+	page, err := api.GetLogsPaged(context.Background(), crit, 0, nil)
+	require.NoError(t, err)
+	assert.Len(t, page.Logs, 6)
+	assert.Nil(t, page.Cursor)
+
+	var (
+		logs   []*types.Log
+		cursor *LogCursor
+		pages  int
+	)
+	for {
+		page, err := api.GetLogsPaged(context.Background(), crit, 2, cursor)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(page.Logs), 2)
+		logs = append(logs, page.Logs...)
+		pages++
+		if page.Cursor == nil {
+			break
+		}
+		cursor = page.Cursor
+	}
+	assert.Equal(t, 3, pages)
+	require.Len(t, logs, 6)
+	expected := []LogCursor{{2, 0, 0}, {2, 0, 1}, {2, 1, 2}, {3, 0, 0}, {5, 1, 0}, {5, 1, 1}}
+	for i, log := range logs {
+		assert.Equal(t, expected[i], *cursorOf(log))
+	}
+
+	// The cursor in the middle of a block skips the logs before it.
+	page, err = api.GetLogsPaged(context.Background(), crit, 10, &LogCursor{BlockNumber: 2, TxIndex: 1})
+	require.NoError(t, err)
+	assert.Len(t, page.Logs, 4)
+	assert.Nil(t, page.Cursor)
+}
+
+func TestLogs_Replay(t *testing.T) {
+	backend := newPagingTestBackend(t, map[int][]int{2: {2, 1}, 3: {1}, 5: {0, 2}})
+	api := NewPublicFilterAPI(backend, false)
+	defer func(pageSize int) { GetLogsPageSize = pageSize }(GetLogsPageSize)
+	GetLogsPageSize = 2
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	require.NoError(t, server.RegisterName("kaia", api))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	_, err := client.Subscribe(context.Background(), "kaia", make(chan types.Log), "logs", map[string]interface{}{"fromBlock": "0x1", "blockHash": common.Hash{1}})
+	assert.Error(t, err)
+
+	// The logs from the resume point are replayed before the new logs.
+	replay := func(crit map[string]interface{}, cursor *LogCursor, expected []LogCursor) {
+		ch := make(chan types.Log)
+		sub, err := client.Subscribe(context.Background(), "kaia", ch, "logs", crit, cursor)
+		require.NoError(t, err)
+		defer sub.Unsubscribe()
+
+		backend.logsFeed.Send([]*types.Log{{Address: pagingTestAddr, Topics: []common.Hash{{}}, BlockNumber: 11}})
+		for _, cursor := range expected {
+			select {
+			case log := <-ch:
+				assert.Equal(t, cursor, *cursorOf(&log))
+			case err := <-sub.Err():
+				t.Fatal(err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("timeout waiting for logs")
+			}
+		}
+	}
+	replay(map[string]interface{}{"fromBlock": "0x3", "address": pagingTestAddr}, nil, []LogCursor{{3, 0, 0}, {5, 1, 0}, {5, 1, 1}, {11, 0, 0}})
+	replay(map[string]interface{}{"address": pagingTestAddr}, &LogCursor{BlockNumber: 5, TxIndex: 1, LogIndex: 1}, []LogCursor{{5, 1, 1}, {11, 0, 0}})
+}
+
+func TestUnreplayedLogs(t *testing.T) {
+	var (
+		replayedBlock = common.Hash{1}
+		newBlock      = common.Hash{2}
+		logs          = []*types.Log{
+			{BlockNumber: 9, BlockHash: replayedBlock, Removed: true}, // removed after the replay
+			{BlockNumber: 9, BlockHash: newBlock},                     // reborn after the replay
+			{BlockNumber: 10, BlockHash: replayedBlock},               // sent by the replay
+			{BlockNumber: 10, BlockHash: newBlock, Removed: true},     // never sent
+			{BlockNumber: 11, BlockHash: newBlock},
+			{BlockNumber: 11, BlockHash: newBlock, Removed: true},
+		}
+	)
+	assert.Equal(t, []*types.Log{logs[0], logs[1], logs[4], logs[5]}, unreplayedLogs(logs, 10, map[common.Hash]bool{replayedBlock: true}))
+}
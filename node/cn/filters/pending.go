@@ -0,0 +1,97 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"math/big"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+// PendingTxCriteria represents the filter of the pending transactions. The
+// transactions matching all of the given fields are selected.
+type PendingTxCriteria struct {
+	From         []common.Address `json:"from"`         // senders, empty means any sender
+	To           []common.Address `json:"to"`           // recipients, empty means any recipient
+	Types        []types.TxType   `json:"types"`        // tx types (e.g. 9 for TxTypeFeeDelegatedValueTransfer), empty means any type
+	FeeDelegated *bool            `json:"feeDelegated"` // selects the fee-delegated transactions or the others if set
+	MinGasPrice  *hexutil.Big     `json:"minGasPrice"`  // minimum gas price, or gas fee cap of the dynamic fee transactions
+}
+
+// Matches returns true if the given transaction matches the criteria.
+func (crit *PendingTxCriteria) Matches(tx *types.Transaction) bool {
+	if crit == nil {
+		return true
+	}
+	if len(crit.From) > 0 && !includes(crit.From, txSender(tx)) {
+		return false
+	}
+	if len(crit.To) > 0 && (tx.To() == nil || !includes(crit.To, *tx.To())) {
+		return false
+	}
+	if len(crit.Types) > 0 && !includesTxType(crit.Types, tx.Type()) {
+		return false
+	}
+	if crit.FeeDelegated != nil && *crit.FeeDelegated != tx.IsFeeDelegatedTransaction() {
+		return false
+	}
+	if crit.MinGasPrice != nil && tx.GasPrice().Cmp((*big.Int)(crit.MinGasPrice)) < 0 {
+		return false
+	}
+	return true
+}
+
+func includesTxType(txTypes []types.TxType, t types.TxType) bool {
+	for _, txType := range txTypes {
+		if txType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// txSender returns the sender of the transaction. The Ethereum transactions
+// don't have the sender field, so it's recovered from the signature.
+func txSender(tx *types.Transaction) common.Address {
+	var from common.Address
+	if tx.IsEthereumTransaction() {
+		signer := types.LatestSignerForChainID(tx.ChainId())
+		from, _ = types.Sender(signer, tx)
+	} else {
+		from, _ = tx.From()
+	}
+	return from
+}
+
+// newRPCPendingTransaction returns a pending transaction that will serialize to
+// the RPC representation of the kaia namespace.
+func newRPCPendingTransaction(tx *types.Transaction) map[string]interface{} {
+	output := tx.MakeRPCOutput()
+	output["senderTxHash"] = tx.SenderTxHashAll()
+	output["blockHash"] = common.Hash{}
+	output["blockNumber"] = (*hexutil.Big)(new(big.Int))
+	output["from"] = txSender(tx)
+	output["hash"] = tx.Hash()
+	output["transactionIndex"] = hexutil.Uint(0)
+	if tx.Type() == types.TxTypeEthereumDynamicFee {
+		// transaction is not processed yet
+		output["gasPrice"] = (*hexutil.Big)(tx.EffectiveGasPrice(nil, nil))
+	}
+	return output
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPendingTestTxs returns a signed legacy transaction and a fee-delegated
+// value transfer transaction.
+func newPendingTestTxs(t *testing.T) (legacy, feeDelegated *types.Transaction, sender, feePayer common.Address) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender = crypto.PubkeyToAddress(key.PublicKey)
+	feePayer = common.HexToAddress("0xfee")
+
+	legacy, err = types.SignTx(types.NewTransaction(0, pagingTestAddr, big.NewInt(1), 21000, big.NewInt(25), nil), types.LatestSignerForChainID(big.NewInt(1)), key)
+	require.NoError(t, err)
+
+	feeDelegated, err = types.NewTransactionWithMap(types.TxTypeFeeDelegatedValueTransfer, map[types.TxValueKeyType]interface{}{
+		types.TxValueKeyNonce:    uint64(0),
+		types.TxValueKeyTo:       common.HexToAddress("0x5678"),
+		types.TxValueKeyAmount:   big.NewInt(1),
+		types.TxValueKeyGasLimit: uint64(21000),
+		types.TxValueKeyGasPrice: big.NewInt(50),
+		types.TxValueKeyFrom:     feePayer,
+		types.TxValueKeyFeePayer: feePayer,
+	})
+	require.NoError(t, err)
+	return legacy, feeDelegated, sender, feePayer
+}
+
+func TestPendingTxCriteria_Matches(t *testing.T) {
+	legacy, feeDelegated, sender, feePayer := newPendingTestTxs(t)
+	yes, no := true, false
+
+	tests := []struct {
+		crit     *PendingTxCriteria
+		legacy   bool
+		delegate bool
+	}{
+		{nil, true, true},
+		{&PendingTxCriteria{}, true, true},
+		{&PendingTxCriteria{From: []common.Address{sender}}, true, false},
+		{&PendingTxCriteria{From: []common.Address{feePayer}}, false, true},
+		{&PendingTxCriteria{To: []common.Address{pagingTestAddr}}, true, false},
+		{&PendingTxCriteria{Types: []types.TxType{types.TxTypeFeeDelegatedValueTransfer}}, false, true},
+		{&PendingTxCriteria{Types: []types.TxType{types.TxTypeLegacyTransaction, types.TxTypeValueTransfer}}, true, false},
+		{&PendingTxCriteria{FeeDelegated: &yes}, false, true},
+		{&PendingTxCriteria{FeeDelegated: &no}, true, false},
+		{&PendingTxCriteria{MinGasPrice: (*hexutil.Big)(big.NewInt(30))}, false, true},
+		{&PendingTxCriteria{From: []common.Address{sender}, FeeDelegated: &yes}, false, false},
+	}
+	for i, tt := range tests {
+		assert.Equal(t, tt.legacy, tt.crit.Matches(legacy), "test %d", i)
+		assert.Equal(t, tt.delegate, tt.crit.Matches(feeDelegated), "test %d", i)
+	}
+}
+
+func TestNewPendingTransactions_FullTx(t *testing.T) {
+	var (
+		db      = database.NewMemoryDBManager()
+		txFeed  = new(event.Feed)
+		backend = &testBackend{new(event.TypeMux), db, 0, txFeed, new(event.Feed), new(event.Feed), new(event.Feed), params.TestChainConfig}
+		api     = NewPublicFilterAPI(backend, false)
+	)
+	defer db.Close()
+	legacy, feeDelegated, sender, _ := newPendingTestTxs(t)
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	require.NoError(t, server.RegisterName("kaia", api))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	hashes := make(chan common.Hash)
+	hashSub, err := client.Subscribe(context.Background(), "kaia", hashes, "newPendingTransactions", false, map[string]interface{}{"feeDelegated": true})
+	require.NoError(t, err)
+	defer hashSub.Unsubscribe()
+
+	txs := make(chan map[string]interface{})
+	txSub, err := client.Subscribe(context.Background(), "kaia", txs, "newPendingTransactions", true, map[string]interface{}{"from": []common.Address{sender}})
+	require.NoError(t, err)
+	defer txSub.Unsubscribe()
+
+	// Wait for the subscriptions to be installed in the event loop.
+	time.Sleep(100 * time.Millisecond)
+	txFeed.Send(blockchain.NewTxsEvent{Txs: types.Transactions{legacy, feeDelegated}})
+
+	select {
+	case hash := <-hashes:
+		assert.Equal(t, feeDelegated.Hash(), hash)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the pending transaction hash")
+	}
+	select {
+	case tx := <-txs:
+		assert.Equal(t, legacy.Hash().Hex(), tx["hash"])
+		assert.Equal(t, sender.Hex(), common.HexToAddress(tx["from"].(string)).Hex())
+		assert.Equal(t, pagingTestAddr.Hex(), common.HexToAddress(tx["to"].(string)).Hex())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the pending transaction")
+	}
+
+	// The transactions not matching the criteria are not sent.
+	select {
+	case hash := <-hashes:
+		t.Fatalf("unexpected pending transaction hash %v", hash)
+	case tx := <-txs:
+		t.Fatalf("unexpected pending transaction %v", tx)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
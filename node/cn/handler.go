@@ -1303,6 +1303,8 @@ func (pm *ProtocolManager) BroadcastTxs(txs types.Transactions) {
 	// This function calls sendTransaction() to broadcast the transactions for each peer.
 	// In that case, transactions are sorted for each peer in sendTransaction().
 	// Therefore, it prevents sorting transactions by each peer.
+	// The conditional transactions are kept only in this node.
+	txs = types.WithoutConditionalTxs(txs)
 	baseFee := big.NewInt(int64(params.DefaultLowerBoundBaseFee))
 	if pm.blockchain != nil && pm.blockchain.CurrentHeader() != nil && pm.blockchain.CurrentHeader().BaseFee != nil {
 		baseFee = pm.blockchain.CurrentHeader().BaseFee
@@ -1390,6 +1392,8 @@ func (pm *ProtocolManager) ReBroadcastTxs(txs types.Transactions) {
 		return
 	}
 
+	// The conditional transactions are kept only in this node.
+	txs = types.WithoutConditionalTxs(txs)
 	baseFee := big.NewInt(int64(params.DefaultLowerBoundBaseFee))
 	if pm.blockchain != nil && pm.blockchain.CurrentHeader() != nil && pm.blockchain.CurrentHeader().BaseFee != nil {
 		baseFee = pm.blockchain.CurrentHeader().BaseFee
@@ -54,6 +54,7 @@ func (pm *ProtocolManager) syncTransactions(p Peer) {
 	for _, batch := range pending {
 		txs = append(txs, batch...)
 	}
+	txs = types.WithoutConditionalTxs(txs)
 	if len(txs) == 0 {
 		return
 	}
@@ -24,6 +24,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -193,6 +194,17 @@ type TraceConfig struct {
 	Timeout       *string
 	LoggerTimeout *string
 	Reexec        *uint64
+	// Config specific to given tracer. Note struct logger
+	// config are historically embedded in main object.
+	TracerConfig json.RawMessage
+}
+
+// TraceCallConfig is the config for traceCall API. In addition to
+// TraceConfig, it holds the overrides of the state and the block header.
+type TraceCallConfig struct {
+	TraceConfig
+	StateOverrides *kaiaapi.EthStateOverride
+	BlockOverrides *kaiaapi.BlockOverrides
 }
 
 // StdTraceConfig holds extra parameters to standard-json trace functions.
@@ -822,7 +834,7 @@ func (api *CommonAPI) TraceTransaction(ctx context.Context, hash common.Hash, co
 // TraceCall lets you trace a given kaia_call. It collects the structured logs
 // created during the execution of EVM if the given transaction was added on
 // top of the provided block and returns them as a JSON object.
-func (api *CommonAPI) TraceCall(ctx context.Context, args kaiaapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (interface{}, error) {
+func (api *CommonAPI) TraceCall(ctx context.Context, args kaiaapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (interface{}, error) {
 	if !api.unsafeTrace {
 		if atomic.LoadInt32(&heavyAPIRequestCount) >= HeavyAPIRequestLimit {
 			return nil, fmt.Errorf("heavy debug api requests exceed the limit: %d", int64(HeavyAPIRequestLimit))
@@ -830,6 +842,30 @@ func (api *CommonAPI) TraceCall(ctx context.Context, args kaiaapi.CallArgs, bloc
 		atomic.AddInt32(&heavyAPIRequestCount, 1)
 		defer atomic.AddInt32(&heavyAPIRequestCount, -1)
 	}
+	// try to recompute the state
+	var (
+		reexec         = defaultTraceReexec
+		traceConfig    *TraceConfig
+		overrides      *kaiaapi.EthStateOverride
+		blockOverrides *kaiaapi.BlockOverrides
+	)
+	if config != nil {
+		if config.Reexec != nil {
+			reexec = *config.Reexec
+		}
+		traceConfig = &config.TraceConfig
+		overrides, blockOverrides = config.StateOverrides, config.BlockOverrides
+	}
+	msg, blockCtx, txCtx, statedb, err := api.prepareCall(ctx, args, blockNrOrHash, overrides, blockOverrides, reexec)
+	if err != nil {
+		return nil, err
+	}
+	return api.traceTx(ctx, msg, blockCtx, txCtx, statedb, traceConfig)
+}
+
+// prepareCall assembles the message of the given call args and the state and
+// the evm contexts to execute it on top of the specified block.
+func (api *CommonAPI) prepareCall(ctx context.Context, args kaiaapi.CallArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *kaiaapi.EthStateOverride, blockOverrides *kaiaapi.BlockOverrides, reexec uint64) (blockchain.Message, vm.BlockContext, vm.TxContext, *state.StateDB, error) {
 	// Try to retrieve the specified block
 	var (
 		err   error
@@ -840,29 +876,30 @@ func (api *CommonAPI) TraceCall(ctx context.Context, args kaiaapi.CallArgs, bloc
 	} else if number, ok := blockNrOrHash.Number(); ok {
 		block, err = api.blockByNumber(ctx, number)
 	} else {
-		return nil, errors.New("invalid arguments; neither block nor hash specified")
+		return nil, vm.BlockContext{}, vm.TxContext{}, nil, errors.New("invalid arguments; neither block nor hash specified")
 	}
 	if err != nil {
-		return nil, err
-	}
-	// try to recompute the state
-	reexec := defaultTraceReexec
-	if config != nil && config.Reexec != nil {
-		reexec = *config.Reexec
+		return nil, vm.BlockContext{}, vm.TxContext{}, nil, err
 	}
 	statedb, err := api.backend.StateAtBlock(ctx, block, reexec, nil, true, false)
 	if err != nil {
-		return nil, err
+		return nil, vm.BlockContext{}, vm.TxContext{}, nil, err
+	}
+	if err := overrides.Apply(statedb, nil); err != nil {
+		return nil, vm.BlockContext{}, vm.TxContext{}, nil, err
 	}
 
-	// Execute the trace
+	// Assemble the call message
 	intrinsicGas, err := types.IntrinsicGas(args.InputData(), nil, args.To == nil, api.backend.ChainConfig().Rules(block.Number()))
 	if err != nil {
-		return nil, err
+		return nil, vm.BlockContext{}, vm.TxContext{}, nil, err
 	}
 	basefee := new(big.Int).SetUint64(params.ZeroBaseFee)
 	if block.Header().BaseFee != nil {
 		basefee = block.Header().BaseFee
+		if blockOverrides != nil && blockOverrides.BaseFeePerGas != nil {
+			basefee = blockOverrides.BaseFeePerGas.ToInt()
+		}
 	}
 	gasCap := uint64(0)
 	if rpcGasCap := api.backend.RPCGasCap(); rpcGasCap != nil {
@@ -870,7 +907,7 @@ func (api *CommonAPI) TraceCall(ctx context.Context, args kaiaapi.CallArgs, bloc
 	}
 	msg, err := args.ToMessage(gasCap, basefee, intrinsicGas)
 	if err != nil {
-		return nil, err
+		return nil, vm.BlockContext{}, vm.TxContext{}, nil, err
 	}
 
 	// Add gas fee to sender for estimating gasLimit/computing cost or calling a function by insufficient balance sender.
@@ -878,8 +915,12 @@ func (api *CommonAPI) TraceCall(ctx context.Context, args kaiaapi.CallArgs, bloc
 
 	txCtx := blockchain.NewEVMTxContext(msg, block.Header(), api.backend.ChainConfig())
 	blockCtx := blockchain.NewEVMBlockContext(block.Header(), newChainContext(ctx, api.backend), nil)
-
-	return api.traceTx(ctx, msg, blockCtx, txCtx, statedb, config)
+	blockOverrides.Apply(&blockCtx)
+	// After magma hardfork, the effective gas price is the base fee.
+	if block.Header().BaseFee != nil {
+		txCtx.GasPrice = new(big.Int).Set(basefee)
+	}
+	return msg, blockCtx, txCtx, statedb, nil
 }
 
 // traceTx configures a new tracer according to the provided configuration, and
@@ -903,6 +944,10 @@ func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, b
 
 		if *config.Tracer == fastCallTracer {
 			tracer = vm.NewInternalTxTracer()
+		} else if ctor, ok := nativeTracer(*config.Tracer); ok {
+			if tracer, err = ctor(new(Context), config.TracerConfig); err != nil {
+				return nil, err
+			}
 		} else {
 			// Construct the JavaScript tracer to execute with
 			if tracer, err = New(*config.Tracer, new(Context), api.unsafeTrace); err != nil {
@@ -919,6 +964,8 @@ func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, b
 					t.Stop(errors.New("execution timeout"))
 				case *vm.InternalTxTracer:
 					t.Stop(errors.New("execution timeout"))
+				case NativeTracer:
+					t.Stop(errors.New("execution timeout"))
 				default:
 					logger.Warn("unknown tracer type", "type", reflect.TypeOf(t).String())
 				}
@@ -963,6 +1010,8 @@ func (api *CommonAPI) traceTx(ctx context.Context, message blockchain.Message, b
 		return tracer.GetResult()
 	case *vm.InternalTxTracer:
 		return tracer.GetResult()
+	case NativeTracer:
+		return tracer.GetResult()
 
 	default:
 		panic(fmt.Sprintf("bad tracer type %T", tracer))
@@ -206,7 +206,7 @@ func TestTraceCall(t *testing.T) {
 	testSuite := []struct {
 		blockNumber rpc.BlockNumber
 		call        kaiaapi.CallArgs
-		config      *TraceConfig
+		config      *TraceCallConfig
 		expectErr   error
 		expect      interface{}
 	}{
@@ -291,6 +291,21 @@ func TestTraceCall(t *testing.T) {
 		assert.Equal(t, err, testspec.expectErr)
 		assert.Equal(t, result, testspec.expect)
 	}
+
+	// Trace with the overridden code and block number.
+	var (
+		latest   = rpc.LatestBlockNumber
+		contract = common.HexToAddress("0x1234")
+		// NUMBER, PUSH1 0, MSTORE, PUSH1 0x20, PUSH1 0, RETURN
+		numberCode = hexutil.Bytes(common.FromHex("0x4360005260206000f3"))
+	)
+	result, err := api.TraceCall(context.Background(), kaiaapi.CallArgs{From: accounts[0].addr, To: &contract}, rpc.BlockNumberOrHash{BlockNumber: &latest}, &TraceCallConfig{
+		StateOverrides: &kaiaapi.EthStateOverride{contract: {Code: &numberCode}},
+		BlockOverrides: &kaiaapi.BlockOverrides{Number: (*hexutil.Big)(big.NewInt(0x1234))},
+	})
+	assert.NoError(t, err)
+	assert.False(t, result.(*kaiaapi.ExecutionResult).Failed)
+	assert.Equal(t, common.BigToHash(big.NewInt(0x1234)).Hex()[2:], result.(*kaiaapi.ExecutionResult).ReturnValue)
 }
 
 func TestTraceTransaction(t *testing.T) {
@@ -0,0 +1,699 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
+
+	kaiaapi "github.com/klaytn/klaytn/api"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/kerrors"
+	"github.com/klaytn/klaytn/networks/rpc"
+)
+
+const (
+	// maxTraceFilterBlockRange is the maximum number of blocks trace_filter
+	// replays in a single request.
+	maxTraceFilterBlockRange = 1000
+
+	traceTypeTrace     = "trace"
+	traceTypeStateDiff = "stateDiff"
+	traceTypeVmTrace   = "vmTrace"
+)
+
+var (
+	errTraceFilterRange = fmt.Errorf("block range of trace_filter exceeds the limit: %d", maxTraceFilterBlockRange)
+	errVmTraceDisabled  = errors.New("vmTrace is not supported")
+)
+
+// TraceAPI provides the OpenEthereum-style trace namespace. The call tree of a
+// transaction is reported as a flat list of traces addressed by their position
+// in the tree. Block reward traces are not produced since the rewards are not
+// distributed by transactions in Kaia.
+type TraceAPI struct {
+	api *CommonAPI
+}
+
+// NewTraceAPI creates a new TraceAPI definition.
+func NewTraceAPI(backend Backend) *TraceAPI {
+	return &TraceAPI{
+		api: &CommonAPI{backend: backend, unsafeTrace: false},
+	}
+}
+
+// ParityTrace is a single flat trace record of the trace namespace.
+type ParityTrace struct {
+	Action              interface{}  `json:"action"`
+	BlockHash           *common.Hash `json:"blockHash,omitempty"`
+	BlockNumber         *uint64      `json:"blockNumber,omitempty"`
+	Error               string       `json:"error,omitempty"`
+	Result              interface{}  `json:"result"`
+	Subtraces           int          `json:"subtraces"`
+	TraceAddress        []int        `json:"traceAddress"`
+	TransactionHash     *common.Hash `json:"transactionHash,omitempty"`
+	TransactionPosition *uint64      `json:"transactionPosition,omitempty"`
+	Type                string       `json:"type"`
+}
+
+// ParityCallAction is the action of a call trace.
+type ParityCallAction struct {
+	CallType string         `json:"callType"`
+	From     common.Address `json:"from"`
+	Gas      hexutil.Uint64 `json:"gas"`
+	Input    hexutil.Bytes  `json:"input"`
+	To       common.Address `json:"to"`
+	Value    *hexutil.Big   `json:"value"`
+}
+
+// ParityCreateAction is the action of a create trace.
+type ParityCreateAction struct {
+	From  common.Address `json:"from"`
+	Gas   hexutil.Uint64 `json:"gas"`
+	Init  hexutil.Bytes  `json:"init"`
+	Value *hexutil.Big   `json:"value"`
+}
+
+// ParitySuicideAction is the action of a suicide trace.
+type ParitySuicideAction struct {
+	Address       common.Address `json:"address"`
+	RefundAddress common.Address `json:"refundAddress"`
+	Balance       *hexutil.Big   `json:"balance"`
+}
+
+// ParityCallResult is the result of a successful call trace.
+type ParityCallResult struct {
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Output  hexutil.Bytes  `json:"output"`
+}
+
+// ParityCreateResult is the result of a successful create trace.
+type ParityCreateResult struct {
+	Address common.Address `json:"address"`
+	Code    hexutil.Bytes  `json:"code"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+}
+
+// ParityAccountDiff is the state difference of an account. Each field is
+// either "=" when unchanged, or an object keyed by "+" (created), "-"
+// (deleted) or "*" (modified, with "from" and "to").
+type ParityAccountDiff struct {
+	Balance interface{}                 `json:"balance"`
+	Code    interface{}                 `json:"code"`
+	Nonce   interface{}                 `json:"nonce"`
+	Storage map[common.Hash]interface{} `json:"storage"`
+}
+
+// ParityTraceResults is the result of replaying a transaction with the
+// requested trace types.
+type ParityTraceResults struct {
+	Output          hexutil.Bytes                         `json:"output"`
+	StateDiff       map[common.Address]*ParityAccountDiff `json:"stateDiff"`
+	Trace           []*ParityTrace                        `json:"trace"`
+	VmTrace         interface{}                           `json:"vmTrace"`
+	TransactionHash *common.Hash                          `json:"transactionHash,omitempty"`
+}
+
+// TraceFilterArgs is the filter of trace_filter. A trace matches the filter if
+// its sender is one of FromAddress and its recipient is one of ToAddress. An
+// empty address list matches any address.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+// traceLocation is the position of a traced transaction in the chain.
+type traceLocation struct {
+	blockHash   common.Hash
+	blockNumber uint64
+	txHash      common.Hash
+	txIndex     uint64
+}
+
+// traceTypes is the set of the requested trace types.
+type traceTypes struct {
+	trace     bool
+	stateDiff bool
+}
+
+func parseTraceTypes(names []string) (traceTypes, error) {
+	var requested traceTypes
+	for _, name := range names {
+		switch name {
+		case traceTypeTrace:
+			requested.trace = true
+		case traceTypeStateDiff:
+			requested.stateDiff = true
+		case traceTypeVmTrace:
+			return requested, errVmTraceDisabled
+		default:
+			return requested, fmt.Errorf("unknown trace type: %s", name)
+		}
+	}
+	return requested, nil
+}
+
+// acquire reserves a slot of the heavy api requests. The returned function
+// releases the slot.
+func (api *TraceAPI) acquire() (func(), error) {
+	if atomic.LoadInt32(&heavyAPIRequestCount) >= HeavyAPIRequestLimit {
+		return nil, fmt.Errorf("heavy debug api requests exceed the limit: %d", int64(HeavyAPIRequestLimit))
+	}
+	atomic.AddInt32(&heavyAPIRequestCount, 1)
+	return func() { atomic.AddInt32(&heavyAPIRequestCount, -1) }, nil
+}
+
+// Block returns the flat traces of all the transactions in the given block.
+func (api *TraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]*ParityTrace, error) {
+	release, err := api.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	block, err := api.api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return api.blockTraces(ctx, block)
+}
+
+// Transaction returns the flat traces of the given transaction.
+func (api *TraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]*ParityTrace, error) {
+	release, err := api.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	res, err := api.replayTransaction(ctx, hash, traceTypes{trace: true}, true)
+	if err != nil {
+		return nil, err
+	}
+	return res.Trace, nil
+}
+
+// ReplayTransaction replays the given transaction and returns the requested
+// trace types of it.
+func (api *TraceAPI) ReplayTransaction(ctx context.Context, hash common.Hash, traceTypeNames []string) (*ParityTraceResults, error) {
+	requested, err := parseTraceTypes(traceTypeNames)
+	if err != nil {
+		return nil, err
+	}
+	release, err := api.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return api.replayTransaction(ctx, hash, requested, false)
+}
+
+// ReplayBlockTransactions replays all the transactions in the given block and
+// returns the requested trace types of them.
+func (api *TraceAPI) ReplayBlockTransactions(ctx context.Context, number rpc.BlockNumber, traceTypeNames []string) ([]*ParityTraceResults, error) {
+	requested, err := parseTraceTypes(traceTypeNames)
+	if err != nil {
+		return nil, err
+	}
+	release, err := api.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	block, err := api.api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return api.replayBlock(ctx, block, requested, false)
+}
+
+// Call executes the given call on top of the given block, latest by default,
+// and returns the requested trace types of it.
+func (api *TraceAPI) Call(ctx context.Context, args kaiaapi.CallArgs, traceTypeNames []string, blockNrOrHash *rpc.BlockNumberOrHash) (*ParityTraceResults, error) {
+	requested, err := parseTraceTypes(traceTypeNames)
+	if err != nil {
+		return nil, err
+	}
+	release, err := api.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if blockNrOrHash == nil {
+		latest := rpc.LatestBlockNumber
+		blockNrOrHash = &rpc.BlockNumberOrHash{BlockNumber: &latest}
+	}
+	msg, blockCtx, txCtx, statedb, err := api.api.prepareCall(ctx, args, *blockNrOrHash, nil, nil, defaultTraceReexec)
+	if err != nil {
+		return nil, err
+	}
+	return api.replayTx(ctx, msg, blockCtx, txCtx, statedb, requested, nil)
+}
+
+// Filter returns the flat traces matching the given filter in the block range.
+func (api *TraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]*ParityTrace, error) {
+	release, err := api.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	start, end := rpc.LatestBlockNumber, rpc.LatestBlockNumber
+	if args.FromBlock != nil {
+		start = *args.FromBlock
+	}
+	if args.ToBlock != nil {
+		end = *args.ToBlock
+	}
+	from, err := api.api.blockByNumber(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.api.blockByNumber(ctx, end)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		return nil, fmt.Errorf("starting block #%d not found", start)
+	}
+	if to == nil {
+		return nil, fmt.Errorf("end block #%d not found", end)
+	}
+	if from.NumberU64() > to.NumberU64() {
+		return nil, fmt.Errorf("end block #%d needs to come after start block #%d", end, start)
+	}
+	if to.NumberU64()-from.NumberU64() >= maxTraceFilterBlockRange {
+		return nil, errTraceFilterRange
+	}
+	var (
+		fromAddrs = addressSet(args.FromAddress)
+		toAddrs   = addressSet(args.ToAddress)
+		skip      uint64
+		results   = []*ParityTrace{}
+	)
+	if args.After != nil {
+		skip = *args.After
+	}
+	for number := from.NumberU64(); number <= to.NumberU64(); number++ {
+		// There is nothing to trace in the genesis block
+		if number == 0 {
+			continue
+		}
+		block, err := api.api.blockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		traces, err := api.blockTraces(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for _, trace := range traces {
+			sender, recipient := trace.addresses()
+			if !matchAddress(fromAddrs, sender) || !matchAddress(toAddrs, recipient) {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if args.Count != nil && uint64(len(results)) >= *args.Count {
+				return results, nil
+			}
+			results = append(results, trace)
+		}
+	}
+	return results, nil
+}
+
+// blockTraces returns the flat traces of all the transactions in the block.
+func (api *TraceAPI) blockTraces(ctx context.Context, block *types.Block) ([]*ParityTrace, error) {
+	results, err := api.replayBlock(ctx, block, traceTypes{trace: true}, true)
+	if err != nil {
+		return nil, err
+	}
+	traces := []*ParityTrace{}
+	for _, res := range results {
+		traces = append(traces, res.Trace...)
+	}
+	return traces, nil
+}
+
+// replayTransaction replays the transaction of the given hash on top of its
+// parent state.
+func (api *TraceAPI) replayTransaction(ctx context.Context, hash common.Hash, requested traceTypes, located bool) (*ParityTraceResults, error) {
+	tx, blockHash, blockNumber, index := api.api.backend.GetTxAndLookupInfo(hash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %#x not found", hash)
+	}
+	// It shouldn't happen in practice.
+	if blockNumber == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	block, err := api.api.blockByNumberAndHash(ctx, rpc.BlockNumber(blockNumber), blockHash)
+	if err != nil {
+		return nil, err
+	}
+	msg, blockCtx, txCtx, statedb, err := api.api.backend.StateAtTransaction(ctx, block, int(index), defaultTraceReexec)
+	if err != nil {
+		return nil, err
+	}
+	var loc *traceLocation
+	if located {
+		loc = &traceLocation{blockHash: blockHash, blockNumber: blockNumber, txHash: hash, txIndex: index}
+	}
+	return api.replayTx(ctx, msg, blockCtx, txCtx, statedb, requested, loc)
+}
+
+// replayBlock replays all the transactions in the block one by one on top of
+// the parent state.
+func (api *TraceAPI) replayBlock(ctx context.Context, block *types.Block, requested traceTypes, located bool) ([]*ParityTraceResults, error) {
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	parent, err := api.api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := api.api.backend.StateAtBlock(ctx, parent, defaultTraceReexec, nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		signer  = types.MakeSigner(api.api.backend.ChainConfig(), block.Number())
+		txs     = block.Transactions()
+		results = make([]*ParityTraceResults, 0, len(txs))
+	)
+	for i, tx := range txs {
+		msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, block.NumberU64())
+		if err != nil {
+			return nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), err)
+		}
+		txCtx := blockchain.NewEVMTxContext(msg, block.Header(), api.api.backend.ChainConfig())
+		blockCtx := blockchain.NewEVMBlockContext(block.Header(), newChainContext(ctx, api.api.backend), nil)
+
+		var loc *traceLocation
+		if located {
+			loc = &traceLocation{blockHash: block.Hash(), blockNumber: block.NumberU64(), txHash: tx.Hash(), txIndex: uint64(i)}
+		}
+		res, err := api.replayTx(ctx, msg, blockCtx, txCtx, statedb, requested, loc)
+		if err != nil {
+			return nil, err
+		}
+		if !located {
+			hash := tx.Hash()
+			res.TransactionHash = &hash
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// replayTx executes the message on the given state and collects the requested
+// trace types. The state is finalised after the execution so that the next
+// transaction of the block can be replayed on top of it.
+func (api *TraceAPI) replayTx(ctx context.Context, message blockchain.Message, blockCtx vm.BlockContext, txCtx vm.TxContext, statedb *state.StateDB, requested traceTypes, loc *traceLocation) (*ParityTraceResults, error) {
+	var prestate *state.StateDB
+	if requested.stateDiff {
+		prestate = statedb.Copy()
+	}
+	tracer := newTouchTracer()
+	tracer.touchAccount(message.ValidatedFeePayer())
+
+	// Handle timeouts and RPC cancellations
+	deadlineCtx, cancel := context.WithTimeout(ctx, defaultTraceTimeout)
+	go func() {
+		<-deadlineCtx.Done()
+		if errors.Is(deadlineCtx.Err(), context.DeadlineExceeded) {
+			tracer.Stop(errors.New("execution timeout"))
+		}
+	}()
+	defer cancel()
+
+	vmenv := vm.NewEVM(blockCtx, txCtx, statedb, api.api.backend.ChainConfig(), &vm.Config{Debug: true, Tracer: tracer})
+	ret, err := blockchain.ApplyMessage(vmenv, message)
+	if err != nil {
+		return nil, fmt.Errorf("tracing failed: %v", err)
+	}
+	statedb.Finalise(true, true)
+
+	result := &ParityTraceResults{Output: ret.Return()}
+	trace, err := tracer.GetResult()
+	if err != nil {
+		return nil, err
+	}
+	if requested.trace {
+		result.Trace = flattenTrace(trace, []int{}, loc, nil)
+	}
+	if requested.stateDiff {
+		result.StateDiff = tracer.stateDiff(prestate, statedb)
+	}
+	return result, nil
+}
+
+// flattenTrace appends the frame and its children to the traces in the
+// depth-first order.
+func flattenTrace(frame *vm.InternalTxTrace, traceAddress []int, loc *traceLocation, traces []*ParityTrace) []*ParityTrace {
+	var (
+		from  = addressOrZero(frame.From)
+		to    = addressOrZero(frame.To)
+		value = (*hexutil.Big)(parseHexBig(frame.Value))
+		trace = &ParityTrace{Subtraces: len(frame.Calls), TraceAddress: traceAddress}
+	)
+	if loc != nil {
+		trace.BlockHash, trace.BlockNumber = &loc.blockHash, &loc.blockNumber
+		trace.TransactionHash, trace.TransactionPosition = &loc.txHash, &loc.txIndex
+	}
+	switch frame.Type {
+	case vm.CREATE.String(), vm.CREATE2.String():
+		trace.Type = "create"
+		trace.Action = &ParityCreateAction{From: from, Gas: hexutil.Uint64(frame.Gas), Init: common.FromHex(frame.Input), Value: value}
+		if frame.Error == nil {
+			trace.Result = &ParityCreateResult{Address: to, Code: common.FromHex(frame.Output), GasUsed: hexutil.Uint64(frame.GasUsed)}
+		}
+	case vm.OpCode(vm.SELFDESTRUCT).String():
+		trace.Type = "suicide"
+		trace.Action = &ParitySuicideAction{Address: from, RefundAddress: to, Balance: value}
+	default:
+		trace.Type = "call"
+		trace.Action = &ParityCallAction{CallType: strings.ToLower(frame.Type), From: from, Gas: hexutil.Uint64(frame.Gas), Input: common.FromHex(frame.Input), To: to, Value: value}
+		if frame.Error == nil {
+			trace.Result = &ParityCallResult{GasUsed: hexutil.Uint64(frame.GasUsed), Output: common.FromHex(frame.Output)}
+		}
+	}
+	if frame.Error != nil {
+		trace.Error = parityError(frame.Error)
+	}
+	traces = append(traces, trace)
+	for i, call := range frame.Calls {
+		address := append(append(make([]int, 0, len(traceAddress)+1), traceAddress...), i)
+		traces = flattenTrace(call, address, loc, traces)
+	}
+	return traces
+}
+
+// addresses returns the sender and the recipient of the trace. The recipient
+// of a create trace is the created contract.
+func (t *ParityTrace) addresses() (common.Address, common.Address) {
+	switch action := t.Action.(type) {
+	case *ParityCallAction:
+		return action.From, action.To
+	case *ParityCreateAction:
+		if res, ok := t.Result.(*ParityCreateResult); ok {
+			return action.From, res.Address
+		}
+		return action.From, common.Address{}
+	case *ParitySuicideAction:
+		return action.Address, action.RefundAddress
+	}
+	return common.Address{}, common.Address{}
+}
+
+// parityError converts the execution error into the message used by the
+// trace namespace.
+func parityError(err error) string {
+	switch msg := err.Error(); {
+	case strings.HasSuffix(msg, "execution reverted"):
+		return "Reverted"
+	case msg == kerrors.ErrOutOfGas.Error(), msg == vm.ErrCodeStoreOutOfGas.Error():
+		return "Out of gas"
+	case msg == vm.ErrInvalidJump.Error():
+		return "Bad jump destination"
+	default:
+		return msg
+	}
+}
+
+func addressOrZero(addr *common.Address) common.Address {
+	if addr == nil {
+		return common.Address{}
+	}
+	return *addr
+}
+
+func parseHexBig(s string) *big.Int {
+	if v, err := hexutil.DecodeBig(s); err == nil {
+		return v
+	}
+	return new(big.Int)
+}
+
+func addressSet(addrs []common.Address) map[common.Address]struct{} {
+	set := make(map[common.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = struct{}{}
+	}
+	return set
+}
+
+func matchAddress(set map[common.Address]struct{}, addr common.Address) bool {
+	if len(set) == 0 {
+		return true
+	}
+	_, ok := set[addr]
+	return ok
+}
+
+// touchTracer extends the internal tx tracer to record the accounts and the
+// storage slots touched during the execution, which are compared to build the
+// state diff.
+type touchTracer struct {
+	*vm.InternalTxTracer
+	touched map[common.Address]map[common.Hash]struct{}
+}
+
+func newTouchTracer() *touchTracer {
+	return &touchTracer{
+		InternalTxTracer: vm.NewInternalTxTracer(),
+		touched:          make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+func (t *touchTracer) touchAccount(addr common.Address) {
+	if _, ok := t.touched[addr]; !ok {
+		t.touched[addr] = make(map[common.Hash]struct{})
+	}
+}
+
+func (t *touchTracer) touchSlot(addr common.Address, slot common.Hash) {
+	t.touchAccount(addr)
+	t.touched[addr][slot] = struct{}{}
+}
+
+// CaptureStart implements the Tracer interface to record the accounts of the
+// outermost call along with the accounts receiving the transaction fee.
+func (t *touchTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.touchAccount(from)
+	t.touchAccount(to)
+	t.touchAccount(env.Context.Coinbase)
+	t.touchAccount(env.Context.Rewardbase)
+	t.InternalTxTracer.CaptureStart(env, from, to, create, input, gas, value)
+}
+
+// CaptureEnter implements the Tracer interface to record the accounts of the
+// internal calls.
+func (t *touchTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.touchAccount(from)
+	t.touchAccount(to)
+	t.InternalTxTracer.CaptureEnter(typ, from, to, input, gas, value)
+}
+
+// CaptureState implements the Tracer interface to record the accessed storage
+// slots and the accounts whose balance may change.
+func (t *touchTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+	if stack := scope.Stack.Data(); len(stack) > 0 {
+		switch op {
+		case vm.SLOAD, vm.SSTORE:
+			t.touchSlot(scope.Contract.Address(), common.Hash(stack[len(stack)-1].Bytes32()))
+		case vm.SELFDESTRUCT:
+			t.touchAccount(scope.Contract.Address())
+			t.touchAccount(common.Address(stack[len(stack)-1].Bytes20()))
+		}
+	}
+	t.InternalTxTracer.CaptureState(env, pc, op, gas, cost, ccLeft, ccOpcode, scope, depth, err)
+}
+
+// stateDiff compares the touched accounts of the states before and after the
+// execution. The unchanged accounts are omitted.
+func (t *touchTracer) stateDiff(pre, post *state.StateDB) map[common.Address]*ParityAccountDiff {
+	diffs := make(map[common.Address]*ParityAccountDiff)
+	for addr, slots := range t.touched {
+		var (
+			existed = pre.Exist(addr)
+			exists  = post.Exist(addr)
+		)
+		if !existed && !exists {
+			continue
+		}
+		diff := &ParityAccountDiff{
+			Balance: diffValue(existed, exists, (*hexutil.Big)(pre.GetBalance(addr)), (*hexutil.Big)(post.GetBalance(addr)), pre.GetBalance(addr).Cmp(post.GetBalance(addr)) == 0),
+			Nonce:   diffValue(existed, exists, hexutil.Uint64(pre.GetNonce(addr)), hexutil.Uint64(post.GetNonce(addr)), pre.GetNonce(addr) == post.GetNonce(addr)),
+			Code:    diffValue(existed, exists, hexutil.Bytes(pre.GetCode(addr)), hexutil.Bytes(post.GetCode(addr)), bytes.Equal(pre.GetCode(addr), post.GetCode(addr))),
+			Storage: make(map[common.Hash]interface{}),
+		}
+		for slot := range slots {
+			var (
+				before = pre.GetState(addr, slot)
+				after  = post.GetState(addr, slot)
+			)
+			switch {
+			case existed && exists && before != after:
+				diff.Storage[slot] = diffValue(true, true, before, after, false)
+			case !existed && after != (common.Hash{}):
+				diff.Storage[slot] = diffValue(false, true, before, after, false)
+			case !exists && before != (common.Hash{}):
+				diff.Storage[slot] = diffValue(true, false, before, after, false)
+			}
+		}
+		if diff.Balance == "=" && diff.Nonce == "=" && diff.Code == "=" && len(diff.Storage) == 0 {
+			continue
+		}
+		diffs[addr] = diff
+	}
+	return diffs
+}
+
+// diffValue returns the difference of a field in the format of the trace
+// namespace.
+func diffValue(existed, exists bool, before, after interface{}, equal bool) interface{} {
+	switch {
+	case !existed:
+		return map[string]interface{}{"+": after}
+	case !exists:
+		return map[string]interface{}{"-": before}
+	case equal:
+		return "="
+	default:
+		return map[string]interface{}{"*": map[string]interface{}{"from": before, "to": after}}
+	}
+}
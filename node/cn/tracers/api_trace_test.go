@@ -0,0 +1,182 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	kaiaapi "github.com/klaytn/klaytn/api"
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTraceTestAPI creates a chain where every block has a transaction calling
+// the caller contract, which in turn calls the callee contract storing 0x2a
+// into the slot 0.
+func newTraceTestAPI(t *testing.T, genBlocks int) (*TraceAPI, Accounts, common.Address, common.Address) {
+	var (
+		accounts = newAccounts(1)
+		caller   = common.HexToAddress("0x000000000000000000000000000000000000ca11")
+		callee   = common.HexToAddress("0x000000000000000000000000000000000000ca1e")
+		signer   = types.LatestSignerForChainID(params.TestChainConfig.ChainID)
+	)
+	// PUSH1 0 x5, PUSH20 callee, GAS, CALL, STOP
+	callerCode := append(append(common.FromHex("0x6000600060006000600073"), callee.Bytes()...), common.FromHex("0x5af100")...)
+	// PUSH1 0x2a, PUSH1 0, SSTORE, STOP
+	calleeCode := common.FromHex("0x602a60005500")
+
+	genesis := &blockchain.Genesis{Alloc: blockchain.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.KAIA)},
+		caller:           {Balance: big.NewInt(0), Code: callerCode},
+		callee:           {Balance: big.NewInt(0), Code: calleeCode},
+	}}
+	api := NewTraceAPI(newTestBackend(t, genBlocks, genesis, func(i int, b *blockchain.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), caller, big.NewInt(0), 100000, big.NewInt(0), nil), signer, accounts[0].key)
+		require.NoError(t, err)
+		b.AddTx(tx)
+	}))
+	return api, accounts, caller, callee
+}
+
+func TestTraceAPIBlock(t *testing.T) {
+	api, accounts, caller, callee := newTraceTestAPI(t, 2)
+
+	traces, err := api.Block(context.Background(), rpc.BlockNumber(1))
+	require.NoError(t, err)
+	require.Len(t, traces, 2)
+
+	outer, inner := traces[0], traces[1]
+	assert.Equal(t, "call", outer.Type)
+	assert.Equal(t, []int{}, outer.TraceAddress)
+	assert.Equal(t, 1, outer.Subtraces)
+	assert.Equal(t, uint64(1), *outer.BlockNumber)
+	assert.Equal(t, uint64(0), *outer.TransactionPosition)
+	action := outer.Action.(*ParityCallAction)
+	assert.Equal(t, "call", action.CallType)
+	assert.Equal(t, accounts[0].addr, action.From)
+	assert.Equal(t, caller, action.To)
+
+	assert.Equal(t, "call", inner.Type)
+	assert.Equal(t, []int{0}, inner.TraceAddress)
+	assert.Equal(t, 0, inner.Subtraces)
+	assert.Equal(t, caller, inner.Action.(*ParityCallAction).From)
+	assert.Equal(t, callee, inner.Action.(*ParityCallAction).To)
+	assert.NotNil(t, inner.Result)
+	assert.Empty(t, inner.Error)
+
+	_, err = api.Block(context.Background(), rpc.BlockNumber(0))
+	assert.EqualError(t, err, "genesis is not traceable")
+}
+
+func TestTraceAPITransaction(t *testing.T) {
+	api, _, _, callee := newTraceTestAPI(t, 1)
+
+	block, err := api.api.blockByNumber(context.Background(), rpc.BlockNumber(1))
+	require.NoError(t, err)
+	hash := block.Transactions()[0].Hash()
+
+	traces, err := api.Transaction(context.Background(), hash)
+	require.NoError(t, err)
+	require.Len(t, traces, 2)
+	assert.Equal(t, hash, *traces[1].TransactionHash)
+	assert.Equal(t, callee, traces[1].Action.(*ParityCallAction).To)
+}
+
+func TestTraceAPIFilter(t *testing.T) {
+	api, accounts, caller, callee := newTraceTestAPI(t, 3)
+
+	var (
+		from  = rpc.BlockNumber(1)
+		to    = rpc.BlockNumber(3)
+		after = uint64(1)
+		count = uint64(1)
+	)
+	traces, err := api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from, ToBlock: &to})
+	require.NoError(t, err)
+	assert.Len(t, traces, 6)
+
+	traces, err = api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from, ToBlock: &to, ToAddress: []common.Address{callee}})
+	require.NoError(t, err)
+	require.Len(t, traces, 3)
+	for i, trace := range traces {
+		assert.Equal(t, uint64(i+1), *trace.BlockNumber)
+		assert.Equal(t, caller, trace.Action.(*ParityCallAction).From)
+	}
+
+	traces, err = api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from, ToBlock: &to, FromAddress: []common.Address{accounts[0].addr}, After: &after, Count: &count})
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	assert.Equal(t, uint64(2), *traces[0].BlockNumber)
+
+	_, err = api.Filter(context.Background(), TraceFilterArgs{FromBlock: &to, ToBlock: &from})
+	assert.Error(t, err)
+}
+
+func TestTraceAPIReplayBlockTransactions(t *testing.T) {
+	api, accounts, _, callee := newTraceTestAPI(t, 2)
+
+	results, err := api.ReplayBlockTransactions(context.Background(), rpc.BlockNumber(2), []string{"trace", "stateDiff"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	res := results[0]
+	assert.NotNil(t, res.TransactionHash)
+	assert.Len(t, res.Trace, 2)
+	assert.Nil(t, res.Trace[0].BlockNumber)
+
+	// The slot is already set by the transaction of the first block.
+	assert.NotContains(t, res.StateDiff, callee)
+	sender := res.StateDiff[accounts[0].addr]
+	require.NotNil(t, sender)
+	assert.Equal(t, map[string]interface{}{"*": map[string]interface{}{"from": hexutil.Uint64(1), "to": hexutil.Uint64(2)}}, sender.Nonce)
+	assert.Equal(t, "=", sender.Balance)
+
+	results, err = api.ReplayBlockTransactions(context.Background(), rpc.BlockNumber(1), []string{"stateDiff"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Nil(t, results[0].Trace)
+	require.Contains(t, results[0].StateDiff, callee)
+	slot := results[0].StateDiff[callee].Storage[common.Hash{}]
+	assert.Equal(t, map[string]interface{}{"*": map[string]interface{}{"from": common.Hash{}, "to": common.BigToHash(big.NewInt(0x2a))}}, slot)
+
+	_, err = api.ReplayBlockTransactions(context.Background(), rpc.BlockNumber(1), []string{"vmTrace"})
+	assert.Equal(t, errVmTraceDisabled, err)
+}
+
+func TestTraceAPICall(t *testing.T) {
+	api, accounts, caller, callee := newTraceTestAPI(t, 1)
+
+	latest := rpc.LatestBlockNumber
+	res, err := api.Call(context.Background(), kaiaapi.CallArgs{
+		From: accounts[0].addr,
+		To:   &caller,
+		Gas:  hexutil.Uint64(100000),
+	}, []string{"trace"}, &rpc.BlockNumberOrHash{BlockNumber: &latest})
+	require.NoError(t, err)
+	require.Len(t, res.Trace, 2)
+	assert.Nil(t, res.Trace[0].TransactionHash)
+	assert.Equal(t, callee, res.Trace[1].Action.(*ParityCallAction).To)
+	assert.Nil(t, res.StateDiff)
+}
@@ -16,12 +16,14 @@
 
 /*
 Package tracers provides implementation of Tracer that evaluates a Javascript
-function for each VM execution step.
+function for each VM execution step. Tracers written in go can be plugged in
+via RegisterNativeTracer; see the native subpackage.
 
 Source Files
 
   - tracer.go  : implementation of Tracer
   - tracers.go : provides managing functions of tracers
   - api.go     : provides private debug API related to trace chain, block and state
+  - api_trace.go: provides the OpenEthereum-style trace API reporting flat call traces and state diffs
 */
 package tracers
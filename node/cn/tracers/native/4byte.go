@@ -0,0 +1,151 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/node/cn/tracers"
+)
+
+// fourByteTracer is a native go tracer which searches for 4byte-identifiers,
+// and collects them for post-processing. It is a port of 4byte_tracer.js and
+// reports the same output.
+//
+// It collects the methods identifiers along with the size of the supplied data,
+// so a reversed signature can be matched against the size of the data.
+//
+// Example:
+//
+//	> debug.traceTransaction( "0x214e597e35da083692f5386141e69f47e973b2c56e7a8073b1ea08fd7571e9de", {tracer: "4byteTracer"})
+//	{
+//	  0x27dc297e-128: 1,
+//	  0x38cc4831-0: 2,
+//	  0x524f3889-96: 1,
+//	  0xadf59f99-288: 1,
+//	  0xc281d19e-0: 1
+//	}
+type fourByteTracer struct {
+	ids map[string]int // ids aggregates the 4byte ids found
+
+	input []byte
+
+	err       error  // Error, if one has occurred
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+// newFourByteTracer returns a native go tracer which collects
+// 4 byte-identifiers of a tx, and implements vm.Tracer.
+func newFourByteTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.NativeTracer, error) {
+	return &fourByteTracer{
+		ids: make(map[string]int),
+	}, nil
+}
+
+// store saves the given identifier and datasize.
+func (t *fourByteTracer) store(id []byte, size int64) {
+	key := hexutil.Encode(id) + "-" + strconv.FormatInt(size, 10)
+	t.ids[key] += 1
+}
+
+// CaptureTxStart implements the Tracer interface and is not used.
+func (t *fourByteTracer) CaptureTxStart(gasLimit uint64) {}
+
+// CaptureTxEnd implements the Tracer interface and is not used.
+func (t *fourByteTracer) CaptureTxEnd(restGas uint64) {}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *fourByteTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.input = common.CopyBytes(input)
+}
+
+// CaptureEnd implements the Tracer interface and is not used.
+func (t *fourByteTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+// CaptureEnter implements the Tracer interface and is not used.
+func (t *fourByteTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureExit implements the Tracer interface and is not used.
+func (t *fourByteTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *fourByteTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+	if t.err != nil {
+		return
+	}
+	// If tracing was interrupted, set the error and stop
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.err = t.reason
+		return
+	}
+	// Skip any opcodes that are not internal calls, and find the stack
+	// position of the input offset.
+	var ct int
+	switch op {
+	case vm.CALL, vm.CALLCODE:
+		// gas, addr, val, memin, meminsz, memout, memoutsz
+		ct = 3
+	case vm.DELEGATECALL, vm.STATICCALL:
+		// gas, addr, memin, meminsz, memout, memoutsz
+		ct = 2
+	default:
+		return
+	}
+	stack := scope.Stack
+	// Skip any pre-compile invocations, those are just fancy opcodes
+	if _, ok := vm.PrecompiledContractsByzantium[common.BytesToAddress(stack.Back(1).Bytes())]; ok {
+		return
+	}
+	// Gather internal call details
+	inSz := int64(stack.Back(ct + 1).Uint64())
+	if inSz >= 4 {
+		inOff := int64(stack.Back(ct).Uint64())
+		t.store(scope.Memory.Slice(inOff, inOff+4), inSz-4)
+	}
+}
+
+// CaptureFault implements the Tracer interface and is not used.
+func (t *fourByteTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// GetResult returns the json-encoded 4byte identifiers, and any error arising
+// from the encoding or forceful termination (via `Stop`).
+func (t *fourByteTracer) GetResult() (json.RawMessage, error) {
+	// Save the outer calldata also
+	if len(t.input) >= 4 {
+		t.store(t.input[:4], int64(len(t.input)-4))
+	}
+	res, err := json.Marshal(t.ids)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), t.err
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *fourByteTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
@@ -0,0 +1,455 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/klaytn/klaytn/accounts/abi"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/node/cn/tracers"
+)
+
+const (
+	errStrExecutionReverted = "execution reverted"
+	errStrInternalFailure   = "internal failure"
+)
+
+// revertSelector is the selector of Error(string), the solidity revert reason.
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+type callLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+type revertedInfo struct {
+	Contract *common.Address `json:"contract"`
+	Message  *string         `json:"message,omitempty"`
+}
+
+// callFrame is a single call in the call tree. The order of the fields follows
+// the output of call_tracer.js.
+type callFrame struct {
+	Type     string          `json:"type"`
+	From     *common.Address `json:"from,omitempty"`
+	To       *common.Address `json:"to,omitempty"`
+	Value    *hexutil.Big    `json:"value,omitempty"`
+	Gas      *hexutil.Uint64 `json:"gas,omitempty"`
+	GasUsed  *hexutil.Uint64 `json:"gasUsed,omitempty"`
+	Input    *hexutil.Bytes  `json:"input,omitempty"`
+	Output   *hexutil.Bytes  `json:"output,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Time     *int64          `json:"time,omitempty"`
+	Calls    []*callFrame    `json:"calls,omitempty"`
+	Logs     []callLog       `json:"logs,omitempty"`
+	Reverted *revertedInfo   `json:"reverted,omitempty"`
+
+	// Below are used while the call is in progress and are not reported.
+	gasIn   uint64
+	gasCost uint64
+	outOff  int64
+	outLen  int64
+}
+
+type callTracerConfig struct {
+	OnlyTopCall bool `json:"onlyTopCall"` // If true, call tracer won't collect any subcalls
+	WithLog     bool `json:"withLog"`     // If true, call tracer will collect event logs
+}
+
+// callTracer is a native go tracer which extracts and reports all the internal
+// calls made by a transaction. It is a port of call_tracer.js and reports the
+// same output.
+type callTracer struct {
+	config callTracerConfig
+
+	callstack        []*callFrame
+	descended        bool
+	revertedContract *common.Address
+
+	// Transaction context gathered throughout execution
+	typ      string
+	from     common.Address
+	to       common.Address
+	input    []byte
+	output   []byte
+	gas      uint64
+	gasUsed  uint64
+	gasLimit uint64
+	value    *big.Int
+	errStr   string
+
+	err       error  // Error, if one has occurred
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+// newCallTracer returns a native go tracer which reports the internal calls
+// made by a transaction.
+func newCallTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.NativeTracer, error) {
+	var config callTracerConfig
+	if err := parseConfig(cfg, &config); err != nil {
+		return nil, err
+	}
+	return &callTracer{
+		config:    config,
+		callstack: []*callFrame{{}},
+	}, nil
+}
+
+// CaptureTxStart implements the Tracer interface to record the gas bought for the transaction.
+func (t *callTracer) CaptureTxStart(gasLimit uint64) {
+	t.gasLimit = gasLimit
+}
+
+// CaptureTxEnd implements the Tracer interface to record the gas used by the transaction.
+func (t *callTracer) CaptureTxEnd(restGas uint64) {
+	t.gasUsed = t.gasLimit - restGas
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *callTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.typ = vm.CALL.String()
+	if create {
+		t.typ = vm.CREATE.String()
+	}
+	t.from = from
+	t.to = to
+	t.input = common.CopyBytes(input)
+	t.gas = gas
+	t.value = value
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	t.output = common.CopyBytes(output)
+	t.gasUsed = gasUsed
+	if err != nil {
+		t.errStr = err.Error()
+	}
+}
+
+// CaptureEnter is not used since the internal calls are followed opcode by opcode.
+func (t *callTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureExit is not used since the internal calls are followed opcode by opcode.
+func (t *callTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+	if t.err != nil {
+		return
+	}
+	// If tracing was interrupted, set the error and stop
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.err = t.reason
+		return
+	}
+	if t.config.OnlyTopCall && depth > 1 {
+		return
+	}
+	// Capture any errors immediately
+	if err != nil {
+		t.fault(err)
+		return
+	}
+	t.step(env, op, gas, cost, scope, depth)
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *callTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+	if t.err != nil {
+		return
+	}
+	if t.config.OnlyTopCall && depth > 1 {
+		return
+	}
+	t.fault(err)
+}
+
+func (t *callTracer) step(env *vm.EVM, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int) {
+	var (
+		stack    = scope.Stack
+		memory   = scope.Memory
+		contract = scope.Contract.Address()
+	)
+	// We only care about system opcodes, faster if we pre-check once
+	sysCall := (op & 0xf0) == 0xf0
+
+	// If a new contract is being created, add to the call stack
+	if sysCall && (op == vm.CREATE || op == vm.CREATE2) {
+		if t.config.OnlyTopCall {
+			return
+		}
+		inOff := int64(stack.Back(1).Uint64())
+		inEnd := inOff + int64(stack.Back(2).Uint64())
+
+		input := hexutil.Bytes(memory.Slice(inOff, inEnd))
+		t.push(&callFrame{
+			Type:    op.String(),
+			From:    &contract,
+			Input:   &input,
+			Value:   (*hexutil.Big)(stack.Back(0).ToBig()),
+			gasIn:   gas,
+			gasCost: cost,
+		})
+		t.descended = true
+		return
+	}
+	// If a contract is being self destructed, gather that as a subcall too
+	if sysCall && op == vm.SELFDESTRUCT {
+		if t.config.OnlyTopCall {
+			return
+		}
+		to := common.BytesToAddress(stack.Back(0).Bytes())
+		t.top().Calls = append(t.top().Calls, &callFrame{
+			Type:  op.String(),
+			From:  &contract,
+			To:    &to,
+			Value: (*hexutil.Big)(new(big.Int).Set(env.StateDB.GetBalance(contract))),
+		})
+		return
+	}
+	// If a new method invocation is being done, add to the call stack
+	if sysCall && (op == vm.CALL || op == vm.CALLCODE || op == vm.DELEGATECALL || op == vm.STATICCALL) {
+		if t.config.OnlyTopCall {
+			return
+		}
+		// Skip any pre-compile invocations, those are just fancy opcodes
+		to := common.BytesToAddress(stack.Back(1).Bytes())
+		if _, ok := vm.PrecompiledContractsByzantium[to]; ok {
+			return
+		}
+		off := 1
+		if op == vm.DELEGATECALL || op == vm.STATICCALL {
+			off = 0
+		}
+		inOff := int64(stack.Back(2 + off).Uint64())
+		inEnd := inOff + int64(stack.Back(3+off).Uint64())
+
+		input := hexutil.Bytes(memory.Slice(inOff, inEnd))
+		call := &callFrame{
+			Type:    op.String(),
+			From:    &contract,
+			To:      &to,
+			Input:   &input,
+			gasIn:   gas,
+			gasCost: cost,
+			outOff:  int64(stack.Back(4 + off).Uint64()),
+			outLen:  int64(stack.Back(5 + off).Uint64()),
+		}
+		if op != vm.DELEGATECALL && op != vm.STATICCALL {
+			call.Value = (*hexutil.Big)(stack.Back(2).ToBig())
+		}
+		t.push(call)
+		t.descended = true
+		return
+	}
+	// If we've just descended into an inner call, retrieve it's true allowance. We
+	// need to extract if from within the call as there may be funky gas dynamics
+	// with regard to requested and actually given gas (2300 stipend, 63/64 rule).
+	// If the call was made to a plain account, the gas is left unknown.
+	if t.descended {
+		if depth >= len(t.callstack) {
+			callGas := hexutil.Uint64(gas)
+			t.top().Gas = &callGas
+		}
+		t.descended = false
+	}
+	// Collect the emitted event logs into the current call
+	if t.config.WithLog && op >= vm.LOG0 && op <= vm.LOG4 {
+		mStart := int64(stack.Back(0).Uint64())
+		mSize := int64(stack.Back(1).Uint64())
+		topics := make([]common.Hash, int(op-vm.LOG0))
+		for i := range topics {
+			topics[i] = common.Hash(stack.Back(2 + i).Bytes32())
+		}
+		t.top().Logs = append(t.top().Logs, callLog{
+			Address: contract,
+			Topics:  topics,
+			Data:    common.CopyBytes(memory.Slice(mStart, mStart+mSize)),
+		})
+		return
+	}
+	// If an existing call is returning, pop off the call stack
+	if sysCall && op == vm.REVERT {
+		top := t.top()
+		top.Error = errStrExecutionReverted
+		if t.revertedContract == nil {
+			if top.To == nil {
+				t.revertedContract = &contract
+			} else {
+				t.revertedContract = top.To
+			}
+		}
+		return
+	}
+	if depth == len(t.callstack)-1 {
+		// Pop off the last call and get the execution results
+		call := t.pop()
+
+		ret := stack.Back(0)
+		if call.Type == vm.CREATE.String() || call.Type == vm.CREATE2.String() {
+			// If the call was a CREATE, retrieve the contract address and output code
+			gasUsed := hexutil.Uint64(call.gasIn - call.gasCost - gas)
+			call.GasUsed = &gasUsed
+
+			if !ret.IsZero() {
+				to := common.BytesToAddress(ret.Bytes())
+				output := hexutil.Bytes(env.StateDB.GetCode(to))
+				call.To, call.Output = &to, &output
+			} else if call.Error == "" {
+				call.Error = errStrInternalFailure
+			}
+		} else {
+			// If the call was a contract call, retrieve the gas usage and output
+			if call.Gas != nil {
+				gasUsed := hexutil.Uint64(call.gasIn - call.gasCost + uint64(*call.Gas) - gas)
+				call.GasUsed = &gasUsed
+			}
+			if !ret.IsZero() {
+				output := hexutil.Bytes(memory.Slice(call.outOff, call.outOff+call.outLen))
+				call.Output = &output
+			} else if call.Error == "" {
+				call.Error = errStrInternalFailure
+			}
+		}
+		// Inject the call into the previous one
+		t.top().Calls = append(t.top().Calls, call)
+	}
+}
+
+// fault is invoked when the actual execution of an opcode fails.
+func (t *callTracer) fault(err error) {
+	// If the topmost call already reverted, don't handle the additional fault again
+	if t.top().Error != "" {
+		return
+	}
+	// Pop off the just failed call and consume all available gas
+	call := t.pop()
+	call.Error = err.Error()
+	if call.Gas != nil {
+		gasUsed := *call.Gas
+		call.GasUsed = &gasUsed
+	}
+	// Flatten the failed call into its parent
+	if len(t.callstack) > 0 {
+		t.top().Calls = append(t.top().Calls, call)
+		return
+	}
+	// Last call failed too, leave it in the stack
+	t.push(call)
+}
+
+func (t *callTracer) push(call *callFrame) {
+	t.callstack = append(t.callstack, call)
+}
+
+func (t *callTracer) pop() *callFrame {
+	call := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+	return call
+}
+
+func (t *callTracer) top() *callFrame {
+	return t.callstack[len(t.callstack)-1]
+}
+
+// GetResult returns the json-encoded nested list of call traces, and any
+// error arising from the encoding or forceful termination (via `Stop`).
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	res, err := json.Marshal(t.result())
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), t.err
+}
+
+// result assembles the outermost call from the transaction context and the
+// calls collected during the execution.
+func (t *callTracer) result() *callFrame {
+	var (
+		value   = new(big.Int)
+		gas     = hexutil.Uint64(t.gas)
+		gasUsed = hexutil.Uint64(t.gasUsed)
+		input   = hexutil.Bytes(t.input)
+		output  = hexutil.Bytes(t.output)
+		elapsed int64
+	)
+	if t.value != nil {
+		value = t.value
+	}
+	root := t.callstack[0]
+	result := &callFrame{
+		Type:    t.typ,
+		From:    &t.from,
+		To:      &t.to,
+		Value:   (*hexutil.Big)(value),
+		Gas:     &gas,
+		GasUsed: &gasUsed,
+		Input:   &input,
+		Output:  &output,
+		Time:    &elapsed,
+		Calls:   root.Calls,
+		Logs:    root.Logs,
+	}
+	if root.Error != "" {
+		result.Error = root.Error
+	} else {
+		result.Error = t.errStr
+	}
+	if result.Error != "" && (result.Error != errStrExecutionReverted || len(output) == 0) {
+		result.Output = nil
+	}
+	if t.errStr == vm.ErrExecutionReverted.Error() {
+		result.Reverted = &revertedInfo{Contract: t.revertedContract}
+		if bytes.HasPrefix(t.output, revertSelector) {
+			message, _ := abi.UnpackRevert(t.output)
+			result.Reverted.Message = &message
+		}
+	}
+	if t.config.WithLog {
+		clearFailedLogs(result, false)
+	}
+	return result
+}
+
+// clearFailedLogs clears the logs of the failed calls and their subcalls,
+// as the logs are discarded by the reverted state.
+func clearFailedLogs(call *callFrame, parentFailed bool) {
+	failed := call.Error != "" || parentFailed
+	if failed {
+		call.Logs = nil
+	}
+	for _, subcall := range call.Calls {
+		clearFailedLogs(subcall, failed)
+	}
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *callTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
@@ -0,0 +1,298 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/node/cn/tracers"
+)
+
+// account is the state of an account as reported by prestate_tracer.js.
+type account struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   uint64                      `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// accountDiff is the state of an account in the diff mode, where only the
+// changed fields are reported.
+type accountDiff struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   *uint64                     `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+type stateDiff struct {
+	Pre  map[common.Address]*accountDiff `json:"pre"`
+	Post map[common.Address]*accountDiff `json:"post"`
+}
+
+type prestateTracerConfig struct {
+	DiffMode bool `json:"diffMode"` // If true, this tracer will return state modifications
+}
+
+// prestateTracer is a native go tracer which reports the state of the accounts
+// and storage slots touched by a transaction before its execution. It is a
+// port of prestate_tracer.js and reports the same output. In the diff mode,
+// the state after the execution is reported as well.
+type prestateTracer struct {
+	config prestateTracerConfig
+
+	env      *vm.EVM
+	prestate map[common.Address]*account
+	created  map[common.Address]bool
+
+	create bool
+	from   common.Address
+	to     common.Address
+	value  *big.Int
+
+	err       error  // Error, if one has occurred
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+// newPrestateTracer returns a native go tracer which reports the state
+// touched by a transaction.
+func newPrestateTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.NativeTracer, error) {
+	var config prestateTracerConfig
+	if err := parseConfig(cfg, &config); err != nil {
+		return nil, err
+	}
+	return &prestateTracer{
+		config:   config,
+		prestate: make(map[common.Address]*account),
+		created:  make(map[common.Address]bool),
+	}, nil
+}
+
+// CaptureTxStart implements the Tracer interface and is not used.
+func (t *prestateTracer) CaptureTxStart(gasLimit uint64) {}
+
+// CaptureTxEnd implements the Tracer interface and is not used.
+func (t *prestateTracer) CaptureTxEnd(restGas uint64) {}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *prestateTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	t.create = create
+	t.from = from
+	t.to = to
+	t.value = value
+	if create {
+		t.created[to] = true
+	}
+}
+
+// CaptureEnd implements the Tracer interface and is not used.
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+
+// CaptureEnter implements the Tracer interface and is not used.
+func (t *prestateTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureExit implements the Tracer interface and is not used.
+func (t *prestateTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+	if t.err != nil {
+		return
+	}
+	// If tracing was interrupted, set the error and stop
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.err = t.reason
+		return
+	}
+	var (
+		stack    = scope.Stack
+		contract = scope.Contract.Address()
+	)
+	// Add the current account if we just started tracing. Balance will
+	// potentially be wrong here, since this will include the value sent along
+	// with the message. We fix that in result.
+	if len(t.prestate) == 0 {
+		t.lookupAccount(contract)
+	}
+	// Whenever new state is accessed, add it to the prestate
+	switch op {
+	case vm.EXTCODECOPY, vm.EXTCODESIZE, vm.BALANCE:
+		t.lookupAccount(common.BytesToAddress(stack.Back(0).Bytes()))
+	case vm.CREATE:
+		addr := crypto.CreateAddress(contract, env.StateDB.GetNonce(contract))
+		t.created[addr] = true
+		t.lookupAccount(addr)
+	case vm.CREATE2:
+		// stack: endowment, offset, size, salt
+		offset := int64(stack.Back(1).Uint64())
+		size := int64(stack.Back(2).Uint64())
+		initcode := scope.Memory.Slice(offset, offset+size)
+		addr := crypto.CreateAddress2(contract, stack.Back(3).Bytes32(), crypto.Keccak256(initcode))
+		t.created[addr] = true
+		t.lookupAccount(addr)
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.lookupAccount(common.BytesToAddress(stack.Back(1).Bytes()))
+	case vm.SSTORE, vm.SLOAD:
+		t.lookupStorage(contract, common.Hash(stack.Back(0).Bytes32()))
+	}
+}
+
+// CaptureFault implements the Tracer interface and is not used.
+func (t *prestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost, ccLeft, ccOpcode uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// lookupAccount injects the specified account into the prestate.
+func (t *prestateTracer) lookupAccount(addr common.Address) {
+	if _, ok := t.prestate[addr]; ok {
+		return
+	}
+	t.prestate[addr] = &account{
+		Balance: (*hexutil.Big)(new(big.Int).Set(t.env.StateDB.GetBalance(addr))),
+		Nonce:   t.env.StateDB.GetNonce(addr),
+		Code:    common.CopyBytes(t.env.StateDB.GetCode(addr)),
+		Storage: make(map[common.Hash]common.Hash),
+	}
+}
+
+// lookupStorage injects the specified storage entry of the given account into
+// the prestate.
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	t.lookupAccount(addr)
+	if _, ok := t.prestate[addr].Storage[key]; ok {
+		return
+	}
+	t.prestate[addr].Storage[key] = t.env.StateDB.GetState(addr, key)
+}
+
+// GetResult returns the json-encoded prestate, or the pre and post states in
+// the diff mode, and any error arising from the encoding or forceful
+// termination (via `Stop`).
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	if t.env == nil {
+		return json.RawMessage(`{}`), t.err
+	}
+	// At this point, we need to deduct the 'value' from the outer transaction,
+	// and move it back to the origin.
+	t.lookupAccount(t.from)
+	t.lookupAccount(t.to)
+
+	value := new(big.Int)
+	if t.value != nil {
+		value = t.value
+	}
+	from, to := t.prestate[t.from], t.prestate[t.to]
+	to.Balance = (*hexutil.Big)(new(big.Int).Sub(to.Balance.ToInt(), value))
+	from.Balance = (*hexutil.Big)(new(big.Int).Add(from.Balance.ToInt(), value))
+
+	// Decrement the caller's nonce
+	from.Nonce--
+
+	var result interface{} = t.prestate
+	if t.config.DiffMode {
+		result = t.diff()
+	} else if t.create {
+		// We can blindly delete the contract prestate, as any existing state would
+		// have caused the transaction to be rejected as invalid in the first place.
+		delete(t.prestate, t.to)
+	}
+	res, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), t.err
+}
+
+// diff compares the prestate with the current state and reports the changed
+// fields of the accounts. Unchanged accounts are omitted from both sides, and
+// the accounts created by the transaction are only reported in the post state.
+func (t *prestateTracer) diff() *stateDiff {
+	var (
+		db   = t.env.StateDB
+		diff = &stateDiff{
+			Pre:  make(map[common.Address]*accountDiff),
+			Post: make(map[common.Address]*accountDiff),
+		}
+	)
+	for addr, acc := range t.prestate {
+		created := t.created[addr] && acc.Nonce == 0 && len(acc.Code) == 0
+		if t.create && addr == t.to {
+			created = true
+		}
+		var (
+			pre      = &accountDiff{Balance: acc.Balance, Nonce: &acc.Nonce, Code: acc.Code}
+			post     = new(accountDiff)
+			modified = created
+		)
+		if balance := db.GetBalance(addr); created || balance.Cmp(acc.Balance.ToInt()) != 0 {
+			modified = true
+			post.Balance = (*hexutil.Big)(new(big.Int).Set(balance))
+		}
+		if nonce := db.GetNonce(addr); created || nonce != acc.Nonce {
+			modified = true
+			post.Nonce = &nonce
+		}
+		if code := db.GetCode(addr); created || !bytes.Equal(code, acc.Code) {
+			modified = true
+			post.Code = common.CopyBytes(code)
+		}
+		for key, value := range acc.Storage {
+			newValue := db.GetState(addr, key)
+			if newValue == value && !created {
+				continue
+			}
+			modified = true
+			if value != (common.Hash{}) {
+				if pre.Storage == nil {
+					pre.Storage = make(map[common.Hash]common.Hash)
+				}
+				pre.Storage[key] = value
+			}
+			if newValue != (common.Hash{}) {
+				if post.Storage == nil {
+					post.Storage = make(map[common.Hash]common.Hash)
+				}
+				post.Storage[key] = newValue
+			}
+		}
+		if !modified {
+			continue
+		}
+		if !created {
+			diff.Pre[addr] = pre
+		}
+		if db.Exist(addr) {
+			diff.Post[addr] = post
+		}
+	}
+	return diff
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *prestateTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+/*
+Package native is a collection of tracers written in go.
+
+The tracers in this package register themselves to the tracers package under
+the names of the JavaScript tracers they replace, and produce the same output.
+Importing this package for its side effects is enough to make them selectable
+via TraceConfig.Tracer.
+
+Source Files
+
+  - tracer.go  : registration of the native tracers
+  - call.go    : callTracer reporting all the internal calls made by a transaction
+  - prestate.go: prestateTracer reporting the accounts and storage touched by a transaction
+  - 4byte.go   : 4byteTracer collecting the function selectors and call data sizes
+*/
+package native
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/klaytn/klaytn/node/cn/tracers"
+)
+
+func init() {
+	tracers.RegisterNativeTracer("callTracer", newCallTracer)
+	tracers.RegisterNativeTracer("prestateTracer", newPrestateTracer)
+	tracers.RegisterNativeTracer("4byteTracer", newFourByteTracer)
+}
+
+// parseConfig decodes the tracer specific configuration into cfg. An empty
+// configuration leaves cfg untouched.
+func parseConfig(raw json.RawMessage, cfg interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, cfg); err != nil {
+		return fmt.Errorf("invalid tracer config: %v", err)
+	}
+	return nil
+}
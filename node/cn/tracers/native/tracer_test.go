@@ -0,0 +1,351 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/common/math"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/node/cn/tracers"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The golden files are shared with the JavaScript tracers.
+const testdataDir = "../testdata"
+
+type reverted struct {
+	Contract *common.Address `json:"contract"`
+	Message  string          `json:"message"`
+}
+
+// callTrace is the result of a callTracer run.
+type callTrace struct {
+	Type     string               `json:"type"`
+	From     *common.Address      `json:"from"`
+	To       *common.Address      `json:"to"`
+	Input    hexutil.Bytes        `json:"input"`
+	Output   hexutil.Bytes        `json:"output"`
+	Gas      hexutil.Uint64       `json:"gas,omitempty"`
+	GasUsed  hexutil.Uint64       `json:"gasUsed,omitempty"`
+	Value    math.HexOrDecimal256 `json:"value,omitempty"`
+	Error    string               `json:"error,omitempty"`
+	Calls    []callTrace          `json:"calls,omitempty"`
+	Logs     []callLog            `json:"logs,omitempty"`
+	Reverted *reverted            `json:"reverted,omitempty"`
+}
+
+type callContext struct {
+	Number     math.HexOrDecimal64   `json:"number"`
+	BlockScore *math.HexOrDecimal256 `json:"blockScore"`
+	Time       math.HexOrDecimal64   `json:"timestamp"`
+	GasLimit   math.HexOrDecimal64   `json:"gasLimit"`
+	Miner      common.Address        `json:"miner"`
+}
+
+// callTracerTest defines a single test to check the call tracer against.
+type callTracerTest struct {
+	Genesis     *blockchain.Genesis `json:"genesis"`
+	Context     *callContext        `json:"context"`
+	Input       string              `json:"input,omitempty"`
+	Transaction map[string]string   `json:"transaction,omitempty"`
+	Result      *callTrace          `json:"result"`
+}
+
+// forEachTestCase runs fn against every call tracer golden file.
+func forEachTestCase(t *testing.T, fn func(t *testing.T, test *callTracerTest)) {
+	files, err := os.ReadDir(testdataDir)
+	require.NoError(t, err)
+
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), "call_tracer_") {
+			continue
+		}
+		file := file // capture range variable
+		t.Run(strings.TrimSuffix(strings.TrimPrefix(file.Name(), "call_tracer_"), ".json"), func(t *testing.T) {
+			blob, err := os.ReadFile(filepath.Join(testdataDir, file.Name()))
+			require.NoError(t, err)
+
+			test := new(callTracerTest)
+			require.NoError(t, json.Unmarshal(blob, test))
+			fn(t, test)
+		})
+	}
+}
+
+// runTracer executes the transaction of the test case with the given tracer
+// and returns the tracing result.
+func runTracer(t *testing.T, test *callTracerTest, tracer tracers.NativeTracer) json.RawMessage {
+	res, err := traceTx(t, test, tracer)
+	require.NoError(t, err)
+	return res
+}
+
+// traceTx executes the transaction of the test case with the given tracer
+// and returns the tracing result along with the error reported by the tracer.
+func traceTx(t *testing.T, test *callTracerTest, tracer tracers.NativeTracer) (json.RawMessage, error) {
+	signer := types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)))
+	tx := new(types.Transaction)
+	if test.Input != "" {
+		require.NoError(t, rlp.DecodeBytes(common.FromHex(test.Input), tx))
+	} else {
+		value := new(big.Int)
+		gasPrice := new(big.Int)
+		require.NoError(t, value.UnmarshalJSON([]byte(test.Transaction["value"])))
+		require.NoError(t, gasPrice.UnmarshalJSON([]byte(test.Transaction["gasPrice"])))
+		nonce, ok := math.ParseUint64(test.Transaction["nonce"])
+		require.True(t, ok)
+		gas, ok := math.ParseUint64(test.Transaction["gas"])
+		require.True(t, ok)
+
+		to := common.HexToAddress(test.Transaction["to"])
+		input := common.FromHex(test.Transaction["input"])
+		tx = types.NewTransaction(nonce, to, value, gas, gasPrice, input)
+
+		testKey, err := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		require.NoError(t, err)
+		require.NoError(t, tx.Sign(signer, testKey))
+	}
+	origin, _ := signer.Sender(tx)
+
+	txContext := vm.TxContext{
+		Origin:   origin,
+		GasPrice: tx.GasPrice(),
+	}
+	blockContext := vm.BlockContext{
+		CanTransfer: blockchain.CanTransfer,
+		Transfer:    blockchain.Transfer,
+		BlockNumber: new(big.Int).SetUint64(uint64(test.Context.Number)),
+		Time:        new(big.Int).SetUint64(uint64(test.Context.Time)),
+		BlockScore:  (*big.Int)(test.Context.BlockScore),
+		GasLimit:    uint64(test.Context.GasLimit),
+	}
+	statedb := tests.MakePreState(database.NewMemoryDBManager(), test.Genesis.Alloc)
+	evm := vm.NewEVM(blockContext, txContext, statedb, test.Genesis.Config, &vm.Config{Debug: true, Tracer: tracer})
+
+	fork.SetHardForkBlockNumberConfig(test.Genesis.Config)
+	msg, err := tx.AsMessageWithAccountKeyPicker(signer, statedb, blockContext.BlockNumber.Uint64())
+	require.NoError(t, err)
+	_, err = blockchain.NewStateTransition(evm, msg).TransitionDb()
+	require.NoError(t, err)
+
+	return tracer.GetResult()
+}
+
+// newTracer creates a native tracer registered under the given name.
+func newTracer(t *testing.T, name string, cfg string) tracers.NativeTracer {
+	var (
+		tracer tracers.NativeTracer
+		err    error
+	)
+	switch name {
+	case "callTracer":
+		tracer, err = newCallTracer(new(tracers.Context), json.RawMessage(cfg))
+	case "prestateTracer":
+		tracer, err = newPrestateTracer(new(tracers.Context), json.RawMessage(cfg))
+	case "4byteTracer":
+		tracer, err = newFourByteTracer(new(tracers.Context), json.RawMessage(cfg))
+	default:
+		t.Fatalf("unknown native tracer %s", name)
+	}
+	require.NoError(t, err)
+	return tracer
+}
+
+// newJSTracer creates the JavaScript tracer of the given name.
+func newJSTracer(t *testing.T, name string) tracers.NativeTracer {
+	tracer, err := tracers.New(name, new(tracers.Context), false)
+	require.NoError(t, err)
+	return tracer
+}
+
+// canonical re-encodes a JSON object so that the keys are sorted.
+func canonical(t *testing.T, blob json.RawMessage) string {
+	var v interface{}
+	require.NoError(t, json.Unmarshal(blob, &v))
+	out, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(out)
+}
+
+// Compare JSON representations for human-friendly diffs.
+func jsonEqual(t *testing.T, x, y interface{}) {
+	xj, err := json.MarshalIndent(x, "", "  ")
+	assert.Nil(t, err)
+
+	yj, err := json.MarshalIndent(y, "", "  ")
+	assert.Nil(t, err)
+
+	assert.Equal(t, string(xj), string(yj))
+}
+
+// Iterates over all the input-output datasets in the tracer test harness and
+// runs the native callTracer against them.
+func TestCallTracer(t *testing.T) {
+	forEachTestCase(t, func(t *testing.T, test *callTracerTest) {
+		res := runTracer(t, test, newTracer(t, "callTracer", ""))
+
+		ret := new(callTrace)
+		require.NoError(t, json.Unmarshal(res, ret))
+		jsonEqual(t, ret, test.Result)
+	})
+}
+
+// TestNativeTracersMatchJS checks that the native tracers produce the same
+// output as the JavaScript tracers they replace.
+func TestNativeTracersMatchJS(t *testing.T) {
+	for _, name := range []string{"callTracer", "prestateTracer", "4byteTracer"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			forEachTestCase(t, func(t *testing.T, test *callTracerTest) {
+				js, err := traceTx(t, test, newJSTracer(t, name))
+				if err != nil {
+					// prestate_tracer.js cannot handle transactions without any step.
+					t.Skipf("JavaScript tracer failed: %v", err)
+				}
+				native := runTracer(t, test, newTracer(t, name, ""))
+				if name == "callTracer" {
+					// The call frames have a fixed field order.
+					assert.Equal(t, string(js), string(native))
+				} else {
+					assert.Equal(t, canonical(t, js), canonical(t, native))
+				}
+			})
+		})
+	}
+}
+
+func TestCallTracerOnlyTopCall(t *testing.T) {
+	forEachTestCase(t, func(t *testing.T, test *callTracerTest) {
+		res := runTracer(t, test, newTracer(t, "callTracer", `{"onlyTopCall": true}`))
+
+		ret := new(callTrace)
+		require.NoError(t, json.Unmarshal(res, ret))
+		assert.Empty(t, ret.Calls)
+
+		expected := *test.Result
+		expected.Calls = nil
+		jsonEqual(t, ret, &expected)
+	})
+}
+
+func TestCallTracerWithLog(t *testing.T) {
+	var (
+		contract = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		topic    = common.HexToHash("0xcafebabe")
+	)
+	// LOG1 with the topic 0xcafebabe and the 32 bytes at memory[0:32] holding 0x2a
+	code := hexutil.MustDecode("0x602a60005263cafebabe60206000a100")
+	test := &callTracerTest{
+		Genesis: &blockchain.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: blockchain.GenesisAlloc{
+				contract: {Code: code, Balance: big.NewInt(0)},
+				common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7"): {Balance: big.NewInt(1e18)},
+			},
+		},
+		Context: &callContext{Number: 1, GasLimit: 1000000, BlockScore: (*math.HexOrDecimal256)(big.NewInt(1))},
+		Transaction: map[string]string{
+			"nonce":    "0",
+			"gas":      "100000",
+			"gasPrice": "0",
+			"value":    "0",
+			"to":       contract.Hex(),
+		},
+	}
+
+	res := runTracer(t, test, newTracer(t, "callTracer", `{"withLog": true}`))
+	ret := new(callTrace)
+	require.NoError(t, json.Unmarshal(res, ret))
+	require.Len(t, ret.Logs, 1)
+	assert.Equal(t, contract, ret.Logs[0].Address)
+	assert.Equal(t, []common.Hash{topic}, ret.Logs[0].Topics)
+	assert.Equal(t, common.LeftPadBytes([]byte{0x2a}, 32), []byte(ret.Logs[0].Data))
+
+	// The logs are not reported without the option
+	res = runTracer(t, test, newTracer(t, "callTracer", ""))
+	ret = new(callTrace)
+	require.NoError(t, json.Unmarshal(res, ret))
+	assert.Empty(t, ret.Logs)
+}
+
+func TestPrestateTracerDiffMode(t *testing.T) {
+	var (
+		contract = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		sender   = common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7")
+	)
+	// SSTORE(0, 0x2a); SLOAD(1)
+	code := hexutil.MustDecode("0x602a600055600154")
+	test := &callTracerTest{
+		Genesis: &blockchain.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: blockchain.GenesisAlloc{
+				contract: {Code: code, Balance: big.NewInt(0), Storage: map[common.Hash]common.Hash{{}: common.HexToHash("0x01")}},
+				sender:   {Balance: big.NewInt(1e18)},
+			},
+		},
+		Context: &callContext{Number: 1, GasLimit: 1000000, BlockScore: (*math.HexOrDecimal256)(big.NewInt(1))},
+		Transaction: map[string]string{
+			"nonce":    "0",
+			"gas":      "100000",
+			"gasPrice": "0",
+			"value":    "7",
+			"to":       contract.Hex(),
+		},
+	}
+
+	res := runTracer(t, test, newTracer(t, "prestateTracer", `{"diffMode": true}`))
+	diff := new(stateDiff)
+	require.NoError(t, json.Unmarshal(res, diff))
+
+	// The contract received the value and its slot 0 has changed, while the unchanged slot 1 is omitted.
+	require.Contains(t, diff.Pre, contract)
+	require.Contains(t, diff.Post, contract)
+	assert.Equal(t, int64(0), diff.Pre[contract].Balance.ToInt().Int64())
+	assert.Equal(t, int64(7), diff.Post[contract].Balance.ToInt().Int64())
+	assert.Equal(t, map[common.Hash]common.Hash{{}: common.HexToHash("0x01")}, diff.Pre[contract].Storage)
+	assert.Equal(t, map[common.Hash]common.Hash{{}: common.HexToHash("0x2a")}, diff.Post[contract].Storage)
+	assert.Nil(t, diff.Post[contract].Code)
+	assert.Nil(t, diff.Post[contract].Nonce)
+
+	// The sender paid the value and increased its nonce.
+	require.Contains(t, diff.Pre, sender)
+	require.Contains(t, diff.Post, sender)
+	assert.Equal(t, uint64(0), *diff.Pre[sender].Nonce)
+	assert.Equal(t, uint64(1), *diff.Post[sender].Nonce)
+}
+
+func TestInvalidTracerConfig(t *testing.T) {
+	_, err := newCallTracer(new(tracers.Context), json.RawMessage(`{"onlyTopCall": 1}`))
+	assert.Error(t, err)
+}
@@ -22,15 +22,46 @@
 package tracers
 
 import (
+	"encoding/json"
 	"strings"
 	"unicode"
 
+	"github.com/klaytn/klaytn/blockchain/vm"
 	"github.com/klaytn/klaytn/node/cn/tracers/internal/tracers"
 )
 
 // all contains all the built in JavaScript tracers by name.
 var all = make(map[string]string)
 
+// NativeTracer is a vm.Tracer written in Go which can be selected by name
+// through TraceConfig.Tracer, in the same way as the JavaScript tracers.
+type NativeTracer interface {
+	vm.Tracer
+	// GetResult returns the JSON encoded result of the tracing.
+	GetResult() (json.RawMessage, error)
+	// Stop terminates execution of the tracer at the first opportune moment.
+	Stop(err error)
+}
+
+// NativeTracerCtor creates a native tracer from the transaction context and the
+// tracer specific configuration given in TraceConfig.TracerConfig.
+type NativeTracerCtor func(ctx *Context, cfg json.RawMessage) (NativeTracer, error)
+
+// natives contains all the registered native tracers by name.
+var natives = make(map[string]NativeTracerCtor)
+
+// RegisterNativeTracer makes a native tracer available under the given name.
+// A native tracer takes precedence over a JavaScript tracer of the same name.
+func RegisterNativeTracer(name string, ctor NativeTracerCtor) {
+	natives[name] = ctor
+}
+
+// nativeTracer retrieves a specific native tracer constructor by name.
+func nativeTracer(name string) (NativeTracerCtor, bool) {
+	ctor, ok := natives[name]
+	return ctor, ok
+}
+
 // camel converts a snake cased input string into a camel cased output.
 func camel(str string) string {
 	pieces := strings.Split(str, "_")
@@ -169,6 +169,17 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// JWTSecret is the path to the hex-encoded secret used to authenticate the
+	// HTTP and websocket RPC requests with the JSON web tokens. A new secret is
+	// generated if the file doesn't exist. If empty, the requests are not
+	// authenticated.
+	JWTSecret string `toml:",omitempty"`
+
+	// JWTPolicy is the path to the JSON file mapping the roles claimed by the
+	// tokens to the allowed namespaces and methods. If empty, the authenticated
+	// requests can call all methods exposed by the endpoints.
+	JWTPolicy string `toml:",omitempty"`
+
 	// GRPCHost is the host interface on which to start the gRPC server. If
 	// this field is empty, no gRPC API endpoint will be started.
 	GRPCHost string `toml:",omitempty"`
@@ -181,6 +192,10 @@ type Config struct {
 	// UpstreamArchiveEN is an archive mode EN endpoint
 	UpstreamArchiveEN string
 
+	// UpstreamRoutes is the path to the JSON file routing the HTTP and websocket
+	// RPC calls to the upstream EN endpoints. It can't be used with UpstreamArchiveEN.
+	UpstreamRoutes string `toml:",omitempty"`
+
 	// Ntp server:port to check the synchronization when booting the node
 	NtpRemoteServer string `toml:",omitempty"`
 
@@ -68,7 +68,9 @@ type Node struct {
 	services    map[reflect.Type]Service // Currently running services
 
 	rpcAPIs       []rpc.API
-	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+	inprocHandler *rpc.Server         // In-process RPC request handler to process the API requests
+	rpcAuth       *rpc.JWTAuth        // Authenticator of the HTTP and websocket requests (nil = disabled)
+	rpcUpstream   *rpc.UpstreamRouter // Router of the HTTP and websocket calls to the upstream ENs (nil = disabled)
 
 	ipcEndpoint string       // IPC endpoint to listen at (empty = IPC disabled)
 	ipcListener net.Listener // IPC RPC listener socket to serve API requests
@@ -316,17 +318,28 @@ func (n *Node) openDataDir() error {
 		return nil // ephemeral
 	}
 
+	release, err := n.LockInstanceDir()
+	if err != nil {
+		return err
+	}
+	n.instanceDirLock = release
+	return nil
+}
+
+// LockInstanceDir acquires the lock of the instance directory held by a
+// running node. Offline commands use it to avoid opening the databases of a
+// running node. It returns ErrDatadirUsed if the lock is held by another process.
+func (n *Node) LockInstanceDir() (flock.Releaser, error) {
 	instdir := filepath.Join(n.config.DataDir, n.config.name())
 	if err := os.MkdirAll(instdir, 0o700); err != nil {
-		return err
+		return nil, err
 	}
 
 	release, _, err := flock.New(filepath.Join(instdir, "LOCK"))
 	if err != nil {
-		return convertFileLockError(err)
+		return nil, convertFileLockError(err)
 	}
-	n.instanceDirLock = release
-	return nil
+	return release, nil
 }
 
 // startRPC is a helper method to start all the various RPC endpoint during node
@@ -337,6 +350,17 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 	for _, service := range services {
 		apis = append(apis, service.APIs()...)
 	}
+	auth, err := n.newRPCAuth()
+	if err != nil {
+		return err
+	}
+	n.rpcAuth = auth
+	upstream, err := n.newRPCUpstreamRouter()
+	if err != nil {
+		return err
+	}
+	n.rpcUpstream = upstream
+
 	// Start the various API endpoints, terminating all in case of errors
 	if err := n.startInProc(apis); err != nil {
 		return err
@@ -367,10 +391,61 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 	}
 	// All API endpoints started successfully
 	n.rpcAPIs = apis
+	if n.rpcUpstream != nil {
+		n.rpcUpstream.Start()
+	}
 
 	return nil
 }
 
+// newRPCAuth creates the authenticator of the HTTP and websocket requests from
+// the configured JWT secret and access policy.
+func (n *Node) newRPCAuth() (*rpc.JWTAuth, error) {
+	if n.config.JWTSecret == "" {
+		if n.config.JWTPolicy != "" {
+			return nil, errors.New("the JWT access policy requires the JWT secret")
+		}
+		return nil, nil
+	}
+	secret, err := rpc.ObtainJWTSecret(n.config.JWTSecret)
+	if err != nil {
+		return nil, err
+	}
+	var policy *rpc.AccessPolicy
+	if n.config.JWTPolicy != "" {
+		if policy, err = rpc.LoadAccessPolicy(n.config.JWTPolicy); err != nil {
+			return nil, err
+		}
+	}
+	n.logger.Info("Enabled JWT authentication of RPC endpoints", "secret", n.config.JWTSecret, "policy", n.config.JWTPolicy)
+	return rpc.NewJWTAuth(secret, policy)
+}
+
+// newRPCUpstreamRouter creates the router of the HTTP and websocket calls to
+// the upstream ENs from the configured upstream archive EN or routes.
+func (n *Node) newRPCUpstreamRouter() (*rpc.UpstreamRouter, error) {
+	var config *rpc.UpstreamConfig
+	switch {
+	case n.config.UpstreamArchiveEN != "" && n.config.UpstreamRoutes != "":
+		return nil, errors.New("the upstream archive EN and the upstream routes are exclusive")
+	case n.config.UpstreamRoutes != "":
+		c, err := rpc.LoadUpstreamConfig(n.config.UpstreamRoutes)
+		if err != nil {
+			return nil, err
+		}
+		config = c
+	case n.config.UpstreamArchiveEN != "":
+		config = &rpc.UpstreamConfig{
+			Upstreams: map[string][]string{"archive": {n.config.UpstreamArchiveEN}},
+			Fallback:  "archive",
+		}
+	default:
+		return nil, nil
+	}
+	n.logger.Info("Set the upstream routes of RPC calls", "upstreams", len(config.Upstreams), "rules", len(config.Rules), "fallback", config.Fallback)
+	return rpc.NewUpstreamRouter(config)
+}
+
 // startInProc initializes an in-process RPC endpoint.
 func (n *Node) startInProc(apis []rpc.API) error {
 	// Register all the APIs exposed by the services
@@ -454,7 +529,7 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts)
+	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts, n.rpcAuth, n.rpcUpstream)
 	if err != nil {
 		return err
 	}
@@ -487,7 +562,7 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
+	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll, n.rpcAuth, n.rpcUpstream)
 	if err != nil {
 		return err
 	}
@@ -544,6 +619,10 @@ func (n *Node) Stop() error {
 	n.stopHTTP()
 	n.stopIPC()
 	n.stopgRPC()
+	if n.rpcUpstream != nil {
+		n.rpcUpstream.Stop()
+		n.rpcUpstream = nil
+	}
 	n.rpcAPIs = nil
 	failure := &StopError{
 		Services: make(map[reflect.Type]error),
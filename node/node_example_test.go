@@ -22,6 +22,7 @@ package node_test
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/networks/p2p"
@@ -47,8 +48,15 @@ func (s *SampleService) Components() []interface{}              { return nil }
 func (s *SampleService) SetComponents(components []interface{}) {}
 
 func ExampleService() {
-	// Create a network node to run protocols with the default values.
-	stack, err := node.New(&node.Config{})
+	// Create a network node to run protocols with the default values in a
+	// temporary data directory.
+	datadir, err := os.MkdirTemp("", "kaia-example-node")
+	if err != nil {
+		log.Fatalf("Failed to create data directory: %v", err)
+	}
+	defer os.RemoveAll(datadir)
+
+	stack, err := node.New(&node.Config{DataDir: datadir})
 	if err != nil {
 		log.Fatalf("Failed to create network node: %v", err)
 	}
@@ -222,6 +222,10 @@ type ChainConfig struct {
 	RandaoCompatibleBlock *big.Int        `json:"randaoCompatibleBlock,omitempty"` // RandaoCompatible activate block (nil = no fork)
 	RandaoRegistry        *RegistryConfig `json:"randaoRegistry,omitempty"`        // Registry initial states
 
+	// BlsSeal is an optional hardfork which requires Randao
+	// After BlsSeal, the committed seals are BLS signatures aggregated into one in the header
+	BlsSealCompatibleBlock *big.Int `json:"blsSealCompatibleBlock,omitempty"` // BlsSealCompatible activate block (nil = no fork)
+
 	// Various consensus engines
 	Gxhash   *GxhashConfig   `json:"gxhash,omitempty"` // (deprecated) not supported engine
 	Clique   *CliqueConfig   `json:"clique,omitempty"`
@@ -329,7 +333,7 @@ func (c *ChainConfig) String() string {
 	kip160 := fmt.Sprintf("KIP160CompatibleBlock: %v KIP160ContractAddress %s", c.Kip160CompatibleBlock, c.Kip160ContractAddress.String())
 
 	if c.Istanbul != nil {
-		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v LondonCompatibleBlock: %v EthTxTypeCompatibleBlock: %v MagmaCompatibleBlock: %v KoreCompatibleBlock: %v ShanghaiCompatibleBlock: %v CancunCompatibleBlock: %v KaiaCompatibleBlock: %v RandaoCompatibleBlock: %v %s %s SubGroupSize: %d UnitPrice: %d DeriveShaImpl: %d Engine: %v}",
+		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v LondonCompatibleBlock: %v EthTxTypeCompatibleBlock: %v MagmaCompatibleBlock: %v KoreCompatibleBlock: %v ShanghaiCompatibleBlock: %v CancunCompatibleBlock: %v KaiaCompatibleBlock: %v RandaoCompatibleBlock: %v BlsSealCompatibleBlock: %v %s %s SubGroupSize: %d UnitPrice: %d DeriveShaImpl: %d Engine: %v}",
 			c.ChainID,
 			c.IstanbulCompatibleBlock,
 			c.LondonCompatibleBlock,
@@ -340,6 +344,7 @@ func (c *ChainConfig) String() string {
 			c.CancunCompatibleBlock,
 			c.KaiaCompatibleBlock,
 			c.RandaoCompatibleBlock,
+			c.BlsSealCompatibleBlock,
 			kip103,
 			kip160,
 			c.Istanbul.SubGroupSize,
@@ -348,7 +353,7 @@ func (c *ChainConfig) String() string {
 			engine,
 		)
 	} else {
-		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v LondonCompatibleBlock: %v EthTxTypeCompatibleBlock: %v MagmaCompatibleBlock: %v KoreCompatibleBlock: %v ShanghaiCompatibleBlock: %v CancunCompatibleBlock: %v KaiaCompatibleBlock: %v RandaoCompatibleBlock: %v %s %s UnitPrice: %d DeriveShaImpl: %d Engine: %v }",
+		return fmt.Sprintf("{ChainID: %v IstanbulCompatibleBlock: %v LondonCompatibleBlock: %v EthTxTypeCompatibleBlock: %v MagmaCompatibleBlock: %v KoreCompatibleBlock: %v ShanghaiCompatibleBlock: %v CancunCompatibleBlock: %v KaiaCompatibleBlock: %v RandaoCompatibleBlock: %v BlsSealCompatibleBlock: %v %s %s UnitPrice: %d DeriveShaImpl: %d Engine: %v }",
 			c.ChainID,
 			c.IstanbulCompatibleBlock,
 			c.LondonCompatibleBlock,
@@ -359,6 +364,7 @@ func (c *ChainConfig) String() string {
 			c.CancunCompatibleBlock,
 			c.KaiaCompatibleBlock,
 			c.RandaoCompatibleBlock,
+			c.BlsSealCompatibleBlock,
 			kip103,
 			kip160,
 			c.UnitPrice,
@@ -420,6 +426,11 @@ func (c *ChainConfig) IsRandaoForkEnabled(num *big.Int) bool {
 	return isForked(c.RandaoCompatibleBlock, num)
 }
 
+// IsBlsSealForkEnabled returns whether num is either equal to the blsSeal block or greater.
+func (c *ChainConfig) IsBlsSealForkEnabled(num *big.Int) bool {
+	return isForked(c.BlsSealCompatibleBlock, num)
+}
+
 // IsKIP103ForkBlock returns whether num is equal to the kip103 block.
 func (c *ChainConfig) IsKIP103ForkBlock(num *big.Int) bool {
 	return isForkBlock(c.Kip103CompatibleBlock, num)
@@ -501,6 +512,17 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	// BlsSeal is not ordered with the other forks, but it needs the BLS public keys registered since Randao
+	if c.BlsSealCompatibleBlock != nil {
+		if c.RandaoCompatibleBlock == nil {
+			return fmt.Errorf("unsupported fork ordering: randaoBlock not enabled, but blsSealBlock enabled at %v",
+				c.BlsSealCompatibleBlock)
+		}
+		if c.RandaoCompatibleBlock.Cmp(c.BlsSealCompatibleBlock) > 0 {
+			return fmt.Errorf("unsupported fork ordering: randaoBlock enabled at %v, but blsSealBlock enabled at %v",
+				c.RandaoCompatibleBlock, c.BlsSealCompatibleBlock)
+		}
+	}
 	return nil
 }
 
@@ -534,6 +556,9 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.RandaoCompatibleBlock, newcfg.RandaoCompatibleBlock, head) {
 		return newCompatError("Randao Block", c.RandaoCompatibleBlock, newcfg.RandaoCompatibleBlock)
 	}
+	if isForkIncompatible(c.BlsSealCompatibleBlock, newcfg.BlsSealCompatibleBlock, head) {
+		return newCompatError("BlsSeal Block", c.BlsSealCompatibleBlock, newcfg.BlsSealCompatibleBlock)
+	}
 	return nil
 }
 
@@ -672,6 +697,7 @@ type Rules struct {
 	IsCancun    bool
 	IsKaia      bool
 	IsRandao    bool
+	IsBlsSeal   bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -691,6 +717,7 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 		IsCancun:    c.IsCancunForkEnabled(num),
 		IsKaia:      c.IsKaiaForkEnabled(num),
 		IsRandao:    c.IsRandaoForkEnabled(num),
+		IsBlsSeal:   c.IsBlsSealForkEnabled(num),
 	}
 }
 
@@ -20,12 +20,22 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/klaytn/klaytn/common"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestChainConfig_CheckConfigForkOrder(t *testing.T) {
 	assert.Nil(t, BaobabChainConfig.CheckConfigForkOrder())
 	assert.Nil(t, CypressChainConfig.CheckConfigForkOrder())
+
+	// BlsSeal needs Randao enabled before or at the same block
+	config := BaobabChainConfig.Copy()
+	config.BlsSealCompatibleBlock = new(big.Int).Set(config.RandaoCompatibleBlock)
+	assert.Nil(t, config.CheckConfigForkOrder())
+	config.BlsSealCompatibleBlock = new(big.Int).Sub(config.RandaoCompatibleBlock, common.Big1)
+	assert.NotNil(t, config.CheckConfigForkOrder())
+	config.RandaoCompatibleBlock = nil
+	assert.NotNil(t, config.CheckConfigForkOrder())
 }
 
 func TestChainConfig_Copy(t *testing.T) {
@@ -51,7 +51,58 @@ type (
 	leafCallbackFn func(accountHash, codeHash common.Hash, stat *generateStats) (common.Hash, error)
 )
 
-// TODO-Kaia-Snapshot port GenerateAccountTrieRoot/GenerateStorageTrieRoot/GenerateTrie
+// GenerateAccountTrieRoot takes an account iterator and reproduces the root hash.
+func GenerateAccountTrieRoot(it AccountIterator) (common.Hash, error) {
+	return generateTrieRoot(it, common.Hash{}, stackTrieGenerator(nil), nil, newGenerateStats(), false)
+}
+
+// GenerateStorageTrieRoot takes a storage iterator and reproduces the root hash.
+func GenerateStorageTrieRoot(account common.Hash, it StorageIterator) (common.Hash, error) {
+	return generateTrieRoot(it, account, stackTrieGenerator(nil), nil, newGenerateStats(), false)
+}
+
+// GenerateTrie takes the whole snapshot tree as the input, traverses all the
+// accounts as well as the corresponding storages and regenerate the whole state
+// (account trie + all storage tries) into the given destination database. The
+// contract codes are copied from the source database as well.
+func GenerateTrie(snaptree *Tree, root common.Hash, src database.DBManager, dst database.DBManager) error {
+	// Traverse all state by snapshot, re-generate the whole state trie
+	acctIt, err := snaptree.AccountIterator(root, common.Hash{})
+	if err != nil {
+		return err // The required snapshot might not exist.
+	}
+	defer acctIt.Release()
+
+	got, err := generateTrieRoot(acctIt, common.Hash{}, stackTrieGenerator(dst), func(accountHash, codeHash common.Hash, stat *generateStats) (common.Hash, error) {
+		// Migrate the code as well
+		if dst != nil && codeHash != emptyCode {
+			code := src.ReadCode(codeHash)
+			if len(code) == 0 {
+				return common.Hash{}, fmt.Errorf("failed to read contract code %x", codeHash)
+			}
+			dst.WriteCode(codeHash, code)
+		}
+		// Then migrate all storage trie nodes into the destination database.
+		storageIt, err := snaptree.StorageIterator(root, accountHash, common.Hash{})
+		if err != nil {
+			return common.Hash{}, err
+		}
+		defer storageIt.Release()
+
+		hash, err := generateTrieRoot(storageIt, accountHash, stackTrieGenerator(dst), nil, stat, false)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		return hash, nil
+	}, newGenerateStats(), true)
+	if err != nil {
+		return err
+	}
+	if got != root {
+		return fmt.Errorf("state root hash mismatch: got %x, want %x", got, root)
+	}
+	return nil
+}
 
 // generateStats is a collection of statistics gathered by the trie generator
 // for logging purposes.
@@ -329,3 +380,22 @@ func trieGenerate(in chan trieKV, out chan common.Hash) {
 	}
 	out <- root
 }
+
+// stackTrieGenerator returns a trie generator which generates the trie with
+// the stack trie. The generated trie nodes are written into the given database,
+// or only the root hash is calculated if the database is nil.
+func stackTrieGenerator(db database.DBManager) trieGeneratorFn {
+	return func(in chan trieKV, out chan common.Hash) {
+		t := statedb.NewStackTrie(db)
+		for leaf := range in {
+			t.TryUpdate(leaf.key[:], leaf.value)
+		}
+		var root common.Hash
+		if db == nil {
+			root = t.Hash()
+		} else {
+			root, _ = t.Commit()
+		}
+		out <- root
+	}
+}
@@ -80,6 +80,24 @@ func NewBadgerDB(dbDir string) (*badgerDB, error) {
 	return badger, nil
 }
 
+// newReadOnlyBadgerDB opens an existing badgerDB in read-only mode. The value
+// log gc is not run since it writes to the database.
+func newReadOnlyBadgerDB(dbDir string) (*badgerDB, error) {
+	opts := getBadgerDBOptions(dbDir)
+	opts.ReadOnly = true
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open badgerDB in read-only mode. dbDir: %v, err: %v", dbDir, err)
+	}
+	return &badgerDB{
+		fn:       dbDir,
+		db:       db,
+		logger:   logger.NewWith("dbDir", dbDir),
+		gcTicker: time.NewTicker(sizeGCTickerTime),
+		closeCh:  make(chan struct{}),
+	}, nil
+}
+
 // runValueLogGC runs gc for two cases.
 // It periodically checks the size of value log and runs gc if it exceeds gcThreshold.
 func (bg *badgerDB) runValueLogGC() {
@@ -352,6 +352,18 @@ func (ts *commonDatabaseTestSuite) TestParallelPutGet() {
 
 // TestDBEntryLengthCheck checks if dbDirs and dbConfigRatio are
 // specified for every DBEntryType.
+func TestLevelDB_PutSync(t *testing.T) {
+	db, remove, _ := newTestLDB()
+	defer remove()
+
+	syncDB, ok := db.(KeyValueSyncWriter)
+	assert.True(t, ok)
+	assert.NoError(t, syncDB.PutSync([]byte("key"), []byte("value")))
+	value, err := db.Get([]byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+}
+
 func TestDBEntryLengthCheck(t *testing.T) {
 	dbRatioSum := 0
 	for i := 0; i < int(databaseEntryTypeSize); i++ {
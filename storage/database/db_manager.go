@@ -69,6 +69,7 @@ type DBManager interface {
 	GetStateTrieMigrationDB() Database
 	GetMiscDB() Database
 	GetSnapshotDB() Database
+	GetDatabase(dbEntryType DBEntryType) Database
 
 	// from accessors_chain.go
 	ReadCanonicalHash(number uint64) common.Hash
@@ -135,6 +136,12 @@ type DBManager interface {
 	WriteIstanbulSnapshot(hash common.Hash, blob []byte)
 	DeleteIstanbulSnapshot(hash common.Hash)
 
+	WriteIstanbulMisbehaviour(sequence uint64, hash common.Hash, blob []byte)
+	ReadIstanbulMisbehaviours(startSequence, endSequence uint64) [][]byte
+
+	WriteIstanbulRoundState(blob []byte)
+	ReadIstanbulRoundState() ([]byte, error)
+
 	WriteMerkleProof(key, value []byte)
 
 	// Bytecodes related operations
@@ -354,6 +361,17 @@ func (et DBEntryType) String() string {
 	return dbBaseDirs[et]
 }
 
+// ParseDBEntryType returns the DBEntryType of the given database name, which
+// is the base directory name of the database such as "misc" or "statetrie".
+func ParseDBEntryType(name string) (DBEntryType, error) {
+	for et, dir := range dbBaseDirs {
+		if dir == name {
+			return DBEntryType(et), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown database %q, available databases: %s", name, strings.Join(dbBaseDirs[:], ", "))
+}
+
 const (
 	notInMigrationFlag = 0
 	inMigrationFlag    = 1
@@ -461,6 +479,7 @@ type DBConfig struct {
 	ParallelDBWrite     bool
 	OpenFilesLimit      int
 	EnableDBPerfMetrics bool // If true, read and write performance will be logged
+	ReadOnly            bool // If true, databases are opened in read-only mode
 
 	// LevelDB related configurations.
 	LevelDBCacheSize   int // LevelDBCacheSize = BlockCacheCapacity + WriteBuffer
@@ -555,13 +574,29 @@ func newDatabase(dbc *DBConfig, entryType DBEntryType) (Database, error) {
 	case LevelDB:
 		return NewLevelDB(dbc, entryType)
 	case RocksDB:
-		return NewRocksDB(dbc.Dir, dbc.RocksDBConfig)
+		rocksDBConfig := dbc.RocksDBConfig
+		if dbc.ReadOnly {
+			// A secondary instance only serves reads.
+			readOnlyConfig := *dbc.RocksDBConfig
+			readOnlyConfig.Secondary = true
+			rocksDBConfig = &readOnlyConfig
+		}
+		return NewRocksDB(dbc.Dir, rocksDBConfig)
 	case BadgerDB:
+		if dbc.ReadOnly {
+			return newReadOnlyBadgerDB(dbc.Dir)
+		}
 		return NewBadgerDB(dbc.Dir)
 	case MemoryDB:
 		return NewMemDB(), nil
 	case DynamoDB:
-		return NewDynamoDB(dbc.DynamoDBConfig)
+		dynamoDBConfig := dbc.DynamoDBConfig
+		if dbc.ReadOnly {
+			readOnlyConfig := *dbc.DynamoDBConfig
+			readOnlyConfig.ReadOnly = true
+			dynamoDBConfig = &readOnlyConfig
+		}
+		return NewDynamoDB(dynamoDBConfig)
 	default:
 		logger.Info("database type is not set, fall back to default LevelDB")
 		return NewLevelDB(dbc, 0)
@@ -876,6 +911,13 @@ func (dbm *databaseManager) GetSnapshotDB() Database {
 	return dbm.getDatabase(SnapshotDB)
 }
 
+// GetDatabase returns the database of the given entry type.
+// It returns nil if the database is not opened, e.g. StateTrieMigrationDB
+// while no migration is in progress.
+func (dbm *databaseManager) GetDatabase(dbEntryType DBEntryType) Database {
+	return dbm.getDatabase(dbEntryType)
+}
+
 func (dbm *databaseManager) TryCatchUpWithPrimary() error {
 	for _, db := range dbm.dbs {
 		if db != nil {
@@ -1748,6 +1790,59 @@ func (dbm *databaseManager) DeleteIstanbulSnapshot(hash common.Hash) {
 	}
 }
 
+// WriteIstanbulMisbehaviour stores the evidence of conflicting consensus messages
+// signed by a validator for the given sequence.
+func (dbm *databaseManager) WriteIstanbulMisbehaviour(sequence uint64, hash common.Hash, blob []byte) {
+	db := dbm.getDatabase(MiscDB)
+	if err := db.Put(istanbulMisbehaviourKey(sequence, hash), blob); err != nil {
+		logger.Crit("Failed to write istanbul misbehaviour", "err", err)
+	}
+}
+
+// ReadIstanbulMisbehaviours reads the evidences of conflicting consensus messages
+// in the sequence range [startSequence, endSequence). If endSequence is 0, it
+// reads all the evidences from startSequence.
+func (dbm *databaseManager) ReadIstanbulMisbehaviours(startSequence, endSequence uint64) [][]byte {
+	prefix := istanbulMisbehaviourPrefix
+	it := dbm.getDatabase(MiscDB).NewIterator(prefix, common.Int64ToByteBigEndian(startSequence))
+	defer it.Release()
+
+	var blobs [][]byte
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8+common.HashLength {
+			continue
+		}
+		if endSequence != 0 && binary.BigEndian.Uint64(key[len(prefix):]) >= endSequence {
+			break
+		}
+		blobs = append(blobs, common.CopyBytes(it.Value()))
+	}
+	return blobs
+}
+
+// WriteIstanbulRoundState stores the latest round state of the istanbul core.
+// The write is synced to the disk if the database supports it, so the locks and
+// the sent votes are kept after a crash.
+func (dbm *databaseManager) WriteIstanbulRoundState(blob []byte) {
+	db := dbm.getDatabase(MiscDB)
+	var err error
+	if syncDB, ok := db.(KeyValueSyncWriter); ok {
+		err = syncDB.PutSync(istanbulRoundStateKey, blob)
+	} else {
+		err = db.Put(istanbulRoundStateKey, blob)
+	}
+	if err != nil {
+		logger.Crit("Failed to write istanbul round state", "err", err)
+	}
+}
+
+// ReadIstanbulRoundState retrieves the latest round state of the istanbul core.
+func (dbm *databaseManager) ReadIstanbulRoundState() ([]byte, error) {
+	db := dbm.getDatabase(MiscDB)
+	return db.Get(istanbulRoundStateKey)
+}
+
 // Merkle Proof operation.
 func (dbm *databaseManager) WriteMerkleProof(key, value []byte) {
 	db := dbm.getDatabase(MiscDB)
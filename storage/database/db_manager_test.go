@@ -456,6 +456,42 @@ func TestDBManager_IstanbulSnapshot(t *testing.T) {
 	}
 }
 
+func TestDBManager_IstanbulMisbehaviour(t *testing.T) {
+	log.EnableLogForTest(log.LvlCrit, log.LvlTrace)
+	for _, dbm := range dbManagers {
+		if dbm.GetMiscDB().Type() == BadgerDB {
+			continue // badgerDB doesn't support NewIterator, so cannot test ReadIstanbulMisbehaviours.
+		}
+
+		assert.Empty(t, dbm.ReadIstanbulMisbehaviours(0, 0))
+
+		dbm.WriteIstanbulMisbehaviour(100, hash1, hash1[:])
+		dbm.WriteIstanbulMisbehaviour(200, hash2, hash2[:])
+		dbm.WriteIstanbulMisbehaviour(300, hash3, hash3[:])
+
+		assert.Equal(t, [][]byte{hash1[:], hash2[:], hash3[:]}, dbm.ReadIstanbulMisbehaviours(0, 0))
+		assert.Equal(t, [][]byte{hash2[:], hash3[:]}, dbm.ReadIstanbulMisbehaviours(200, 0))
+		assert.Equal(t, [][]byte{hash1[:], hash2[:]}, dbm.ReadIstanbulMisbehaviours(100, 300))
+		assert.Empty(t, dbm.ReadIstanbulMisbehaviours(301, 0))
+	}
+}
+
+func TestDBManager_IstanbulRoundState(t *testing.T) {
+	log.EnableLogForTest(log.LvlCrit, log.LvlTrace)
+	for _, dbm := range dbManagers {
+		roundState, _ := dbm.ReadIstanbulRoundState()
+		assert.Nil(t, roundState)
+
+		dbm.WriteIstanbulRoundState(hash1[:])
+		roundState, _ = dbm.ReadIstanbulRoundState()
+		assert.Equal(t, hash1[:], roundState)
+
+		dbm.WriteIstanbulRoundState(hash2[:])
+		roundState, _ = dbm.ReadIstanbulRoundState()
+		assert.Equal(t, hash2[:], roundState)
+	}
+}
+
 // TestDBManager_TrieNode tests read and write operations of state trie nodes.
 func TestDBManager_TrieNode(t *testing.T) {
 	log.EnableLogForTest(log.LvlCrit, log.LvlTrace)
@@ -1305,3 +1341,43 @@ func genRandomData() (common.Hash, []byte) {
 	data := common.MakeRandomBytes(100)
 	return hash, data
 }
+
+func TestParseDBEntryType(t *testing.T) {
+	for et := MiscDB; et < databaseEntryTypeSize; et++ {
+		parsed, err := ParseDBEntryType(et.String())
+		assert.NoError(t, err)
+		assert.Equal(t, et, parsed)
+	}
+	_, err := ParseDBEntryType("unknown")
+	assert.Error(t, err)
+}
+
+func TestDBManager_ReadOnly(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test-db-manager-read-only")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dbc := &DBConfig{Dir: dir, DBType: LevelDB, NumStateTrieShards: 4}
+	dbm := NewDBManager(dbc)
+	hash, data := genRandomData()
+	dbm.WriteCode(hash, data)
+	dbm.Close()
+
+	roDBC := *dbc
+	roDBC.ReadOnly = true
+	dbm = NewDBManager(&roDBC)
+	defer dbm.Close()
+
+	db := dbm.GetDatabase(StateTrieDB)
+	shard, ok := ShardIndex(db, CodeKey(hash))
+	assert.True(t, ok)
+	assert.Equal(t, int(CodeKey(hash)[0])&3, shard)
+	_, ok = ShardIndex(dbm.GetDatabase(MiscDB), CodeKey(hash))
+	assert.False(t, ok)
+
+	assert.Equal(t, data, dbm.ReadCode(hash))
+	assert.Error(t, db.Put(CodeKey(hash), data))
+	assert.Error(t, db.Delete(CodeKey(hash)))
+}
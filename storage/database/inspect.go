@@ -0,0 +1,208 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// InspectEntry holds the number of keys and the total size of the key-value
+// pairs of a category stored in a database.
+type InspectEntry struct {
+	Database string `json:"database"`
+	Category string `json:"category"`
+	Count    uint64 `json:"count"`
+	Size     uint64 `json:"size"`
+}
+
+// inspectCategory classifies a key into a schema category.
+type inspectCategory struct {
+	name  string
+	match func(key []byte) bool
+}
+
+// hasPrefixAndLen returns a matcher of the keys with the given prefix and
+// the given total length.
+func hasPrefixAndLen(prefix []byte, length int) func([]byte) bool {
+	return func(key []byte) bool {
+		return len(key) == length && bytes.HasPrefix(key, prefix)
+	}
+}
+
+// hasPrefix returns a matcher of the keys with the given prefix.
+func hasPrefix(prefix []byte) func([]byte) bool {
+	return func(key []byte) bool {
+		return bytes.HasPrefix(key, prefix)
+	}
+}
+
+// metadataKeys is the list of the singleton keys which are not prefixed.
+var metadataKeys = [][]byte{
+	databaseVerisionKey, headHeaderKey, headBlockKey, headBlockBackupKey,
+	headFastBlockKey, headFastBlockBackupKey, fastTrieProgressKey, validSectionKey,
+	snapshotJournalKey, SnapshotGeneratorKey, snapshotDisabledKey, snapshotRecoveryKey,
+	snapshotSyncStatusKey, snapshotRootKey, badBlockKey, pruningEnabledKey,
+	lastPrunedBlockNumberKey, lastServiceChainTxReceiptKey, lastIndexedBlockKey,
+	migrationStatusKey, lastAccRewardBlockNumberKey, chaindatafetcherCheckpointKey,
+}
+
+// inspectCategories is the ordered list of the categories. The multi-byte
+// prefixes are checked before the single-byte ones since some of them share
+// the first byte, e.g. "accReward" and SnapshotAccountPrefix.
+var inspectCategories = []inspectCategory{
+	{"Metadata", func(key []byte) bool {
+		for _, meta := range metadataKeys {
+			if bytes.Equal(key, meta) {
+				return true
+			}
+		}
+		return false
+	}},
+	{"Governance", hasPrefix(governancePrefix)},
+	{"Staking info", hasPrefix(stakingInfoPrefix)},
+	{"Accumulated rewards", hasPrefix(accRewardPrefix)},
+	{"Istanbul snapshots", hasPrefixAndLen(snapshotKeyPrefix, len(snapshotKeyPrefix)+common.HashLength)},
+	{"Preimages", hasPrefixAndLen(preimagePrefix, len(preimagePrefix)+common.HashLength)},
+	{"Chain configs", hasPrefixAndLen(configPrefix, len(configPrefix)+common.HashLength)},
+	{"Pruning marks", hasPrefixAndLen(pruningMarkPrefix, pruningMarkKeyLen)},
+	{"Sender tx hashes", hasPrefix(senderTxHashToTxHashPrefix)},
+	{"Section heads", hasPrefix(sectionHeadKeyPrefix)},
+	{"Database directories", hasPrefix(databaseDirPrefix)},
+	{"Bloombit index", hasPrefix(BloomBitsIndexPrefix)},
+	{"Bridge data", func(key []byte) bool {
+		return bytes.HasPrefix(key, childChainTxHashPrefix) ||
+			bytes.HasPrefix(key, receiptFromParentChainKeyPrefix) ||
+			bytes.HasPrefix(key, valueTransferTxHashPrefix) ||
+			bytes.Equal(key, parentOperatorFeePayerPrefix) ||
+			bytes.Equal(key, childOperatorFeePayerPrefix)
+	}},
+	{"Headers", hasPrefixAndLen(headerPrefix, len(headerPrefix)+8+common.HashLength)},
+	{"Block scores", func(key []byte) bool {
+		return len(key) == len(headerPrefix)+8+common.HashLength+len(headerTDSuffix) &&
+			bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerTDSuffix)
+	}},
+	{"Canonical hashes", func(key []byte) bool {
+		return len(key) == len(headerPrefix)+8+len(headerHashSuffix) &&
+			bytes.HasPrefix(key, headerPrefix) && bytes.HasSuffix(key, headerHashSuffix)
+	}},
+	{"Header numbers", hasPrefixAndLen(headerNumberPrefix, len(headerNumberPrefix)+common.HashLength)},
+	{"Bodies", hasPrefixAndLen(blockBodyPrefix, len(blockBodyPrefix)+8+common.HashLength)},
+	{"Receipts", hasPrefixAndLen(blockReceiptsPrefix, len(blockReceiptsPrefix)+8+common.HashLength)},
+	{"Transaction lookups", hasPrefixAndLen(txLookupPrefix, len(txLookupPrefix)+common.HashLength)},
+	{"Bloombits", hasPrefixAndLen(bloomBitsPrefix, len(bloomBitsPrefix)+10+common.HashLength)},
+	{"Snapshot accounts", hasPrefixAndLen(SnapshotAccountPrefix, len(SnapshotAccountPrefix)+common.HashLength)},
+	{"Snapshot storage", hasPrefixAndLen(SnapshotStoragePrefix, len(SnapshotStoragePrefix)+2*common.HashLength)},
+	{"Contract codes", hasPrefixAndLen(codePrefix, len(codePrefix)+common.HashLength)},
+	{"Trie nodes", func(key []byte) bool {
+		return len(key) == common.HashLength || len(key) == common.ExtHashLength
+	}},
+}
+
+// unaccountedCategory is the category of the keys matching no other category.
+const unaccountedCategory = "Unaccounted"
+
+// inspectInterval is the interval to log the progress of the inspection.
+const inspectInterval = 8 * time.Second
+
+// InspectDatabase traverses every database managed by the given DBManager and
+// returns the number of keys and the total size of each schema category per
+// database. The categories having no key are omitted.
+func InspectDatabase(dbm DBManager) ([]*InspectEntry, error) {
+	dbc := dbm.GetDBConfig()
+	if !dbc.DBType.Iterable() {
+		return nil, fmt.Errorf("%s does not support the iteration of the database", dbc.DBType)
+	}
+
+	// All entry types share one database in case of the single or memory database.
+	if dbc.SingleDB || dbc.DBType == MemoryDB {
+		return inspectDatabase("single", dbm.getDatabase(MiscDB))
+	}
+	var entries []*InspectEntry
+	for et := MiscDB; et < databaseEntryTypeSize; et++ {
+		db := dbm.getDatabase(et)
+		if db == nil {
+			continue
+		}
+		res, err := inspectDatabase(et.String(), db)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, res...)
+	}
+	return entries, nil
+}
+
+// inspectDatabase classifies every key stored in the given database.
+func inspectDatabase(name string, db Database) ([]*InspectEntry, error) {
+	// Iterate the shards one by one since merging them in order is not needed.
+	dbs := []Database{db}
+	if sdb, ok := db.(*shardedDB); ok {
+		dbs = sdb.shards
+	}
+
+	var (
+		counts = make([]uint64, len(inspectCategories)+1)
+		sizes  = make([]uint64, len(inspectCategories)+1)
+		total  = uint64(0)
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for _, shard := range dbs {
+		it := shard.NewIterator(nil, nil)
+		for it.Next() {
+			key := it.Key()
+			idx := len(inspectCategories)
+			for i, category := range inspectCategories {
+				if category.match(key) {
+					idx = i
+					break
+				}
+			}
+			counts[idx]++
+			sizes[idx] += uint64(len(key) + len(it.Value()))
+
+			total++
+			if time.Since(logged) > inspectInterval {
+				logger.Info("Inspecting database", "database", name, "count", total, "elapsed", common.PrettyDuration(time.Since(start)))
+				logged = time.Now()
+			}
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			return nil, err
+		}
+	}
+	logger.Info("Inspected database", "database", name, "count", total, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	var entries []*InspectEntry
+	for i := range counts {
+		if counts[i] == 0 {
+			continue
+		}
+		category := unaccountedCategory
+		if i < len(inspectCategories) {
+			category = inspectCategories[i].name
+		}
+		entries = append(entries, &InspectEntry{Database: name, Category: category, Count: counts[i], Size: sizes[i]})
+	}
+	return entries, nil
+}
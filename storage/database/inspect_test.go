@@ -0,0 +1,102 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeInspectTestData writes an entry of each of the major data categories.
+func writeInspectTestData(t *testing.T, dbm DBManager) {
+	header := &types.Header{Number: big.NewInt(1), BlockScore: big.NewInt(1)}
+	dbm.WriteHeader(header)
+	dbm.WriteCanonicalHash(header.Hash(), 1)
+	dbm.WriteBody(header.Hash(), 1, &types.Body{})
+	dbm.WriteReceipts(header.Hash(), 1, types.Receipts{})
+	dbm.WriteTrieNode(common.Hash{0x1}.ExtendZero(), []byte{0x1})
+	dbm.WriteTrieNode(common.BytesToExtHash(common.Hash{0x2}.Bytes()), []byte{0x2})
+	dbm.WriteCode(common.Hash{0x3}, []byte{0x3})
+	dbm.WriteAccountSnapshot(common.Hash{0x4}, []byte{0x4})
+	dbm.WriteStorageSnapshot(common.Hash{0x4}, common.Hash{0x5}, []byte{0x5})
+	dbm.WriteHeadBlockHash(header.Hash())
+	require.NoError(t, dbm.WriteStakingInfo(1, []byte("{}")))
+}
+
+// inspectCounts returns the count of each category per database.
+func inspectCounts(t *testing.T, dbm DBManager) map[string]map[string]uint64 {
+	entries, err := InspectDatabase(dbm)
+	require.NoError(t, err)
+
+	counts := make(map[string]map[string]uint64)
+	for _, entry := range entries {
+		if counts[entry.Database] == nil {
+			counts[entry.Database] = make(map[string]uint64)
+		}
+		counts[entry.Database][entry.Category] = entry.Count
+		assert.NotZero(t, entry.Size)
+	}
+	return counts
+}
+
+func TestInspectDatabase(t *testing.T) {
+	dir, err := os.MkdirTemp("", "test-inspect-database")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dbm := NewDBManager(&DBConfig{Dir: dir, DBType: LevelDB, NumStateTrieShards: 4})
+	defer dbm.Close()
+	writeInspectTestData(t, dbm)
+
+	counts := inspectCounts(t, dbm)
+	assert.Equal(t, uint64(1), counts["header"]["Headers"])
+	assert.Equal(t, uint64(1), counts["header"]["Header numbers"])
+	assert.Equal(t, uint64(1), counts["header"]["Canonical hashes"])
+	assert.Equal(t, uint64(1), counts["body"]["Bodies"])
+	assert.Equal(t, uint64(1), counts["receipts"]["Receipts"])
+	assert.Equal(t, uint64(2), counts["statetrie"]["Trie nodes"])
+	assert.Equal(t, uint64(1), counts["statetrie"]["Contract codes"])
+	assert.Equal(t, uint64(1), counts["snapshot"]["Snapshot accounts"])
+	assert.Equal(t, uint64(1), counts["snapshot"]["Snapshot storage"])
+	assert.Equal(t, uint64(1), counts["misc"]["Staking info"])
+	assert.Equal(t, uint64(1), counts["header"]["Metadata"])
+	for db := range counts {
+		assert.NotContains(t, counts[db], unaccountedCategory, db)
+	}
+}
+
+func TestInspectDatabase_Single(t *testing.T) {
+	dbm := NewMemoryDBManager()
+	defer dbm.Close()
+	writeInspectTestData(t, dbm)
+
+	counts := inspectCounts(t, dbm)
+	require.Len(t, counts, 1)
+	single := counts["single"]
+	assert.Equal(t, uint64(1), single["Headers"])
+	assert.Equal(t, uint64(1), single["Bodies"])
+	assert.Equal(t, uint64(2), single["Trie nodes"])
+	assert.Equal(t, uint64(1), single["Snapshot storage"])
+	assert.Equal(t, uint64(1), single["Staking info"])
+	assert.NotContains(t, single, unaccountedCategory)
+}
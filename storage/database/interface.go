@@ -59,6 +59,15 @@ func (db DBType) selfShardable() bool {
 	return false
 }
 
+// Iterable returns if the db supports the iteration over its keys or not
+func (db DBType) Iterable() bool {
+	switch db {
+	case BadgerDB, DynamoDB:
+		return false
+	}
+	return true
+}
+
 // KeyValueWriter wraps the Put method of a backing data store.
 type KeyValueWriter interface {
 	// Put inserts the given value into the key-value data store.
@@ -68,6 +77,14 @@ type KeyValueWriter interface {
 	Delete(key []byte) error
 }
 
+// KeyValueSyncWriter wraps the PutSync method of a backing data store which can
+// sync a write to the disk.
+type KeyValueSyncWriter interface {
+	// PutSync inserts the given value into the key-value data store, and returns
+	// after the write is synced to the disk.
+	PutSync(key []byte, value []byte) error
+}
+
 // KeyValueStater wraps the Stat method of a backing data store.
 type KeyValueStater interface {
 	// Stat returns a particular internal stat of the database.
@@ -160,6 +160,7 @@ func NewLevelDB(dbc *DBConfig, entryType DBEntryType) (*levelDB, error) {
 
 	ldbOpts := getLevelDBOptions(dbc)
 	ldbOpts.Compression = getCompressionType(dbc.LevelDBCompression, entryType)
+	ldbOpts.ReadOnly = dbc.ReadOnly
 
 	localLogger.Info("LevelDB configurations",
 		"levelDBCacheSize", (ldbOpts.WriteBuffer+ldbOpts.BlockCacheCapacity)/opt.MiB, "openFilesLimit", ldbOpts.OpenFilesCacheCapacity,
@@ -168,7 +169,7 @@ func NewLevelDB(dbc *DBConfig, entryType DBEntryType) (*levelDB, error) {
 
 	// Open the db and recover any potential corruptions
 	db, err := leveldb.OpenFile(dbc.Dir, ldbOpts)
-	if _, corrupted := err.(*errors.ErrCorrupted); corrupted {
+	if _, corrupted := err.(*errors.ErrCorrupted); corrupted && !dbc.ReadOnly {
 		db, err = leveldb.RecoverFile(dbc.Dir, nil)
 	}
 	// (Re)check for errors and abort if opening of the db failed
@@ -279,6 +280,11 @@ func (db *levelDB) put(key []byte, value []byte) error {
 	return db.db.Put(key, value, nil)
 }
 
+// PutSync puts the given key / value, and syncs the write to the disk.
+func (db *levelDB) PutSync(key []byte, value []byte) error {
+	return db.db.Put(key, value, &opt.WriteOptions{Sync: true})
+}
+
 func (db *levelDB) Has(key []byte) (bool, error) {
 	return db.db.Has(key, nil)
 }
@@ -180,6 +180,17 @@ func (db *rocksDB) Put(key []byte, value []byte) error {
 	return db.db.Put(db.wo, key, value)
 }
 
+// PutSync puts the given key / value, and syncs the write to the disk.
+func (db *rocksDB) PutSync(key []byte, value []byte) error {
+	if db.config.Secondary {
+		return nil
+	}
+	wo := grocksdb.NewDefaultWriteOptions()
+	defer wo.Destroy()
+	wo.SetSync(true)
+	return db.db.Put(wo, key, value)
+}
+
 func (db *rocksDB) Has(key []byte) (bool, error) {
 	dat, err := db.db.GetBytes(db.ro, key)
 	if dat == nil || err != nil {
@@ -56,6 +56,12 @@ var (
 	// snapshotKeyPrefix is a governance snapshot prefix
 	snapshotKeyPrefix = []byte("snapshot")
 
+	// istanbulMisbehaviourPrefix + sequence (uint64 big endian) + hash -> evidence of conflicting consensus messages
+	istanbulMisbehaviourPrefix = []byte("istanbulMisbehaviour")
+
+	// istanbulRoundStateKey tracks the latest round state and hash lock of the istanbul core
+	istanbulRoundStateKey = []byte("IstanbulRoundState")
+
 	// snapshotJournalKey tracks the in-memory diff layers across restarts.
 	snapshotJournalKey = []byte("SnapshotJournal")
 
@@ -230,6 +236,11 @@ func snapshotKey(hash common.Hash) []byte {
 	return append(snapshotKeyPrefix, hash[:]...)
 }
 
+// istanbulMisbehaviourKey = istanbulMisbehaviourPrefix + sequence (uint64 big endian) + hash
+func istanbulMisbehaviourKey(sequence uint64, hash common.Hash) []byte {
+	return append(append(istanbulMisbehaviourPrefix, common.Int64ToByteBigEndian(sequence)...), hash.Bytes()...)
+}
+
 func childChainTxHashKey(ccBlockHash common.Hash) []byte {
 	return append(childChainTxHashPrefix, ccBlockHash.Bytes()...)
 }
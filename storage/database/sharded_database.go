@@ -124,6 +124,17 @@ func shardIndexByKey(key []byte, numShards uint) (int, error) {
 	return int(key[0]) & (int(numShards) - 1), nil
 }
 
+// ShardIndex returns the index of the shard storing the given key if the
+// given database is sharded.
+func ShardIndex(db Database, key []byte) (int, bool) {
+	sdb, ok := db.(*shardedDB)
+	if !ok {
+		return 0, false
+	}
+	shardIndex, err := shardIndexByKey(key, sdb.numShards)
+	return shardIndex, err == nil
+}
+
 // getShardByKey returns the shard corresponding to the given key.
 func (db *shardedDB) getShardByKey(key []byte) (Database, error) {
 	if shardIndex, err := shardIndexByKey(key, uint(db.numShards)); err != nil {
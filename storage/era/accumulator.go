@@ -0,0 +1,77 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/crypto"
+)
+
+// ComputeAccumulator returns the accumulator root of the header records of an
+// era. A header record is the pair of a block hash and its total block score.
+//
+// The leaves of the accumulator are keccak256(hash || score) where the score is
+// a 32-byte big endian integer. The leaves are padded with zero hashes up to
+// the capacity of an era, merkleized with keccak256(left || right), and the
+// number of the records is mixed into the root, similar to the SSZ hash tree
+// root of a bounded list.
+func ComputeAccumulator(hashes []common.Hash, scores []*big.Int) (common.Hash, error) {
+	if len(hashes) != len(scores) {
+		return common.Hash{}, fmt.Errorf("number of hashes (%d) and scores (%d) mismatch", len(hashes), len(scores))
+	}
+	if len(hashes) > MaxEra1Size {
+		return common.Hash{}, fmt.Errorf("too many records: %d > %d", len(hashes), MaxEra1Size)
+	}
+	leaves := make([]common.Hash, len(hashes))
+	for i := range hashes {
+		if scores[i].Sign() < 0 || scores[i].BitLen() > 256 {
+			return common.Hash{}, fmt.Errorf("invalid total block score of record %d: %v", i, scores[i])
+		}
+		leaves[i] = crypto.Keccak256Hash(hashes[i][:], common.LeftPadBytes(scores[i].Bytes(), 32))
+	}
+
+	// Merkleize the leaves level by level. The missing nodes of each level are
+	// the roots of the empty subtrees of the same depth.
+	var (
+		zero  common.Hash
+		level = leaves
+	)
+	for size := MaxEra1Size; size > 1; size /= 2 {
+		next := make([]common.Hash, (len(level)+1)/2)
+		for i := range next {
+			left, right := level[2*i], zero
+			if 2*i+1 < len(level) {
+				right = level[2*i+1]
+			}
+			next[i] = crypto.Keccak256Hash(left[:], right[:])
+		}
+		zero = crypto.Keccak256Hash(zero[:], zero[:])
+		level = next
+	}
+	root := zero
+	if len(level) > 0 {
+		root = level[0]
+	}
+
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:8], uint64(len(hashes)))
+	return crypto.Keccak256Hash(root[:], length[:]), nil
+}
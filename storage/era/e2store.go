@@ -0,0 +1,111 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// headerSize is the size of the header of an e2store entry. The header
+// consists of the type (2 bytes), the length of the value (4 bytes) and the
+// reserved bytes (2 bytes) which must be zero, all in little endian.
+const headerSize = 8
+
+var (
+	errReservedNotZero = errors.New("reserved bytes of the entry header are not zero")
+	errEntryTooLarge   = errors.New("length of the entry exceeds the file size")
+)
+
+// Entry is a type-length-value record of an e2store file.
+type Entry struct {
+	Type  uint16
+	Value []byte
+}
+
+// e2storeWriter writes the entries of an e2store file.
+type e2storeWriter struct {
+	w io.Writer
+}
+
+func newE2storeWriter(w io.Writer) *e2storeWriter {
+	return &e2storeWriter{w: w}
+}
+
+// Write writes an entry of the given type and value and returns the number
+// of the bytes written including the header.
+func (w *e2storeWriter) Write(typ uint16, value []byte) (int, error) {
+	if uint64(len(value)) > uint64(^uint32(0)) {
+		return 0, fmt.Errorf("value of the entry is too large: %d bytes", len(value))
+	}
+	var header [headerSize]byte
+	binary.LittleEndian.PutUint16(header[0:2], typ)
+	binary.LittleEndian.PutUint32(header[2:6], uint32(len(value)))
+	if n, err := w.w.Write(header[:]); err != nil {
+		return n, err
+	}
+	n, err := w.w.Write(value)
+	return headerSize + n, err
+}
+
+// e2storeReader reads the entries of an e2store file of the given size at
+// given offsets.
+type e2storeReader struct {
+	r    io.ReaderAt
+	size int64
+}
+
+func newE2storeReader(r io.ReaderAt, size int64) *e2storeReader {
+	return &e2storeReader{r: r, size: size}
+}
+
+// ReadAt reads the entry at the given offset. The length in the header is
+// checked against the file size before the value is allocated.
+func (r *e2storeReader) ReadAt(off int64) (*Entry, error) {
+	typ, length, err := r.ReadHeaderAt(off)
+	if err != nil {
+		return nil, err
+	}
+	if off+headerSize+int64(length) > r.size {
+		return nil, fmt.Errorf("%w: %d bytes at offset %d", errEntryTooLarge, length, off)
+	}
+	entry := &Entry{Type: typ, Value: make([]byte, length)}
+	if _, err := r.r.ReadAt(entry.Value, off+headerSize); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+// ReadHeaderAt reads the type and the length of the entry at the given offset.
+func (r *e2storeReader) ReadHeaderAt(off int64) (uint16, uint32, error) {
+	var header [headerSize]byte
+	if n, err := r.r.ReadAt(header[:], off); err != nil {
+		if err == io.EOF && n > 0 {
+			return 0, 0, io.ErrUnexpectedEOF
+		}
+		return 0, 0, err
+	}
+	if header[6] != 0 || header[7] != 0 {
+		return 0, 0, errReservedNotZero
+	}
+	return binary.LittleEndian.Uint16(header[0:2]), binary.LittleEndian.Uint32(header[2:6]), nil
+}
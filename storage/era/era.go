@@ -0,0 +1,418 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package era implements an era1-like flat-file archive of the block history.
+//
+// An era file is an e2store file holding the headers, bodies, receipts and
+// total block scores of a fixed range of blocks, followed by the accumulator
+// root of the header records and an index of the blocks:
+//
+//	era := Version | block-tuple* | Accumulator | BlockIndex
+//	block-tuple := CompressedHeader | CompressedBody | CompressedReceipts | TotalScore
+//
+// The headers, bodies and receipts are RLP encoded and compressed with the
+// snappy framing format. The receipts are encoded in the storage format.
+package era
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/snappy"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// Types of the e2store entries of an era file.
+const (
+	TypeVersion            uint16 = 0x3265
+	TypeCompressedHeader   uint16 = 0x03
+	TypeCompressedBody     uint16 = 0x04
+	TypeCompressedReceipts uint16 = 0x05
+	TypeTotalScore         uint16 = 0x06
+	TypeAccumulator        uint16 = 0x07
+	TypeBlockIndex         uint16 = 0x3266
+)
+
+// MaxEra1Size is the maximum number of blocks stored in an era file.
+const MaxEra1Size = 8192
+
+var (
+	errEmptyEra    = errors.New("no block is added to the era")
+	errFinalized   = errors.New("era is already finalized")
+	errInvalidFile = errors.New("invalid era file")
+)
+
+// Filename returns the file name of an era. The name consists of the network
+// name, the epoch and the first 4 bytes of the accumulator root.
+func Filename(network string, epoch int, root common.Hash) string {
+	return fmt.Sprintf("%s-%05d-%s.era1", network, epoch, root.Hex()[2:10])
+}
+
+// ReadDir returns the era files of the given network in the directory,
+// sorted by the epoch.
+func ReadDir(dir, network string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		files  []string
+		epochs = make(map[string]int)
+	)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".era1" || !strings.HasPrefix(name, network+"-") {
+			continue
+		}
+		parts := strings.Split(strings.TrimSuffix(name, ".era1"), "-")
+		if len(parts) != 3 || parts[0] != network {
+			continue
+		}
+		epoch, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("malformed era file name %s: %v", name, err)
+		}
+		files = append(files, filepath.Join(dir, name))
+		epochs[files[len(files)-1]] = epoch
+	}
+	sort.Slice(files, func(i, j int) bool { return epochs[files[i]] < epochs[files[j]] })
+	return files, nil
+}
+
+// Builder writes the blocks of an era into an e2store stream. The blocks must
+// be added in ascending order of the block number without a gap.
+type Builder struct {
+	w       *e2storeWriter
+	written uint64
+
+	start     *uint64
+	offsets   []uint64
+	hashes    []common.Hash
+	scores    []*big.Int
+	finalized bool
+
+	buf    *bytes.Buffer
+	snappy *snappy.Writer
+}
+
+// NewBuilder returns a new Builder writing into the given writer.
+func NewBuilder(w io.Writer) *Builder {
+	buf := new(bytes.Buffer)
+	return &Builder{
+		w:      newE2storeWriter(w),
+		buf:    buf,
+		snappy: snappy.NewBufferedWriter(buf),
+	}
+}
+
+// Add adds a block with its receipts and total block score to the era.
+func (b *Builder) Add(block *types.Block, receipts types.Receipts, score *big.Int) error {
+	header, err := rlp.EncodeToBytes(block.Header())
+	if err != nil {
+		return err
+	}
+	body, err := rlp.EncodeToBytes(block.Body())
+	if err != nil {
+		return err
+	}
+	storageReceipts := make([]*types.ReceiptForStorage, len(receipts))
+	for i, receipt := range receipts {
+		storageReceipts[i] = (*types.ReceiptForStorage)(receipt)
+	}
+	rs, err := rlp.EncodeToBytes(storageReceipts)
+	if err != nil {
+		return err
+	}
+	return b.AddRLP(header, body, rs, block.NumberU64(), block.Hash(), score)
+}
+
+// AddRLP adds the RLP encoded header, body and receipts of a block to the era.
+func (b *Builder) AddRLP(header, body, receipts []byte, number uint64, hash common.Hash, score *big.Int) error {
+	if b.finalized {
+		return errFinalized
+	}
+	if score.Sign() < 0 || score.BitLen() > 256 {
+		return fmt.Errorf("invalid total block score of block %d: %v", number, score)
+	}
+	if len(b.offsets) >= MaxEra1Size {
+		return fmt.Errorf("exceeds the maximum number of blocks in an era: %d", MaxEra1Size)
+	}
+	if b.start == nil {
+		// The version entry is written before the first block.
+		if err := b.write(TypeVersion, nil); err != nil {
+			return err
+		}
+		b.start = &number
+	} else if expected := *b.start + uint64(len(b.offsets)); number != expected {
+		return fmt.Errorf("block number %d is not sequential, expected %d", number, expected)
+	}
+	b.offsets = append(b.offsets, b.written)
+	b.hashes = append(b.hashes, hash)
+	b.scores = append(b.scores, new(big.Int).Set(score))
+
+	for _, entry := range []struct {
+		typ  uint16
+		data []byte
+	}{
+		{TypeCompressedHeader, header},
+		{TypeCompressedBody, body},
+		{TypeCompressedReceipts, receipts},
+	} {
+		if err := b.writeCompressed(entry.typ, entry.data); err != nil {
+			return err
+		}
+	}
+	return b.write(TypeTotalScore, bigToBytes32(score))
+}
+
+// Finalize writes the accumulator root and the block index, and returns the
+// accumulator root.
+func (b *Builder) Finalize() (common.Hash, error) {
+	if b.finalized {
+		return common.Hash{}, errFinalized
+	}
+	if b.start == nil {
+		return common.Hash{}, errEmptyEra
+	}
+	root, err := ComputeAccumulator(b.hashes, b.scores)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := b.write(TypeAccumulator, root[:]); err != nil {
+		return common.Hash{}, err
+	}
+
+	// The index consists of the first block number, the offsets of the
+	// blocks relative to the beginning of the index entry and the count.
+	var (
+		base  = int64(b.written)
+		count = len(b.offsets)
+		index = make([]byte, 16+8*count)
+	)
+	binary.LittleEndian.PutUint64(index, *b.start)
+	for i, offset := range b.offsets {
+		binary.LittleEndian.PutUint64(index[8+8*i:], uint64(int64(offset)-base))
+	}
+	binary.LittleEndian.PutUint64(index[8+8*count:], uint64(count))
+	if err := b.write(TypeBlockIndex, index); err != nil {
+		return common.Hash{}, err
+	}
+	b.finalized = true
+	return root, nil
+}
+
+func (b *Builder) write(typ uint16, value []byte) error {
+	n, err := b.w.Write(typ, value)
+	b.written += uint64(n)
+	return err
+}
+
+func (b *Builder) writeCompressed(typ uint16, data []byte) error {
+	b.buf.Reset()
+	b.snappy.Reset(b.buf)
+	if _, err := b.snappy.Write(data); err != nil {
+		return err
+	}
+	if err := b.snappy.Flush(); err != nil {
+		return err
+	}
+	return b.write(typ, b.buf.Bytes())
+}
+
+// BlockTuple is a block stored in an era with its receipts and total block score.
+type BlockTuple struct {
+	Header     *types.Header
+	Body       *types.Body
+	Receipts   types.Receipts
+	TotalScore *big.Int
+}
+
+// Block returns the block assembled from the header and the body.
+func (t *BlockTuple) Block() *types.Block {
+	return types.NewBlockWithHeader(t.Header).WithBody(t.Body.Transactions)
+}
+
+// Era reads the blocks of an era file.
+type Era struct {
+	f     *os.File
+	s     *e2storeReader
+	start uint64
+	count uint64
+	index int64 // offset of the block index entry
+}
+
+// Open opens the era file of the given path.
+func Open(path string) (*Era, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	e, err := from(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return e, nil
+}
+
+func from(f *os.File) (*Era, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size < headerSize+24 {
+		return nil, errInvalidFile
+	}
+	var buf [8]byte
+	if _, err := f.ReadAt(buf[:], size-8); err != nil {
+		return nil, err
+	}
+	count := binary.LittleEndian.Uint64(buf[:])
+	if count == 0 || count > MaxEra1Size {
+		return nil, fmt.Errorf("%w: invalid block count %d", errInvalidFile, count)
+	}
+	e := &Era{
+		f:     f,
+		s:     newE2storeReader(f, size),
+		count: count,
+		index: size - headerSize - 16 - 8*int64(count),
+	}
+	typ, length, err := e.s.ReadHeaderAt(e.index)
+	if err != nil {
+		return nil, err
+	}
+	if typ != TypeBlockIndex || int64(length) != 16+8*int64(count) {
+		return nil, fmt.Errorf("%w: block index not found", errInvalidFile)
+	}
+	if _, err := f.ReadAt(buf[:], e.index+headerSize); err != nil {
+		return nil, err
+	}
+	e.start = binary.LittleEndian.Uint64(buf[:])
+	return e, nil
+}
+
+// Close closes the era file.
+func (e *Era) Close() error {
+	return e.f.Close()
+}
+
+// Start returns the number of the first block of the era.
+func (e *Era) Start() uint64 {
+	return e.start
+}
+
+// Count returns the number of the blocks of the era.
+func (e *Era) Count() uint64 {
+	return e.count
+}
+
+// Accumulator returns the accumulator root stored in the era.
+func (e *Era) Accumulator() (common.Hash, error) {
+	entry, err := e.s.ReadAt(e.index - headerSize - common.HashLength)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if entry.Type != TypeAccumulator || len(entry.Value) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("%w: accumulator not found", errInvalidFile)
+	}
+	return common.BytesToHash(entry.Value), nil
+}
+
+// GetBlockTuple returns the block of the given number with its receipts and
+// total block score.
+func (e *Era) GetBlockTuple(number uint64) (*BlockTuple, error) {
+	if number < e.start || number >= e.start+e.count {
+		return nil, fmt.Errorf("block %d is out of the era [%d, %d]", number, e.start, e.start+e.count-1)
+	}
+	var buf [8]byte
+	if _, err := e.f.ReadAt(buf[:], e.index+headerSize+8+8*int64(number-e.start)); err != nil {
+		return nil, err
+	}
+	off := e.index + int64(binary.LittleEndian.Uint64(buf[:]))
+
+	var (
+		tuple    BlockTuple
+		receipts []*types.ReceiptForStorage
+	)
+	for _, item := range []struct {
+		typ uint16
+		val interface{}
+	}{
+		{TypeCompressedHeader, &tuple.Header},
+		{TypeCompressedBody, &tuple.Body},
+		{TypeCompressedReceipts, &receipts},
+		{TypeTotalScore, nil},
+	} {
+		entry, err := e.s.ReadAt(off)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Type != item.typ {
+			return nil, fmt.Errorf("%w: unexpected entry type %#x of block %d, expected %#x", errInvalidFile, entry.Type, number, item.typ)
+		}
+		off += headerSize + int64(len(entry.Value))
+
+		if item.typ == TypeTotalScore {
+			if len(entry.Value) != 32 {
+				return nil, fmt.Errorf("%w: invalid total block score of block %d", errInvalidFile, number)
+			}
+			tuple.TotalScore = bytes32ToBig(entry.Value)
+			continue
+		}
+		data, err := io.ReadAll(snappy.NewReader(bytes.NewReader(entry.Value)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress the entry %#x of block %d: %v", item.typ, number, err)
+		}
+		if err := rlp.DecodeBytes(data, item.val); err != nil {
+			return nil, fmt.Errorf("failed to decode the entry %#x of block %d: %v", item.typ, number, err)
+		}
+	}
+	tuple.Receipts = make(types.Receipts, len(receipts))
+	for i, receipt := range receipts {
+		tuple.Receipts[i] = (*types.Receipt)(receipt)
+	}
+	return &tuple, nil
+}
+
+// bigToBytes32 encodes the given integer into 32 bytes in little endian.
+func bigToBytes32(n *big.Int) []byte {
+	b := common.LeftPadBytes(n.Bytes(), 32)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
+
+// bytes32ToBig decodes the given 32 bytes in little endian into an integer.
+func bytes32ToBig(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
@@ -0,0 +1,219 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/blockchain/vm"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/gxhash"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/klaytn/klaytn/storage/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChain returns a database storing a chain of the given length whose
+// blocks have a value transfer transaction each, with the genesis spec.
+func newTestChain(t *testing.T, n int) (database.DBManager, *blockchain.Genesis) {
+	blockchain.InitDeriveSha(params.TestChainConfig)
+
+	var (
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		signer  = types.LatestSignerForChainID(params.TestChainConfig.ChainID)
+		engine  = gxhash.NewFaker()
+		gspec   = &blockchain.Genesis{Config: params.TestChainConfig, Alloc: blockchain.GenesisAlloc{addr: {Balance: big.NewInt(params.KAIA)}}}
+		gendb   = database.NewMemoryDBManager()
+		genesis = gspec.MustCommit(gendb)
+	)
+	blocks, _ := blockchain.GenerateChain(gspec.Config, genesis, engine, gendb, n, func(i int, b *blockchain.BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), common.Address{0x1}, big.NewInt(1), params.TxGas, big.NewInt(0), nil), signer, key)
+		require.NoError(t, err)
+		b.AddTx(tx)
+	})
+
+	db := database.NewMemoryDBManager()
+	gspec.MustCommit(db)
+	cacheConfig := &blockchain.CacheConfig{
+		ArchiveMode:         true,
+		CacheSize:           512,
+		BlockInterval:       blockchain.DefaultBlockInterval,
+		TriesInMemory:       blockchain.DefaultTriesInMemory,
+		TrieNodeCacheConfig: statedb.GetEmptyTrieNodeCacheConfig(),
+	}
+	chain, err := blockchain.NewBlockChain(db, cacheConfig, gspec.Config, engine, vm.Config{})
+	require.NoError(t, err)
+	defer chain.Stop()
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+	return db, gspec
+}
+
+func TestE2store(t *testing.T) {
+	var (
+		buf     bytes.Buffer
+		w       = newE2storeWriter(&buf)
+		entries = []Entry{{TypeVersion, []byte{}}, {TypeCompressedHeader, []byte{0x1, 0x2}}, {TypeAccumulator, bytes.Repeat([]byte{0xff}, 32)}}
+		offsets []int64
+		written int64
+	)
+	for _, entry := range entries {
+		offsets = append(offsets, written)
+		n, err := w.Write(entry.Type, entry.Value)
+		require.NoError(t, err)
+		assert.Equal(t, headerSize+len(entry.Value), n)
+		written += int64(n)
+	}
+
+	r := newE2storeReader(bytes.NewReader(buf.Bytes()), written)
+	for i, entry := range entries {
+		read, err := r.ReadAt(offsets[i])
+		require.NoError(t, err)
+		assert.Equal(t, entry.Type, read.Type)
+		assert.Equal(t, entry.Value, read.Value)
+	}
+	_, err := r.ReadAt(written - 1)
+	assert.Error(t, err)
+
+	// The length must not exceed the file size.
+	data := buf.Bytes()
+	data[5] = 0xff
+	_, err = r.ReadAt(0)
+	assert.ErrorIs(t, err, errEntryTooLarge)
+	data[5] = 0
+
+	// The reserved bytes must be zero.
+	data[6] = 1
+	_, err = r.ReadAt(0)
+	assert.Equal(t, errReservedNotZero, err)
+}
+
+func TestComputeAccumulator(t *testing.T) {
+	hashes := []common.Hash{{0x1}, {0x2}, {0x3}}
+	scores := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+
+	root, err := ComputeAccumulator(hashes, scores)
+	require.NoError(t, err)
+	again, err := ComputeAccumulator(hashes, scores)
+	require.NoError(t, err)
+	assert.Equal(t, root, again)
+
+	// The root commits to the hashes, the scores and the number of the records.
+	other, err := ComputeAccumulator(hashes, []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(4)})
+	require.NoError(t, err)
+	assert.NotEqual(t, root, other)
+	other, err = ComputeAccumulator(hashes[:2], scores[:2])
+	require.NoError(t, err)
+	assert.NotEqual(t, root, other)
+
+	_, err = ComputeAccumulator(hashes, scores[:2])
+	assert.Error(t, err)
+	_, err = ComputeAccumulator(hashes[:1], []*big.Int{new(big.Int).Lsh(common.Big1, 256)})
+	assert.Error(t, err)
+}
+
+func TestExportVerifyImport(t *testing.T) {
+	db, gspec := newTestChain(t, 20)
+	dir := t.TempDir()
+
+	// The blocks are split into [0, 7], [8, 15] and [16, 20].
+	files, err := Export(db, dir, "test", 0, 20, 8)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	read, err := ReadDir(dir, "test")
+	require.NoError(t, err)
+	assert.Equal(t, files, read)
+
+	for i, file := range files {
+		e, err := Open(file)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(8*i), e.Start())
+		root, err := Verify(e)
+		require.NoError(t, err)
+		assert.Equal(t, Filename("test", i, root), filepath.Base(file))
+
+		tuple, err := e.GetBlockTuple(e.Start() + 1)
+		require.NoError(t, err)
+		hash := db.ReadCanonicalHash(e.Start() + 1)
+		assert.Equal(t, hash, tuple.Header.Hash())
+		assert.Equal(t, db.ReadTd(hash, e.Start()+1), tuple.TotalScore)
+		assert.Len(t, tuple.Body.Transactions, 1)
+		assert.Len(t, tuple.Receipts, 1)
+		require.NoError(t, e.Close())
+	}
+
+	// A block is imported only if its parent is stored in the database.
+	imported := database.NewMemoryDBManager()
+	gspec.MustCommit(imported)
+	assert.Error(t, Import(imported, files[1:]))
+	require.NoError(t, Import(imported, files))
+	for number := uint64(0); number <= 20; number++ {
+		hash := db.ReadCanonicalHash(number)
+		assert.Equal(t, hash, imported.ReadCanonicalHash(number))
+		assert.Equal(t, db.ReadTd(hash, number), imported.ReadTd(hash, number))
+		assert.Equal(t, db.ReadBodyRLP(hash, number), imported.ReadBodyRLP(hash, number))
+		assert.Equal(t, len(db.ReadReceipts(hash, number)), len(imported.ReadReceipts(hash, number)))
+	}
+	head := db.ReadCanonicalHash(20)
+	assert.Equal(t, head, imported.ReadHeadHeaderHash())
+	assert.Equal(t, head, imported.ReadHeadFastBlockHash())
+	tx := db.ReadBlock(head, 20).Transactions()[0]
+	blockHash, _, _ := imported.ReadTxLookupEntry(tx.Hash())
+	assert.Equal(t, head, blockHash)
+
+	// Importing the files again skips the stored blocks.
+	require.NoError(t, Import(imported, files))
+
+	// A different canonical block is not overwritten.
+	imported.WriteCanonicalHash(common.Hash{0x1}, 5)
+	assert.ErrorIs(t, Import(imported, files[:1]), errCanonicalMismatch)
+	assert.Equal(t, common.Hash{0x1}, imported.ReadCanonicalHash(5))
+}
+
+func TestVerify_Corrupted(t *testing.T) {
+	db, _ := newTestChain(t, 4)
+	files, err := Export(db, t.TempDir(), "test", 0, 4, MaxEra1Size)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// Replace the total block score of the last block, which is the entry
+	// right before the accumulator.
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	e, err := Open(files[0])
+	require.NoError(t, err)
+	pos := e.index - headerSize - common.HashLength - 32
+	require.NoError(t, e.Close())
+	data[pos] ^= 0xff
+	require.NoError(t, os.WriteFile(files[0], data, 0o644))
+
+	e, err = Open(files[0])
+	require.NoError(t, err)
+	defer e.Close()
+	_, err = Verify(e)
+	assert.ErrorContains(t, err, "total block score mismatch")
+}
@@ -0,0 +1,302 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/klaytn/klaytn/storage/database"
+)
+
+var logger = log.NewModuleLogger(log.StorageEra)
+
+var errCanonicalMismatch = errors.New("canonical block differs from the era")
+
+// Export writes the canonical blocks in [first, last] stored in the database
+// into era files of the given step in the directory. The epoch of an era file
+// is the number of its first block divided by the step. It returns the paths
+// of the written files.
+func Export(dbm database.DBManager, dir, network string, first, last, step uint64) ([]string, error) {
+	if first > last {
+		return nil, fmt.Errorf("the first block number %d is greater than the last %d", first, last)
+	}
+	if step == 0 || step > MaxEra1Size {
+		return nil, fmt.Errorf("invalid step %d, it should be in [1, %d]", step, MaxEra1Size)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var (
+		files    []string
+		start    = time.Now()
+		reported = time.Now()
+	)
+	for from := first; from <= last; from += step {
+		to := from + step - 1
+		if to > last || to < from {
+			to = last
+		}
+		file, err := exportEra(dbm, dir, network, int(from/step), from, to)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, file)
+		if time.Since(reported) >= log.StatsReportLimit || to == last {
+			logger.Info("Exported era files", "files", len(files), "last", to, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+		if to == last {
+			break
+		}
+	}
+	return files, nil
+}
+
+// exportEra writes the blocks in [from, to] into an era file. The file is
+// written under a temporary name and renamed after the accumulator root is known.
+func exportEra(dbm database.DBManager, dir, network string, epoch int, from, to uint64) (string, error) {
+	tmp := filepath.Join(dir, fmt.Sprintf("%s-%05d.era1.tmp", network, epoch))
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	builder := NewBuilder(f)
+	for number := from; number <= to; number++ {
+		hash := dbm.ReadCanonicalHash(number)
+		if common.EmptyHash(hash) {
+			return "", fmt.Errorf("canonical hash of block %d not found", number)
+		}
+		header := dbm.ReadHeaderRLP(hash, number)
+		if len(header) == 0 {
+			return "", fmt.Errorf("header of block %d not found", number)
+		}
+		body := dbm.ReadBodyRLP(hash, number)
+		if len(body) == 0 {
+			return "", fmt.Errorf("body of block %d not found", number)
+		}
+		score := dbm.ReadTd(hash, number)
+		if score == nil {
+			return "", fmt.Errorf("total block score of block %d not found", number)
+		}
+		// The receipts of a block without a transaction, e.g. the genesis
+		// block, may not be stored.
+		receipts := dbm.ReadReceipts(hash, number)
+		storageReceipts := make([]*types.ReceiptForStorage, len(receipts))
+		for i, receipt := range receipts {
+			storageReceipts[i] = (*types.ReceiptForStorage)(receipt)
+		}
+		rs, err := rlp.EncodeToBytes(storageReceipts)
+		if err != nil {
+			return "", err
+		}
+		if err := builder.AddRLP(header, body, rs, number, hash, score); err != nil {
+			return "", err
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		return "", err
+	}
+	if err := f.Sync(); err != nil {
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	file := filepath.Join(dir, Filename(network, epoch, root))
+	if err := os.Rename(tmp, file); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+// Verify checks the integrity of the era. It checks that the headers are
+// chained in order, that the total block scores are accumulated from the
+// block scores of the headers, that the transactions and the receipts match
+// the roots and the bloom of the headers, and that the stored accumulator root
+// is computed from the header records. types.DeriveSha must be initialized
+// before calling Verify. It returns the accumulator root.
+func Verify(e *Era) (common.Hash, error) {
+	var (
+		hashes = make([]common.Hash, 0, e.Count())
+		scores = make([]*big.Int, 0, e.Count())
+		parent *BlockTuple
+	)
+	for number := e.Start(); number < e.Start()+e.Count(); number++ {
+		tuple, err := e.GetBlockTuple(number)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if err := verifyBlockTuple(tuple, parent); err != nil {
+			return common.Hash{}, fmt.Errorf("block %d: %v", number, err)
+		}
+		hashes = append(hashes, tuple.Header.Hash())
+		scores = append(scores, tuple.TotalScore)
+		parent = tuple
+	}
+
+	root, err := ComputeAccumulator(hashes, scores)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	stored, err := e.Accumulator()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if root != stored {
+		return common.Hash{}, fmt.Errorf("accumulator root mismatch: stored %s, computed %s", stored.Hex(), root.Hex())
+	}
+	return root, nil
+}
+
+// verifyBlockTuple checks a block stored in an era against its parent stored
+// in the same era. The parent is nil for the first block of the era.
+func verifyBlockTuple(tuple, parent *BlockTuple) error {
+	header := tuple.Header
+	if parent != nil {
+		if header.ParentHash != parent.Header.Hash() {
+			return fmt.Errorf("parent hash mismatch: %s != %s", header.ParentHash.Hex(), parent.Header.Hash().Hex())
+		}
+		if expected := new(big.Int).Add(parent.TotalScore, header.BlockScore); tuple.TotalScore.Cmp(expected) != 0 {
+			return fmt.Errorf("total block score mismatch: %v != %v", tuple.TotalScore, expected)
+		}
+	}
+	if root := types.DeriveSha(types.Transactions(tuple.Body.Transactions), header.Number); root != header.TxHash {
+		return fmt.Errorf("transaction root mismatch: %s != %s", root.Hex(), header.TxHash.Hex())
+	}
+	if root := types.DeriveSha(tuple.Receipts, header.Number); root != header.ReceiptHash {
+		return fmt.Errorf("receipt root mismatch: %s != %s", root.Hex(), header.ReceiptHash.Hex())
+	}
+	if bloom := types.CreateBloom(tuple.Receipts); bloom != header.Bloom {
+		return fmt.Errorf("bloom mismatch")
+	}
+	return nil
+}
+
+// Import verifies the given era files and writes their blocks into the
+// database without executing them. The files must be given in ascending order
+// of the block numbers, and the first block of each file must be connected to
+// a canonical block stored in the database. The blocks already stored in the
+// database are skipped, and the import is refused if a different block is
+// stored as canonical at the same number. The head header and the head fast block are moved to
+// the last imported block, while the head block is kept since the states of
+// the imported blocks are not available.
+func Import(dbm database.DBManager, files []string) error {
+	start := time.Now()
+	for _, file := range files {
+		e, err := Open(file)
+		if err != nil {
+			return err
+		}
+		err = importEra(dbm, e)
+		e.Close()
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", file, err)
+		}
+		logger.Info("Imported era file", "file", filepath.Base(file), "first", e.Start(), "last", e.Start()+e.Count()-1,
+			"elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+func importEra(dbm database.DBManager, e *Era) error {
+	if _, err := Verify(e); err != nil {
+		return err
+	}
+
+	var last *types.Header
+	for number := e.Start(); number < e.Start()+e.Count(); number++ {
+		tuple, err := e.GetBlockTuple(number)
+		if err != nil {
+			return err
+		}
+		header, hash := tuple.Header, tuple.Header.Hash()
+		last = header
+
+		// The blocks in an era are chained, so that only the first block
+		// needs to be checked against the database.
+		if number == e.Start() {
+			if err := checkConnected(dbm, tuple); err != nil {
+				return err
+			}
+		}
+		// A different canonical block is not overwritten, since its tx
+		// lookups and the head block would be left inconsistent. The chain
+		// should be rewound first to import the blocks in the era.
+		if canonical := dbm.ReadCanonicalHash(number); !common.EmptyHash(canonical) {
+			if canonical != hash {
+				return fmt.Errorf("%w: block %d is %s in the database, %s in the era", errCanonicalMismatch, number, canonical.Hex(), hash.Hex())
+			}
+			if dbm.HasBody(hash, number) {
+				continue
+			}
+		}
+		dbm.WriteHeader(header)
+		dbm.WriteTd(hash, number, tuple.TotalScore)
+		dbm.WriteBody(hash, number, tuple.Body)
+		dbm.WriteReceipts(hash, number, tuple.Receipts)
+		dbm.WriteCanonicalHash(hash, number)
+		dbm.WriteTxLookupEntries(tuple.Block())
+	}
+
+	headNumber := uint64(0)
+	if number := dbm.ReadHeaderNumber(dbm.ReadHeadHeaderHash()); number != nil {
+		headNumber = *number
+	}
+	if last.Number.Uint64() > headNumber {
+		dbm.WriteHeadHeaderHash(last.Hash())
+		dbm.WriteHeadFastBlockHash(last.Hash())
+	}
+	return nil
+}
+
+// checkConnected checks that the given block is connected to the canonical
+// chain stored in the database.
+func checkConnected(dbm database.DBManager, tuple *BlockTuple) error {
+	number := tuple.Header.Number.Uint64()
+	if number == 0 {
+		if genesis := dbm.ReadCanonicalHash(0); !common.EmptyHash(genesis) && genesis != tuple.Header.Hash() {
+			return fmt.Errorf("genesis block mismatch: %s != %s", tuple.Header.Hash().Hex(), genesis.Hex())
+		}
+		return nil
+	}
+	parent := dbm.ReadCanonicalHash(number - 1)
+	if parent != tuple.Header.ParentHash {
+		return fmt.Errorf("block %d is not connected to the canonical chain", number)
+	}
+	score := dbm.ReadTd(parent, number-1)
+	if score == nil {
+		return fmt.Errorf("total block score of block %d not found", number-1)
+	}
+	if expected := new(big.Int).Add(score, tuple.Header.BlockScore); tuple.TotalScore.Cmp(expected) != 0 {
+		return fmt.Errorf("total block score mismatch of block %d: %v != %v", number, tuple.TotalScore, expected)
+	}
+	return nil
+}
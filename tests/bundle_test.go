@@ -0,0 +1,139 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/work"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyBundles checks that a bundle is included in a block only if all of
+// its transactions succeed in the order. The failed bundles are dropped only if
+// they can't be applied to the later blocks, and the others, like a bundle with
+// a future nonce, are included in a later block.
+func TestApplyBundles(t *testing.T) {
+	log.EnableLogForTest(log.LvlCrit, log.LvlError)
+
+	bcdata, err := NewBCData(6, 4)
+	require.NoError(t, err)
+	defer bcdata.Shutdown()
+
+	header, err := bcdata.prepareHeader()
+	require.NoError(t, err)
+	statedb, err := bcdata.bc.State()
+	require.NoError(t, err)
+
+	var (
+		signer   = types.MakeSigner(bcdata.bc.Config(), header.Number)
+		from     = *bcdata.addrs[0]
+		to       = *bcdata.addrs[1]
+		nonce    = statedb.GetNonce(from)
+		balance  = statedb.GetBalance(to)
+		number   = header.Number.Uint64()
+		pool     = blockchain.NewBundlePool(0)
+		gasLimit = uint64(1000000)
+	)
+	signTx := func(tx *types.Transaction) *types.Transaction {
+		signedTx, err := types.SignTx(tx, signer, bcdata.privKeys[0])
+		require.NoError(t, err)
+		return signedTx
+	}
+	transfer := func(nonce uint64, amount int64) *types.Transaction {
+		return signTx(types.NewTransaction(nonce, to, big.NewInt(amount), gasLimit, common.Big0, nil))
+	}
+	addBundle := func(minBlock uint64, txs ...*types.Transaction) *blockchain.Bundle {
+		bundle := &blockchain.Bundle{Txs: txs, MinBlock: minBlock, MaxBlock: minBlock + 10}
+		_, err := pool.Add(bundle, number-1)
+		require.NoError(t, err)
+		return bundle
+	}
+
+	// The transactions of the bundles are validated on the state.
+	validate := func(txs ...*types.Transaction) error {
+		bundle := &blockchain.Bundle{Txs: txs, MinBlock: number, MaxBlock: number}
+		return bundle.ValidateTxs(signer, statedb, number-1)
+	}
+	assert.NoError(t, validate(transfer(nonce, 1), transfer(nonce+1, 1)))
+	assert.NoError(t, validate(transfer(nonce+1, 1)))
+	assert.ErrorIs(t, validate(transfer(nonce, 1), transfer(nonce+2, 1)), blockchain.ErrNonceTooHigh)
+	assert.ErrorContains(t, validate(types.NewTransaction(nonce, to, common.Big1, gasLimit, common.Big0, nil)), "invalid sender")
+	assert.ErrorIs(t, validate(signTx(types.NewTransaction(nonce, to, new(big.Int).Add(statedb.GetBalance(from), common.Big1), gasLimit, common.Big0, nil))), blockchain.ErrInsufficientFundsFrom)
+	assert.ErrorIs(t, validate(signTx(types.NewTransaction(nonce, to, common.Big1, 1000, common.Big0, nil))), blockchain.ErrIntrinsicGas)
+
+	// The transfer is rolled back since the contract creation is reverted.
+	reverted := addBundle(number, transfer(nonce, 1), signTx(types.NewContractCreation(nonce+1, common.Big0, gasLimit, common.Big0, hexutil.MustDecode("0x60006000fd"))))
+	committed := addBundle(number, transfer(nonce, 10), transfer(nonce+1, 20))
+	// The bundle is outdated by the committed one.
+	outdated := addBundle(number, transfer(nonce, 30))
+	// The bundle with an invalid signature can't be applied.
+	unsigned := addBundle(number, types.NewTransaction(nonce+2, to, common.Big1, gasLimit, common.Big0, nil))
+	// The bundle is not applied before its block range.
+	next := addBundle(number+1, transfer(nonce+2, 40))
+	// The bundle has a future nonce until the next one is applied.
+	future := addBundle(number, transfer(nonce+3, 50))
+
+	mine := func(header *types.Header, statedb *state.StateDB) *work.Task {
+		task := work.NewTask(bcdata.bc.Config(), signer, statedb, header)
+		task.ApplyBundles(pool, bcdata.bc, *bcdata.rewardBase)
+
+		// The block with the committed bundles is accepted by the chain.
+		block, err := bcdata.engine.Finalize(bcdata.bc, header, task.State(), task.Transactions(), task.Receipts())
+		require.NoError(t, err)
+		block, err = sealBlock(block, bcdata.validatorPrivKeys)
+		require.NoError(t, err)
+		_, err = bcdata.bc.InsertChain(types.Blocks{block})
+		require.NoError(t, err)
+		return task
+	}
+
+	task := mine(header, statedb)
+	assert.Equal(t, committed.Txs, types.Transactions(task.Transactions()))
+	require.Len(t, task.Receipts(), 2)
+	assert.Equal(t, new(big.Int).Add(balance, big.NewInt(30)), task.State().GetBalance(to))
+	assert.Equal(t, nonce+2, task.State().GetNonce(from))
+	// The bundles which can't be applied anymore are dropped, and the reverted
+	// and future ones are kept.
+	assert.Nil(t, pool.Get(outdated.Hash()))
+	assert.Nil(t, pool.Get(unsigned.Hash()))
+	assert.NotNil(t, pool.Get(reverted.Hash()))
+	assert.NotNil(t, pool.Get(future.Hash()))
+	assert.Equal(t, 4, pool.Len())
+
+	// The future bundle is included in the next block after the one making its nonce valid.
+	header, err = bcdata.prepareHeader()
+	require.NoError(t, err)
+	statedb, err = bcdata.bc.State()
+	require.NoError(t, err)
+
+	task = mine(header, statedb)
+	assert.Equal(t, types.Transactions{next.Txs[0], future.Txs[0]}, types.Transactions(task.Transactions()))
+	assert.Equal(t, nonce+4, task.State().GetNonce(from))
+	// The included bundles are outdated in the later blocks.
+	assert.Nil(t, pool.Get(reverted.Hash()))
+	assert.Nil(t, pool.Get(committed.Hash()))
+	assert.Equal(t, 2, pool.Len())
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/log"
+	"github.com/klaytn/klaytn/work"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyConditionalTransactions checks that a conditional transaction is
+// committed only if its preconditions hold in the block, and that the one
+// whose preconditions can't be met anymore is marked to be dropped.
+func TestApplyConditionalTransactions(t *testing.T) {
+	log.EnableLogForTest(log.LvlCrit, log.LvlError)
+
+	bcdata, err := NewBCData(6, 4)
+	require.NoError(t, err)
+	defer bcdata.Shutdown()
+
+	header, err := bcdata.prepareHeader()
+	require.NoError(t, err)
+	statedb, err := bcdata.bc.State()
+	require.NoError(t, err)
+
+	var (
+		signer = types.MakeSigner(bcdata.bc.Config(), header.Number)
+		to     = *bcdata.addrs[3]
+		number = header.Number.Int64()
+	)
+	conditional := func(i int, cond *types.TransactionConditional) *types.Transaction {
+		from := *bcdata.addrs[i]
+		tx, err := types.SignTx(types.NewTransaction(statedb.GetNonce(from), to, common.Big1, 1000000, common.Big0, nil), signer, bcdata.privKeys[i])
+		require.NoError(t, err)
+		tx.SetConditional(cond)
+		return tx
+	}
+	expired := conditional(0, &types.TransactionConditional{BlockNumberMax: (*hexutil.Big)(big.NewInt(number - 1))})
+	notYet := conditional(1, &types.TransactionConditional{BlockNumberMin: (*hexutil.Big)(big.NewInt(number + 1))})
+	met := conditional(2, &types.TransactionConditional{
+		BlockNumberMin: (*hexutil.Big)(big.NewInt(number)),
+		KnownAccounts: map[common.Address]types.KnownAccount{
+			to: {StorageSlots: map[common.Hash]common.Hash{{}: {}}},
+		},
+	})
+	pending := map[common.Address]types.Transactions{
+		*bcdata.addrs[0]: {expired},
+		*bcdata.addrs[1]: {notYet},
+		*bcdata.addrs[2]: {met},
+	}
+
+	task := work.NewTask(bcdata.bc.Config(), signer, statedb, header)
+	task.ApplyTransactions(types.NewTransactionsByPriceAndNonce(signer, pending, nil), bcdata.bc, *bcdata.rewardBase)
+
+	assert.Equal(t, []*types.Transaction{met}, task.Transactions())
+	assert.True(t, expired.IsMarkedUnexecutable())
+	assert.False(t, notYet.IsMarkedUnexecutable())
+	assert.False(t, met.IsMarkedUnexecutable())
+}
@@ -191,6 +191,20 @@ func (mr *MockTxPoolMockRecorder) Stats() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockTxPool)(nil).Stats))
 }
 
+// Status mocks base method.
+func (m *MockTxPool) Status(arg0 []common.Hash) []blockchain.TxStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", arg0)
+	ret0, _ := ret[0].([]blockchain.TxStatus)
+	return ret0
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockTxPoolMockRecorder) Status(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockTxPool)(nil).Status), arg0)
+}
+
 // Stop mocks base method.
 func (m *MockTxPool) Stop() {
 	m.ctrl.T.Helper()
@@ -228,3 +242,31 @@ func (mr *MockTxPoolMockRecorder) SubscribeNewTxsEvent(arg0 interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeNewTxsEvent", reflect.TypeOf((*MockTxPool)(nil).SubscribeNewTxsEvent), arg0)
 }
+
+// SubscribeTxLifecycleEvent mocks base method.
+func (m *MockTxPool) SubscribeTxLifecycleEvent(arg0 chan<- blockchain.TxLifecycleEvent) event.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeTxLifecycleEvent", arg0)
+	ret0, _ := ret[0].(event.Subscription)
+	return ret0
+}
+
+// SubscribeTxLifecycleEvent indicates an expected call of SubscribeTxLifecycleEvent.
+func (mr *MockTxPoolMockRecorder) SubscribeTxLifecycleEvent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeTxLifecycleEvent", reflect.TypeOf((*MockTxPool)(nil).SubscribeTxLifecycleEvent), arg0)
+}
+
+// TxLifecycle mocks base method.
+func (m *MockTxPool) TxLifecycle(arg0 common.Hash) []blockchain.TxLifecycleEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxLifecycle", arg0)
+	ret0, _ := ret[0].([]blockchain.TxLifecycleEvent)
+	return ret0
+}
+
+// TxLifecycle indicates an expected call of TxLifecycle.
+func (mr *MockTxPoolMockRecorder) TxLifecycle(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxLifecycle", reflect.TypeOf((*MockTxPool)(nil).TxLifecycle), arg0)
+}
@@ -61,6 +61,10 @@ type TxPool interface {
 	// NewTxsEvent and send events to the given channel.
 	SubscribeNewTxsEvent(chan<- blockchain.NewTxsEvent) event.Subscription
 
+	// SubscribeTxLifecycleEvent should return an event subscription of
+	// TxLifecycleEvent and send events to the given channel.
+	SubscribeTxLifecycleEvent(chan<- blockchain.TxLifecycleEvent) event.Subscription
+
 	GetPendingNonce(addr common.Address) uint64
 	AddLocal(tx *types.Transaction) error
 	GasPrice() *big.Int
@@ -68,6 +72,8 @@ type TxPool interface {
 	Stop()
 	Get(hash common.Hash) *types.Transaction
 	Stats() (int, int)
+	Status(hashes []common.Hash) []blockchain.TxStatus
+	TxLifecycle(hash common.Hash) []blockchain.TxLifecycleEvent
 	Content() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
 	StartSpamThrottler(conf *blockchain.ThrottlerConfig) error
 	StopSpamThrottler()
@@ -78,6 +84,7 @@ type Backend interface {
 	AccountManager() accounts.AccountManager
 	BlockChain() BlockChain
 	TxPool() TxPool
+	BundlePool() *blockchain.BundlePool
 	ChainDB() database.DBManager
 	ReBroadcastTxs(transactions types.Transactions)
 }
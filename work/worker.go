@@ -21,6 +21,8 @@
 package work
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 	"sync"
 	"sync/atomic"
@@ -54,8 +56,12 @@ const (
 	chainSideChanSize = 10
 	// maxResendSize is the size of resending transactions to peer in order to prevent the txs from missing.
 	maxResendTxSize = 1000
+	// maxBundleTxsPerBlock is the maximum number of bundle transactions tried in a block.
+	maxBundleTxsPerBlock = 8 * blockchain.MaxBundleTxs
 )
 
+var errBundleTxFailed = errors.New("bundle transaction failed")
+
 var (
 	// Metrics for miner
 	timeLimitReachedCounter = metrics.NewRegisteredCounter("miner/timelimitreached", nil)
@@ -69,6 +75,9 @@ var (
 	nonceTooHighTxsGauge    = metrics.NewRegisteredGauge("miner/nonce/high/txs", nil)
 	gasLimitReachedTxsGauge = metrics.NewRegisteredGauge("miner/limitreached/gas/txs", nil)
 	strangeErrorTxsCounter  = metrics.NewRegisteredCounter("miner/strangeerror/txs", nil)
+	committedBundlesCounter = metrics.NewRegisteredCounter("miner/bundles/committed", nil)
+	failedBundlesCounter    = metrics.NewRegisteredCounter("miner/bundles/failed", nil)
+	conditionFailedCounter  = metrics.NewRegisteredCounter("miner/conditional/failed", nil)
 
 	blockBaseFee              = metrics.NewRegisteredGauge("miner/block/mining/basefee", nil)
 	blockMiningTimer          = kaiametrics.NewRegisteredHybridTimer("miner/block/mining/time", nil)
@@ -118,6 +127,7 @@ type Task struct {
 	receipts []*types.Receipt
 
 	createdAt time.Time
+	deadline  time.Time // the end of the time to apply the transactions, set when they are first applied
 }
 
 type Result struct {
@@ -576,7 +586,7 @@ func (self *worker) commitNewWork() {
 	work := self.current
 	if self.nodetype == common.CONSENSUSNODE {
 		txs := types.NewTransactionsByPriceAndNonce(self.current.signer, pending, work.header.BaseFee)
-		work.commitTransactions(self.mux, txs, self.chain, self.rewardbase)
+		work.commitTransactions(self.mux, self.backend.BundlePool(), txs, self.chain, self.rewardbase)
 		finishedCommitTx := time.Now()
 
 		// Create the new block to seal with the consensus engine
@@ -643,8 +653,9 @@ func (self *worker) updateSnapshot() {
 	self.snapshotState = self.current.state.Copy()
 }
 
-func (env *Task) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByPriceAndNonce, bc BlockChain, rewardbase common.Address) {
-	coalescedLogs := env.ApplyTransactions(txs, bc, rewardbase)
+func (env *Task) commitTransactions(mux *event.TypeMux, bundles *blockchain.BundlePool, txs *types.TransactionsByPriceAndNonce, bc BlockChain, rewardbase common.Address) {
+	coalescedLogs := env.ApplyBundles(bundles, bc, rewardbase)
+	coalescedLogs = append(coalescedLogs, env.ApplyTransactions(txs, bc, rewardbase)...)
 
 	if len(coalescedLogs) > 0 || env.tcount > 0 {
 		// make a copy, the state caches the logs and these logs get "upgraded" from pending to mined
@@ -670,45 +681,10 @@ func (env *Task) ApplyTransactions(txs *types.TransactionsByPriceAndNonce, bc Bl
 	var coalescedLogs []*types.Log
 
 	// Limit the execution time of all transactions in a block
-	var abort int32 = 0       // To break the below commitTransaction for loop when timed out
-	chDone := make(chan bool) // To stop the goroutine below when processing txs is completed
-
-	// chEVM is used to notify the below goroutine of the running EVM so it can call evm.Cancel
-	// when timed out.  We use a buffered channel to prevent the main EVM execution routine
-	// from being blocked due to the channel communication.
-	chEVM := make(chan *vm.EVM, 1)
-
-	go func() {
-		blockTimer := time.NewTimer(params.BlockGenerationTimeLimit)
-		timeout := false
-		var evm *vm.EVM
-
-		for {
-			select {
-			case <-blockTimer.C:
-				timeout = true
-				atomic.StoreInt32(&abort, 1)
-
-			case <-chDone:
-				// Everything is done. Stop this goroutine.
-				return
-
-			case evm = <-chEVM:
-			}
-
-			if timeout && evm != nil {
-				// Allow the first transaction to complete although it exceeds the time limit.
-				if env.tcount > 0 {
-					// The total time limit reached, thus we stop the currently running EVM.
-					evm.Cancel(vm.CancelByTotalTimeLimit)
-				}
-				evm = nil
-			}
-		}
-	}()
+	timer := env.startExecTimer(env.remainingTime())
 
 	vmConfig := &vm.Config{
-		RunningEVM: chEVM,
+		RunningEVM: timer.chEVM,
 	}
 
 	var numTxsChecked int64 = 0
@@ -716,7 +692,7 @@ func (env *Task) ApplyTransactions(txs *types.TransactionsByPriceAndNonce, bc Bl
 	var numTxsNonceTooHigh int64 = 0
 	var numTxsGasLimitReached int64 = 0
 CommitTransactionLoop:
-	for atomic.LoadInt32(&abort) == 0 {
+	for !timer.aborted() {
 		// Retrieve the next transaction and abort if all done
 		tx := txs.Peek()
 		if tx == nil {
@@ -743,6 +719,20 @@ CommitTransactionLoop:
 		//	txs.Pop()
 		//	continue
 		//}
+		// Check the preconditions of the conditional transaction on top of the current state
+		if cond := tx.Conditional(); cond != nil {
+			if err := cond.Check(env.header.Number, env.header.Time.Uint64(), env.state); err != nil {
+				// The failed transaction is dropped from the transaction pool, while
+				// the one not yet met is retried in the later blocks.
+				if errors.Is(err, types.ErrTxConditionFailed) {
+					tx.MarkUnexecutable(true)
+					conditionFailedCounter.Inc(1)
+				}
+				logger.Debug("Skipping conditional transaction", "sender", from, "hash", tx.Hash().String(), "err", err)
+				txs.Pop()
+				continue
+			}
+		}
 		// Start executing the transaction
 		env.state.SetTxContext(tx.Hash(), common.Hash{}, env.tcount)
 
@@ -803,11 +793,181 @@ CommitTransactionLoop:
 	gasLimitReachedTxsGauge.Update(numTxsGasLimitReached)
 
 	// Stop the goroutine that has been handling the timer.
-	chDone <- true
+	timer.stop()
 
 	return coalescedLogs
 }
 
+// remainingTime returns the time left to apply the transactions of the task.
+// The bundles and the other transactions share params.BlockGenerationTimeLimit
+// counted from the first time the transactions are applied.
+func (env *Task) remainingTime() time.Duration {
+	if env.deadline.IsZero() {
+		env.deadline = time.Now().Add(params.BlockGenerationTimeLimit)
+	}
+	return time.Until(env.deadline)
+}
+
+// execTimer limits the execution time of the transactions applied to a task.
+type execTimer struct {
+	abort  int32     // To break the loops applying the transactions when timed out
+	chDone chan bool // To stop the goroutine of the timer when processing txs is completed
+
+	// chEVM is used to notify the goroutine of the running EVM so it can call evm.Cancel
+	// when timed out.  We use a buffered channel to prevent the main EVM execution routine
+	// from being blocked due to the channel communication.
+	chEVM chan *vm.EVM
+}
+
+// startExecTimer starts the timer which aborts the application of the
+// transactions after the given time.
+func (env *Task) startExecTimer(limit time.Duration) *execTimer {
+	t := &execTimer{
+		chDone: make(chan bool),
+		chEVM:  make(chan *vm.EVM, 1),
+	}
+	go func() {
+		blockTimer := time.NewTimer(limit)
+		defer blockTimer.Stop()
+		timeout := false
+		var evm *vm.EVM
+
+		for {
+			select {
+			case <-blockTimer.C:
+				timeout = true
+				atomic.StoreInt32(&t.abort, 1)
+
+			case <-t.chDone:
+				// Everything is done. Stop this goroutine.
+				return
+
+			case evm = <-t.chEVM:
+			}
+
+			if timeout && evm != nil {
+				// Allow the first transaction to complete although it exceeds the time limit.
+				if env.tcount > 0 {
+					// The total time limit reached, thus we stop the currently running EVM.
+					evm.Cancel(vm.CancelByTotalTimeLimit)
+				}
+				evm = nil
+			}
+		}
+	}()
+	return t
+}
+
+func (t *execTimer) aborted() bool {
+	return atomic.LoadInt32(&t.abort) == 1
+}
+
+func (t *execTimer) stop() {
+	t.chDone <- true
+}
+
+// ApplyBundles applies the bundles targeting the block before the other
+// transactions in their arrival order. A bundle is applied only if all of its
+// transactions succeed in the order, so the state of the task is replaced with
+// the one before the bundle if any of them fails. The failed bundles are dropped
+// from the pool only if they can't be applied to the later blocks either, and
+// the others, e.g. the ones with future nonces or reverted transactions, are
+// kept to be retried. The bundles may use at most half of the time to apply the
+// transactions and maxBundleTxsPerBlock transactions, leaving the rest to the
+// transactions of the pool.
+func (env *Task) ApplyBundles(pool *blockchain.BundlePool, bc BlockChain, rewardbase common.Address) []*types.Log {
+	var (
+		coalescedLogs []*types.Log
+		numTxs        int
+	)
+	timer := env.startExecTimer(env.remainingTime() / 2)
+	vmConfig := &vm.Config{
+		RunningEVM: timer.chEVM,
+	}
+
+	for _, bundle := range pool.Pending(env.header.Number.Uint64()) {
+		if timer.aborted() {
+			break
+		}
+		if numTxs+len(bundle.Txs) > maxBundleTxsPerBlock {
+			continue
+		}
+		numTxs += len(bundle.Txs)
+
+		logs, err := env.commitBundle(bundle, bc, rewardbase, vmConfig)
+		if err != nil {
+			failedBundlesCounter.Inc(1)
+			if bundle.MaxBlock <= env.header.Number.Uint64() || isPermanentBundleError(err) {
+				logger.Trace("Dropping failed bundle", "hash", bundle.Hash(), "err", err)
+				pool.Remove(bundle.Hash())
+			} else {
+				logger.Trace("Skipping failed bundle", "hash", bundle.Hash(), "err", err)
+			}
+			if err == vm.ErrTotalTimeLimitReached {
+				timeLimitReachedCounter.Inc(1)
+				break
+			}
+			continue
+		}
+		committedBundlesCounter.Inc(1)
+		coalescedLogs = append(coalescedLogs, logs...)
+	}
+
+	// Stop the goroutine that has been handling the timer.
+	timer.stop()
+
+	return coalescedLogs
+}
+
+// isPermanentBundleError reports whether a bundle failed with the error can't
+// be applied to the later blocks either.
+func isPermanentBundleError(err error) bool {
+	return errors.Is(err, blockchain.ErrNonceTooLow) || errors.Is(err, blockchain.ErrInvalidSender)
+}
+
+// commitBundle applies the transactions of the bundle in the order. If any of
+// them fails or is reverted, the task is rolled back to the state before the bundle.
+func (env *Task) commitBundle(bundle *blockchain.Bundle, bc BlockChain, rewardbase common.Address, vmConfig *vm.Config) ([]*types.Log, error) {
+	var (
+		// The state can't be reverted to a snapshot once a transaction is finalised,
+		// so a copy is kept to roll back the bundle.
+		backup  = env.state.Copy()
+		gasUsed = env.header.GasUsed
+		numTxs  = len(env.txs)
+		tcount  = env.tcount
+		logs    []*types.Log
+	)
+	for _, tx := range bundle.Txs {
+		env.state.SetTxContext(tx.Hash(), common.Hash{}, env.tcount)
+
+		// The signature is checked in advance to tell the invalid ones from the
+		// other errors of the transaction.
+		var (
+			receipt *types.Receipt
+			err     error
+		)
+		if _, err = tx.ValidateSender(env.signer, env.state, env.header.Number.Uint64()); err != nil {
+			err = fmt.Errorf("%w: %v", blockchain.ErrInvalidSender, err)
+		} else {
+			receipt, _, err = bc.ApplyTransaction(env.config, &rewardbase, env.state, env.header, tx, &env.header.GasUsed, vmConfig)
+		}
+		if err == nil && receipt.Status != types.ReceiptStatusSuccessful {
+			err = fmt.Errorf("%w: %v", errBundleTxFailed, tx.Hash().String())
+		}
+		if err != nil {
+			env.state = backup
+			env.header.GasUsed = gasUsed
+			env.txs, env.receipts, env.tcount = env.txs[:numTxs], env.receipts[:numTxs], tcount
+			return nil, err
+		}
+		env.txs = append(env.txs, tx)
+		env.receipts = append(env.receipts, receipt)
+		env.tcount++
+		logs = append(logs, receipt.Logs...)
+	}
+	return logs, nil
+}
+
 func (env *Task) commitTransaction(tx *types.Transaction, bc BlockChain, rewardbase common.Address, vmConfig *vm.Config) (error, []*types.Log) {
 	snap := env.state.Snapshot()
 
@@ -837,3 +997,4 @@ func NewTask(config *params.ChainConfig, signer types.Signer, statedb *state.Sta
 
 func (env *Task) Transactions() []*types.Transaction { return env.txs }
 func (env *Task) Receipts() []*types.Receipt         { return env.receipts }
+func (env *Task) State() *state.StateDB              { return env.state }